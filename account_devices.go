@@ -0,0 +1,120 @@
+package nakama
+
+import (
+	"slices"
+
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// The length limits of a device id, checked by the server too.
+const (
+	DeviceIdMinLength = 10
+	DeviceIdMaxLength = 128
+)
+
+var (
+	// ErrDeviceIdInvalid is returned when a device id is out of the DeviceIdMinLength-DeviceIdMaxLength bytes.
+	ErrDeviceIdInvalid = newError("device id invalid, must be 10-128 bytes")
+	// ErrDeviceNotLinked is returned when unlinking a device not linked to the account.
+	ErrDeviceNotLinked = newError("device not linked to the account")
+	// ErrLastAuthMethod is returned when unlinking the last way to authenticate the account,
+	// link another device or a social profile first.
+	ErrLastAuthMethod = newError("cannot unlink the last authentication method of the account")
+)
+
+// AccountDevices are the devices linked to an account.
+type AccountDevices []*api.AccountDevice
+
+// DevicesOf returns the devices linked to the account.
+func DevicesOf(account *api.Account) AccountDevices {
+	return AccountDevices(account.GetDevices())
+}
+
+// Ids returns the ids of the devices.
+func (d AccountDevices) Ids() []string {
+	ids := make([]string, 0, len(d))
+	for _, device := range d {
+		ids = append(ids, device.GetId())
+	}
+	return ids
+}
+
+// Contains reports whether the device id is linked.
+func (d AccountDevices) Contains(id string) bool {
+	return slices.ContainsFunc(d, func(device *api.AccountDevice) bool { return device.GetId() == id })
+}
+
+// ValidateDeviceId checks the device id like the server does, it returns ErrDeviceIdInvalid.
+func ValidateDeviceId(id string) error {
+	if len(id) < DeviceIdMinLength || len(id) > DeviceIdMaxLength {
+		return ErrDeviceIdInvalid.With(len(id))
+	}
+	return nil
+}
+
+// authMethods counts the ways to authenticate the account: the devices, the email, the custom id and the social profiles.
+func authMethods(account *api.Account) int {
+	n := len(account.GetDevices())
+	user := account.GetUser()
+	for _, id := range []string{
+		account.GetEmail(), account.GetCustomId(),
+		user.GetFacebookId(), user.GetFacebookInstantGameId(), user.GetGoogleId(),
+		user.GetGamecenterId(), user.GetSteamId(), user.GetAppleId(),
+	} {
+		if id != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// checkUnlinkDevice checks the device can be unlinked from the account.
+func checkUnlinkDevice(account *api.Account, id string) error {
+	if !DevicesOf(account).Contains(id) {
+		return ErrDeviceNotLinked.With(id)
+	}
+	if authMethods(account) <= 1 {
+		return ErrLastAuthMethod.With(id)
+	}
+	return nil
+}
+
+// ListDevices returns the devices linked to the account of the current user.
+func (c *Client) ListDevices(session *Session) (AccountDevices, error) {
+	account, err := c.GetAccount(session)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return DevicesOf(account), nil
+}
+
+// LinkDeviceId validates the device id and links it to the account of the current user.
+func (c *Client) LinkDeviceId(session *Session, id string, vars map[string]string) error {
+	if err := ValidateDeviceId(id); err != nil {
+		return wrapErr(err)
+	}
+	if err := c.LinkDevice(session, &api.AccountDevice{Id: id, Vars: vars}); err != nil {
+		return wrapErr(err, id)
+	}
+	return nil
+}
+
+// UnlinkDeviceId unlinks the device id from the account of the current user.
+// It fetches the account first, and returns ErrDeviceNotLinked or ErrLastAuthMethod without calling the server
+// when the device isn't linked or is the last way to authenticate the account.
+func (c *Client) UnlinkDeviceId(session *Session, id string) error {
+	if err := ValidateDeviceId(id); err != nil {
+		return wrapErr(err)
+	}
+	account, err := c.GetAccount(session)
+	if err != nil {
+		return wrapErr(err)
+	}
+	if err := checkUnlinkDevice(account, id); err != nil {
+		return wrapErr(err)
+	}
+	if err := c.UnlinkDevice(session, &api.AccountDevice{Id: id}); err != nil {
+		return wrapErr(err, id)
+	}
+	return nil
+}
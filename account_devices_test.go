@@ -0,0 +1,28 @@
+package nakama
+
+import (
+	"errors"
+	"testing"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountDevices(t *testing.T) {
+	assert.True(t, errors.Is(ValidateDeviceId("short"), ErrDeviceIdInvalid))
+	assert.NoError(t, ValidateDeviceId("376C007D-260F-579B-BD75-A3CBBFC2EF99"))
+
+	account := &api.Account{
+		User:    &api.User{},
+		Devices: []*api.AccountDevice{{Id: "device-0001"}},
+	}
+	devices := DevicesOf(account)
+	assert.Equal(t, []string{"device-0001"}, devices.Ids())
+	assert.True(t, devices.Contains("device-0001"))
+
+	assert.True(t, errors.Is(checkUnlinkDevice(account, "device-0002"), ErrDeviceNotLinked))
+	assert.True(t, errors.Is(checkUnlinkDevice(account, "device-0001"), ErrLastAuthMethod))
+
+	account.User.SteamId = "steam"
+	assert.NoError(t, checkUnlinkDevice(account, "device-0001"))
+}
@@ -0,0 +1,59 @@
+package nakama
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// DefaultAccountMetadataRpcId is the rpc id used by UpdateAccountMetadata when none is set.
+// The user metadata is only writable by the server, the rpc is expected to call nk.AccountUpdateId
+// with the metadata received in the payload.
+const DefaultAccountMetadataRpcId = "account_metadata_update"
+
+// GetAccountMetadata fetches the account of the current user and decodes its metadata into a T.
+// A zero T is returned when the account has no metadata.
+func GetAccountMetadata[T any](ctx context.Context, client *Client, session *Session) (*T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrapErr(err)
+	}
+	account, err := client.GetAccount(session)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	result := new(T)
+	metadata := account.GetUser().GetMetadata()
+	if metadata == "" {
+		return result, nil
+	}
+	if err := DecodeJSON([]byte(metadata), result); err != nil {
+		return nil, wrapErr(err, metadata)
+	}
+	return result, nil
+}
+
+// UpdateAccountMetadata sends the metadata to the rpc updating the account of the current user,
+// rpcId defaults to DefaultAccountMetadataRpcId when empty.
+func UpdateAccountMetadata[T any](ctx context.Context, client *Client, session *Session, rpcId string, metadata *T) error {
+	if err := ctx.Err(); err != nil {
+		return wrapErr(err)
+	}
+	if metadata == nil {
+		return newError("'metadata' is a required parameter but is null")
+	}
+	if rpcId == "" {
+		rpcId = DefaultAccountMetadataRpcId
+	}
+	if err := client.refreshSession(session); err != nil {
+		return wrapErr(err)
+	}
+
+	payload, err := json.Marshal(metadata)
+	if err != nil {
+		return wrapErr(err)
+	}
+	if _, err := client.apiFor(session).RpcFunc(session.Token, rpcId, string(payload), "", make(map[string]string)); err != nil {
+		return wrapErr(err, rpcId)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package nakama
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMetadata struct {
+	Level int    `json:"level"`
+	Title string `json:"title"`
+}
+
+func TestAccountMetadata(t *testing.T) {
+	metadata := `{"level":3,"title":"knight"}`
+	updated := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/account":
+			data, _ := json.Marshal(map[string]any{"user": map[string]any{"id": "u1", "metadata": metadata}})
+			w.Write(data)
+		case "/v2/rpc/" + DefaultAccountMetadataRpcId:
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &updated)
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+	ctx := context.Background()
+
+	got, err := GetAccountMetadata[testMetadata](ctx, client, session)
+	assert.NoError(t, err)
+	assert.Equal(t, &testMetadata{Level: 3, Title: "knight"}, got)
+
+	metadata = ""
+	got, err = GetAccountMetadata[testMetadata](ctx, client, session)
+	assert.NoError(t, err)
+	assert.Equal(t, &testMetadata{}, got, "no metadata is a zero value")
+
+	metadata = "not json"
+	_, err = GetAccountMetadata[testMetadata](ctx, client, session)
+	assert.Error(t, err)
+
+	assert.NoError(t, UpdateAccountMetadata(ctx, client, session, "", &testMetadata{Level: 4}))
+	assert.JSONEq(t, `{"level":4,"title":""}`, updated)
+	assert.Error(t, UpdateAccountMetadata[testMetadata](ctx, client, session, "", nil))
+	assert.Error(t, UpdateAccountMetadata(ctx, client, session, "missing", &testMetadata{}))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = GetAccountMetadata[testMetadata](canceled, client, session)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,129 @@
+package nakama
+
+import (
+	"encoding/json"
+)
+
+// The default ids of the rpcs of the account sessions, see WithAccountSessionRpcs.
+const (
+	DefaultAccountSessionsListRpc   = "account_sessions_list"
+	DefaultAccountSessionsRevokeRpc = "account_sessions_revoke"
+)
+
+// ErrAccountSessionsUnsupported is returned by the account session calls when the server doesn't register their rpcs.
+var ErrAccountSessionsUnsupported = newError("account sessions unsupported by the server")
+
+// AccountSessionRpcs are the ids of the rpcs listing and revoking the sessions of the user. The public API of Nakama
+// can only log out the current session, the server registers them in its runtime with this contract:
+//
+//   - List gets {} and returns {"sessions": [...]}, the active refresh tokens of the caller as AccountSession,
+//     the id is the "tid" claim of the tokens, e.g. tracked by the after hooks of the authentications and refreshes.
+//   - Revoke gets {"session_ids"} and logs them out, e.g. with SessionLogout of the Go runtime,
+//     the ids of other users are ignored.
+type AccountSessionRpcs struct {
+	List   string
+	Revoke string
+}
+
+// WithAccountSessionRpcs sets the ids of the rpcs of the account sessions when they're not the default ones.
+func WithAccountSessionRpcs(list, revoke string) ClientOption {
+	return func(opts *ClientOptions) error {
+		if list == "" || revoke == "" {
+			return newError("invalid account session rpcs").With(list, revoke)
+		}
+		opts.AccountSessionRpcs = &AccountSessionRpcs{List: list, Revoke: revoke}
+		return nil
+	}
+}
+
+// AccountSession is an active session of the user, e.g. on another device. The times are in unix seconds.
+type AccountSession struct {
+	Id         string            `json:"id"`
+	DeviceId   string            `json:"device_id,omitempty"` // the device id, when the session authenticated with one
+	CreatedAt  int64             `json:"created_at"`
+	ExpiresAt  int64             `json:"expires_at"`             // the expiry of the refresh token
+	LastUsedAt int64             `json:"last_used_at,omitempty"` // the last authentication or refresh
+	Vars       map[string]string `json:"vars,omitempty"`
+	Current    bool              `json:"-"` // the session of the caller, set by the client
+}
+
+// accountSessionsRequest is the payload of the rpcs of the account sessions.
+type accountSessionsRequest struct {
+	SessionIds []string `json:"session_ids,omitempty"`
+}
+
+// accountSessionsResponse is the payload returned by the list rpc.
+type accountSessionsResponse struct {
+	Sessions []*AccountSession `json:"sessions"`
+}
+
+// TokenId returns the id of the session, the "tid" claim of its token, empty if the server doesn't set it.
+func (s *Session) TokenId() string {
+	claims, err := s.decodeJWT(s.Token)
+	if err != nil {
+		return ""
+	}
+	id, _ := claims["tid"].(string)
+	return id
+}
+
+// ListAccountSessions lists the active sessions of the user with the list rpc of AccountSessionRpcs,
+// the session of the caller has Current set.
+func (c *Client) ListAccountSessions(session *Session) ([]*AccountSession, error) {
+	rpc, err := c.capabilityRpc(session, CapabilityAccountSessions, c.accountSessionRpcs.List, &accountSessionsRequest{})
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	response := &accountSessionsResponse{}
+	if rpc.GetPayload() != "" {
+		if err := json.Unmarshal([]byte(rpc.GetPayload()), response); err != nil {
+			return nil, wrapErr(err, rpc.GetPayload())
+		}
+	}
+	current := session.TokenId()
+	sessions := make([]*AccountSession, 0, len(response.Sessions))
+	for _, s := range response.Sessions {
+		if s == nil {
+			continue
+		}
+		s.Current = current != "" && s.Id == current
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+// RevokeAccountSessions logs out the sessions of the user with the revoke rpc of AccountSessionRpcs,
+// their refresh tokens can't be used anymore.
+func (c *Client) RevokeAccountSessions(session *Session, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := c.capabilityRpc(session, CapabilityAccountSessions, c.accountSessionRpcs.Revoke, &accountSessionsRequest{SessionIds: ids}); err != nil {
+		return wrapErr(err)
+	}
+	return nil
+}
+
+// LogoutOtherDevices revokes all the sessions of the user but the current one, it returns the revoked sessions.
+// The current session can't be told apart when its token has no id, nothing is revoked then.
+func (c *Client) LogoutOtherDevices(session *Session) ([]*AccountSession, error) {
+	if session.TokenId() == "" {
+		return nil, newError("session token without id, the current session is unknown")
+	}
+	sessions, err := c.ListAccountSessions(session)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	others := []*AccountSession{}
+	ids := []string{}
+	for _, s := range sessions {
+		if !s.Current {
+			others = append(others, s)
+			ids = append(ids, s.Id)
+		}
+	}
+	if err := c.RevokeAccountSessions(session, ids); err != nil {
+		return nil, wrapErr(err)
+	}
+	return others, nil
+}
@@ -0,0 +1,76 @@
+package nakama
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestLogoutOtherDevices(t *testing.T) {
+	var revoked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		payload := ""
+		json.Unmarshal(body, &payload)
+		request := accountSessionsRequest{}
+		assert.NoError(t, json.Unmarshal([]byte(payload), &request))
+
+		rpc := &api.Rpc{}
+		switch r.URL.Path {
+		case "/v2/rpc/account_sessions_list":
+			rpc.Payload = `{"sessions":[
+				{"id":"t1","device_id":"phone-0123456","created_at":100,"expires_at":200},
+				{"id":"t2","created_at":150,"expires_at":250,"vars":{"platform":"pc"}},
+				{"id":"t3","created_at":160,"expires_at":260}]}`
+		case "/v2/rpc/account_sessions_revoke":
+			revoked = request.SessionIds
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		data, _ := protojson.Marshal(rpc)
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	assert.NoError(t, err)
+	claims, _ := json.Marshal(map[string]any{"exp": time.Now().Add(time.Hour).Unix(), "uid": "user", "tid": "t2"})
+	session := NewSession("header."+base64.URLEncoding.EncodeToString(claims)+".signature", "", false)
+	assert.Equal(t, "t2", session.TokenId())
+
+	sessions, err := client.ListAccountSessions(session)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 3)
+	assert.Equal(t, "phone-0123456", sessions[0].DeviceId)
+	assert.True(t, sessions[1].Current)
+	assert.Equal(t, "pc", sessions[1].Vars["platform"])
+
+	others, err := client.LogoutOtherDevices(session)
+	assert.NoError(t, err)
+	assert.Len(t, others, 2)
+	assert.Equal(t, []string{"t1", "t3"}, revoked)
+
+	// the current session is unknown without a token id
+	_, err = client.LogoutOtherDevices(NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false))
+	assert.Error(t, err)
+
+	// a server without the rpcs
+	older := httptest.NewServer(http.HandlerFunc(http.NotFound))
+	defer older.Close()
+	client, err = NewClientWithOptions(WithURL(older.URL))
+	assert.NoError(t, err)
+	_, err = client.ListAccountSessions(session)
+	assert.True(t, errors.Is(err, ErrAccountSessionsUnsupported))
+	assert.False(t, client.Supports(CapabilityAccountSessions))
+	assert.True(t, errors.Is(client.RevokeAccountSessions(session, []string{"t1"}), ErrAccountSessionsUnsupported))
+}
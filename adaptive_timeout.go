@@ -0,0 +1,85 @@
+package nakama
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// AdaptiveTimeout defaults
+const (
+	DefaultAdaptivePercentile = 0.95
+	DefaultAdaptiveFactor     = 3
+	DefaultAdaptiveWindow     = 100
+	DefaultAdaptiveMinSamples = 10
+)
+
+// AdaptiveTimeout scales the timeout of the calls with the recent latencies, instead of the fixed TimeoutMs:
+// the Percentile of the last Window latencies × Factor, bounded by Min and Max.
+// The calls fail fast while the server is fast, and wait longer while it's temporarily slow.
+// Max is used until MinSamples latencies are observed.
+type AdaptiveTimeout struct {
+	Min        time.Duration
+	Max        time.Duration
+	Percentile float64 // in (0, 1]
+	Factor     float64
+	Window     int
+	MinSamples int
+
+	mu      sync.Mutex
+	samples []time.Duration // ring of the last Window latencies
+	next    int
+}
+
+// NewAdaptiveTimeout creates an AdaptiveTimeout bounded by min and max with the default settings.
+func NewAdaptiveTimeout(min, max time.Duration) *AdaptiveTimeout {
+	return &AdaptiveTimeout{
+		Min:        min,
+		Max:        max,
+		Percentile: DefaultAdaptivePercentile,
+		Factor:     DefaultAdaptiveFactor,
+		Window:     DefaultAdaptiveWindow,
+		MinSamples: DefaultAdaptiveMinSamples,
+	}
+}
+
+// Observe records the latency of an attempt, a timed out attempt records the time waited.
+func (a *AdaptiveTimeout) Observe(latency time.Duration) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	window := max(a.Window, 1)
+	if len(a.samples) < window {
+		a.samples = append(a.samples, latency)
+		return
+	}
+	a.samples[a.next%window] = latency
+	a.next = (a.next + 1) % window
+}
+
+// Timeout returns the timeout of the next call.
+func (a *AdaptiveTimeout) Timeout() time.Duration {
+	a.mu.Lock()
+	if len(a.samples) == 0 || len(a.samples) < a.MinSamples {
+		a.mu.Unlock()
+		return a.Max
+	}
+	sorted := slices.Clone(a.samples)
+	a.mu.Unlock()
+
+	slices.Sort(sorted)
+	percentile := min(max(a.Percentile, 0), 1)
+	i := min(int(float64(len(sorted))*percentile), len(sorted)-1)
+	timeout := time.Duration(float64(sorted[i]) * a.Factor)
+	return min(max(timeout, a.Min), a.Max)
+}
+
+// WithAdaptiveTimeout scales the timeout of the calls with the recent latencies, it replaces WithTimeout.
+func WithAdaptiveTimeout(timeout *AdaptiveTimeout) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.AdaptiveTimeout = timeout
+		return nil
+	}
+}
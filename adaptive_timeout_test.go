@@ -0,0 +1,60 @@
+package nakama
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptiveTimeout(t *testing.T) {
+	timeout := NewAdaptiveTimeout(50*time.Millisecond, time.Second)
+	assert.Equal(t, time.Second, timeout.Timeout(), "max until enough samples")
+
+	for i := 0; i < 20; i++ {
+		timeout.Observe(10 * time.Millisecond)
+	}
+	assert.Equal(t, 50*time.Millisecond, timeout.Timeout(), "bounded by min")
+
+	for i := 0; i < 10; i++ {
+		timeout.Observe(100 * time.Millisecond)
+	}
+	assert.Equal(t, 300*time.Millisecond, timeout.Timeout())
+
+	// the old samples leave the window
+	for i := 0; i < DefaultAdaptiveWindow; i++ {
+		timeout.Observe(time.Second)
+	}
+	assert.Equal(t, time.Second, timeout.Timeout(), "bounded by max")
+}
+
+func TestAdaptiveTimeoutCalls(t *testing.T) {
+	var slow atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slow.Load() {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(`{"friends":[]}`))
+	}))
+	defer server.Close()
+
+	timeout := NewAdaptiveTimeout(20*time.Millisecond, 2*time.Second)
+	client, err := NewClientWithOptions(WithURL(server.URL), WithAdaptiveTimeout(timeout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := "token"
+	for i := 0; i < DefaultAdaptiveMinSamples; i++ {
+		_, err := client.ApiClient.ListFriends(&token, nil, nil, nil, nil)
+		assert.NoError(t, err)
+	}
+	assert.Less(t, timeout.Timeout(), 200*time.Millisecond)
+
+	// a slow server fails fast while the latencies are low
+	slow.Store(true)
+	_, err = client.ApiClient.ListFriends(&token, nil, nil, nil, nil)
+	assert.Error(t, err)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,26 +14,86 @@ import (
 	"strings"
 	"time"
 
-	"github.com/gwaylib/errors"
+	"github.com/NorthNorthGames/nakama-go/backoff"
+	logproto "github.com/gwaylib/log/proto"
 	api "github.com/heroiclabs/nakama-common/api"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
 
 var (
-	ErrNoContent = errors.New("No content by 204")
+	ErrNoContent = newError("No content by 204")
 )
 
+// responseUnmarshal ignores the fields unknown to nakama-common, e.g. the fields added by a newer server.
+var responseUnmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// DefaultMaxIdleConnsPerHost is the number of idle connections to the server kept by NewHttpTransport,
+// http.DefaultTransport keeps 2 so the concurrent calls open new connections.
+const DefaultMaxIdleConnsPerHost = 32
+
+// NewHttpTransport returns a transport with the pooled defaults of the client, to wrap or to adjust before WithHTTPTransport,
+// e.g. to set a proxy.
+func NewHttpTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	return transport
+}
+
+// defaultHttpClient is shared by the api clients without HttpClient, so the connections are reused.
+var defaultHttpClient = &http.Client{Transport: NewHttpTransport()}
+
 type NakamaApi struct {
 	ServerKey string
 	BasePath  string
-	TimeoutMs int // need set a validate value
+	TimeoutMs int             // the timeout of a call including its retries, need set a validate value
+	Clock     *ServerClock    // optional, fed by the Date header of the responses
+	Stats     *ClientStats    // optional, counts the calls and keeps the recent errors
+	Limiter   *RequestLimiter // optional, bounds the requests in flight, see WithMaxConcurrentRequests
+
+	RetryPolicy      RetryPolicy      // retries of the transient failures, no retry by default
+	AttemptTimeoutMs int              // optional, the timeout of each attempt, bounded by the remaining TimeoutMs
+	AdaptiveTimeout  *AdaptiveTimeout // optional, replaces TimeoutMs and observes the latencies
+	StreamBodyBytes  int              // optional, the storage writes of more value bytes are streamed, see WithStorageStreaming
+	// optional, endpoint:timeout replacing TimeoutMs, AdaptiveTimeout and AttemptTimeoutMs for the endpoint, see WithEndpointTimeouts
+	EndpointTimeoutMs map[string]int
+	HttpClient        *http.Client    // optional, a shared http.Client is used when nil
+	Logger            logproto.Logger // optional, the package logger is used when nil
+	// optional, returns a new bearer token when the server rejects the token of a call with a 401,
+	// the call is then sent once more with it unless it's "". ctx is the context of the call.
+	OnUnauthorized func(ctx context.Context, rejected string) (string, error)
+
+	responseInfo *ResponseInfo   // set by WithResponseInfo
+	ctx          context.Context // set by WithContext
+	middlewares  []Middleware    // set by Use
+}
+
+// ResponseInfo is the metadata of the last http response of a call.
+type ResponseInfo struct {
+	StatusCode int
+	Header     http.Header   // e.g. the rate-limit headers or the request id set by a gateway
+	Duration   time.Duration // the time spent in all the attempts
+	Attempts   int
+}
+
+// WithContext returns a copy of the api client bounding its calls by ctx, on top of TimeoutMs.
+func (napi *NakamaApi) WithContext(ctx context.Context) NakamaApiInterface {
+	clone := *napi
+	clone.ctx = ctx
+	return &clone
+}
+
+// WithResponseInfo returns a copy of the api client filling info on each call.
+func (napi *NakamaApi) WithResponseInfo(info *ResponseInfo) NakamaApiInterface {
+	clone := *napi
+	clone.responseInfo = info
+	return &clone
 }
 
 func (napi NakamaApi) SetBasicAuth(req *http.Request, username, passwd string) {
-	if checkStr(&username) {
+	if username != "" {
 		auth := username + ":"
-		if checkStr(&passwd) {
+		if passwd != "" {
 			auth += passwd
 		}
 		encodedAuth := base64.StdEncoding.EncodeToString([]byte(auth))
@@ -40,50 +101,227 @@ func (napi NakamaApi) SetBasicAuth(req *http.Request, username, passwd string) {
 	}
 }
 
-func (napi *NakamaApi) doReq(bearerToken string, req *http.Request, options map[string]string, rsp proto.Message) error {
-	if checkStr(&bearerToken) {
+func (napi *NakamaApi) logger() logproto.Logger {
+	if napi.Logger != nil {
+		return napi.Logger
+	}
+	return GetLogger()
+}
+
+// doReq sends the request of the endpoint, the NakamaApi method name, see EndpointFromContext.
+func (napi *NakamaApi) doReq(endpoint, bearerToken string, req *http.Request, options map[string]string, rsp proto.Message) (err error) {
+	if bearerToken != "" {
 		req.Header.Set("Authorization", "Bearer "+bearerToken)
 	}
 	// Apply additional custom headers or options if needed
 	for key, value := range options {
 		req.Header.Set(key, value)
 	}
+	defer func() {
+		napi.Stats.record(req, err)
+	}()
+	if info := napi.responseInfo; info != nil {
+		*info = ResponseInfo{}
+		startTime := time.Now()
+		defer func() {
+			info.Duration = time.Since(startTime)
+		}()
+	}
+
+	// the deadline of the call is shared by all its attempts
+	ctx := napi.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = context.WithValue(ctx, endpointContextKey, endpoint)
+	if timeoutMs, ok := napi.EndpointTimeoutMs[endpoint]; ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+		defer cancel()
+	} else if napi.AdaptiveTimeout != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, napi.AdaptiveTimeout.Timeout())
+		defer cancel()
+	} else if napi.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(napi.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	err = napi.doAttempts(ctx, req, rsp)
+	if bearerToken == "" || napi.OnUnauthorized == nil || httpStatusOf(err) != http.StatusUnauthorized {
+		return err
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// a streamed body can't be sent again
+		return err
+	}
+	// the token may have been revoked before its expiry, e.g. by a logout on another device
+	token, refreshErr := napi.OnUnauthorized(ctx, bearerToken)
+	if refreshErr != nil {
+		napi.logger().Warn(wrapErr(refreshErr, "session refresh after 401 failed"))
+		return err
+	}
+	if token == "" {
+		return err
+	}
+	if rewindErr := rewindBody(req); rewindErr != nil {
+		return rewindErr
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return napi.doAttempts(ctx, req, rsp)
+}
+
+// doAttempts sends the request with the retries of RetryPolicy, a *RetryError is returned when the last of
+// several attempts fails with a transient error.
+func (napi *NakamaApi) doAttempts(ctx context.Context, req *http.Request, rsp proto.Message) error {
+	attempts := []RetryAttempt{}
+	for attempt := 1; ; attempt++ {
+		startTime := time.Now()
+		retryable, err := napi.doOnce(context.WithValue(ctx, attemptContextKey, attempt), req, rsp)
+		if err == nil || !retryable {
+			return err
+		}
+		attempts = append(attempts, RetryAttempt{StatusCode: httpStatusOf(err), Duration: time.Since(startTime), Err: err})
+		if attempt >= napi.RetryPolicy.MaxAttempts {
+			return retryErrorOf(attempts)
+		}
+		delay := napi.RetryPolicy.delay(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			// no budget left for another attempt
+			return retryErrorOf(attempts)
+		}
+		if backoff.Sleep(ctx, delay) != nil {
+			return retryErrorOf(attempts)
+		}
+		if err := rewindBody(req); err != nil {
+			return err
+		}
+	}
+}
+
+// retryErrorOf returns the error of the attempts of a call ending on a transient error,
+// the error of the attempt as it is when it's the only one.
+func retryErrorOf(attempts []RetryAttempt) error {
+	if len(attempts) == 1 {
+		return attempts[0].Err
+	}
+	retryErr := &RetryError{Attempts: attempts}
+	httpErr := &HTTPError{}
+	if errors.As(attempts[len(attempts)-1].Err, &httpErr) {
+		retryErr.RetryAfter = httpErr.RetryAfter
+	}
+	return retryErr
+}
+
+// rewindBody rewinds the body of the request for another attempt.
+func rewindBody(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return wrapErr(err)
+	}
+	req.Body = body
+	return nil
+}
+
+// doOnce sends the request once, retryable reports whether the error is transient.
+func (napi *NakamaApi) doOnce(ctx context.Context, req *http.Request, rsp proto.Message) (retryable bool, err error) {
+	if info := napi.responseInfo; info != nil {
+		info.Attempts++
+	}
+
+	// the wait for a slot isn't part of the attempt timeout
+	wait, err := napi.Limiter.acquire(ctx)
+	napi.Stats.recordWait(wait)
+	if err != nil {
+		return false, wrapErr(err)
+	}
+	defer napi.Limiter.release()
 
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
+	_, slow := napi.EndpointTimeoutMs[EndpointFromContext(ctx)]
+	if napi.AttemptTimeoutMs > 0 && !slow {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(napi.AttemptTimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
 
-	// Make the HTTP request
-	client := &http.Client{}
+	// Make the HTTP request, ctx bounds it without any goroutine
+	client := napi.HttpClient
+	if client == nil {
+		client = defaultHttpClient
+	}
 
-	// Run the HTTP request in a goroutine
-	resp, err := client.Do(req.WithContext(ctx))
+	startTime := time.Now()
+	resp, err := napi.roundTrip(client, req.WithContext(ctx))
+	if IsDebug() {
+		dumpHttp(napi.logger(), req, resp, time.Since(startTime), err)
+	}
+	if !slow && (err == nil || errors.Is(err, context.DeadlineExceeded)) {
+		// the fast transport failures, e.g. a refused connection, tell nothing about the latency,
+		// and the slow endpoints would stretch the timeout of the others
+		napi.AdaptiveTimeout.Observe(time.Since(startTime))
+	}
 	if err != nil {
-		return errors.As(err)
+		return true, wrapErr(err)
+	}
+	defer func() {
+		// the rest of the body is read so the connection is reused
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+		resp.Body.Close()
+	}()
+	napi.Clock.ObserveHttpDate(resp.Header.Get("Date"), startTime, time.Now())
+	if info := napi.responseInfo; info != nil {
+		info.StatusCode = resp.StatusCode
+		info.Header = resp.Header
 	}
-	defer resp.Body.Close()
 
 	// Handle HTTP response
 	if resp.StatusCode == http.StatusNoContent {
 		if rsp != nil {
-			return ErrNoContent.As(resp.StatusCode)
+			return false, ErrNoContent.With(resp.StatusCode)
 		}
-		return nil
+		return false, nil
 	} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
 		bodyBytes, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return errors.As(err, string(bodyBytes))
+			return true, wrapErr(err, string(bodyBytes))
 		}
 		if rsp == nil {
-			return nil
+			return false, nil
 		}
 
-		if err := protojson.Unmarshal(bodyBytes, rsp); err != nil {
-			return errors.As(err)
+		if err := responseUnmarshal.Unmarshal(bodyBytes, rsp); err != nil {
+			return false, wrapErr(err)
 		}
-		return nil
+		return false, nil
+	}
+	return retryableStatus(resp.StatusCode), &HTTPError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Message:    serverMessageOf(resp.Body),
+		RetryAfter: retryAfterHeader(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// maxDrainBytes is the most read from the rest of a response body to reuse its connection, a bigger body closes it.
+const maxDrainBytes = 64 << 10
+
+// serverMessageOf returns the message of an error response body like {"code":3,"message":"..."}.
+func serverMessageOf(body io.Reader) string {
+	data, err := io.ReadAll(io.LimitReader(body, 4096))
+	if err != nil {
+		return ""
 	}
-	return errors.New(resp.Status).As(resp.StatusCode)
+	rsp := struct {
+		Message string `json:"message"`
+	}{}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return string(data)
+	}
+	return rsp.Message
 }
 
 // Healthcheck is a healthcheck function that load balancers can use to check the service.
@@ -98,10 +336,10 @@ func (napi *NakamaApi) Healthcheck(bearerToken string, options map[string]string
 	// Prepare the HTTP request
 	req, err := http.NewRequest("GET", fullUrl, nil)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("Healthcheck", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -120,8 +358,8 @@ func (napi *NakamaApi) DeleteAccount(bearerToken string, options map[string]stri
 	if err != nil {
 		return err
 	}
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("DeleteAccount", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -141,8 +379,8 @@ func (napi *NakamaApi) GetAccount(bearerToken string, options map[string]string)
 		return nil, err
 	}
 	result := &api.Account{}
-	if err := napi.doReq(bearerToken, req, options, result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("GetAccount", bearerToken, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
 	return result, nil
 }
@@ -151,7 +389,7 @@ func (napi *NakamaApi) GetAccount(bearerToken string, options map[string]string)
 func (napi *NakamaApi) UpdateAccount(bearerToken string, body *api.UpdateAccountRequest, options map[string]string) error {
 	// Check if the body is nil
 	if body == nil {
-		return errors.New("'body' is a required parameter but is null or undefined")
+		return newError("'body' is a required parameter but is null or undefined")
 	}
 
 	// Define the URL path and query parameters
@@ -161,7 +399,7 @@ func (napi *NakamaApi) UpdateAccount(bearerToken string, body *api.UpdateAccount
 	// Convert the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -170,11 +408,11 @@ func (napi *NakamaApi) UpdateAccount(bearerToken string, body *api.UpdateAccount
 	// Prepare the HTTP request
 	req, err := http.NewRequest("PUT", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("UpdateAccount", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 
 	return nil
@@ -212,8 +450,8 @@ func (napi *NakamaApi) AuthenticateApple(basicAuthUsername string, basicAuthPass
 	napi.SetBasicAuth(req, basicAuthUsername, basicAuthPassword)
 
 	var result = &api.Session{}
-	if err := napi.doReq("", req, options, result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("AuthenticateApple", "", req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return result, nil
@@ -257,8 +495,8 @@ func (napi *NakamaApi) AuthenticateCustom(
 	// Set Basic Authorization header
 	napi.SetBasicAuth(req, basicAuthUsername, basicAuthPassword)
 	var result api.Session
-	if err := napi.doReq("", req, options, &result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("AuthenticateCustom", "", req, options, &result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return &result, nil
@@ -303,8 +541,8 @@ func (napi *NakamaApi) AuthenticateDevice(
 	napi.SetBasicAuth(req, basicAuthUsername, basicAuthPassword)
 
 	var result api.Session
-	if err := napi.doReq("", req, options, &result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("AuthenticateDevice", "", req, options, &result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return &result, nil
@@ -349,8 +587,8 @@ func (napi *NakamaApi) AuthenticateEmail(
 	napi.SetBasicAuth(req, basicAuthUsername, basicAuthPassword)
 
 	var result = &api.Session{}
-	if err := napi.doReq("", req, options, result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("AuthenticateEmail", "", req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return result, nil
@@ -398,8 +636,8 @@ func (napi *NakamaApi) AuthenticateFacebook(
 	// Set Basic Authorization header
 	napi.SetBasicAuth(req, basicAuthUsername, basicAuthPassword)
 	var result api.Session
-	if err := napi.doReq("", req, options, &result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("AuthenticateFacebook", "", req, options, &result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return &result, nil
@@ -444,8 +682,8 @@ func (napi *NakamaApi) AuthenticateFacebookInstantGame(
 	napi.SetBasicAuth(req, basicAuthUsername, basicAuthPassword)
 
 	var result api.Session
-	if err := napi.doReq("", req, options, &result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("AuthenticateFacebookInstantGame", "", req, options, &result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return &result, nil
@@ -490,8 +728,8 @@ func (napi *NakamaApi) AuthenticateGameCenter(
 	napi.SetBasicAuth(req, basicAuthUsername, basicAuthPassword)
 
 	var result api.Session
-	if err := napi.doReq("", req, options, &result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("AuthenticateGameCenter", "", req, options, &result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return &result, nil
@@ -534,8 +772,8 @@ func (napi *NakamaApi) AuthenticateGoogle(
 	napi.SetBasicAuth(req, basicAuthUsername, basicAuthPassword)
 
 	var result api.Session
-	if err := napi.doReq("", req, options, &result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("AuthenticateGoogle", "", req, options, &result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return &result, nil
@@ -568,7 +806,7 @@ func (napi *NakamaApi) AuthenticateSteam(
 	// Convert the account to JSON
 	bodyJson, err := json.Marshal(account)
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -577,7 +815,7 @@ func (napi *NakamaApi) AuthenticateSteam(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
@@ -585,8 +823,8 @@ func (napi *NakamaApi) AuthenticateSteam(
 	napi.SetBasicAuth(req, basicAuthUsername, basicAuthPassword)
 
 	var result api.Session
-	if err := napi.doReq("", req, options, &result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("AuthenticateSteam", "", req, options, &result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return &result, nil
@@ -605,7 +843,7 @@ func (napi *NakamaApi) LinkApple(
 	// Convert the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -614,11 +852,11 @@ func (napi *NakamaApi) LinkApple(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("LinkApple", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -636,7 +874,7 @@ func (napi *NakamaApi) LinkCustom(
 	// Convert the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -645,11 +883,11 @@ func (napi *NakamaApi) LinkCustom(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("LinkCustom", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -667,7 +905,7 @@ func (napi *NakamaApi) LinkDevice(
 	// Convert the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -676,11 +914,11 @@ func (napi *NakamaApi) LinkDevice(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("LinkDevice", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -698,7 +936,7 @@ func (napi *NakamaApi) LinkEmail(
 	// Convert the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -707,11 +945,11 @@ func (napi *NakamaApi) LinkEmail(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("LinkEmail", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -745,8 +983,8 @@ func (napi *NakamaApi) LinkFacebook(
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("LinkFacebook", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 
@@ -765,7 +1003,7 @@ func (napi *NakamaApi) LinkFacebookInstantGame(
 	// Convert the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -774,11 +1012,11 @@ func (napi *NakamaApi) LinkFacebookInstantGame(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("LinkFacebookInstantGame", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 
 	return nil
@@ -797,7 +1035,7 @@ func (napi *NakamaApi) LinkGameCenter(
 	// Convert the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -806,11 +1044,11 @@ func (napi *NakamaApi) LinkGameCenter(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("LinkGameCenter", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 
@@ -829,7 +1067,7 @@ func (napi *NakamaApi) LinkGoogle(
 	// Convert the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -838,11 +1076,11 @@ func (napi *NakamaApi) LinkGoogle(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("LinkGoogle", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -860,7 +1098,7 @@ func (napi *NakamaApi) LinkSteam(
 	// Convert the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -869,11 +1107,11 @@ func (napi *NakamaApi) LinkSteam(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("LinkSteam", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -908,57 +1146,14 @@ func (napi *NakamaApi) SessionRefresh(
 	// Set Basic Auth header
 	napi.SetBasicAuth(req, basicAuthUsername, basicAuthPassword)
 
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
-	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, err
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
+	result := &api.Session{}
+	if err := napi.doReq("SessionRefresh", "", req, options, result); err != nil {
+		if errors.Is(err, ErrNoContent) {
 			return nil, nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var result api.Session
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-			err = json.Unmarshal(bodyBytes, &result)
-			if err != nil {
-				return nil, err
-			}
-			return &result, nil
-		} else {
-			return nil, errors.New(resp.Status)
 		}
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 // UnlinkApple removes the Apple ID from the social profiles on the current user's account.
@@ -974,7 +1169,7 @@ func (napi *NakamaApi) UnlinkApple(
 	// Serialize the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -983,11 +1178,11 @@ func (napi *NakamaApi) UnlinkApple(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("UnlinkApple", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -1005,7 +1200,7 @@ func (napi *NakamaApi) UnlinkCustom(
 	// Serialize the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -1014,12 +1209,12 @@ func (napi *NakamaApi) UnlinkCustom(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("UnlinkCustom", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 
@@ -1038,7 +1233,7 @@ func (napi *NakamaApi) UnlinkDevice(
 	// Serialize the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -1047,11 +1242,11 @@ func (napi *NakamaApi) UnlinkDevice(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("UnlinkDevice", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -1069,7 +1264,7 @@ func (napi *NakamaApi) UnlinkEmail(
 	// Serialize the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -1078,11 +1273,11 @@ func (napi *NakamaApi) UnlinkEmail(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("UnlinkEmail", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -1100,7 +1295,7 @@ func (napi *NakamaApi) UnlinkFacebook(
 	// Serialize the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -1109,11 +1304,11 @@ func (napi *NakamaApi) UnlinkFacebook(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("UnlinkFacebook", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -1131,7 +1326,7 @@ func (napi *NakamaApi) UnlinkFacebookInstantGame(
 	// Serialize the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -1140,11 +1335,11 @@ func (napi *NakamaApi) UnlinkFacebookInstantGame(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("UnlinkFacebookInstantGame", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -1162,7 +1357,7 @@ func (napi *NakamaApi) UnlinkGameCenter(
 	// Serialize the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -1171,11 +1366,11 @@ func (napi *NakamaApi) UnlinkGameCenter(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("UnlinkGameCenter", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -1193,7 +1388,7 @@ func (napi *NakamaApi) UnlinkGoogle(
 	// Serialize the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -1202,11 +1397,11 @@ func (napi *NakamaApi) UnlinkGoogle(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("UnlinkGoogle", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -1224,7 +1419,7 @@ func (napi *NakamaApi) UnlinkSteam(
 	// Serialize the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -1233,11 +1428,11 @@ func (napi *NakamaApi) UnlinkSteam(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("UnlinkSteam", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -1251,12 +1446,12 @@ func (napi *NakamaApi) ListChannelMessages(
 	cursor *string,
 	options map[string]string,
 ) (*api.ChannelMessageList, error) {
-	if !checkStr(channelId) {
-		return nil, errors.New("'channelId' is a required parameter but is empty")
+	if isEmpty(channelId) {
+		return nil, newError("'channelId' is a required parameter but is empty")
 	}
 
 	// Define the URL path and query parameters
-	urlPath := strings.Replace("/v2/channel/{channelId}", "{channelId}", url.PathEscape(*channelId), 1)
+	urlPath := buildPath("/v2/channel/{channelId}", "channelId", *channelId)
 	queryParams := url.Values{}
 
 	if limit != nil {
@@ -1279,62 +1474,18 @@ func (napi *NakamaApi) ListChannelMessages(
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, err
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return &api.ChannelMessageList{}, nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var result api.ChannelMessageList
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-			err = json.Unmarshal(bodyBytes, &result)
-			if err != nil {
-				return nil, err
-			}
-			return &result, nil
-		} else {
-			return nil, errors.New(resp.Status)
+	result := &api.ChannelMessageList{}
+	if err := napi.doReq("ListChannelMessages", token, req, options, result); err != nil {
+		if errors.Is(err, ErrNoContent) {
+			return result, nil
 		}
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 // Event submits an event for processing in the server's registered runtime custom events handler.
@@ -1350,7 +1501,7 @@ func (napi *NakamaApi) Event(
 	// Serialize the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -1359,57 +1510,18 @@ func (napi *NakamaApi) Event(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if bearerToken != nil && *bearerToken != "" {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
-	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return errors.New("request timed out")
-	case err := <-errorChan:
-		return errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
-		} else {
-			return errors.New(resp.Status).As(resp.StatusCode)
-		}
+	if err := napi.doReq("Event", token, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
+	return nil
 }
 
 func (napi *NakamaApi) DeleteFriends(
@@ -1435,57 +1547,18 @@ func (napi *NakamaApi) DeleteFriends(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("DELETE", fullUrl, nil)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if bearerToken != nil && *bearerToken != "" {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return errors.New("request timed out")
-	case err := <-errorChan:
-		return errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
-		} else {
-			return errors.New(resp.Status)
-		}
+	if err := napi.doReq("DeleteFriends", token, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
+	return nil
 }
 
 // ListFriends fetches the list of all friends for the current user.
@@ -1520,62 +1593,18 @@ func (napi *NakamaApi) ListFriends(
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return &api.FriendList{}, errors.New("request timed out")
-	case err := <-errorChan:
-		return &api.FriendList{}, err
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return &api.FriendList{}, nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var result api.FriendList
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return &api.FriendList{}, err
-			}
-			err = json.Unmarshal(bodyBytes, &result)
-			if err != nil {
-				return &api.FriendList{}, err
-			}
-			return &result, nil
-		} else {
-			return &api.FriendList{}, errors.New(resp.Status)
+	result := &api.FriendList{}
+	if err := napi.doReq("ListFriends", token, req, options, result); err != nil {
+		if errors.Is(err, ErrNoContent) {
+			return result, nil
 		}
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 func (napi *NakamaApi) AddFriends(
@@ -1601,57 +1630,18 @@ func (napi *NakamaApi) AddFriends(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, nil)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if bearerToken == nil && *bearerToken != "" {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return errors.New("request timed out")
-	case err := <-errorChan:
-		return errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
-		} else {
-			return errors.New(resp.Status)
-		}
+	if err := napi.doReq("AddFriends", token, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
+	return nil
 }
 
 func (napi *NakamaApi) BlockFriends(
@@ -1677,57 +1667,18 @@ func (napi *NakamaApi) BlockFriends(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, nil)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if bearerToken != nil && *bearerToken != "" {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return errors.New("request timed out")
-	case err := <-errorChan:
-		return errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
-		} else {
-			return errors.New(resp.Status)
-		}
+	if err := napi.doReq("BlockFriends", token, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
+	return nil
 }
 
 func (napi *NakamaApi) ImportFacebookFriends(
@@ -1739,14 +1690,14 @@ func (napi *NakamaApi) ImportFacebookFriends(
 	// Define the URL path and query parameters
 	urlPath := "/v2/friend/facebook"
 	queryParams := url.Values{}
-	if checkBool(reset) {
+	if hasValue(reset) {
 		queryParams.Set("reset", strconv.FormatBool(*reset))
 	}
 
 	// Serialize the account object to JSON
 	bodyJson, err := json.Marshal(account)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -1755,57 +1706,18 @@ func (napi *NakamaApi) ImportFacebookFriends(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return errors.New("request timed out")
-	case err := <-errorChan:
-		return errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
-		} else {
-			return errors.New(resp.Status)
-		}
+	if err := napi.doReq("ImportFacebookFriends", token, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
+	return nil
 }
 
 func (napi *NakamaApi) ListFriendsOfFriends(
@@ -1835,62 +1747,18 @@ func (napi *NakamaApi) ListFriendsOfFriends(
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, err
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
+	result := &api.FriendsOfFriendsList{}
+	if err := napi.doReq("ListFriendsOfFriends", token, req, options, result); err != nil {
+		if errors.Is(err, ErrNoContent) {
 			return nil, nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var result api.FriendsOfFriendsList
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-			err = json.Unmarshal(bodyBytes, &result)
-			if err != nil {
-				return nil, err
-			}
-			return &result, nil
-		} else {
-			return nil, errors.New(resp.Status)
 		}
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 func (napi *NakamaApi) ImportSteamFriends(
@@ -1902,14 +1770,14 @@ func (napi *NakamaApi) ImportSteamFriends(
 	// Define the URL path and query parameters
 	urlPath := "/v2/friend/steam"
 	queryParams := url.Values{}
-	if checkBool(reset) {
+	if hasValue(reset) {
 		queryParams.Set("reset", strconv.FormatBool(*reset))
 	}
 
 	// Serialize the account object to JSON
 	bodyJson, err := json.Marshal(account)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -1918,57 +1786,18 @@ func (napi *NakamaApi) ImportSteamFriends(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return errors.New("request timed out")
-	case err := <-errorChan:
-		return errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
-		} else {
-			return errors.New(resp.Status)
-		}
+	if err := napi.doReq("ImportSteamFriends", token, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
+	return nil
 }
 
 func (napi *NakamaApi) ListGroups(
@@ -2014,62 +1843,18 @@ func (napi *NakamaApi) ListGroups(
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, err
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
+	result := &api.GroupList{}
+	if err := napi.doReq("ListGroups", token, req, options, result); err != nil {
+		if errors.Is(err, ErrNoContent) {
 			return nil, nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var result *api.GroupList
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-			err = json.Unmarshal(bodyBytes, &result)
-			if err != nil {
-				return nil, err
-			}
-			return result, nil
-		} else {
-			return nil, errors.New(resp.Status)
 		}
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 // CreateGroup creates a new group with the current user as the owner.
@@ -2085,7 +1870,7 @@ func (napi *NakamaApi) CreateGroup(
 	// Serialize the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -2094,65 +1879,19 @@ func (napi *NakamaApi) CreateGroup(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if bearerToken != nil && *bearerToken != "" {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- errors.As(err)
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil, ErrNoContent.As(resp.StatusCode)
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, errors.As(err)
-			}
-			var result api.Group
-			if err = json.Unmarshal(bodyBytes, &result); err != nil {
-				return nil, errors.As(err)
-			}
-			return &result, nil
-		} else {
-			return nil, errors.New(resp.Status)
-		}
+	result := &api.Group{}
+	if err := napi.doReq("CreateGroup", token, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 // DeleteGroup deletes a group by ID.
@@ -2161,12 +1900,12 @@ func (napi *NakamaApi) DeleteGroup(
 	groupId *string,
 	options map[string]string,
 ) error {
-	if !checkStr(groupId) {
-		return errors.New("'groupId' is a required parameter but is empty")
+	if isEmpty(groupId) {
+		return newError("'groupId' is a required parameter but is empty")
 	}
 
 	// Define the URL path and query parameters
-	urlPath := strings.Replace("/v2/group/{groupId}", "{groupId}", url.QueryEscape(*groupId), 1)
+	urlPath := buildPath("/v2/group/{groupId}", "groupId", *groupId)
 	queryParams := url.Values{}
 
 	// Construct the full URL
@@ -2175,57 +1914,18 @@ func (napi *NakamaApi) DeleteGroup(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("DELETE", fullUrl, nil)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
-	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return errors.New("request timed out")
-	case err := <-errorChan:
-		return errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
-		} else {
-			return errors.New(resp.Status)
-		}
+	if err := napi.doReq("DeleteGroup", token, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
+	return nil
 }
 
 // UpdateGroup updates fields in a given group.
@@ -2236,21 +1936,21 @@ func (napi *NakamaApi) UpdateGroup(
 	options map[string]string,
 ) error {
 	// Validate required parameters
-	if checkStr(groupId) {
-		return errors.New("'groupId' is a required parameter but is empty")
+	if isEmpty(groupId) {
+		return newError("'groupId' is a required parameter but is empty")
 	}
 	if body == nil {
-		return errors.New("'body' is a required parameter but is empty")
+		return newError("'body' is a required parameter but is empty")
 	}
 
 	// Define the URL path and query parameters
-	urlPath := strings.Replace("/v2/group/{groupId}", "{groupId}", url.QueryEscape(*groupId), 1)
+	urlPath := buildPath("/v2/group/{groupId}", "groupId", *groupId)
 	queryParams := url.Values{}
 
 	// Serialize the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -2259,12 +1959,12 @@ func (napi *NakamaApi) UpdateGroup(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("PUT", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("UpdateGroup", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 
 	return nil
@@ -2280,12 +1980,12 @@ func (napi *NakamaApi) AddGroupUsers(
 ) error {
 
 	// Check required parameters
-	if !checkStr(groupId) {
-		return errors.New("'groupId' is a required parameter but is empty")
+	if isEmpty(groupId) {
+		return newError("'groupId' is a required parameter but is empty")
 	}
 
 	// Define the URL path and query parameters
-	urlPath := strings.Replace("/v2/group/{groupId}/add", "{groupId}", url.QueryEscape(*groupId), 1)
+	urlPath := buildPath("/v2/group/{groupId}/add", "groupId", *groupId)
 	queryParams := url.Values{}
 	for _, userId := range userIds {
 		queryParams.Add("user_ids", userId)
@@ -2297,58 +1997,19 @@ func (napi *NakamaApi) AddGroupUsers(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, nil)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if bearerToken != nil && *bearerToken != "" {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	if err := napi.doReq("AddGroupUsers", token, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return errors.New("request timed out")
-	case err := <-errorChan:
-		return errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
-		} else {
-			return errors.New(resp.Status)
-		}
-	}
-}
+	return nil
+}
 
 // BanGroupUsers bans a set of users from a group.
 func (napi *NakamaApi) BanGroupUsers(
@@ -2358,12 +2019,12 @@ func (napi *NakamaApi) BanGroupUsers(
 	options map[string]string,
 ) error {
 	// Check required parameters
-	if !checkStr(groupId) {
-		return errors.New("'groupId' is a required parameter but is empty")
+	if isEmpty(groupId) {
+		return newError("'groupId' is a required parameter but is empty")
 	}
 
 	// Define the URL path and query parameters
-	urlPath := strings.Replace("/v2/group/{groupId}/ban", "{groupId}", url.QueryEscape(*groupId), 1)
+	urlPath := buildPath("/v2/group/{groupId}/ban", "groupId", *groupId)
 	queryParams := url.Values{}
 	for _, userId := range userIds {
 		queryParams.Add("user_ids", userId)
@@ -2375,57 +2036,18 @@ func (napi *NakamaApi) BanGroupUsers(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, nil)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if bearerToken != nil && *bearerToken != "" {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
-	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return errors.New("request timed out")
-	case err := <-errorChan:
-		return errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
-		} else {
-			return errors.New(resp.Status)
-		}
+	if err := napi.doReq("BanGroupUsers", token, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
+	return nil
 }
 
 // DemoteGroupUsers demotes a set of users in a group to the next role down.
@@ -2437,11 +2059,11 @@ func (napi *NakamaApi) DemoteGroupUsers(
 ) error {
 	// Check required parameters
 	if groupId == nil || *groupId == "" {
-		return errors.New("'groupId' is a required parameter but is empty")
+		return newError("'groupId' is a required parameter but is empty")
 	}
 
 	// Define the URL path and query parameters
-	urlPath := strings.Replace("/v2/group/{groupId}/demote", "{groupId}", url.QueryEscape(*groupId), 1)
+	urlPath := buildPath("/v2/group/{groupId}/demote", "groupId", *groupId)
 	queryParams := url.Values{}
 	for _, userId := range userIds {
 		queryParams.Add("user_ids", userId)
@@ -2453,57 +2075,18 @@ func (napi *NakamaApi) DemoteGroupUsers(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, nil)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
-	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return errors.New("request timed out")
-	case err := <-errorChan:
-		return errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
-		} else {
-			return errors.New(resp.Status)
-		}
+	if err := napi.doReq("DemoteGroupUsers", token, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
+	return nil
 }
 
 // JoinGroup immediately joins an open group, or requests to join a closed one.
@@ -2512,12 +2095,12 @@ func (napi *NakamaApi) JoinGroup(
 	groupId *string,
 	options map[string]string,
 ) error {
-	if !checkStr(groupId) {
-		return errors.New("'groupId' is a required parameter but is empty")
+	if isEmpty(groupId) {
+		return newError("'groupId' is a required parameter but is empty")
 	}
 
 	// Define the URL path and query parameters
-	urlPath := strings.Replace("/v2/group/{groupId}/join", "{groupId}", url.QueryEscape(*groupId), 1)
+	urlPath := buildPath("/v2/group/{groupId}/join", "groupId", *groupId)
 	queryParams := url.Values{}
 
 	// Construct the full URL
@@ -2526,57 +2109,18 @@ func (napi *NakamaApi) JoinGroup(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, nil)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return errors.New("request timed out")
-	case err := <-errorChan:
-		return errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
-		} else {
-			return errors.New(resp.Status)
-		}
+	if err := napi.doReq("JoinGroup", token, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
+	return nil
 }
 
 // KickGroupUsers kicks a set of users from a group.
@@ -2588,12 +2132,12 @@ func (napi *NakamaApi) KickGroupUsers(
 ) error {
 
 	// Validate required parameter
-	if !checkStr(groupId) {
-		return errors.New("'groupId' is a required parameter but is empty")
+	if isEmpty(groupId) {
+		return newError("'groupId' is a required parameter but is empty")
 	}
 
 	// Define the URL path and query parameters
-	urlPath := strings.Replace("/v2/group/{groupId}/kick", "{groupId}", url.QueryEscape(*groupId), 1)
+	urlPath := buildPath("/v2/group/{groupId}/kick", "groupId", *groupId)
 	queryParams := url.Values{}
 	for _, userId := range userIds {
 		queryParams.Add("user_ids", userId)
@@ -2605,57 +2149,18 @@ func (napi *NakamaApi) KickGroupUsers(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, nil)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
-	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return errors.New("request timed out")
-	case err := <-errorChan:
-		return errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
-		} else {
-			return errors.New(resp.Status)
-		}
+	if err := napi.doReq("KickGroupUsers", token, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
+	return nil
 }
 
 // LeaveGroup allows a user to leave a group they are a member of.
@@ -2665,12 +2170,12 @@ func (napi *NakamaApi) LeaveGroup(
 	options map[string]string,
 ) error {
 	// Validate the required parameter
-	if checkStr(groupId) {
-		return errors.New("'groupId' is a required parameter but is empty")
+	if isEmpty(groupId) {
+		return newError("'groupId' is a required parameter but is empty")
 	}
 
 	// Define the URL path and query parameters
-	urlPath := strings.Replace("/v2/group/{groupId}/leave", "{groupId}", url.QueryEscape(*groupId), 1)
+	urlPath := buildPath("/v2/group/{groupId}/leave", "groupId", *groupId)
 	queryParams := url.Values{}
 
 	// Construct the full URL
@@ -2679,57 +2184,18 @@ func (napi *NakamaApi) LeaveGroup(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, nil)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
-	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return errors.New("request timed out")
-	case err := <-errorChan:
-		return errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
-		} else {
-			return errors.New(resp.Status)
-		}
+	if err := napi.doReq("LeaveGroup", token, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
+	return nil
 }
 
 // PromoteGroupUsers promotes a set of users in a group to the next role up.
@@ -2740,12 +2206,12 @@ func (napi *NakamaApi) PromoteGroupUsers(
 	options map[string]string,
 ) error {
 	// Validate required parameter
-	if checkStr(&groupId) {
-		return errors.New("'groupId' is a required parameter but is empty")
+	if groupId == "" {
+		return newError("'groupId' is a required parameter but is empty")
 	}
 
 	// Define the URL path and query parameters
-	urlPath := strings.Replace("/v2/group/{groupId}/promote", "{groupId}", url.QueryEscape(groupId), 1)
+	urlPath := buildPath("/v2/group/{groupId}/promote", "groupId", groupId)
 	queryParams := url.Values{}
 	for _, userId := range userIds {
 		queryParams.Add("user_ids", userId)
@@ -2757,12 +2223,12 @@ func (napi *NakamaApi) PromoteGroupUsers(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, nil)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("PromoteGroupUsers", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 
 	return nil
@@ -2779,12 +2245,12 @@ func (napi *NakamaApi) ListGroupUsers(
 	options map[string]string,
 ) (*api.GroupUserList, error) {
 	// Validate the required parameter
-	if checkStr(groupId) {
-		return nil, errors.New("'groupId' is a required parameter but is empty")
+	if isEmpty(groupId) {
+		return nil, newError("'groupId' is a required parameter but is empty")
 	}
 
 	// Define the URL path and query parameters
-	urlPath := strings.Replace("/v2/group/{groupId}/user", "{groupId}", url.QueryEscape(*groupId), 1)
+	urlPath := buildPath("/v2/group/{groupId}/user", "groupId", *groupId)
 	queryParams := url.Values{}
 	if limit != nil {
 		queryParams.Set("limit", strconv.Itoa(*limit))
@@ -2806,62 +2272,18 @@ func (napi *NakamaApi) ListGroupUsers(
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, err
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil, nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var result api.GroupUserList
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-			err = json.Unmarshal(bodyBytes, &result)
-			if err != nil {
-				return nil, err
-			}
-			return &result, nil
-		} else {
-			return nil, errors.New(resp.Status)
+	result := &api.GroupUserList{}
+	if err := napi.doReq("ListGroupUsers", token, req, options, result); err != nil {
+		if errors.Is(err, ErrNoContent) {
+			return result, nil
 		}
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 func (napi *NakamaApi) ValidatePurchaseApple(
@@ -2889,62 +2311,18 @@ func (napi *NakamaApi) ValidatePurchaseApple(
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
-	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, err
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
+	result := &api.ValidatePurchaseResponse{}
+	if err := napi.doReq("ValidatePurchaseApple", token, req, options, result); err != nil {
+		if errors.Is(err, ErrNoContent) {
 			return nil, nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var result api.ValidatePurchaseResponse
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-			err = json.Unmarshal(bodyBytes, &result)
-			if err != nil {
-				return nil, err
-			}
-			return &result, nil
-		} else {
-			return nil, errors.New(resp.Status)
 		}
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 // ValidatePurchaseFacebookInstant validates an Instant IAP receipt from Facebook.
@@ -2955,7 +2333,7 @@ func (napi *NakamaApi) ValidatePurchaseFacebookInstant(
 ) (*api.ValidatePurchaseResponse, error) {
 	// Validate the required parameter
 	if body == nil {
-		return nil, errors.New("'body' is a required parameter but is null or undefined.")
+		return nil, newError("'body' is a required parameter but is null or undefined.")
 	}
 
 	// Define the URL path
@@ -2978,62 +2356,18 @@ func (napi *NakamaApi) ValidatePurchaseFacebookInstant(
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
-	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, err
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
+	result := &api.ValidatePurchaseResponse{}
+	if err := napi.doReq("ValidatePurchaseFacebookInstant", token, req, options, result); err != nil {
+		if errors.Is(err, ErrNoContent) {
 			return nil, nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var result api.ValidatePurchaseResponse
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-			err = json.Unmarshal(bodyBytes, &result)
-			if err != nil {
-				return nil, err
-			}
-			return &result, nil
-		} else {
-			return nil, errors.New(resp.Status)
 		}
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 // ValidatePurchaseGoogle validates an IAP receipt from Google.
@@ -3044,7 +2378,7 @@ func (napi *NakamaApi) ValidatePurchaseGoogle(
 ) (*api.ValidatePurchaseResponse, error) {
 	// Validate the required parameter
 	if body == nil {
-		return nil, errors.New("'body' is a required parameter but is null or undefined.")
+		return nil, newError("'body' is a required parameter but is null or undefined.")
 	}
 
 	// Define the URL path
@@ -3067,62 +2401,18 @@ func (napi *NakamaApi) ValidatePurchaseGoogle(
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
-	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, err
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
+	result := &api.ValidatePurchaseResponse{}
+	if err := napi.doReq("ValidatePurchaseGoogle", token, req, options, result); err != nil {
+		if errors.Is(err, ErrNoContent) {
 			return nil, nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var result api.ValidatePurchaseResponse
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-			err = json.Unmarshal(bodyBytes, &result)
-			if err != nil {
-				return nil, err
-			}
-			return &result, nil
-		} else {
-			return nil, errors.New(resp.Status)
 		}
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 // ValidatePurchaseHuawei validates an IAP receipt from Huawei.
@@ -3133,7 +2423,7 @@ func (napi *NakamaApi) ValidatePurchaseHuawei(
 ) (*api.ValidatePurchaseResponse, error) {
 	// Validate the required parameter
 	if body == nil {
-		return nil, errors.New("'body' is a required parameter but is null or undefined.")
+		return nil, newError("'body' is a required parameter but is null or undefined.")
 	}
 
 	// Define the URL path
@@ -3156,62 +2446,18 @@ func (napi *NakamaApi) ValidatePurchaseHuawei(
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, err
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
+	result := &api.ValidatePurchaseResponse{}
+	if err := napi.doReq("ValidatePurchaseHuawei", token, req, options, result); err != nil {
+		if errors.Is(err, ErrNoContent) {
 			return nil, nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var result api.ValidatePurchaseResponse
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-			err = json.Unmarshal(bodyBytes, &result)
-			if err != nil {
-				return nil, err
-			}
-			return &result, nil
-		} else {
-			return nil, errors.New(resp.Status)
 		}
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 // ListSubscriptions lists user's subscriptions.
@@ -3223,7 +2469,7 @@ func (napi *NakamaApi) ListSubscriptions(
 
 	// Validate the required parameter
 	if body == nil {
-		return nil, errors.New("'body' is a required parameter but is null or undefined.")
+		return nil, newError("'body' is a required parameter but is null or undefined.")
 	}
 
 	// Define the URL path
@@ -3233,7 +2479,7 @@ func (napi *NakamaApi) ListSubscriptions(
 	// Serialize the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -3242,66 +2488,19 @@ func (napi *NakamaApi) ListSubscriptions(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewReader(bodyJson))
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
-	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil, ErrNoContent.As(resp.StatusCode)
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var result api.SubscriptionList
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, errors.As(err)
-			}
-			err = json.Unmarshal(bodyBytes, &result)
-			if err != nil {
-				return nil, errors.As(err)
-			}
-			return &result, nil
-		} else {
-			return nil, errors.New(resp.Status)
-		}
+	result := &api.SubscriptionList{}
+	if err := napi.doReq("ListSubscriptions", token, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 // ValidateSubscriptionApple validates an Apple subscription receipt.
@@ -3313,7 +2512,7 @@ func (napi *NakamaApi) ValidateSubscriptionApple(
 
 	// Validate the required parameter
 	if body == nil {
-		return nil, errors.New("'body' is a required parameter but is null or undefined.")
+		return nil, newError("'body' is a required parameter but is null or undefined.")
 	}
 
 	// Define the URL path
@@ -3336,62 +2535,18 @@ func (napi *NakamaApi) ValidateSubscriptionApple(
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, err
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return &api.ValidateSubscriptionResponse{}, nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var result api.ValidateSubscriptionResponse
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-			err = json.Unmarshal(bodyBytes, &result)
-			if err != nil {
-				return nil, err
-			}
-			return &result, nil
-		} else {
-			return nil, errors.New(resp.Status)
+	result := &api.ValidateSubscriptionResponse{}
+	if err := napi.doReq("ValidateSubscriptionApple", token, req, options, result); err != nil {
+		if errors.Is(err, ErrNoContent) {
+			return result, nil
 		}
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 // ValidateSubscriptionGoogle validates a Google subscription receipt.
@@ -3403,7 +2558,7 @@ func (napi *NakamaApi) ValidateSubscriptionGoogle(
 
 	// Validate the required parameter
 	if body == nil {
-		return nil, errors.New("'body' is a required parameter but is null or undefined.")
+		return nil, newError("'body' is a required parameter but is null or undefined.")
 	}
 
 	// Define the URL path
@@ -3426,62 +2581,18 @@ func (napi *NakamaApi) ValidateSubscriptionGoogle(
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
-	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, err
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return &api.ValidateSubscriptionResponse{}, nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var result api.ValidateSubscriptionResponse
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-			err = json.Unmarshal(bodyBytes, &result)
-			if err != nil {
-				return nil, err
-			}
-			return &result, nil
-		} else {
-			return nil, errors.New(resp.Status)
+	result := &api.ValidateSubscriptionResponse{}
+	if err := napi.doReq("ValidateSubscriptionGoogle", token, req, options, result); err != nil {
+		if errors.Is(err, ErrNoContent) {
+			return result, nil
 		}
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 // GetSubscription retrieves a subscription by product ID.
@@ -3493,11 +2604,11 @@ func (napi *NakamaApi) GetSubscription(
 
 	// Validate the required parameter
 	if productId == nil || *productId == "" {
-		return nil, errors.New("'productId' is a required parameter but is null or empty.")
+		return nil, newError("'productId' is a required parameter but is null or empty.")
 	}
 
 	// Define the URL path
-	urlPath := fmt.Sprintf("/v2/iap/subscription/%s", url.QueryEscape(*productId))
+	urlPath := buildPath("/v2/iap/subscription/{productId}", "productId", *productId)
 	queryParams := url.Values{}
 
 	// Construct the full URL
@@ -3509,62 +2620,15 @@ func (napi *NakamaApi) GetSubscription(
 		return nil, err
 	}
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
-	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil, ErrNoContent.As(resp.StatusCode)
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			var result api.ValidatedSubscription
-			bodyBytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, errors.As(err)
-			}
-			err = protojson.Unmarshal(bodyBytes, &result)
-			if err != nil {
-				return nil, errors.As(err)
-			}
-			return &result, nil
-		} else {
-			return nil, errors.New(resp.Status)
-		}
+	result := &api.ValidatedSubscription{}
+	if err := napi.doReq("GetSubscription", token, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 // DeleteLeaderboardRecord deletes a leaderboard record.
@@ -3575,12 +2639,12 @@ func (napi *NakamaApi) DeleteLeaderboardRecord(
 ) error {
 
 	// Validate the required parameter
-	if !checkStr(leaderboardId) {
-		return errors.New("'leaderboardId' is a required parameter but is null or empty.")
+	if isEmpty(leaderboardId) {
+		return newError("'leaderboardId' is a required parameter but is null or empty.")
 	}
 
 	// Define the URL path
-	urlPath := fmt.Sprintf("/v2/leaderboard/%s", url.QueryEscape(*leaderboardId))
+	urlPath := buildPath("/v2/leaderboard/{leaderboardId}", "leaderboardId", *leaderboardId)
 	queryParams := url.Values{}
 
 	// Construct the full URL
@@ -3592,53 +2656,14 @@ func (napi *NakamaApi) DeleteLeaderboardRecord(
 		return err
 	}
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
-	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return errors.New("request timed out")
-	case err := <-errorChan:
-		return err
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
-		} else {
-			return errors.New(resp.Status)
-		}
+	if err := napi.doReq("DeleteLeaderboardRecord", token, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
+	return nil
 }
 
 // ListLeaderboardRecords retrieves a list of leaderboard records.
@@ -3653,12 +2678,12 @@ func (napi *NakamaApi) ListLeaderboardRecords(
 ) (*api.LeaderboardRecordList, error) {
 
 	// Validate the required parameter
-	if !checkStr(leaderboardId) {
-		return nil, errors.New("'leaderboardId' is a required parameter but is null or empty.")
+	if isEmpty(leaderboardId) {
+		return nil, newError("'leaderboardId' is a required parameter but is null or empty.")
 	}
 
 	// Define the URL path
-	urlPath := fmt.Sprintf("/v2/leaderboard/%s", url.QueryEscape(*leaderboardId))
+	urlPath := buildPath("/v2/leaderboard/{leaderboardId}", "leaderboardId", *leaderboardId)
 	queryParams := url.Values{}
 
 	// Add query parameters
@@ -3683,66 +2708,18 @@ func (napi *NakamaApi) ListLeaderboardRecords(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("GET", fullUrl, nil)
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	// Set Bearer Token authorization header
-	if checkStr(bearerToken) {
-		req.Header.Set("Authorization", "Bearer "+*bearerToken)
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
 	}
-
-	// Apply additional custom headers or options if needed
-	for key, value := range options {
-		req.Header.Set(key, value)
-	}
-
-	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(napi.TimeoutMs)*time.Millisecond)
-	defer cancel()
-
-	// Make the HTTP request
-	client := &http.Client{}
-	responseChan := make(chan *http.Response, 1)
-	errorChan := make(chan error, 1)
-
-	// Run the HTTP request in a goroutine
-	go func() {
-		resp, err := client.Do(req.WithContext(ctx))
-		if err != nil {
-			errorChan <- err
-			return
-		}
-		responseChan <- resp
-	}()
-
-	// Wait for the response or the timeout
-	select {
-	case <-ctx.Done():
-		return nil, errors.New("request timed out")
-	case err := <-errorChan:
-		return nil, errors.As(err)
-	case resp := <-responseChan:
-		defer resp.Body.Close()
-		bodyBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, errors.As(err)
-		}
-
-		// Handle HTTP response
-		if resp.StatusCode == http.StatusNoContent {
-			return nil, ErrNoContent.As(resp.StatusCode)
-		} else if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			result := &api.LeaderboardRecordList{}
-			fmt.Println(string(bodyBytes))
-			err = protojson.Unmarshal(bodyBytes, result)
-			if err != nil {
-				return nil, errors.As(err)
-			}
-			return result, nil
-		} else {
-			return nil, errors.New(resp.Status).As(string(bodyBytes))
-		}
+	result := &api.LeaderboardRecordList{}
+	if err := napi.doReq("ListLeaderboardRecords", token, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
+	return result, nil
 }
 
 // WriteLeaderboardRecord writes a record to a leaderboard.
@@ -3754,21 +2731,21 @@ func (napi *NakamaApi) WriteLeaderboardRecord(
 ) (*api.LeaderboardRecord, error) {
 
 	// Validate the required parameters
-	if !checkStr(&leaderboardId) {
-		return nil, errors.New("'leaderboardId' is a required parameter but is null or empty.")
+	if leaderboardId == "" {
+		return nil, newError("'leaderboardId' is a required parameter but is null or empty.")
 	}
 	if record == nil {
-		return nil, errors.New("'record' is a required parameter but is null or empty.")
+		return nil, newError("'record' is a required parameter but is null or empty.")
 	}
 
 	// Define the URL path
-	urlPath := fmt.Sprintf("/v2/leaderboard/%s", url.QueryEscape(leaderboardId))
+	urlPath := buildPath("/v2/leaderboard/{leaderboardId}", "leaderboardId", leaderboardId)
 	queryParams := url.Values{}
 
 	// Convert the record to JSON
 	bodyJson, err := json.Marshal(record)
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -3777,15 +2754,15 @@ func (napi *NakamaApi) WriteLeaderboardRecord(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, bytes.NewBuffer(bodyJson))
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	// Set the Content-Type header
 	req.Header.Set("Content-Type", "application/json")
 
 	result := &api.LeaderboardRecord{}
-	if err := napi.doReq(bearerToken, req, options, result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("WriteLeaderboardRecord", bearerToken, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
 	return result, nil
 }
@@ -3802,19 +2779,15 @@ func (napi *NakamaApi) ListLeaderboardRecordsAroundOwner(
 ) (*api.LeaderboardRecordList, error) {
 
 	// Validate the required parameters
-	if !checkStr(&leaderboardId) {
-		return nil, errors.New("'leaderboardId' is a required parameter but is null or empty.")
+	if leaderboardId == "" {
+		return nil, newError("'leaderboardId' is a required parameter but is null or empty.")
 	}
-	if !checkStr(&ownerId) {
-		return nil, errors.New("'ownerId' is a required parameter but is null or empty.")
+	if ownerId == "" {
+		return nil, newError("'ownerId' is a required parameter but is null or empty.")
 	}
 
 	// Define the URL path
-	urlPath := fmt.Sprintf(
-		"/v2/leaderboard/%s/owner/%s",
-		url.QueryEscape(leaderboardId),
-		url.QueryEscape(ownerId),
-	)
+	urlPath := buildPath("/v2/leaderboard/{leaderboardId}/owner/{ownerId}", "leaderboardId", leaderboardId, "ownerId", ownerId)
 	queryParams := url.Values{}
 
 	// Add optional parameters to the query
@@ -3834,12 +2807,12 @@ func (napi *NakamaApi) ListLeaderboardRecordsAroundOwner(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("GET", fullUrl, nil)
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	result := &api.LeaderboardRecordList{}
-	if err := napi.doReq(bearerToken, req, options, result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("ListLeaderboardRecordsAroundOwner", bearerToken, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return result, nil
@@ -3890,8 +2863,8 @@ func (napi *NakamaApi) ListMatches(
 	}
 
 	var result api.MatchList
-	if err := napi.doReq(bearerToken, req, options, &result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("ListMatches", bearerToken, req, options, &result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return &result, nil
@@ -3921,10 +2894,10 @@ func (napi *NakamaApi) DeleteNotifications(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("DELETE", fullUrl, strings.NewReader(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("DeleteNotifications", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -3954,12 +2927,12 @@ func (napi *NakamaApi) ListNotifications(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("GET", fullUrl, nil)
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	result := &api.NotificationList{}
-	if err := napi.doReq(bearerToken, req, options, result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("ListNotifications", bearerToken, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return result, nil
@@ -3974,12 +2947,12 @@ func (napi *NakamaApi) RpcFunc2(
 ) (*api.Rpc, error) {
 
 	// Validate the required parameter 'id'
-	if !checkStr(&id) {
-		return nil, errors.New("'id' is a required parameter but is empty")
+	if id == "" {
+		return nil, newError("'id' is a required parameter but is empty")
 	}
 
 	// Define the URL path
-	urlPath := fmt.Sprintf("/v2/rpc/%s", url.QueryEscape(id))
+	urlPath := buildPath("/v2/rpc/{id}", "id", id)
 
 	// Add query parameters
 	queryParams := url.Values{}
@@ -3999,12 +2972,12 @@ func (napi *NakamaApi) RpcFunc2(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("GET", fullUrl, strings.NewReader(bodyJson))
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	result := &api.Rpc{}
-	if err := napi.doReq(bearerToken, req, options, result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("RpcFunc2", bearerToken, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
 	return result, nil
 }
@@ -4017,15 +2990,15 @@ func (napi *NakamaApi) RpcFunc(
 	options map[string]string,
 ) (*api.Rpc, error) {
 	// Validate the required parameters 'id' and 'body'
-	if !checkStr(&id) {
-		return nil, errors.New("'id' is a required parameter but is empty")
+	if id == "" {
+		return nil, newError("'id' is a required parameter but is empty")
 	}
-	if !checkStr(&body) {
-		return nil, errors.New("'body' is a required parameter but is empty")
+	if body == "" {
+		return nil, newError("'body' is a required parameter but is empty")
 	}
 
 	// Define the URL path
-	urlPath := fmt.Sprintf("/v2/rpc/%s", url.QueryEscape(id))
+	urlPath := buildPath("/v2/rpc/{id}", "id", id)
 
 	// Add query parameters
 	queryParams := url.Values{}
@@ -4036,7 +3009,7 @@ func (napi *NakamaApi) RpcFunc(
 	// Convert the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -4045,12 +3018,12 @@ func (napi *NakamaApi) RpcFunc(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, strings.NewReader(string(bodyJson)))
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	result := &api.Rpc{}
-	if err := napi.doReq(bearerToken, req, options, result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("RpcFunc", bearerToken, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return result, nil
@@ -4063,7 +3036,7 @@ func (napi *NakamaApi) SessionLogout(
 ) error {
 	// Validate the required parameter 'body'
 	if body == nil {
-		return errors.New("'body' is a required parameter but is null or undefined")
+		return newError("'body' is a required parameter but is null or undefined")
 	}
 
 	// Define the URL path
@@ -4075,7 +3048,7 @@ func (napi *NakamaApi) SessionLogout(
 	// Convert the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -4084,11 +3057,11 @@ func (napi *NakamaApi) SessionLogout(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, strings.NewReader(string(bodyJson)))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("SessionLogout", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -4107,7 +3080,7 @@ func (napi *NakamaApi) ReadStorageObjects(
 	// Convert the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -4116,12 +3089,12 @@ func (napi *NakamaApi) ReadStorageObjects(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, strings.NewReader(string(bodyJson)))
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	result := &api.StorageObjects{}
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("ReadStorageObjects", bearerToken, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return result, nil
@@ -4138,10 +3111,18 @@ func (napi *NakamaApi) WriteStorageObjects(
 	// Add query parameters (empty for this request)
 	queryParams := url.Values{}
 
+	if napi.StreamBodyBytes > 0 && storageValueBytes(body) >= napi.StreamBodyBytes {
+		result, err := napi.writeStorageObjectsStreamed(bearerToken, body, options)
+		if !chunkedRefused(err) {
+			return result, err
+		}
+		napi.logger().Warnf("chunked storage write refused, buffering it: %s", err.Error())
+	}
+
 	// Convert the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -4150,12 +3131,12 @@ func (napi *NakamaApi) WriteStorageObjects(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("PUT", fullUrl, strings.NewReader(string(bodyJson)))
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	var result api.StorageObjectAcks
-	if err := napi.doReq(bearerToken, req, options, &result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("WriteStorageObjects", bearerToken, req, options, &result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return &result, nil
@@ -4175,7 +3156,7 @@ func (napi *NakamaApi) DeleteStorageObjects(
 	// Convert the body to JSON
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -4184,11 +3165,11 @@ func (napi *NakamaApi) DeleteStorageObjects(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("PUT", fullUrl, strings.NewReader(string(bodyJson)))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("DeleteStorageObjects", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -4196,23 +3177,24 @@ func (napi *NakamaApi) DeleteStorageObjects(
 func (napi *NakamaApi) ListStorageObjects(
 	bearerToken string,
 	collection string,
-	userId string,
+	userId *string,
 	limit int,
 	cursor string,
 	options map[string]string,
 ) (*api.StorageObjectList, error) {
 	// Validate the 'collection' parameter
-	if !checkStr(&collection) {
-		return nil, errors.New("'collection' is a required parameter but is empty.")
+	if collection == "" {
+		return nil, newError("'collection' is a required parameter but is empty.")
 	}
 
 	// Define the URL path and replace the placeholder
-	urlPath := strings.Replace("/v2/storage/{collection}", "{collection}", url.QueryEscape(collection), 1)
+	urlPath := buildPath("/v2/storage/{collection}", "collection", collection)
 
 	// Add query parameters
+	// no user_id lists the objects of all the owners, the system user included
 	queryParams := url.Values{}
-	if userId != "" {
-		queryParams.Set("user_id", userId)
+	if !isEmpty(userId) {
+		queryParams.Set("user_id", *userId)
 	}
 	if limit > 0 {
 		queryParams.Set("limit", fmt.Sprintf("%d", limit))
@@ -4230,12 +3212,12 @@ func (napi *NakamaApi) ListStorageObjects(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("GET", fullUrl, strings.NewReader(bodyJson))
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	result := &api.StorageObjectList{}
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("ListStorageObjects", bearerToken, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return result, nil
@@ -4251,18 +3233,15 @@ func (napi *NakamaApi) ListStorageObjects2(
 ) (*api.StorageObjectList, error) {
 
 	// Validate 'collection' and 'userId' parameters
-	if !checkStr(&collection) {
-		return nil, errors.New("'collection' is a required parameter but is empty.")
+	if collection == "" {
+		return nil, newError("'collection' is a required parameter but is empty.")
 	}
-	if checkStr(&userId) {
-		return nil, errors.New("'userId' is a required parameter but is empty.")
+	if userId == "" {
+		return nil, newError("'userId' is a required parameter but is empty.")
 	}
 
 	// Define the URL path and replace placeholders
-	urlPath := strings.Replace(
-		strings.Replace("/v2/storage/{collection}/{userId}", "{collection}", url.QueryEscape(collection), 1),
-		"{userId}", url.QueryEscape(userId), 1,
-	)
+	urlPath := buildPath("/v2/storage/{collection}/{userId}", "collection", collection, "userId", userId)
 
 	// Add query parameters
 	queryParams := url.Values{}
@@ -4282,12 +3261,12 @@ func (napi *NakamaApi) ListStorageObjects2(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("GET", fullUrl, strings.NewReader(bodyJson))
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	result := &api.StorageObjectList{}
-	if err := napi.doReq(bearerToken, req, options, result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("ListStorageObjects2", bearerToken, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return result, nil
@@ -4340,8 +3319,8 @@ func (napi *NakamaApi) ListTournaments(
 		return nil, err
 	}
 	var result api.TournamentList
-	if err := napi.doReq(bearerToken, req, options, &result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("ListTournaments", bearerToken, req, options, &result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return &result, nil
@@ -4355,11 +3334,11 @@ func (napi *NakamaApi) DeleteTournamentRecord(
 ) error {
 	// Validate the tournamentId
 	if tournamentId == "" {
-		return errors.New("'tournamentId' is a required parameter but is empty.")
+		return newError("'tournamentId' is a required parameter but is empty.")
 	}
 
 	// Define the URL path
-	urlPath := "/v2/tournament/" + url.QueryEscape(tournamentId)
+	urlPath := buildPath("/v2/tournament/{tournamentId}", "tournamentId", tournamentId)
 
 	// No query parameters for this function
 	queryParams := url.Values{}
@@ -4373,10 +3352,10 @@ func (napi *NakamaApi) DeleteTournamentRecord(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("DELETE", fullUrl, strings.NewReader(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("DeleteTournamentRecord", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -4393,12 +3372,12 @@ func (napi *NakamaApi) ListTournamentRecords(
 ) (*api.TournamentRecordList, error) {
 
 	// Validate the tournamentId
-	if !checkStr(&tournamentId) {
-		return nil, errors.New("'tournamentId' is a required parameter but is empty.")
+	if tournamentId == "" {
+		return nil, newError("'tournamentId' is a required parameter but is empty.")
 	}
 
 	// Define the URL path
-	urlPath := "/v2/tournament/" + url.QueryEscape(tournamentId)
+	urlPath := buildPath("/v2/tournament/{tournamentId}", "tournamentId", tournamentId)
 
 	// Add query parameters
 	queryParams := url.Values{}
@@ -4426,12 +3405,12 @@ func (napi *NakamaApi) ListTournamentRecords(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("GET", fullUrl, strings.NewReader(bodyJson))
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	result := &api.TournamentRecordList{}
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("ListTournamentRecords", bearerToken, req, options, nil); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return result, nil
@@ -4446,20 +3425,20 @@ func (napi *NakamaApi) WriteTournamentRecord2(
 ) (*api.LeaderboardRecord, error) {
 
 	// Validate the tournamentId and record
-	if checkStr(&tournamentId) {
-		return nil, errors.New("'tournamentId' is a required parameter but is empty.")
+	if tournamentId == "" {
+		return nil, newError("'tournamentId' is a required parameter but is empty.")
 	}
 	if record == nil {
-		return nil, errors.New("'record' is a required parameter but is empty.")
+		return nil, newError("'record' is a required parameter but is empty.")
 	}
 
 	// Define the URL path
-	urlPath := "/v2/tournament/" + url.QueryEscape(tournamentId)
+	urlPath := buildPath("/v2/tournament/{tournamentId}", "tournamentId", tournamentId)
 
 	// Prepare the request body
 	bodyJson, err := json.Marshal(record)
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	// Construct the full URL
@@ -4468,12 +3447,12 @@ func (napi *NakamaApi) WriteTournamentRecord2(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, strings.NewReader(string(bodyJson)))
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	result := &api.LeaderboardRecord{}
-	if err := napi.doReq(bearerToken, req, options, result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("WriteTournamentRecord2", bearerToken, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return result, nil
@@ -4488,20 +3467,20 @@ func (napi *NakamaApi) WriteTournamentRecord(
 ) (*api.LeaderboardRecord, error) {
 
 	// Validate the tournamentId and record
-	if !checkStr(&tournamentId) {
-		return nil, errors.New("'tournamentId' is a required parameter but is empty.")
+	if tournamentId == "" {
+		return nil, newError("'tournamentId' is a required parameter but is empty.")
 	}
 	if record == nil {
-		return nil, errors.New("'record' is a required parameter but is empty.")
+		return nil, newError("'record' is a required parameter but is empty.")
 	}
 
 	// Define the URL path
-	urlPath := "/v2/tournament/" + url.QueryEscape(tournamentId)
+	urlPath := buildPath("/v2/tournament/{tournamentId}", "tournamentId", tournamentId)
 
 	// Prepare the request body
 	bodyJson, err := json.Marshal(record)
 	if err != nil {
-		return nil, errors.New("failed to marshal record").As(err)
+		return nil, newError("failed to marshal record").With(err)
 	}
 
 	// Construct the full URL
@@ -4510,12 +3489,12 @@ func (napi *NakamaApi) WriteTournamentRecord(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("PUT", fullUrl, strings.NewReader(string(bodyJson)))
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	result := &api.LeaderboardRecord{}
-	if err := napi.doReq(bearerToken, req, options, result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("WriteTournamentRecord", bearerToken, req, options, result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return result, nil
@@ -4529,12 +3508,12 @@ func (napi *NakamaApi) JoinTournament(
 ) error {
 
 	// Validate the tournamentId
-	if !checkStr(&tournamentId) {
-		return errors.New("'tournamentId' is a required parameter but is empty.")
+	if tournamentId == "" {
+		return newError("'tournamentId' is a required parameter but is empty.")
 	}
 
 	// Define the URL path
-	urlPath := "/v2/tournament/" + url.QueryEscape(tournamentId) + "/join"
+	urlPath := buildPath("/v2/tournament/{tournamentId}/join", "tournamentId", tournamentId)
 
 	// Prepare the query params (if any, currently empty map)
 	queryParams := url.Values{}
@@ -4548,10 +3527,10 @@ func (napi *NakamaApi) JoinTournament(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("POST", fullUrl, strings.NewReader(bodyJson))
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return errors.As(err)
+	if err := napi.doReq("JoinTournament", bearerToken, req, options, nil); err != nil {
+		return wrapErr(err)
 	}
 	return nil
 }
@@ -4568,15 +3547,15 @@ func (napi *NakamaApi) ListTournamentRecordsAroundOwner(
 ) (*api.TournamentRecordList, error) {
 
 	// Validate the tournamentId and ownerId
-	if !checkStr(&tournamentId) {
-		return nil, errors.New("'tournamentId' is a required parameter but is empty.")
+	if tournamentId == "" {
+		return nil, newError("'tournamentId' is a required parameter but is empty.")
 	}
-	if !checkStr(&ownerId) {
-		return nil, errors.New("'ownerId' is a required parameter but is empty.")
+	if ownerId == "" {
+		return nil, newError("'ownerId' is a required parameter but is empty.")
 	}
 
 	// Define the URL path
-	urlPath := "/v2/tournament/" + url.QueryEscape(tournamentId) + "/owner/" + url.QueryEscape(ownerId)
+	urlPath := buildPath("/v2/tournament/{tournamentId}/owner/{ownerId}", "tournamentId", tournamentId, "ownerId", ownerId)
 
 	// Prepare the query params
 	queryParams := url.Values{}
@@ -4599,12 +3578,12 @@ func (napi *NakamaApi) ListTournamentRecordsAroundOwner(
 	// Prepare the HTTP request
 	req, err := http.NewRequest("GET", fullUrl, strings.NewReader(bodyJson))
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	var result api.TournamentRecordList
-	if err := napi.doReq(bearerToken, req, options, nil); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("ListTournamentRecordsAroundOwner", bearerToken, req, options, nil); err != nil {
+		return nil, wrapErr(err)
 	}
 	return &result, nil
 }
@@ -4644,66 +3623,79 @@ func (napi *NakamaApi) GetUsers(
 	if err != nil {
 		return nil, err
 	}
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
+	}
 	var result api.Users
-	if err := napi.doReq("", req, options, &result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("GetUsers", token, req, options, &result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return &result, nil
 
 }
 
-// ListUserGroups lists the groups the current user belongs to.
+// ListUserGroups lists the groups a user belongs to, the nil filters aren't sent.
 func (napi *NakamaApi) ListUserGroups(
-	bearerToken string,
+	bearerToken *string,
 	userId string,
 	state *int,
-	limit int,
-	cursor string,
+	limit *int,
+	cursor *string,
 	options map[string]string,
 ) (*api.UserGroupList, error) {
 
 	// Validate required parameters
-	if !checkStr(&userId) {
-		return nil, errors.New("'userId' is a required parameter but is empty.")
+	if userId == "" {
+		return nil, newError("'userId' is a required parameter but is empty.")
 	}
 
 	// Define the URL path and replace placeholder
-	urlPath := "/v2/user/{userId}/group"
-	urlPath = strings.Replace(urlPath, "{userId}", url.QueryEscape(userId), 1)
+	urlPath := buildPath("/v2/user/{userId}/group", "userId", userId)
 
 	// Prepare the query params
 	queryParams := url.Values{}
-	if limit > 0 {
-		queryParams.Set("limit", strconv.Itoa(limit))
+	if limit != nil {
+		queryParams.Set("limit", strconv.Itoa(*limit))
 	}
 	if state != nil {
 		queryParams.Set("state", strconv.Itoa(*state))
 	}
-	if cursor != "" {
-		queryParams.Set("cursor", cursor)
+	if cursor != nil {
+		queryParams.Set("cursor", *cursor)
 	}
 
-	// Prepare the request body (empty)
-	bodyJson := ""
-
 	// Construct the full URL
 	fullUrl := napi.buildFullUrl(napi.BasePath, urlPath, queryParams)
 
 	// Prepare the HTTP request
-	req, err := http.NewRequest("GET", fullUrl, strings.NewReader(bodyJson))
+	req, err := http.NewRequest("GET", fullUrl, nil)
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
+	token := ""
+	if bearerToken != nil {
+		token = *bearerToken
+	}
 	var result api.UserGroupList
-	if err := napi.doReq(bearerToken, req, options, &result); err != nil {
-		return nil, errors.As(err)
+	if err := napi.doReq("ListUserGroups", token, req, options, &result); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	return &result, nil
 }
 
+// buildPath fills the {name} placeholders of a path template with the path-escaped values,
+// params are name and value pairs.
+func buildPath(template string, params ...string) string {
+	for i := 0; i+1 < len(params); i += 2 {
+		template = strings.Replace(template, "{"+params[i]+"}", url.PathEscape(params[i+1]), 1)
+	}
+	return template
+}
+
 func (napi *NakamaApi) buildFullUrl(basePath string, fragment string, queryParams url.Values) string {
 	fullPath := basePath + fragment + "?"
 
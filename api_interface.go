@@ -0,0 +1,117 @@
+package nakama
+
+import (
+	"context"
+
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// NakamaApiInterface is the low-level layer of Client, one method per endpoint of the server.
+// NakamaApi implements it, replace Client.ApiClient to wrap or replace it, e.g. to sign the requests:
+//
+//	type signingApi struct {
+//		nakama.NakamaApiInterface
+//	}
+//
+//	func (s *signingApi) RpcFunc(bearerToken, id, body, httpKey string, options map[string]string) (*api.Rpc, error) {
+//		return s.NakamaApiInterface.RpcFunc(bearerToken, id, body, httpKey, sign(options, body))
+//	}
+//
+//	client.ApiClient = &signingApi{client.ApiClient}
+//
+// A wrapper embedding the interface should override WithContext and WithResponseInfo to wrap their result,
+// the embedded ones return the wrapped api.
+type NakamaApiInterface interface {
+	// WithContext returns a copy of the api client bounding its calls by ctx.
+	WithContext(ctx context.Context) NakamaApiInterface
+	// WithResponseInfo returns a copy of the api client filling info on each call.
+	WithResponseInfo(info *ResponseInfo) NakamaApiInterface
+
+	Healthcheck(bearerToken string, options map[string]string) error
+	DeleteAccount(bearerToken string, options map[string]string) error
+	GetAccount(bearerToken string, options map[string]string) (*api.Account, error)
+	UpdateAccount(bearerToken string, body *api.UpdateAccountRequest, options map[string]string) error
+	AuthenticateApple(basicAuthUsername string, basicAuthPassword string, account *api.AccountApple, create *bool, username string, options map[string]string) (*api.Session, error)
+	AuthenticateCustom(basicAuthUsername string, basicAuthPassword string, account *api.AccountCustom, create *bool, username *string, options map[string]string) (*api.Session, error)
+	AuthenticateDevice(basicAuthUsername string, basicAuthPassword string, account *api.AccountDevice, create *bool, username string, options map[string]string) (*api.Session, error)
+	AuthenticateEmail(basicAuthUsername string, basicAuthPassword string, account *api.AccountEmail, create *bool, username *string, options map[string]string) (*api.Session, error)
+	AuthenticateFacebook(basicAuthUsername string, basicAuthPassword string, account *api.AccountFacebook, create *bool, username string, sync *bool, options map[string]string) (*api.Session, error)
+	AuthenticateFacebookInstantGame(basicAuthUsername string, basicAuthPassword string, account *api.AccountFacebookInstantGame, create *bool, username string, options map[string]string) (*api.Session, error)
+	AuthenticateGameCenter(basicAuthUsername string, basicAuthPassword string, account *api.AccountGameCenter, create *bool, username string, options map[string]string) (*api.Session, error)
+	AuthenticateGoogle(basicAuthUsername string, basicAuthPassword string, account *api.AccountGoogle, create *bool, username string, options map[string]string) (*api.Session, error)
+	AuthenticateSteam(basicAuthUsername string, basicAuthPassword string, account *api.AccountSteam, create *bool, username string, sync *bool, options map[string]string) (*api.Session, error)
+	LinkApple(bearerToken string, body *api.AccountApple, options map[string]string) error
+	LinkCustom(bearerToken string, body *api.AccountCustom, options map[string]string) error
+	LinkDevice(bearerToken string, body *api.AccountDevice, options map[string]string) error
+	LinkEmail(bearerToken string, body *api.AccountEmail, options map[string]string) error
+	LinkFacebook(bearerToken string, account *api.AccountFacebook, sync *bool, options map[string]string) error
+	LinkFacebookInstantGame(bearerToken string, body *api.AccountFacebookInstantGame, options map[string]string) error
+	LinkGameCenter(bearerToken string, body *api.AccountGameCenter, options map[string]string) error
+	LinkGoogle(bearerToken string, body *api.AccountGoogle, options map[string]string) error
+	LinkSteam(bearerToken string, body *api.LinkSteamRequest, options map[string]string) error
+	SessionRefresh(basicAuthUsername string, basicAuthPassword string, body *api.SessionRefreshRequest, options map[string]string) (*api.Session, error)
+	UnlinkApple(bearerToken string, body *api.AccountApple, options map[string]string) error
+	UnlinkCustom(bearerToken string, body *api.AccountCustom, options map[string]string) error
+	UnlinkDevice(bearerToken string, body *api.AccountDevice, options map[string]string) error
+	UnlinkEmail(bearerToken string, body *api.AccountEmail, options map[string]string) error
+	UnlinkFacebook(bearerToken string, body *api.AccountFacebook, options map[string]string) error
+	UnlinkFacebookInstantGame(bearerToken string, body *api.AccountFacebookInstantGame, options map[string]string) error
+	UnlinkGameCenter(bearerToken string, body *api.AccountGameCenter, options map[string]string) error
+	UnlinkGoogle(bearerToken string, body *api.AccountGoogle, options map[string]string) error
+	UnlinkSteam(bearerToken string, body *api.AccountSteam, options map[string]string) error
+	ListChannelMessages(bearerToken *string, channelId *string, limit *int, forward *bool, cursor *string, options map[string]string) (*api.ChannelMessageList, error)
+	Event(bearerToken *string, body *api.Event, options map[string]string) error
+	DeleteFriends(bearerToken *string, ids []string, usernames []string, options map[string]string) error
+	ListFriends(bearerToken *string, limit *int, state *int, cursor *string, options map[string]string) (*api.FriendList, error)
+	AddFriends(bearerToken *string, ids []string, usernames []string, options map[string]string) error
+	BlockFriends(bearerToken *string, ids []string, usernames []string, options map[string]string) error
+	ImportFacebookFriends(bearerToken *string, account *api.AccountFacebook, reset *bool, options map[string]string) error
+	ListFriendsOfFriends(bearerToken *string, limit *int, cursor *string, options map[string]string) (*api.FriendsOfFriendsList, error)
+	ImportSteamFriends(bearerToken *string, account *api.AccountSteam, reset *bool, options map[string]string) error
+	ListGroups(bearerToken *string, name *string, cursor *string, limit *int, langTag *string, members *int, open *bool, options map[string]string) (*api.GroupList, error)
+	CreateGroup(bearerToken *string, body *api.CreateGroupRequest, options map[string]string) (*api.Group, error)
+	DeleteGroup(bearerToken *string, groupId *string, options map[string]string) error
+	UpdateGroup(bearerToken string, groupId *string, body *api.UpdateGroupRequest, options map[string]string) error
+	AddGroupUsers(bearerToken *string, groupId *string, userIds []string, options map[string]string) error
+	BanGroupUsers(bearerToken *string, groupId *string, userIds []string, options map[string]string) error
+	DemoteGroupUsers(bearerToken *string, groupId *string, userIds []string, options map[string]string) error
+	JoinGroup(bearerToken *string, groupId *string, options map[string]string) error
+	KickGroupUsers(bearerToken *string, groupId *string, userIds []string, options map[string]string) error
+	LeaveGroup(bearerToken *string, groupId *string, options map[string]string) error
+	PromoteGroupUsers(bearerToken string, groupId string, userIds []string, options map[string]string) error
+	ListGroupUsers(bearerToken *string, groupId *string, limit *int, state *int, cursor *string, options map[string]string) (*api.GroupUserList, error)
+	ValidatePurchaseApple(bearerToken *string, body *api.ValidatePurchaseAppleRequest, options map[string]string) (*api.ValidatePurchaseResponse, error)
+	ValidatePurchaseFacebookInstant(bearerToken *string, body *api.ValidatePurchaseFacebookInstantRequest, options map[string]string) (*api.ValidatePurchaseResponse, error)
+	ValidatePurchaseGoogle(bearerToken *string, body *api.ValidatePurchaseGoogleRequest, options map[string]string) (*api.ValidatePurchaseResponse, error)
+	ValidatePurchaseHuawei(bearerToken *string, body *api.ValidatePurchaseHuaweiRequest, options map[string]string) (*api.ValidatePurchaseResponse, error)
+	ListSubscriptions(bearerToken *string, body *api.ListSubscriptionsRequest, options map[string]string) (*api.SubscriptionList, error)
+	ValidateSubscriptionApple(bearerToken *string, body *api.ValidateSubscriptionAppleRequest, options map[string]string) (*api.ValidateSubscriptionResponse, error)
+	ValidateSubscriptionGoogle(bearerToken *string, body *api.ValidateSubscriptionGoogleRequest, options map[string]string) (*api.ValidateSubscriptionResponse, error)
+	GetSubscription(bearerToken *string, productId *string, options map[string]string) (*api.ValidatedSubscription, error)
+	DeleteLeaderboardRecord(bearerToken *string, leaderboardId *string, options map[string]string) error
+	ListLeaderboardRecords(bearerToken *string, leaderboardId *string, ownerIds []string, limit *int, cursor *string, expiry *string, options map[string]string) (*api.LeaderboardRecordList, error)
+	WriteLeaderboardRecord(bearerToken string, leaderboardId string, record *api.WriteLeaderboardRecordRequest_LeaderboardRecordWrite, options map[string]string) (*api.LeaderboardRecord, error)
+	ListLeaderboardRecordsAroundOwner(bearerToken string, leaderboardId string, ownerId string, limit int, expiry string, cursor string, options map[string]string) (*api.LeaderboardRecordList, error)
+	ListMatches(bearerToken string, limit int, authoritative *bool, label string, minSize int, maxSize int, query string, options map[string]string) (*api.MatchList, error)
+	DeleteNotifications(bearerToken string, ids []string, options map[string]string) error
+	ListNotifications(bearerToken string, limit int, cacheableCursor string, options map[string]string) (*api.NotificationList, error)
+	RpcFunc2(bearerToken string, id string, payload string, httpKey string, options map[string]string) (*api.Rpc, error)
+	RpcFunc(bearerToken string, id string, body string, httpKey string, options map[string]string) (*api.Rpc, error)
+	SessionLogout(bearerToken string, body *api.SessionLogoutRequest, options map[string]string) error
+	ReadStorageObjects(bearerToken string, body *api.ReadStorageObjectsRequest, options map[string]string) (*api.StorageObjects, error)
+	WriteStorageObjects(bearerToken string, body *api.WriteStorageObjectsRequest, options map[string]string) (*api.StorageObjectAcks, error)
+	DeleteStorageObjects(bearerToken string, body *api.DeleteStorageObjectsRequest, options map[string]string) error
+	ListStorageObjects(bearerToken string, collection string, userId *string, limit int, cursor string, options map[string]string) (*api.StorageObjectList, error)
+	ListStorageObjects2(bearerToken string, collection string, userId string, limit int, cursor string, options map[string]string) (*api.StorageObjectList, error)
+	ListTournaments(bearerToken string, categoryStart *int, categoryEnd *int, startTime *int64, endTime *int64, limit int, cursor string, options map[string]string) (*api.TournamentList, error)
+	DeleteTournamentRecord(bearerToken string, tournamentId string, options map[string]string) error
+	ListTournamentRecords(bearerToken string, tournamentId string, ownerIds []string, limit int, cursor string, expiry string, options map[string]string) (*api.TournamentRecordList, error)
+	WriteTournamentRecord2(bearerToken string, tournamentId string, record *api.WriteTournamentRecordRequest, options map[string]string) (*api.LeaderboardRecord, error)
+	WriteTournamentRecord(bearerToken string, tournamentId string, record *api.WriteTournamentRecordRequest_TournamentRecordWrite, options map[string]string) (*api.LeaderboardRecord, error)
+	JoinTournament(bearerToken string, tournamentId string, options map[string]string) error
+	ListTournamentRecordsAroundOwner(bearerToken string, tournamentId string, ownerId string, limit int, expiry string, cursor string, options map[string]string) (*api.TournamentRecordList, error)
+	GetUsers(bearerToken *string, ids []string, usernames []string, facebookIds []string, options map[string]string) (*api.Users, error)
+	ListUserGroups(bearerToken *string, userId string, state *int, limit *int, cursor *string, options map[string]string) (*api.UserGroupList, error)
+}
+
+var _ NakamaApiInterface = (*NakamaApi)(nil)
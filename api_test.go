@@ -1,8 +1,17 @@
 package nakama
 
 import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	api "github.com/heroiclabs/nakama-common/api"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -23,3 +32,140 @@ func TestAuthenticateWithDeviceId(t *testing.T) {
 	assert.NotNil(t, session)
 	assert.IsType(t, &Session{}, session)
 }
+
+func TestBuildPath(t *testing.T) {
+	cases := []struct {
+		id   string
+		path string
+	}{
+		{"0b5b1a5e-3c4d-4f2a-9d6e-7a8b9c0d1e2f", "/v2/group/0b5b1a5e-3c4d-4f2a-9d6e-7a8b9c0d1e2f/user"},
+		{"weekly.v2", "/v2/group/weekly.v2/user"},
+		{"top players", "/v2/group/top%20players/user"},
+		{"a+b/c", "/v2/group/a+b%2Fc/user"},
+		{"排行榜", "/v2/group/%E6%8E%92%E8%A1%8C%E6%A6%9C/user"},
+	}
+	for _, c := range cases {
+		path := buildPath("/v2/group/{groupId}/user", "groupId", c.id)
+		assert.Equal(t, c.path, path)
+
+		// the server must see the original id
+		u, err := url.Parse("http://127.0.0.1:7350" + path)
+		assert.NoError(t, err)
+		assert.Equal(t, "/v2/group/"+c.id+"/user", u.Path)
+	}
+
+	assert.Equal(t, "/v2/tournament/t%201/owner/o%3F", buildPath("/v2/tournament/{tournamentId}/owner/{ownerId}", "tournamentId", "t 1", "ownerId", "o?"))
+}
+
+// signingApi adds a signature header to the account calls.
+type signingApi struct {
+	NakamaApiInterface
+}
+
+func (s *signingApi) WithContext(ctx context.Context) NakamaApiInterface {
+	return &signingApi{s.NakamaApiInterface.WithContext(ctx)}
+}
+
+func (s *signingApi) GetAccount(bearerToken string, options map[string]string) (*api.Account, error) {
+	options["X-Signature"] = "signed"
+	return s.NakamaApiInterface.GetAccount(bearerToken, options)
+}
+
+func TestApiClientWrapper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Signature") != "signed" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"user":{"id":"user"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	assert.NoError(t, err)
+	session := Restore(testToken(time.Now().Unix()+3600), "")
+
+	_, err = client.GetAccount(session)
+	assert.Equal(t, http.StatusUnauthorized, httpStatusOf(err))
+
+	client.ApiClient = &signingApi{client.ApiClient}
+	account, err := client.WithContext(context.Background()).GetAccount(session)
+	assert.NoError(t, err)
+	assert.Equal(t, "user", account.GetUser().GetId())
+	assert.Equal(t, int64(2), client.DebugReport().Requests, "the stats are kept")
+}
+
+func TestListStorageObjectsUserFilter(t *testing.T) {
+	requests := make(chan *url.URL, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r.URL
+		w.Write([]byte(`{"objects":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	assert.NoError(t, err)
+	session := Restore(testToken(time.Now().Unix()+3600), "")
+
+	_, err = client.ListStorageObjects(session, "config", nil, 10, "")
+	assert.NoError(t, err)
+	u := <-requests
+	assert.Equal(t, "/v2/storage/config", u.Path)
+	assert.Equal(t, url.Values{"limit": {"10"}}, u.Query(), "no user_id without user filter")
+
+	userId := SystemUserId
+	_, err = client.ListStorageObjects(session, "config", &userId, 0, "")
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"user_id": {SystemUserId}}, (<-requests).Query())
+
+	_, err = client.ApiClient.ListStorageObjects2(session.Token, "config", SystemUserId, 0, "", map[string]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, "/v2/storage/config/"+SystemUserId, (<-requests).Path)
+}
+
+// countingTransport counts the calls sent through it.
+type countingTransport struct {
+	next  http.RoundTripper
+	calls atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls.Add(1)
+	return t.next.RoundTrip(req)
+}
+
+func TestHttpConnectionReuse(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/account" {
+			w.Write([]byte(`{"user":{"id":"user"}}`))
+			return
+		}
+		// an error bigger than the message read
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":3,"message":"` + strings.Repeat("x", 32<<10) + `"}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	transport := &countingTransport{next: NewHttpTransport()}
+	client, err := NewClientWithOptions(WithURL(server.URL), WithHTTPTransport(transport))
+	assert.NoError(t, err)
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+	for range 5 {
+		_, err := client.GetAccount(session)
+		assert.NoError(t, err)
+		_, err = client.ListFriends(session, nil, nil, nil)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(10), transport.calls.Load())
+	assert.Equal(t, int32(1), conns.Load(), "the connection is reused")
+
+	_, err = NewClientWithOptions(WithHTTPTransport(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,141 @@
+package nakama
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// AuthStep is a method of authentication tried by Client.Login.
+type AuthStep struct {
+	Name         string
+	Authenticate func(ctx context.Context, c *Client) (*Session, error)
+}
+
+// AuthChain is an ordered list of authentication methods, the first one succeeding ends the chain.
+type AuthChain struct {
+	Steps []AuthStep
+	// Store, if set, receives the session of the succeeding step.
+	Store SessionStore
+	// OnStep is called before each step.
+	OnStep func(step string)
+	// OnStepDone is called after each step with its error, nil on success.
+	OnStepDone func(step string, err error)
+}
+
+// AuthStepError is the failure of a step of an AuthChain.
+type AuthStepError struct {
+	Step string
+	Err  error
+}
+
+// AuthChainError is returned by Client.Login when no step has succeeded.
+type AuthChainError struct {
+	Steps []AuthStepError // the failed steps in the order they were tried
+}
+
+// Error implements the error interface.
+func (e *AuthChainError) Error() string {
+	msgs := make([]string, len(e.Steps))
+	for i, step := range e.Steps {
+		msgs[i] = fmt.Sprintf("%s: %v", step.Step, step.Err)
+	}
+	return "auth chain failed: " + strings.Join(msgs, "; ")
+}
+
+// LastStep returns the step where the chain stopped, empty if the chain had no step.
+func (e *AuthChainError) LastStep() string {
+	if len(e.Steps) == 0 {
+		return ""
+	}
+	return e.Steps[len(e.Steps)-1].Step
+}
+
+// Login runs the steps of the chain in order and returns the session of the first one succeeding.
+// The failure is an *AuthChainError describing every step tried, or the error of ctx if it's done.
+func (c *Client) Login(ctx context.Context, chain *AuthChain) (*Session, error) {
+	chainErr := &AuthChainError{}
+	for _, step := range chain.Steps {
+		if err := ctx.Err(); err != nil {
+			return nil, wrapErr(err, step.Name)
+		}
+		if chain.OnStep != nil {
+			chain.OnStep(step.Name)
+		}
+
+		session, err := step.Authenticate(ctx, c)
+		if err == nil && session == nil {
+			err = newError("no session returned")
+		}
+		if chain.OnStepDone != nil {
+			chain.OnStepDone(step.Name, err)
+		}
+		if err != nil {
+			chainErr.Steps = append(chainErr.Steps, AuthStepError{Step: step.Name, Err: err})
+			continue
+		}
+
+		if chain.Store != nil {
+			if err := chain.Store.Save(session); err != nil {
+				return nil, wrapErr(err, step.Name)
+			}
+		}
+		return session, nil
+	}
+	return nil, chainErr
+}
+
+// StoredSessionStep restores the session of the store, refreshing it when the token has expired.
+func StoredSessionStep(store SessionStore) AuthStep {
+	return AuthStep{
+		Name: "stored_session",
+		Authenticate: func(ctx context.Context, c *Client) (*Session, error) {
+			session, err := store.Load()
+			if err != nil {
+				return nil, wrapErr(err)
+			}
+			now := c.now().Unix()
+			if !session.IsExpired(now + c.ExpiredTimespanMs/1000) {
+				return session, nil
+			}
+			if session.RefreshToken == "" || session.IsRefreshExpired(now) {
+				return nil, newError("stored session has expired")
+			}
+			return c.SessionRefresh(session, nil)
+		},
+	}
+}
+
+// DeviceStep authenticates with a device id.
+func DeviceStep(deviceId string, create bool) AuthStep {
+	return AuthStep{
+		Name: "device",
+		Authenticate: func(ctx context.Context, c *Client) (*Session, error) {
+			return c.AuthenticateDevice(deviceId, &create, "", nil)
+		},
+	}
+}
+
+// CustomStep authenticates with a custom id.
+func CustomStep(customId string, create bool) AuthStep {
+	return AuthStep{
+		Name: "custom",
+		Authenticate: func(ctx context.Context, c *Client) (*Session, error) {
+			return c.AuthenticateCustom(customId, &create, nil, nil)
+		},
+	}
+}
+
+// EmailStep authenticates with the email and the password returned by prompt, e.g. a login form.
+func EmailStep(prompt func(ctx context.Context) (email, password string, err error), create bool) AuthStep {
+	return AuthStep{
+		Name: "email",
+		Authenticate: func(ctx context.Context, c *Client) (*Session, error) {
+			email, password, err := prompt(ctx)
+			if err != nil {
+				return nil, wrapErr(err)
+			}
+			return c.AuthenticateEmail(email, password, &create, nil, nil)
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package nakama
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogin_FallbackChain(t *testing.T) {
+	client := NewClient("defaultkey", "127.0.0.1", "7350", false, 0, false)
+	failing := AuthStep{Name: "failing", Authenticate: func(ctx context.Context, c *Client) (*Session, error) {
+		return nil, errors.New("unavailable")
+	}}
+	succeeding := AuthStep{Name: "succeeding", Authenticate: func(ctx context.Context, c *Client) (*Session, error) {
+		return &Session{Token: "token"}, nil
+	}}
+
+	steps := []string{}
+	session, err := client.Login(context.Background(), &AuthChain{
+		Steps:  []AuthStep{failing, succeeding},
+		OnStep: func(step string) { steps = append(steps, step) },
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "token", session.Token)
+	assert.Equal(t, []string{"failing", "succeeding"}, steps)
+
+	_, err = client.Login(context.Background(), &AuthChain{Steps: []AuthStep{failing, failing}})
+	chainErr, ok := err.(*AuthChainError)
+	assert.True(t, ok)
+	assert.Len(t, chainErr.Steps, 2)
+	assert.Equal(t, "failing", chainErr.LastStep())
+}
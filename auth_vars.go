@@ -0,0 +1,115 @@
+package nakama
+
+import (
+	"maps"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// The vars set by VarsBuilder, read by the server hooks from the session vars.
+const (
+	VarClientVersion = "client_version" // the version of the game
+	VarSdkVersion    = "sdk_version"    // the version of this SDK, see Version
+	VarPlatform      = "platform"       // the OS, e.g. "linux", "windows", "android"
+	VarArch          = "arch"           // e.g. "amd64", "arm64"
+	VarLocale        = "locale"         // a BCP 47 tag, e.g. "en-US"
+	VarDeviceModel   = "device_model"   // e.g. "Pixel 8"
+)
+
+// VarsBuilder builds the vars of the authenticate and refresh requests with a consistent naming, see WithAuthVars.
+// The empty values aren't set.
+type VarsBuilder struct {
+	vars map[string]string
+}
+
+// NewVarsBuilder creates an empty VarsBuilder.
+func NewVarsBuilder() *VarsBuilder {
+	return &VarsBuilder{vars: map[string]string{}}
+}
+
+// RuntimeVars returns a VarsBuilder preset with the client version, the SDK version, the platform and the arch
+// of the running binary, and the locale of the environment if any.
+func RuntimeVars(clientVersion string) *VarsBuilder {
+	return NewVarsBuilder().
+		ClientVersion(clientVersion).
+		Set(VarSdkVersion, Version()).
+		Platform(runtime.GOOS).
+		Set(VarArch, runtime.GOARCH).
+		Locale(envLocale())
+}
+
+// ClientVersion sets the version of the game.
+func (b *VarsBuilder) ClientVersion(version string) *VarsBuilder {
+	return b.Set(VarClientVersion, version)
+}
+
+// Platform sets the platform, e.g. "ios" for a binary reporting "darwin".
+func (b *VarsBuilder) Platform(platform string) *VarsBuilder {
+	return b.Set(VarPlatform, platform)
+}
+
+// Locale sets the locale, the POSIX locales like "en_US.UTF-8" are set as "en-US".
+func (b *VarsBuilder) Locale(locale string) *VarsBuilder {
+	return b.Set(VarLocale, normalizeLocale(locale))
+}
+
+// DeviceModel sets the model of the device.
+func (b *VarsBuilder) DeviceModel(model string) *VarsBuilder {
+	return b.Set(VarDeviceModel, model)
+}
+
+// Set sets a var of the game, an empty value removes it.
+func (b *VarsBuilder) Set(key, value string) *VarsBuilder {
+	if value == "" {
+		delete(b.vars, key)
+		return b
+	}
+	b.vars[key] = value
+	return b
+}
+
+// Build returns a copy of the vars.
+func (b *VarsBuilder) Build() map[string]string {
+	return maps.Clone(b.vars)
+}
+
+// WithAuthVars adds the vars of the builder to the vars of the authenticate and refresh requests of the client,
+// the vars given to a call override them.
+func WithAuthVars(builder *VarsBuilder) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.AuthVars = builder.Build()
+		return nil
+	}
+}
+
+// authVars returns the vars of an authenticate or refresh request, vars override the vars of WithAuthVars.
+func (c *Client) authVars(vars map[string]string) map[string]string {
+	if len(c.defaultVars) == 0 {
+		return vars
+	}
+	merged := maps.Clone(c.defaultVars)
+	maps.Copy(merged, vars)
+	return merged
+}
+
+// envLocale returns the locale of the POSIX environment, empty if none.
+func envLocale() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(key); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+// normalizeLocale turns a POSIX locale like "en_US.UTF-8" into "en-US", "C" and "POSIX" are no locale.
+func normalizeLocale(locale string) string {
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "C" || locale == "POSIX" {
+		return ""
+	}
+	return strings.ReplaceAll(locale, "_", "-")
+}
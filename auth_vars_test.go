@@ -0,0 +1,48 @@
+package nakama
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthVars(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "pt_BR.UTF-8")
+	vars := RuntimeVars("1.2.0").DeviceModel("Pixel 8").Build()
+	assert.Equal(t, map[string]string{
+		VarClientVersion: "1.2.0",
+		VarSdkVersion:    Version(),
+		VarPlatform:      runtime.GOOS,
+		VarArch:          runtime.GOARCH,
+		VarLocale:        "pt-BR",
+		VarDeviceModel:   "Pixel 8",
+	}, vars)
+	assert.NotContains(t, NewVarsBuilder().Locale("C").Build(), VarLocale)
+
+	requests := make(chan map[string]string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		account := struct {
+			Vars map[string]string `json:"vars"`
+		}{}
+		assert.NoError(t, json.Unmarshal(body, &account))
+		requests <- account.Vars
+		w.Write([]byte(`{"token":"` + testToken(2000000000) + `"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL), WithAuthVars(NewVarsBuilder().ClientVersion("1.2.0").Platform("ios")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.AuthenticateDevice("device-id-0001", nil, "", map[string]string{VarPlatform: "ipados", "ab": "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{VarClientVersion: "1.2.0", VarPlatform: "ipados", "ab": "b"}, <-requests, "the vars of the call override")
+}
@@ -0,0 +1,163 @@
+// Package backoff holds the delay, jitter and rate limit primitives used by the retries of the client
+// and the reconnects of the socket, for the game code orchestrating its own retries, e.g. rejoining a match.
+package backoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Exponential computes a delay growing by Multiplier after each attempt.
+type Exponential struct {
+	Initial    time.Duration // The delay after the first attempt.
+	Max        time.Duration // The upper bound of the delay, 0 means no bound.
+	Multiplier float64       // The growth of the delay between two attempts, 0 or 1 keeps it constant.
+}
+
+// Delay returns the delay after the attempt, attempt starts at 1.
+func (e Exponential) Delay(attempt int) time.Duration {
+	delay := e.Initial
+	for i := 1; i < attempt && e.Multiplier > 1; i++ {
+		delay = time.Duration(float64(delay) * e.Multiplier)
+		if e.Max > 0 && delay >= e.Max {
+			return e.Max
+		}
+	}
+	return delay
+}
+
+// Jitter returns d plus a random delay in [0, jitter).
+func Jitter(d, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return d
+	}
+	return d + time.Duration(rand.Int63n(int64(jitter)))
+}
+
+// FullJitter returns a random delay in [0, d).
+func FullJitter(d time.Duration) time.Duration {
+	return Jitter(0, d)
+}
+
+// Sleep waits for d or until ctx is done, it returns the error of ctx in the latter case.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error returned to Retry as not worth a retry, it may be wrapped by fn.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Retry calls fn until it succeeds, returns a Permanent error or maxAttempts calls have failed,
+// waiting the delay of e between two calls. maxAttempts <= 0 means no limit.
+// The error of the last call is returned, or the error of ctx if it's done while waiting.
+func Retry(ctx context.Context, e Exponential, maxAttempts int, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		var p *permanentError
+		if errors.As(err, &p) {
+			if err == error(p) {
+				return p.err
+			}
+			return err
+		}
+		if maxAttempts > 0 && attempt >= maxAttempts {
+			return err
+		}
+		if sErr := Sleep(ctx, e.Delay(attempt)); sErr != nil {
+			return fmt.Errorf("attempt %d: %w", attempt, sErr)
+		}
+	}
+}
+
+// Budget is a token bucket limiting the rate of the attempts, it's safe for concurrent use.
+type Budget struct {
+	mu        sync.Mutex
+	perSecond float64
+	burst     float64
+	tokens    float64
+	last      time.Time
+}
+
+// NewBudget creates a budget allowing perSecond attempts with bursts of burst attempts.
+func NewBudget(perSecond float64, burst int) *Budget {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Budget{
+		perSecond: perSecond,
+		burst:     float64(burst),
+		tokens:    float64(burst),
+		last:      time.Now(),
+	}
+}
+
+// Reserve takes a token and returns zero, or returns how long to wait for the next token.
+func (b *Budget) Reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.perSecond
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	if b.perSecond <= 0 {
+		return time.Second
+	}
+	return time.Duration((1 - b.tokens) / b.perSecond * float64(time.Second))
+}
+
+// Wait blocks until an attempt is allowed or ctx is done, it returns the error of ctx in the latter case.
+// A nil budget allows every attempt.
+func (b *Budget) Wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		wait := b.Reserve()
+		if wait == 0 {
+			return nil
+		}
+		if err := Sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package backoff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestExponentialDelay(t *testing.T) {
+	e := Exponential{Initial: 100 * time.Millisecond, Max: time.Second, Multiplier: 2}
+	expected := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, d := range expected {
+		if got := e.Delay(i + 1); got != d*time.Millisecond {
+			t.Fatalf("attempt %d: %s", i+1, got)
+		}
+	}
+	if got := (Exponential{Initial: time.Second}).Delay(5); got != time.Second {
+		t.Fatalf("constant delay: %s", got)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := Jitter(time.Second, 10*time.Millisecond)
+		if d < time.Second || d >= time.Second+10*time.Millisecond {
+			t.Fatalf("out of range: %s", d)
+		}
+	}
+	if d := Jitter(time.Second, 0); d != time.Second {
+		t.Fatalf("no jitter: %s", d)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	e := Exponential{Initial: time.Millisecond}
+	calls := 0
+	err := Retry(context.Background(), e, 3, func(ctx context.Context) error {
+		calls++
+		return errors.New("transient")
+	})
+	if err == nil || calls != 3 {
+		t.Fatalf("calls %d, err %v", calls, err)
+	}
+
+	calls = 0
+	permanent := errors.New("permanent")
+	err = Retry(context.Background(), e, 0, func(ctx context.Context) error {
+		calls++
+		return Permanent(permanent)
+	})
+	if err != permanent || calls != 1 {
+		t.Fatalf("calls %d, err %v", calls, err)
+	}
+
+	// a wrapped permanent error stops the retries too, it's returned with its context
+	calls = 0
+	err = Retry(context.Background(), e, 0, func(ctx context.Context) error {
+		calls++
+		return fmt.Errorf("load: %w", Permanent(permanent))
+	})
+	if !errors.Is(err, permanent) || err.Error() != "load: permanent" || calls != 1 {
+		t.Fatalf("calls %d, err %v", calls, err)
+	}
+
+	calls = 0
+	err = Retry(context.Background(), e, 0, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("calls %d, err %v", calls, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = Retry(ctx, Exponential{Initial: time.Hour}, 0, func(ctx context.Context) error {
+		return errors.New("transient")
+	})
+	if err == nil {
+		t.Fatal("expected the error of the context")
+	}
+}
+
+func TestBudget(t *testing.T) {
+	b := NewBudget(0, 2)
+	if b.Reserve() != 0 || b.Reserve() != 0 {
+		t.Fatal("the burst should be allowed")
+	}
+	if b.Reserve() == 0 {
+		t.Fatal("the budget should be exhausted")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if b.Wait(ctx) != context.Canceled {
+		t.Fatal("canceled wait should fail")
+	}
+	var nilBudget *Budget
+	if nilBudget.Wait(context.Background()) != nil {
+		t.Fatal("nil budget allows everything")
+	}
+
+	// a long wait returns once ctx is done
+	b = NewBudget(0.001, 1)
+	b.Reserve()
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if b.Wait(ctx) != context.DeadlineExceeded || time.Since(start) > time.Second {
+		t.Fatalf("the wait took %s", time.Since(start))
+	}
+}
@@ -0,0 +1,132 @@
+package nakama
+
+import (
+	"sync"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// chatJoinKey identifies a chat join, joining twice with the same key returns the same channel.
+type chatJoinKey struct {
+	target      string
+	chatType    int32
+	persistence bool
+}
+
+// joinedChat is a chat joined or being joined by the socket.
+type joinedChat struct {
+	join    *rtapi.ChannelJoin
+	ready   chan struct{} // closed when the join has completed
+	channel *rtapi.Channel
+	err     error
+}
+
+// chatJoins tracks the chats joined by a socket, so the joins are idempotent and rejoined after a reconnect.
+type chatJoins struct {
+	mu    sync.Mutex
+	chats map[chatJoinKey]*joinedChat
+}
+
+// join joins the chat once per key, the concurrent joins of a key wait for the first one.
+func (cj *chatJoins) join(join *rtapi.ChannelJoin, joinFn func(*rtapi.ChannelJoin) (*rtapi.Channel, error)) (*rtapi.Channel, error) {
+	key := chatJoinKey{target: join.Target, chatType: join.Type, persistence: join.GetPersistence().GetValue()}
+
+	cj.mu.Lock()
+	if cj.chats == nil {
+		cj.chats = map[chatJoinKey]*joinedChat{}
+	}
+	chat, ok := cj.chats[key]
+	if !ok {
+		chat = &joinedChat{join: join, ready: make(chan struct{})}
+		cj.chats[key] = chat
+	}
+	cj.mu.Unlock()
+	if !ok {
+		channel, err := joinFn(join)
+		cj.mu.Lock()
+		chat.channel, chat.err = channel, err
+		if err != nil {
+			delete(cj.chats, key)
+		}
+		cj.mu.Unlock()
+		close(chat.ready)
+	}
+
+	<-chat.ready
+	cj.mu.Lock()
+	defer cj.mu.Unlock()
+	if chat.err != nil {
+		return nil, wrapErr(chat.err, join.Target)
+	}
+	return chat.channel, nil
+}
+
+// leave forgets the chat of the channel.
+func (cj *chatJoins) leave(channelId string) {
+	cj.mu.Lock()
+	defer cj.mu.Unlock()
+	for key, chat := range cj.chats {
+		if chat.channel != nil && chat.channel.Id == channelId {
+			delete(cj.chats, key)
+		}
+	}
+}
+
+// persisted tells if the channel is joined with persistence on, joined is false if it isn't joined.
+func (cj *chatJoins) persisted(channelId string) (persisted, joined bool) {
+	cj.mu.Lock()
+	defer cj.mu.Unlock()
+	for key, chat := range cj.chats {
+		if chat.channel != nil && chat.channel.Id == channelId {
+			joined = true
+			persisted = persisted || key.persistence
+		}
+	}
+	return persisted, joined
+}
+
+// rejoin joins again the chats joined before a reconnect, the chats failing to join are forgotten.
+func (cj *chatJoins) rejoin(joinFn func(*rtapi.ChannelJoin) (*rtapi.Channel, error)) {
+	cj.mu.Lock()
+	chats := make(map[chatJoinKey]*joinedChat, len(cj.chats))
+	for key, chat := range cj.chats {
+		if chat.channel != nil {
+			chats[key] = chat
+		}
+	}
+	cj.mu.Unlock()
+
+	for key, chat := range chats {
+		channel, err := joinFn(chat.join)
+		cj.mu.Lock()
+		if err != nil {
+			GetLogger().Warn(wrapErr(err, "rejoin", chat.join.Target))
+			if cj.chats[key] == chat {
+				delete(cj.chats, key)
+			}
+		} else {
+			chat.channel = channel
+		}
+		cj.mu.Unlock()
+	}
+}
+
+// clear forgets all the chats, e.g. after Disconnect since the server has left them.
+func (cj *chatJoins) clear() {
+	cj.mu.Lock()
+	defer cj.mu.Unlock()
+	cj.chats = nil
+}
+
+// channels returns the channels joined by the socket.
+func (cj *chatJoins) channels() []*rtapi.Channel {
+	cj.mu.Lock()
+	defer cj.mu.Unlock()
+	channels := make([]*rtapi.Channel, 0, len(cj.chats))
+	for _, chat := range cj.chats {
+		if chat.channel != nil {
+			channels = append(channels, chat.channel)
+		}
+	}
+	return channels
+}
@@ -0,0 +1,62 @@
+package nakama
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestChatJoinsIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	fail := false
+	joinFn := func(join *rtapi.ChannelJoin) (*rtapi.Channel, error) {
+		n := calls.Add(1)
+		if fail {
+			return nil, errors.New("join failed")
+		}
+		return &rtapi.Channel{Id: join.Target + string(rune('0'+n))}, nil
+	}
+	join := func(target string, persistence bool) *rtapi.ChannelJoin {
+		return &rtapi.ChannelJoin{Target: target, Type: 1, Persistence: wrapperspb.Bool(persistence)}
+	}
+	cj := &chatJoins{}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			channel, err := cj.join(join("room", true), joinFn)
+			assert.NoError(t, err)
+			assert.Equal(t, "room1", channel.Id)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+
+	// another persistence is another join
+	_, err := cj.join(join("room", false), joinFn)
+	assert.NoError(t, err)
+	assert.Len(t, cj.channels(), 2)
+
+	// the channels are updated by the rejoin
+	cj.rejoin(joinFn)
+	assert.Equal(t, int32(4), calls.Load())
+	channel, err := cj.join(join("room", true), joinFn)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "room1", channel.Id)
+
+	cj.leave(channel.Id)
+	assert.Len(t, cj.channels(), 1)
+
+	// the failed joins aren't kept
+	fail = true
+	_, err = cj.join(join("lobby", true), joinFn)
+	assert.Error(t, err)
+	assert.Len(t, cj.channels(), 1)
+}
@@ -0,0 +1,90 @@
+package nakama
+
+import (
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+var (
+	// ErrChatMessageNotFound is returned when the message doesn't exist in the channel history,
+	// or the current user isn't allowed to change it, e.g. not its sender.
+	ErrChatMessageNotFound = newError("chat message not found or permission denied")
+	// ErrChatInvalidRequest is returned when the server rejects the request, e.g. an empty content.
+	ErrChatInvalidRequest = newError("invalid chat request")
+)
+
+// chatError maps the socket errors of the chat moderation calls to the typed errors.
+func chatError(err error, channelId, messageId string) error {
+	code, ok := socketErrorCode(err)
+	if !ok {
+		return wrapErr(err, channelId, messageId)
+	}
+	switch code {
+	case rtapi.Error_BAD_INPUT:
+		return ErrChatMessageNotFound.With(channelId, messageId, err)
+	case rtapi.Error_MISSING_PAYLOAD, rtapi.Error_UNRECOGNIZED_PAYLOAD:
+		return ErrChatInvalidRequest.With(channelId, messageId, err)
+	}
+	return wrapErr(err, channelId, messageId)
+}
+
+// ChatModeration updates and removes the chat messages, and reports the changes made by the other users.
+type ChatModeration struct {
+	// OnMessageUpdated is called when a message of a joined channel has been updated.
+	OnMessageUpdated func(message *api.ChannelMessage)
+	// OnMessageRemoved is called when a message of a joined channel has been removed.
+	OnMessageRemoved func(channelId, messageId string, message *api.ChannelMessage)
+
+	socket *DefaultSocket
+}
+
+// NewChatModeration creates a ChatModeration sending through the socket.
+func NewChatModeration(socket *DefaultSocket) *ChatModeration {
+	return &ChatModeration{socket: socket}
+}
+
+// UpdateMessage replaces the content of a message, content is a JSON object.
+func (m *ChatModeration) UpdateMessage(channelId, messageId, content string) (*rtapi.ChannelMessageAck, error) {
+	if channelId == "" || messageId == "" {
+		return nil, ErrChatInvalidRequest.With("'channelId' and 'messageId' are required")
+	}
+	ack, err := m.socket.UpdateChatMessage(channelId, messageId, content)
+	if err != nil {
+		return nil, chatError(err, channelId, messageId)
+	}
+	return ack, nil
+}
+
+// RemoveMessage removes a message from the channel history.
+func (m *ChatModeration) RemoveMessage(channelId, messageId string) (*rtapi.ChannelMessageAck, error) {
+	if channelId == "" || messageId == "" {
+		return nil, ErrChatInvalidRequest.With("'channelId' and 'messageId' are required")
+	}
+	ack, err := m.socket.RemoveChatMessage(channelId, messageId)
+	if err != nil {
+		return nil, chatError(err, channelId, messageId)
+	}
+	return ack, nil
+}
+
+// HandleEvent is an EventHandler calling the callbacks of the updated and removed messages,
+// chain it in the EventHandler passed to CreateSocket.
+func (m *ChatModeration) HandleEvent(event EventType, data *RspResult) {
+	if event != EventTypeMessage || data == nil || data.Decoded == nil {
+		return
+	}
+	message := data.Decoded.GetChannelMessage()
+	if message == nil {
+		return
+	}
+	switch message.GetCode().GetValue() {
+	case ChannelMessageTypeChatUpdate:
+		if m.OnMessageUpdated != nil {
+			m.OnMessageUpdated(message)
+		}
+	case ChannelMessageTypeChatRemove:
+		if m.OnMessageRemoved != nil {
+			m.OnMessageRemoved(message.ChannelId, message.MessageId, message)
+		}
+	}
+}
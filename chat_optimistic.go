@@ -0,0 +1,186 @@
+package nakama
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The domain events of the messages sent by ChatService.SendOptimistic, their payload is the *PendingMessage.
+// The echo of these messages on the socket is not published as DomainEventChatMessage.
+const (
+	DomainEventChatMessagePending   DomainEventKind = "chat_message_pending"
+	DomainEventChatMessageConfirmed DomainEventKind = "chat_message_confirmed"
+	DomainEventChatMessageFailed    DomainEventKind = "chat_message_failed"
+)
+
+// echoTimeout is how long an acked message waits for its echo.
+const echoTimeout = time.Minute
+
+var localMessageSeq atomic.Int64
+
+// PendingMessage is a chat message shown before the server has confirmed it.
+type PendingMessage struct {
+	LocalId string // the id of the message until the server gives one
+	// Message is provisional with an empty MessageId until confirmed,
+	// then it's updated with the id and the times of the server.
+	Message *api.ChannelMessage
+
+	done   chan struct{}
+	err    error
+	echoed bool
+}
+
+// Wait blocks until the message has been confirmed or has failed.
+func (p *PendingMessage) Wait(ctx context.Context) (*api.ChannelMessage, error) {
+	select {
+	case <-ctx.Done():
+		return nil, wrapErr(ctx.Err(), p.LocalId)
+	case <-p.done:
+		if p.err != nil {
+			return nil, p.err
+		}
+		return p.Message, nil
+	}
+}
+
+// optimisticChat tracks the messages sent by SendOptimistic until their echo is received.
+type optimisticChat struct {
+	mu      sync.Mutex
+	pending []*PendingMessage          // not acked yet, the oldest first
+	acked   map[string]*PendingMessage // message id:acked message waiting for its echo
+}
+
+// SendOptimistic publishes a provisional message at once and sends it in the background,
+// the returned PendingMessage is reconciled with the ack or the echo of the server by message id.
+func (s *ChatService) SendOptimistic(channelId, content string) (*PendingMessage, error) {
+	session, err := s.sdk.requireSession()
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	socket, err := s.sdk.requireSocket()
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	now := timestamppb.Now()
+	p := &PendingMessage{
+		LocalId: "local-" + strconv.FormatInt(localMessageSeq.Add(1), 10),
+		Message: &api.ChannelMessage{
+			ChannelId:  channelId,
+			SenderId:   session.UserID,
+			Username:   session.Username,
+			Content:    content,
+			CreateTime: now,
+			UpdateTime: now,
+		},
+		done: make(chan struct{}),
+	}
+	s.optimistic.mu.Lock()
+	s.optimistic.pending = append(s.optimistic.pending, p)
+	s.optimistic.mu.Unlock()
+	s.sdk.Events.Publish(&DomainEvent{Kind: DomainEventChatMessagePending, Payload: p})
+
+	err = socket.lifecycle.Go(func(ctx context.Context) error {
+		ack, err := socket.WriteChatMessage(channelId, content)
+		if err != nil {
+			s.fail(p, wrapErr(err, channelId))
+			return nil
+		}
+		s.acked(p, ack)
+		return nil
+	})
+	if err != nil {
+		s.fail(p, wrapErr(err, channelId))
+	}
+	return p, nil
+}
+
+func (s *ChatService) removePending(p *PendingMessage) {
+	for i, pending := range s.optimistic.pending {
+		if pending == p {
+			s.optimistic.pending = append(s.optimistic.pending[:i], s.optimistic.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *ChatService) fail(p *PendingMessage, err error) {
+	s.optimistic.mu.Lock()
+	s.removePending(p)
+	p.err = err
+	close(p.done)
+	s.optimistic.mu.Unlock()
+	s.sdk.Events.Publish(&DomainEvent{Kind: DomainEventChatMessageFailed, Payload: p})
+}
+
+func (s *ChatService) acked(p *PendingMessage, ack *rtapi.ChannelMessageAck) {
+	s.optimistic.mu.Lock()
+	s.removePending(p)
+	if !p.echoed {
+		p.Message.MessageId = ack.GetMessageId()
+		p.Message.Code = ack.GetCode()
+		p.Message.Username = ack.GetUsername()
+		p.Message.CreateTime = ack.GetCreateTime()
+		p.Message.UpdateTime = ack.GetUpdateTime()
+		p.Message.Persistent = ack.GetPersistent()
+		if s.optimistic.acked == nil {
+			s.optimistic.acked = map[string]*PendingMessage{}
+		}
+		messageId := ack.GetMessageId()
+		s.optimistic.acked[messageId] = p
+		// forget it if the echo never comes, e.g. the socket has reconnected
+		time.AfterFunc(echoTimeout, func() {
+			s.optimistic.mu.Lock()
+			delete(s.optimistic.acked, messageId)
+			s.optimistic.mu.Unlock()
+		})
+	}
+	close(p.done)
+	s.optimistic.mu.Unlock()
+	s.sdk.Events.Publish(&DomainEvent{Kind: DomainEventChatMessageConfirmed, Payload: p})
+}
+
+// suppressEcho reports whether the envelope is the echo of a message sent by SendOptimistic.
+// An echo received before the ack gives its message id to the oldest pending message with the same content.
+func (s *ChatService) suppressEcho(envelope *rtapi.Envelope) bool {
+	message := envelope.GetChannelMessage()
+	if message == nil || message.GetCode().GetValue() != ChannelMessageTypeChat {
+		return false
+	}
+	session := s.sdk.Session()
+	if session == nil || message.SenderId != session.UserID {
+		return false
+	}
+
+	s.optimistic.mu.Lock()
+	defer s.optimistic.mu.Unlock()
+	if _, ok := s.optimistic.acked[message.MessageId]; ok {
+		delete(s.optimistic.acked, message.MessageId)
+		return true
+	}
+	for _, p := range s.optimistic.pending {
+		if p.echoed || p.Message.ChannelId != message.ChannelId || p.Message.Content != message.Content {
+			continue
+		}
+		p.echoed = true
+		p.Message.MessageId = message.MessageId
+		p.Message.Code = message.Code
+		p.Message.Username = message.Username
+		p.Message.CreateTime = message.CreateTime
+		p.Message.UpdateTime = message.UpdateTime
+		p.Message.Persistent = message.Persistent
+		p.Message.RoomName = message.RoomName
+		p.Message.GroupId = message.GroupId
+		p.Message.UserIdOne = message.UserIdOne
+		p.Message.UserIdTwo = message.UserIdTwo
+		return true
+	}
+	return false
+}
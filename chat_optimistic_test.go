@@ -0,0 +1,65 @@
+package nakama
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+func TestOptimisticChatReconcile(t *testing.T) {
+	sdk := NewNakamaSDK(nil, nil)
+	sdk.SetSession(&Session{UserID: "u1", Username: "me"})
+	var published []DomainEventKind
+	sdk.Events.Subscribe("", func(event *DomainEvent) { published = append(published, event.Kind) })
+
+	newPending := func(content string) *PendingMessage {
+		p := &PendingMessage{
+			LocalId: "local",
+			Message: &api.ChannelMessage{ChannelId: "room", SenderId: "u1", Content: content},
+			done:    make(chan struct{}),
+		}
+		sdk.Chat.optimistic.pending = append(sdk.Chat.optimistic.pending, p)
+		return p
+	}
+	echo := func(messageId, content string) *rtapi.Envelope {
+		return &rtapi.Envelope{Message: &rtapi.Envelope_ChannelMessage{ChannelMessage: &api.ChannelMessage{
+			ChannelId: "room", MessageId: messageId, SenderId: "u1", Content: content,
+		}}}
+	}
+
+	// the echo comes before the ack
+	first := newPending(`{"text":"a"}`)
+	if !sdk.Chat.suppressEcho(echo("m1", `{"text":"a"}`)) {
+		t.Fatal("the echo should be suppressed")
+	}
+	if first.Message.MessageId != "m1" {
+		t.Fatalf("message id %q", first.Message.MessageId)
+	}
+	sdk.Chat.acked(first, &rtapi.ChannelMessageAck{MessageId: "m1"})
+	if msg, err := first.Wait(context.Background()); err != nil || msg.MessageId != "m1" {
+		t.Fatalf("wait: %v %v", msg, err)
+	}
+
+	// the ack comes before the echo
+	second := newPending(`{"text":"b"}`)
+	sdk.Chat.acked(second, &rtapi.ChannelMessageAck{MessageId: "m2", Username: "me"})
+	if second.Message.MessageId != "m2" {
+		t.Fatalf("message id %q", second.Message.MessageId)
+	}
+	if !sdk.Chat.suppressEcho(echo("m2", `{"text":"b"}`)) {
+		t.Fatal("the echo should be suppressed")
+	}
+
+	// the messages of the others are not echoes
+	if sdk.Chat.suppressEcho(echo("m3", `{"text":"b"}`)) {
+		t.Fatal("an unknown message isn't an echo")
+	}
+	if len(sdk.Chat.optimistic.pending) != 0 || len(sdk.Chat.optimistic.acked) != 0 {
+		t.Fatalf("leftovers %d %d", len(sdk.Chat.optimistic.pending), len(sdk.Chat.optimistic.acked))
+	}
+	if len(published) != 2 || published[0] != DomainEventChatMessageConfirmed || published[1] != DomainEventChatMessageConfirmed {
+		t.Fatalf("published %v", published)
+	}
+}
@@ -0,0 +1,213 @@
+package nakama
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// Chat signal defaults
+const (
+	DefaultChatSignalNamespace = "nk.signal"
+	DefaultTypingDebounce      = 2 * time.Second
+	DefaultTypingTimeout       = 6 * time.Second
+)
+
+// ErrChatSignalPersisted is returned when a signal is sent to a channel not joined by JoinChat with persistence off,
+// the server would keep the signal in the history of the channel.
+var ErrChatSignalPersisted = newError("chat signal on a channel with persistence")
+
+// Chat signal op codes
+const (
+	ChatSignalOpTyping     = int64(1)
+	ChatSignalOpTypingStop = int64(2)
+	ChatSignalOpRead       = int64(3)
+)
+
+// ChatSignal is the content of a chat message carrying a signal instead of a text.
+// The signals are sent to the channels joined with persistence off only, e.g. JoinChat(GroupTarget(id).WithPersistence(false)).
+type ChatSignal struct {
+	Namespace string `json:"ns"`
+	OpCode    int64  `json:"op"`
+	MessageId string `json:"message_id,omitempty"`
+}
+
+// ChatSignals implements the typing indicators and the read receipts over the chat messages.
+type ChatSignals struct {
+	Namespace      string
+	TypingDebounce time.Duration
+	TypingTimeout  time.Duration
+
+	// OnTyping is called when a user starts or stops typing in a channel.
+	OnTyping func(channelId, userId string, typing bool)
+	// OnRead is called when a user has read a channel up to a message.
+	OnRead func(channelId, userId, messageId string)
+
+	socket *DefaultSocket
+
+	mu         sync.Mutex
+	lastTyping map[string]time.Time            // channel id:last typing sent
+	typing     map[string]map[string]time.Time // channel id:user id:last typing received
+	reads      map[string]map[string]string    // channel id:user id:message id
+}
+
+// NewChatSignals creates a ChatSignals sending through the socket, namespace defaults to DefaultChatSignalNamespace.
+func NewChatSignals(socket *DefaultSocket, namespace string) *ChatSignals {
+	if namespace == "" {
+		namespace = DefaultChatSignalNamespace
+	}
+	return &ChatSignals{
+		Namespace:      namespace,
+		TypingDebounce: DefaultTypingDebounce,
+		TypingTimeout:  DefaultTypingTimeout,
+		socket:         socket,
+		lastTyping:     map[string]time.Time{},
+		typing:         map[string]map[string]time.Time{},
+		reads:          map[string]map[string]string{},
+	}
+}
+
+func (cs *ChatSignals) send(channelId string, signal *ChatSignal) error {
+	if persisted, joined := cs.socket.chats.persisted(channelId); persisted || !joined {
+		return ErrChatSignalPersisted.With(channelId)
+	}
+	signal.Namespace = cs.Namespace
+	content, err := json.Marshal(signal)
+	if err != nil {
+		return wrapErr(err)
+	}
+	if _, err := cs.socket.WriteChatMessage(channelId, string(content)); err != nil {
+		return wrapErr(err, channelId)
+	}
+	return nil
+}
+
+// SendTyping tells the channel the current user is typing, the calls within TypingDebounce are dropped.
+func (cs *ChatSignals) SendTyping(channelId string) error {
+	cs.mu.Lock()
+	if time.Since(cs.lastTyping[channelId]) < cs.TypingDebounce {
+		cs.mu.Unlock()
+		return nil
+	}
+	cs.lastTyping[channelId] = time.Now()
+	cs.mu.Unlock()
+
+	if err := cs.send(channelId, &ChatSignal{OpCode: ChatSignalOpTyping}); err != nil {
+		cs.mu.Lock()
+		delete(cs.lastTyping, channelId)
+		cs.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// SendTypingStop tells the channel the current user has stopped typing.
+func (cs *ChatSignals) SendTypingStop(channelId string) error {
+	cs.mu.Lock()
+	delete(cs.lastTyping, channelId)
+	cs.mu.Unlock()
+
+	return cs.send(channelId, &ChatSignal{OpCode: ChatSignalOpTypingStop})
+}
+
+// SendReadReceipt tells the channel the current user has read the messages up to messageId.
+func (cs *ChatSignals) SendReadReceipt(channelId, messageId string) error {
+	if messageId == "" {
+		return newError("'messageId' is a required parameter but is empty")
+	}
+	return cs.send(channelId, &ChatSignal{OpCode: ChatSignalOpRead, MessageId: messageId})
+}
+
+// HandleEvent is an EventHandler tracking the signals received on the socket,
+// chain it in the EventHandler passed to CreateSocket.
+func (cs *ChatSignals) HandleEvent(event EventType, data *RspResult) {
+	if event != EventTypeMessage || data == nil || data.Decoded == nil {
+		return
+	}
+	msg, ok := data.Decoded.GetMessage().(*rtapi.Envelope_ChannelMessage)
+	if !ok {
+		return
+	}
+	cs.handleMessage(msg.ChannelMessage.GetChannelId(), msg.ChannelMessage.GetSenderId(), msg.ChannelMessage.GetContent())
+}
+
+func (cs *ChatSignals) handleMessage(channelId, userId, content string) {
+	signal := &ChatSignal{}
+	if err := json.Unmarshal([]byte(content), signal); err != nil || signal.Namespace != cs.Namespace {
+		// a regular message ends the typing of the sender
+		cs.setTyping(channelId, userId, false)
+		return
+	}
+
+	switch signal.OpCode {
+	case ChatSignalOpTyping:
+		cs.setTyping(channelId, userId, true)
+	case ChatSignalOpTypingStop:
+		cs.setTyping(channelId, userId, false)
+	case ChatSignalOpRead:
+		cs.mu.Lock()
+		users, ok := cs.reads[channelId]
+		if !ok {
+			users = map[string]string{}
+			cs.reads[channelId] = users
+		}
+		users[userId] = signal.MessageId
+		cs.mu.Unlock()
+		if cs.OnRead != nil {
+			cs.OnRead(channelId, userId, signal.MessageId)
+		}
+	}
+}
+
+func (cs *ChatSignals) setTyping(channelId, userId string, typing bool) {
+	cs.mu.Lock()
+	users, ok := cs.typing[channelId]
+	if !ok {
+		users = map[string]time.Time{}
+		cs.typing[channelId] = users
+	}
+	_, wasTyping := users[userId]
+	if typing {
+		users[userId] = time.Now()
+	} else {
+		delete(users, userId)
+	}
+	cs.mu.Unlock()
+
+	if cs.OnTyping != nil && (typing || wasTyping) {
+		cs.OnTyping(channelId, userId, typing)
+	}
+}
+
+// TypingUsers returns the users typing in the channel, the users silent for TypingTimeout are dropped.
+func (cs *ChatSignals) TypingUsers(channelId string) []string {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	userIds := []string{}
+	for userId, at := range cs.typing[channelId] {
+		if time.Since(at) > cs.TypingTimeout {
+			delete(cs.typing[channelId], userId)
+			continue
+		}
+		userIds = append(userIds, userId)
+	}
+	return userIds
+}
+
+// LastRead returns the last message read by the user in the channel, empty if unknown.
+func (cs *ChatSignals) LastRead(channelId, userId string) string {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.reads[channelId][userId]
+}
+
+// ForgetChannel drops the state tracked for the channel, call it after leaving the channel.
+func (cs *ChatSignals) ForgetChannel(channelId string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	delete(cs.lastTyping, channelId)
+	delete(cs.typing, channelId)
+	delete(cs.reads, channelId)
+}
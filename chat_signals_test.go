@@ -0,0 +1,74 @@
+package nakama
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatSignalsSend(t *testing.T) {
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		answerChannelJoins(conn, req)
+		if send := req.GetChannelMessageSend(); send != nil {
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_ChannelMessageAck{ChannelMessageAck: &rtapi.ChannelMessageAck{ChannelId: send.ChannelId}}})
+		}
+	})
+	socket, _ := server.socket(nil)
+	assert.NoError(t, socket.Connect())
+	signals := NewChatSignals(socket, "")
+
+	// the signals aren't sent to a channel keeping the history, nor to a channel not joined
+	history, err := socket.JoinChat(GroupTarget("group"))
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(signals.SendTyping(history.Id), ErrChatSignalPersisted))
+	assert.True(t, errors.Is(signals.SendReadReceipt("2...other", "m1"), ErrChatSignalPersisted))
+	assert.Empty(t, server.requestsOf("channel_message_send"))
+
+	lobby, err := socket.JoinChat(RoomTarget("lobby", false, false))
+	assert.NoError(t, err)
+	assert.NoError(t, signals.SendTyping(lobby.Id))
+	assert.NoError(t, signals.SendTyping(lobby.Id), "debounced")
+	assert.NoError(t, signals.SendTypingStop(lobby.Id))
+	assert.NoError(t, signals.SendReadReceipt(lobby.Id, "m1"))
+	assert.Error(t, signals.SendReadReceipt(lobby.Id, ""))
+
+	contents := []string{}
+	for _, req := range server.requestsOf("channel_message_send") {
+		assert.Equal(t, lobby.Id, req.GetChannelMessageSend().ChannelId)
+		contents = append(contents, req.GetChannelMessageSend().Content)
+	}
+	assert.Equal(t, []string{
+		`{"ns":"nk.signal","op":1}`,
+		`{"ns":"nk.signal","op":2}`,
+		`{"ns":"nk.signal","op":3,"message_id":"m1"}`,
+	}, contents)
+}
+
+func TestChatSignalsReceive(t *testing.T) {
+	signals := NewChatSignals(nil, "game")
+	typing := []bool{}
+	signals.OnTyping = func(channelId, userId string, isTyping bool) {
+		typing = append(typing, isTyping)
+	}
+	read := ""
+	signals.OnRead = func(channelId, userId, messageId string) {
+		read = messageId
+	}
+
+	signals.handleMessage("c", "u", `{"ns":"game","op":1}`)
+	assert.Equal(t, []string{"u"}, signals.TypingUsers("c"))
+
+	// a regular message ends the typing, the signals of another namespace are regular messages
+	signals.handleMessage("c", "u", `{"ns":"other","op":1}`)
+	assert.Empty(t, signals.TypingUsers("c"))
+	assert.Equal(t, []bool{true, false}, typing)
+
+	signals.handleMessage("c", "u", `{"ns":"game","op":3,"message_id":"m2"}`)
+	assert.Equal(t, "m2", read)
+	assert.Equal(t, "m2", signals.LastRead("c", "u"))
+
+	signals.ForgetChannel("c")
+	assert.Empty(t, signals.LastRead("c", "u"))
+}
@@ -0,0 +1,65 @@
+package nakama
+
+import (
+	"github.com/heroiclabs/nakama-common/rtapi"
+	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ChatTarget is the chat joined by JoinChat, built by RoomTarget, DirectTarget or GroupTarget.
+type ChatTarget struct {
+	target      string
+	chatType    rtapi.ChannelJoin_Type
+	persistence bool
+	hidden      bool
+}
+
+// RoomTarget returns the target of the chat room name, persistence keeps its messages on the server
+// and hidden hides the user from the presences of the room.
+func RoomTarget(name string, persistence, hidden bool) ChatTarget {
+	return ChatTarget{target: name, chatType: rtapi.ChannelJoin_ROOM, persistence: persistence, hidden: hidden}
+}
+
+// DirectTarget returns the target of the direct messages with the user, with their history kept on the server.
+func DirectTarget(userID string) ChatTarget {
+	return ChatTarget{target: userID, chatType: rtapi.ChannelJoin_DIRECT_MESSAGE, persistence: true}
+}
+
+// GroupTarget returns the target of the chat of the group, with its history kept on the server.
+func GroupTarget(groupID string) ChatTarget {
+	return ChatTarget{target: groupID, chatType: rtapi.ChannelJoin_GROUP, persistence: true}
+}
+
+// WithPersistence returns a copy of the target keeping the messages on the server or not.
+func (t ChatTarget) WithPersistence(persistence bool) ChatTarget {
+	t.persistence = persistence
+	return t
+}
+
+// WithHidden returns a copy of the target hiding the user from the presences of the chat or not.
+func (t ChatTarget) WithHidden(hidden bool) ChatTarget {
+	t.hidden = hidden
+	return t
+}
+
+// Target returns the room name, the user id or the group id of the chat.
+func (t ChatTarget) Target() string {
+	return t.target
+}
+
+// Type returns the type of the chat.
+func (t ChatTarget) Type() rtapi.ChannelJoin_Type {
+	return t.chatType
+}
+
+// channelJoin returns the join message of the target.
+func (t ChatTarget) channelJoin() (*rtapi.ChannelJoin, error) {
+	if t.target == "" || t.chatType == rtapi.ChannelJoin_TYPE_UNSPECIFIED {
+		return nil, newError("chat target is empty, use RoomTarget, DirectTarget or GroupTarget")
+	}
+	return &rtapi.ChannelJoin{
+		Target:      t.target,
+		Type:        int32(t.chatType),
+		Persistence: wrapperspb.Bool(t.persistence),
+		Hidden:      wrapperspb.Bool(t.hidden),
+	}, nil
+}
@@ -0,0 +1,38 @@
+package nakama
+
+import (
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatTargets(t *testing.T) {
+	server := newScriptedServer(t, answerChannelJoins)
+	socket, _ := server.socket(nil)
+	assert.NoError(t, socket.Connect())
+
+	_, err := socket.JoinChat(RoomTarget("lobby", false, true))
+	assert.NoError(t, err)
+	_, err = socket.JoinChat(DirectTarget("u1").WithPersistence(false))
+	assert.NoError(t, err)
+	_, err = socket.JoinChat(GroupTarget("g1"))
+	assert.NoError(t, err)
+	_, err = socket.JoinChat(ChatTarget{})
+	assert.Error(t, err)
+
+	joins := server.requestsOf("channel_join")
+	assert.Len(t, joins, 3)
+	expected := []*rtapi.ChannelJoin{
+		{Target: "lobby", Type: int32(rtapi.ChannelJoin_ROOM)},
+		{Target: "u1", Type: int32(rtapi.ChannelJoin_DIRECT_MESSAGE)},
+		{Target: "g1", Type: int32(rtapi.ChannelJoin_GROUP)},
+	}
+	for i, join := range joins {
+		assert.Equal(t, expected[i].Target, join.GetChannelJoin().GetTarget())
+		assert.Equal(t, expected[i].Type, join.GetChannelJoin().GetType())
+	}
+	assert.True(t, joins[0].GetChannelJoin().GetHidden().GetValue())
+	assert.False(t, joins[1].GetChannelJoin().GetPersistence().GetValue())
+	assert.True(t, joins[2].GetChannelJoin().GetPersistence().GetValue())
+}
@@ -1,12 +1,13 @@
 package nakama
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"time"
 
-	"github.com/gwaylib/errors"
 	api "github.com/heroiclabs/nakama-common/api"
 	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
 )
@@ -20,19 +21,48 @@ const (
 	DefaultExpiredTimespanMs = 5 * 60 * 1000 // 5 minutes in milliseconds
 )
 
+// SystemUserId owns the storage objects written by the server runtime without user, e.g. the game config.
+const SystemUserId = "00000000-0000-0000-0000-000000000000"
+
 // Client represents a client for the Nakama server.
 type Client struct {
-	ExpiredTimespanMs  int64      // The expired timespan used to check session lifetime.
-	ApiClient          *NakamaApi // The low-level API client for Nakama server.
+	ExpiredTimespanMs  int64              // The expired timespan used to check session lifetime.
+	ApiClient          NakamaApiInterface // The low-level API client for Nakama server, a *NakamaApi unless replaced.
 	ServerKey          string
 	Host               string
 	Port               string
 	UseSSL             bool
 	Timeout            int
 	AutoRefreshSession bool
+	Clock              *ServerClock // The estimated server clock, shared with the sockets created by the client.
+
+	basePath      string
+	stats         *ClientStats // the stats of the default api client, kept when it's replaced
+	sessions      *SessionManager
+	sockets       *socketRegistry
+	tls           *TLSOptions
+	publicStorage *PublicStorageOptions
+	clock         Clock
+	faults        *FaultInjector
+	experimental  map[Feature]bool
+	capabilities  *serverCapabilities
+	lifecycle     *Lifecycle      // the background components, see Client.Go
+	ctx           context.Context // set by WithContext
+
+	storageTransformers map[string][]ValueTransformer // collection:transformers
+	storageQuotas       map[string]StorageQuota       // collection:quota
+	defaultVars         map[string]string             // the vars of the authenticate and refresh requests
+	groupBanRpcs        GroupBanRpcs
+	accountSessionRpcs  AccountSessionRpcs
+	storageIndex        *StorageIndexOptions
+	scores              *scoreSubmissions       // the score tokens submitted, see SubmitLeaderboardScore
+	socketFormat        SocketFormat            // the format of the sockets created, see WithSocketFormat
+	proxy               ProxyFunc               // the proxy of the sockets created, see WithProxy
+	onSocketRtt         func(rtt time.Duration) // the pongs of the sockets created, see RegionSelector.NewClient
 }
 
 // NewClient creates a new instance of Client with the specified configuration.
+// See NewClientWithOptions for the other options.
 func NewClient(
 	serverKey string,
 	host string,
@@ -41,64 +71,185 @@ func NewClient(
 	timeout int,
 	autoRefreshSession bool,
 ) *Client {
+	return newClient(&ClientOptions{
+		ServerKey:          serverKey,
+		Host:               host,
+		Port:               port,
+		UseSSL:             useSSL,
+		TimeoutMs:          timeout,
+		AutoRefreshSession: autoRefreshSession,
+	})
+}
+
+func newClient(opts *ClientOptions) *Client {
 	// Default values if not provided
-	if serverKey == "" {
-		serverKey = DefaultServerKey
+	if opts.ServerKey == "" {
+		opts.ServerKey = DefaultServerKey
 	}
-	if host == "" {
-		host = DefaultHost
+	if opts.Host == "" {
+		opts.Host = DefaultHost
 	}
-	if port == "" {
-		port = DefaultPort
+	if opts.Port == "" {
+		opts.Port = DefaultPort
 	}
-	if timeout == 0 {
-		timeout = DefaultTimeoutMs
+	if opts.TimeoutMs == 0 {
+		opts.TimeoutMs = DefaultTimeoutMs
 	}
 
 	scheme := "http://"
-	if useSSL {
+	if opts.UseSSL {
 		scheme = "https://"
 	}
-	basePath := scheme + host + ":" + port
+	basePath := scheme + opts.Host + ":" + opts.Port
 
-	return &Client{
-		ExpiredTimespanMs:  DefaultExpiredTimespanMs,
-		ApiClient:          &NakamaApi{serverKey, basePath, timeout},
-		ServerKey:          serverKey,
-		Host:               host,
-		Port:               port,
-		UseSSL:             useSSL,
-		Timeout:            timeout,
-		AutoRefreshSession: autoRefreshSession,
+	httpClient := opts.HttpClient
+	if httpClient == nil && opts.HttpTransport != nil {
+		httpClient = &http.Client{Transport: opts.HttpTransport}
+	} else if httpClient == nil && opts.TLS != nil {
+		httpClient = opts.TLS.httpClient()
 	}
-}
-
-func (c *Client) refreshSession(session *Session) error {
-	if c.AutoRefreshSession && session.RefreshToken != "" &&
-		session.IsExpired((time.Now().UnixMilli()+c.ExpiredTimespanMs)/1000) {
-		if _, err := c.SessionRefresh(session, nil); err != nil {
-			return errors.As(err)
+	if opts.Proxy != nil && opts.HttpClient == nil && opts.HttpTransport == nil {
+		if httpClient == nil {
+			httpClient = &http.Client{Transport: NewHttpTransport()}
 		}
-	}
-	return nil
+		httpClient.Transport.(*http.Transport).Proxy = opts.Proxy
+	}
+	if opts.Faults != nil {
+		httpClient = opts.Faults.httpClient(httpClient)
+	}
+
+	clock := NewServerClock()
+	stats := NewClientStats()
+	var limiter *RequestLimiter
+	if opts.MaxConcurrentRequests > 0 {
+		limiter = NewRequestLimiter(opts.MaxConcurrentRequests)
+	}
+	c := &Client{
+		ExpiredTimespanMs: DefaultExpiredTimespanMs,
+		ApiClient: &NakamaApi{
+			ServerKey:         opts.ServerKey,
+			BasePath:          basePath,
+			TimeoutMs:         opts.TimeoutMs,
+			Clock:             clock,
+			Stats:             stats,
+			Limiter:           limiter,
+			RetryPolicy:       opts.RetryPolicy,
+			AttemptTimeoutMs:  opts.AttemptTimeoutMs,
+			AdaptiveTimeout:   opts.AdaptiveTimeout,
+			StreamBodyBytes:   opts.StorageStreamBytes,
+			EndpointTimeoutMs: opts.EndpointTimeouts,
+			HttpClient:        httpClient,
+			Logger:            opts.Logger,
+		},
+		ServerKey:          opts.ServerKey,
+		Host:               opts.Host,
+		Port:               opts.Port,
+		UseSSL:             opts.UseSSL,
+		Timeout:            opts.TimeoutMs,
+		AutoRefreshSession: opts.AutoRefreshSession,
+		Clock:              clock,
+		basePath:           basePath,
+		stats:              stats,
+		sockets:            &socketRegistry{},
+		capabilities:       &serverCapabilities{},
+		lifecycle:          &Lifecycle{},
+		tls:                opts.TLS,
+		publicStorage:      opts.PublicStorage,
+		clock:              opts.Clock,
+		faults:             opts.Faults,
+		experimental:       opts.Experimental,
+
+		storageTransformers: opts.StorageTransformers,
+		storageQuotas:       opts.StorageQuotas,
+		defaultVars:         opts.AuthVars,
+		storageIndex:        opts.StorageIndex,
+		socketFormat:        opts.SocketFormat,
+		proxy:               opts.Proxy,
+		groupBanRpcs:        GroupBanRpcs{List: DefaultGroupBansListRpc, Lift: DefaultGroupBansLiftRpc},
+		accountSessionRpcs:  AccountSessionRpcs{List: DefaultAccountSessionsListRpc, Revoke: DefaultAccountSessionsRevokeRpc},
+	}
+	if opts.GroupBanRpcs != nil {
+		c.groupBanRpcs = *opts.GroupBanRpcs
+	}
+	if opts.AccountSessionRpcs != nil {
+		c.accountSessionRpcs = *opts.AccountSessionRpcs
+	}
+	c.scores = newScoreSubmissions(DefaultScoreDedupWindow)
+	if opts.ScoreDedupWindow != nil {
+		c.scores = newScoreSubmissions(*opts.ScoreDedupWindow)
+	}
+	c.sessions = newSessionManager(c)
+	c.ApiClient.(*NakamaApi).OnUnauthorized = c.sessions.refreshRejected
+	c.ApiClient.(*NakamaApi).Use(opts.Middlewares...)
+	return c
+}
+
+// Sessions returns the manager refreshing the sessions of the client.
+func (c *Client) Sessions() *SessionManager {
+	return c.sessions
+}
+
+// now returns the time of the session expiry checks.
+func (c *Client) now() time.Time {
+	if c.clock == nil {
+		return SystemClock.Now()
+	}
+	return c.clock.Now()
+}
+
+// WithContext returns a copy of the client bounding its http calls by ctx, e.g. to cancel them on shutdown.
+// It's the context-aware form of all the methods, e.g. client.WithContext(ctx).GetAccount(session), the calls in flight
+// return the error of ctx once it's done. The deadline of ctx is shared by the retries of a call like the client timeout.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	clone := *c
+	clone.ApiClient = c.ApiClient.WithContext(ctx)
+	clone.ctx = ctx
+	return &clone
+}
+
+// WithResponseInfo returns a copy of the client filling info with the status, the headers and the timing
+// of the http response of each call, e.g. client.WithResponseInfo(&info).GetAccount(session).
+func (c *Client) WithResponseInfo(info *ResponseInfo) *Client {
+	clone := *c
+	clone.ApiClient = c.ApiClient.WithResponseInfo(info)
+	return &clone
+}
+
+// Context returns the context bounding the calls of the client, set by WithContext, context.Background() otherwise.
+func (c *Client) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// apiFor returns the api client of a call of the session, the context of the call carries the session.
+func (c *Client) apiFor(session *Session) NakamaApiInterface {
+	return c.ApiClient.WithContext(ContextWithSession(c.Context(), session))
+}
+
+// refreshSession refreshes the expiring session when AutoRefreshSession is set, and fails before the network
+// with the guidance errors of Session.Valid when the session can't be used.
+func (c *Client) refreshSession(session *Session) error {
+	return c.sessions.Ensure(session)
 }
 
 // AddGroupUsers adds users to a group, or accepts their join requests.
 func (c *Client) AddGroupUsers(session *Session, groupId *string, ids []string) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.AddGroupUsers(&session.Token, groupId, ids, make(map[string]string))
+	return c.apiFor(session).AddGroupUsers(&session.Token, groupId, ids, make(map[string]string))
 }
 
 // AddFriends adds friends by ID or username to a user's account.
 func (c *Client) AddFriends(session *Session, ids []string, usernames []string) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.AddFriends(&session.Token, ids, usernames, make(map[string]string))
+	return c.apiFor(session).AddFriends(&session.Token, ids, usernames, make(map[string]string))
 }
 
 // AuthenticateApple authenticates a user with an Apple ID against the server.
@@ -106,7 +257,7 @@ func (c *Client) AuthenticateApple(token string, create *bool, username string,
 	// Prepare the authentication request
 	request := &api.AccountApple{
 		Token: token,
-		Vars:  vars,
+		Vars:  c.authVars(vars),
 	}
 
 	// Call the API client to authenticate with Apple
@@ -116,11 +267,7 @@ func (c *Client) AuthenticateApple(token string, create *bool, username string,
 	}
 
 	// Return a new Session object
-	return &Session{
-		Token:        apiSession.Token,
-		RefreshToken: apiSession.RefreshToken,
-		Created:      apiSession.Created,
-	}, nil
+	return NewSession(apiSession.Token, apiSession.RefreshToken, apiSession.Created), nil
 }
 
 // AuthenticateCustom authenticates a user with a custom ID against the server.
@@ -128,7 +275,7 @@ func (c *Client) AuthenticateCustom(id string, create *bool, username *string, v
 	// Prepare the authentication request
 	request := &api.AccountCustom{
 		Id:   id,
-		Vars: vars,
+		Vars: c.authVars(vars),
 	}
 
 	// Call the API client to authenticate with a custom ID
@@ -138,11 +285,7 @@ func (c *Client) AuthenticateCustom(id string, create *bool, username *string, v
 	}
 
 	// Return a new Session object
-	return &Session{
-		Token:        apiSession.Token,
-		RefreshToken: apiSession.RefreshToken,
-		Created:      apiSession.Created,
-	}, nil
+	return NewSession(apiSession.Token, apiSession.RefreshToken, apiSession.Created), nil
 }
 
 // AuthenticateDevice authenticates a user with a device ID against the server.
@@ -150,7 +293,7 @@ func (c *Client) AuthenticateDevice(id string, create *bool, username string, va
 	// Prepare the authentication request
 	request := &api.AccountDevice{
 		Id:   id,
-		Vars: vars,
+		Vars: c.authVars(vars),
 	}
 
 	// Call the API client to authenticate with a device ID
@@ -160,11 +303,7 @@ func (c *Client) AuthenticateDevice(id string, create *bool, username string, va
 	}
 
 	// Return a new Session object
-	return &Session{
-		Token:        apiSession.Token,
-		RefreshToken: apiSession.RefreshToken,
-		Created:      apiSession.Created,
-	}, nil
+	return NewSession(apiSession.Token, apiSession.RefreshToken, apiSession.Created), nil
 }
 
 // AuthenticateEmail authenticates a user with an email and password against the server.
@@ -173,7 +312,7 @@ func (c *Client) AuthenticateEmail(email string, password string, create *bool,
 	request := &api.AccountEmail{
 		Email:    email,
 		Password: password,
-		Vars:     vars,
+		Vars:     c.authVars(vars),
 	}
 
 	// Call the API client to authenticate with email and password
@@ -183,11 +322,7 @@ func (c *Client) AuthenticateEmail(email string, password string, create *bool,
 	}
 
 	// Return a new Session object
-	return &Session{
-		Token:        apiSession.Token,
-		RefreshToken: apiSession.RefreshToken,
-		Created:      apiSession.Created,
-	}, nil
+	return NewSession(apiSession.Token, apiSession.RefreshToken, apiSession.Created), nil
 }
 
 // AuthenticateFacebookInstantGame authenticates a user with a Facebook Instant Game token against the server.
@@ -195,7 +330,7 @@ func (c *Client) AuthenticateFacebookInstantGame(signedPlayerInfo string, create
 	// Prepare the authentication request
 	request := &api.AccountFacebookInstantGame{
 		SignedPlayerInfo: signedPlayerInfo,
-		Vars:             vars,
+		Vars:             c.authVars(vars),
 	}
 
 	// Call the API client to authenticate with Facebook Instant Game
@@ -205,11 +340,7 @@ func (c *Client) AuthenticateFacebookInstantGame(signedPlayerInfo string, create
 	}
 
 	// Return a new Session object
-	return &Session{
-		Token:        apiSession.Token,
-		RefreshToken: apiSession.RefreshToken,
-		Created:      apiSession.Created,
-	}, nil
+	return NewSession(apiSession.Token, apiSession.RefreshToken, apiSession.Created), nil
 }
 
 // AuthenticateFacebook authenticates a user with a Facebook OAuth token against the server.
@@ -217,7 +348,7 @@ func (c *Client) AuthenticateFacebook(token string, create *bool, username strin
 	// Prepare the authentication request
 	request := &api.AccountFacebook{
 		Token: token,
-		Vars:  vars,
+		Vars:  c.authVars(vars),
 	}
 
 	// Call the API client to authenticate with Facebook
@@ -227,11 +358,7 @@ func (c *Client) AuthenticateFacebook(token string, create *bool, username strin
 	}
 
 	// Return a new Session object
-	return &Session{
-		Token:        apiSession.Token,
-		RefreshToken: apiSession.RefreshToken,
-		Created:      apiSession.Created,
-	}, nil
+	return NewSession(apiSession.Token, apiSession.RefreshToken, apiSession.Created), nil
 }
 
 // AuthenticateGoogle authenticates a user with a Google token against the server.
@@ -239,7 +366,7 @@ func (c *Client) AuthenticateGoogle(token string, create *bool, username string,
 	// Prepare the authentication request
 	request := &api.AccountGoogle{
 		Token: token,
-		Vars:  vars,
+		Vars:  c.authVars(vars),
 	}
 
 	// Call the API client to authenticate with Google
@@ -249,11 +376,7 @@ func (c *Client) AuthenticateGoogle(token string, create *bool, username string,
 	}
 
 	// Return a new Session object
-	return &Session{
-		Token:        apiSession.Token,
-		RefreshToken: apiSession.RefreshToken,
-		Created:      apiSession.Created,
-	}, nil
+	return NewSession(apiSession.Token, apiSession.RefreshToken, apiSession.Created), nil
 }
 
 // AuthenticateGameCenter authenticates a user with GameCenter against the server.
@@ -266,7 +389,7 @@ func (c *Client) AuthenticateGameCenter(bundleId string, playerId string, public
 		Salt:             salt,
 		Signature:        signature,
 		TimestampSeconds: timestamp,
-		Vars:             vars,
+		Vars:             c.authVars(vars),
 	}
 
 	// Call the API client to authenticate with GameCenter
@@ -276,11 +399,7 @@ func (c *Client) AuthenticateGameCenter(bundleId string, playerId string, public
 	}
 
 	// Return a new Session object
-	return &Session{
-		Token:        apiSession.Token,
-		RefreshToken: apiSession.RefreshToken,
-		Created:      apiSession.Created,
-	}, nil
+	return NewSession(apiSession.Token, apiSession.RefreshToken, apiSession.Created), nil
 }
 
 // AuthenticateSteam authenticates a user with a Steam token against the server.
@@ -288,7 +407,7 @@ func (c *Client) AuthenticateSteam(token string, create *bool, username string,
 	// Prepare the authentication request
 	request := &api.AccountSteam{
 		Token: token,
-		Vars:  vars,
+		Vars:  c.authVars(vars),
 	}
 
 	// Call the API client to authenticate with Steam
@@ -299,401 +418,461 @@ func (c *Client) AuthenticateSteam(token string, create *bool, username string,
 	}
 
 	// Return a new Session object
-	return &Session{
-		Token:        apiSession.Token,
-		RefreshToken: apiSession.RefreshToken,
-		Created:      apiSession.Created,
-	}, nil
+	return NewSession(apiSession.Token, apiSession.RefreshToken, apiSession.Created), nil
 }
 
 // BanGroupUsers bans users from a group.
 func (c *Client) BanGroupUsers(session *Session, groupId string, ids []string) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.BanGroupUsers(&session.Token, &groupId, ids, make(map[string]string))
+	return c.apiFor(session).BanGroupUsers(&session.Token, &groupId, ids, make(map[string]string))
 }
 
 // BlockFriends blocks one or more users by ID or username.
 func (c *Client) BlockFriends(session *Session, ids []string, usernames []string) error {
-	if c.AutoRefreshSession && session.RefreshToken != "" &&
-		session.IsExpired((time.Now().Unix()+c.ExpiredTimespanMs)/1000) {
-		_, err := c.SessionRefresh(session, nil)
-		if err != nil {
-			return errors.As(err)
-		}
+	if err := c.refreshSession(session); err != nil {
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.BlockFriends(&session.Token, ids, usernames, make(map[string]string))
+	return c.apiFor(session).BlockFriends(&session.Token, ids, usernames, make(map[string]string))
 }
 
 // CreateGroup creates a new group with the current user as the creator and superadmin.
 func (c *Client) CreateGroup(session *Session, request api.CreateGroupRequest) (*api.Group, error) {
 	// Check if the session requires refresh
-	if c.AutoRefreshSession && session.RefreshToken != "" &&
-		session.IsExpired((time.Now().Unix()+c.ExpiredTimespanMs)/1000) {
-		_, err := c.SessionRefresh(session, nil)
-		if err != nil {
-			return nil, err
-		}
+	if err := c.refreshSession(session); err != nil {
+		return nil, wrapErr(err)
 	}
 
 	// Call the API client to create the group
-	return c.ApiClient.CreateGroup(&session.Token, &request, make(map[string]string))
+	return c.apiFor(session).CreateGroup(&session.Token, &request, make(map[string]string))
 }
 
 // CreateSocket creates a socket using the client's configuration.
+// Set the token source of the socket to refresh the token on reconnect, e.g.
+// socket.SetTokenSource(func() (string, error) { return client.Sessions().Token(session) }).
 func (c *Client) CreateSocket(eventHandle EventHandler, token string, useSSL bool, verbose bool, sendTimeoutMs *int, createStatus *bool) *DefaultSocket {
-	return NewDefaultSocket(eventHandle, c.Host, c.Port, token, useSSL, verbose, sendTimeoutMs, createStatus)
+	socket := NewDefaultSocket(eventHandle, c.Host, c.Port, token, useSSL, verbose, sendTimeoutMs, createStatus)
+	socket.SetServerClock(c.Clock)
+	if c.socketFormat != "" {
+		socket.SetFormat(c.socketFormat)
+	}
+	if c.proxy != nil {
+		socket.SetProxy(c.proxy)
+	}
+	if c.tls != nil {
+		socket.SetTLSConfig(c.tls.socketConfig())
+	}
+	if c.faults != nil {
+		socket.SetFaultInjector(c.faults)
+	}
+	socket.registry = c.sockets
+	socket.onPong = c.onSocketRtt
+	for feature, enabled := range c.experimental {
+		if enabled {
+			socket.EnableExperimental(feature)
+		}
+	}
+	return socket
 }
 
 // DeleteAccount deletes the current user's account.
 func (c *Client) DeleteAccount(session *Session) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.DeleteAccount(session.Token, make(map[string]string))
+	return c.apiFor(session).DeleteAccount(session.Token, make(map[string]string))
 }
 
 // DeleteFriends deletes one or more users by ID or username.
 func (c *Client) DeleteFriends(session *Session, ids []string, usernames []string) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
-	return c.ApiClient.DeleteFriends(&session.Token, ids, usernames, make(map[string]string))
+	return c.apiFor(session).DeleteFriends(&session.Token, ids, usernames, make(map[string]string))
 }
 
 // DeleteGroup deletes a group the user is part of and has permissions to delete.
 func (c *Client) DeleteGroup(session *Session, groupId string) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.DeleteGroup(&session.Token, &groupId, make(map[string]string))
+	return c.apiFor(session).DeleteGroup(&session.Token, &groupId, make(map[string]string))
 }
 
 // DeleteNotifications deletes one or more notifications.
 func (c *Client) DeleteNotifications(session *Session, ids []string) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.DeleteNotifications(session.Token, ids, make(map[string]string))
+	return c.apiFor(session).DeleteNotifications(session.Token, ids, make(map[string]string))
 }
 
 // DeleteStorageObjects deletes one or more storage objects.
 func (c *Client) DeleteStorageObjects(session *Session, request *api.DeleteStorageObjectsRequest) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.DeleteStorageObjects(session.Token, request, make(map[string]string))
+	if err := c.apiFor(session).DeleteStorageObjects(session.Token, request, make(map[string]string)); err != nil {
+		return wrapErr(err)
+	}
+	ids := make([]storageIndexKey, 0, len(request.GetObjectIds()))
+	for _, id := range request.GetObjectIds() {
+		ids = append(ids, storageIndexKey{Collection: id.GetCollection(), Key: id.GetKey()})
+	}
+	c.unindexStorageObjects(session, ids)
+	return nil
 }
 
 // DeleteTournamentRecord deletes a tournament record.
 func (c *Client) DeleteTournamentRecord(session *Session, tournamentId string) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.DeleteTournamentRecord(session.Token, tournamentId, make(map[string]string))
+	return c.apiFor(session).DeleteTournamentRecord(session.Token, tournamentId, make(map[string]string))
 }
 
-// DemoteGroupUsers demotes a set of users in a group to the next role down.
-func (c *Client) DemoteGroupUsers(session *Session, groupId *string, ids []string) error {
-	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+// DemoteGroupUsers demotes a set of users in a group to the next role down and returns which users have changed.
+// It fails with ErrGroupPermissionDenied when the current user isn't an admin or superadmin of the group.
+func (c *Client) DemoteGroupUsers(session *Session, groupId *string, ids []string) (*GroupUsersResult, error) {
+	if isEmpty(groupId) {
+		return nil, newError("'groupId' is a required parameter but is empty")
 	}
-
-	return c.ApiClient.DemoteGroupUsers(&session.Token, groupId, ids, make(map[string]string))
+	return c.changeGroupUsers(session, *groupId, ids, demotedGroupState, func() error {
+		return c.apiFor(session).DemoteGroupUsers(&session.Token, groupId, ids, make(map[string]string))
+	})
 }
 
 // EmitEvent submits an event for processing in the server's registered runtime custom events handler.
 func (c *Client) EmitEvent(session *Session, request *api.Event) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.Event(&session.Token, request, make(map[string]string))
+	return c.apiFor(session).Event(&session.Token, request, make(map[string]string))
 }
 
 // GetAccount fetches the current user's account.
 func (c *Client) GetAccount(session *Session) (*api.Account, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.GetAccount(session.Token, make(map[string]string))
+	return c.apiFor(session).GetAccount(session.Token, make(map[string]string))
 }
 
 // GetSubscription fetches a subscription by product ID.
 func (c *Client) GetSubscription(session *Session, productId *string) (*api.ValidatedSubscription, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.GetSubscription(&session.Token, productId, make(map[string]string))
+	return c.apiFor(session).GetSubscription(&session.Token, productId, make(map[string]string))
 }
 
 // ImportFacebookFriends imports Facebook friends and adds them to a user's account.
 func (c *Client) ImportFacebookFriends(session *Session, request *api.AccountFacebook) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.ImportFacebookFriends(&session.Token, request, nil, make(map[string]string))
+	return c.apiFor(session).ImportFacebookFriends(&session.Token, request, nil, make(map[string]string))
 }
 
 // ImportSteamFriends imports Steam friends and adds them to a user's account.
 func (c *Client) ImportSteamFriends(session *Session, request *api.AccountSteam, reset bool) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.ImportSteamFriends(&session.Token, request, &reset, make(map[string]string))
+	return c.apiFor(session).ImportSteamFriends(&session.Token, request, &reset, make(map[string]string))
 }
 
 // FetchUsers fetches zero or more users by ID and/or username.
 func (c *Client) FetchUsers(session *Session, ids []string, usernames []string, facebookIds []string) (*api.Users, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.GetUsers(&session.Token, ids, usernames, facebookIds, make(map[string]string))
+	return c.apiFor(session).GetUsers(&session.Token, ids, usernames, facebookIds, make(map[string]string))
 }
 
 // JoinGroup either joins a group that's open or sends a request to join a group that's closed.
 func (c *Client) JoinGroup(session *Session, groupId string) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.JoinGroup(&session.Token, &groupId, make(map[string]string))
+	return c.apiFor(session).JoinGroup(&session.Token, &groupId, make(map[string]string))
 }
 
 // JoinTournament allows a user to join a tournament by its ID.
 func (c *Client) JoinTournament(session *Session, tournamentId string) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.JoinTournament(session.Token, tournamentId, make(map[string]string))
+	return c.apiFor(session).JoinTournament(session.Token, tournamentId, make(map[string]string))
 }
 
 // KickGroupUsers kicks users from a group or declines their join requests.
 func (c *Client) KickGroupUsers(session *Session, groupId string, ids []string) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.KickGroupUsers(&session.Token, &groupId, ids, make(map[string]string))
+	return c.apiFor(session).KickGroupUsers(&session.Token, &groupId, ids, make(map[string]string))
 }
 
 // LeaveGroup allows a user to leave a group they are part of.
 func (c *Client) LeaveGroup(session *Session, groupId string) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.LeaveGroup(&session.Token, &groupId, make(map[string]string))
+	return c.apiFor(session).LeaveGroup(&session.Token, &groupId, make(map[string]string))
 }
 
 // ListChannelMessages retrieves a channel's message history.
 func (c *Client) ListChannelMessages(session *Session, channelId string, limit *int, forward *bool, cursor *string) (*api.ChannelMessageList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.ListChannelMessages(&session.Token, &channelId, limit, forward, cursor, make(map[string]string))
+	return c.apiFor(session).ListChannelMessages(&session.Token, &channelId, limit, forward, cursor, make(map[string]string))
 }
 
 // ListGroupUsers retrieves a group's users with optional state, limit, and cursor parameters.
 func (c *Client) ListGroupUsers(session *Session, groupId string, state *int, limit *int, cursor *string) (*api.GroupUserList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.ListGroupUsers(&session.Token, &groupId, state, limit, cursor, make(map[string]string))
+	return c.apiFor(session).ListGroupUsers(&session.Token, &groupId, limit, state, cursor, make(map[string]string))
 }
 
-// ListUserGroups lists a user's groups.
-func (c *Client) ListUserGroups(session *Session, userId string, state *int, limit int, cursor string) (*api.UserGroupList, error) {
+// ListUserGroups lists a user's groups, state filters them and the nil parameters aren't sent.
+func (c *Client) ListUserGroups(session *Session, userId string, state *int, limit *int, cursor *string) (*api.UserGroupList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.ListUserGroups(session.Token, userId, state, limit, cursor, make(map[string]string))
+	return c.apiFor(session).ListUserGroups(&session.Token, userId, state, limit, cursor, make(map[string]string))
 }
 
-// ListGroups retrieves a list of groups based on the given filters.
-func (c *Client) ListGroups(session *Session, name *string, cursor *string, limit *int) (*api.GroupList, error) {
+// ListGroups retrieves a list of groups based on the given filters, see GroupQueryOption for the other filters.
+func (c *Client) ListGroups(session *Session, name *string, cursor *string, limit *int, opts ...GroupQueryOption) (*api.GroupList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.ListGroups(&session.Token, name, cursor, limit, nil, nil, nil, make(map[string]string))
+	query := GroupQuery{}
+	for _, opt := range opts {
+		opt(&query)
+	}
+	return c.apiFor(session).ListGroups(&session.Token, name, cursor, limit, query.LangTag, query.Members, query.Open, make(map[string]string))
 }
 
 // LinkApple adds an Apple ID to the social profiles on the current user's account.
 func (c *Client) LinkApple(session *Session, request *api.AccountApple) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.LinkApple(session.Token, request, make(map[string]string))
+	return c.apiFor(session).LinkApple(session.Token, request, make(map[string]string))
 }
 
 // LinkCustom adds a custom ID to the social profiles on the current user's account.
 func (c *Client) LinkCustom(session *Session, request *api.AccountCustom) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.LinkCustom(session.Token, request, make(map[string]string))
+	return c.apiFor(session).LinkCustom(session.Token, request, make(map[string]string))
 }
 
 // LinkDevice adds a device ID to the social profiles on the current user's account.
 func (c *Client) LinkDevice(session *Session, request *api.AccountDevice) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.LinkDevice(session.Token, request, make(map[string]string))
+	return c.apiFor(session).LinkDevice(session.Token, request, make(map[string]string))
 }
 
 // LinkEmail adds an email and password to the social profiles on the current user's account.
 func (c *Client) LinkEmail(session *Session, request *api.AccountEmail) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.LinkEmail(session.Token, request, make(map[string]string))
+	return c.apiFor(session).LinkEmail(session.Token, request, make(map[string]string))
 }
 
 // LinkFacebook adds a Facebook ID to the social profiles on the current user's account.
 func (c *Client) LinkFacebook(session *Session, request *api.AccountFacebook) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
+	}
+
+	return c.apiFor(session).LinkFacebook(session.Token, request, nil, make(map[string]string))
+}
+
+// LinkFacebookSync adds a Facebook ID like LinkFacebook, the server imports the Facebook friends in the same call.
+// The friends imported are listed by FacebookImportSummary.
+func (c *Client) LinkFacebookSync(session *Session, request *api.AccountFacebook) error {
+	if err := c.refreshSession(session); err != nil {
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.LinkFacebook(session.Token, request, nil, make(map[string]string))
+	sync := true
+	return c.apiFor(session).LinkFacebook(session.Token, request, &sync, make(map[string]string))
 }
 
 // LinkFacebookInstant adds Facebook Instant to the social profiles on the current user's account.
 func (c *Client) LinkFacebookInstant(session *Session, request *api.AccountFacebookInstantGame) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.LinkFacebookInstantGame(session.Token, request, make(map[string]string))
+	return c.apiFor(session).LinkFacebookInstantGame(session.Token, request, make(map[string]string))
 }
 
 // LinkGoogle adds a Google account to the social profiles on the current user's account.
 func (c *Client) LinkGoogle(session *Session, request *api.AccountGoogle) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.LinkGoogle(session.Token, request, make(map[string]string))
+	return c.apiFor(session).LinkGoogle(session.Token, request, make(map[string]string))
 }
 
 // LinkGameCenter adds GameCenter to the social profiles on the current user's account.
 func (c *Client) LinkGameCenter(session *Session, request *api.AccountGameCenter) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
-	return c.ApiClient.LinkGameCenter(session.Token, request, make(map[string]string))
+	return c.apiFor(session).LinkGameCenter(session.Token, request, make(map[string]string))
 }
 
 // LinkSteam adds Steam to the social profiles on the current user's account.
 func (c *Client) LinkSteam(session *Session, request *api.LinkSteamRequest) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.LinkSteam(session.Token, request, make(map[string]string))
+	return c.apiFor(session).LinkSteam(session.Token, request, make(map[string]string))
 }
 
 // ListFriends lists all friends for the current user.
 func (c *Client) ListFriends(session *Session, state *int, limit *int, cursor *string) (*api.FriendList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.ListFriends(&session.Token, limit, state, cursor, make(map[string]string))
+	return c.apiFor(session).ListFriends(&session.Token, limit, state, cursor, make(map[string]string))
 }
 
 // ListFriendsOfFriends lists the friends of friends for the current user.
+// The list is empty on the servers without CapabilityFriendsOfFriends.
 func (c *Client) ListFriendsOfFriends(session *Session, limit *int, cursor *string) (*api.FriendsOfFriendsList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
+	}
+	if !c.Supports(CapabilityFriendsOfFriends) {
+		return &api.FriendsOfFriendsList{}, nil
 	}
 
-	return c.ApiClient.ListFriendsOfFriends(&session.Token, limit, cursor, make(map[string]string))
+	list, err := c.apiFor(session).ListFriendsOfFriends(&session.Token, limit, cursor, make(map[string]string))
+	if endpointMissing(err) {
+		c.capabilities.markMissing(CapabilityFriendsOfFriends)
+		return &api.FriendsOfFriendsList{}, nil
+	} else if err != nil {
+		return nil, wrapErr(err)
+	}
+	if list == nil {
+		list = &api.FriendsOfFriendsList{}
+	}
+	return list, nil
 }
 
 // ListLeaderboardRecords lists the leaderboard records with optional ownerIds, pagination, and expiry filters.
 func (c *Client) ListLeaderboardRecords(session *Session, leaderboardId string, ownerIds []string, limit *int, cursor *string, expiry *string) (*api.LeaderboardRecordList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.ListLeaderboardRecords(&session.Token, &leaderboardId, ownerIds, limit, cursor, expiry, make(map[string]string))
+	return c.apiFor(session).ListLeaderboardRecords(&session.Token, &leaderboardId, ownerIds, limit, cursor, expiry, make(map[string]string))
 }
 
 func (c *Client) ListLeaderboardRecordsAroundOwner(session *Session, leaderboardId string, ownerId string, limit int, expiry string, cursor string) (*api.LeaderboardRecordList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.ListLeaderboardRecordsAroundOwner(session.Token, leaderboardId, ownerId, limit, expiry, cursor, make(map[string]string))
+	return c.apiFor(session).ListLeaderboardRecordsAroundOwner(session.Token, leaderboardId, ownerId, limit, expiry, cursor, make(map[string]string))
 }
 
 // ListMatches fetches a list of running matches.
 func (c *Client) ListMatches(session *Session, limit int, authoritative *bool, label string, minSize int, maxSize int, query string) (*api.MatchList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.ListMatches(session.Token, limit, authoritative, label, minSize, maxSize, query, make(map[string]string))
+	return c.apiFor(session).ListMatches(session.Token, limit, authoritative, label, minSize, maxSize, query, make(map[string]string))
 }
 
 // ListNotifications fetches a list of notifications.
 func (c *Client) ListNotifications(session *Session, limit int, cacheableCursor string) (*api.NotificationList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.ListNotifications(session.Token, limit, cacheableCursor, make(map[string]string))
+	return c.apiFor(session).ListNotifications(session.Token, limit, cacheableCursor, make(map[string]string))
 }
 
-// ListStorageObjects retrieves a list of storage objects.
-func (c *Client) ListStorageObjects(session *Session, collection string, userID string, limit int, cursor string) (*api.StorageObjectList, error) {
+// ListStorageObjects retrieves a list of storage objects, userID filters the owner when not nil.
+// A nil userID lists the objects readable by the user of all the owners, e.g. the public objects of the system user,
+// see SystemUserId.
+func (c *Client) ListStorageObjects(session *Session, collection string, userID *string, limit int, cursor string) (*api.StorageObjectList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.ListStorageObjects(session.Token, collection, userID, limit, cursor, make(map[string]string))
+	list, err := c.apiFor(session).ListStorageObjects(session.Token, collection, userID, limit, cursor, make(map[string]string))
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	c.indexStorageObjects(list.GetObjects())
+	if err := c.decodeStorageObjects(list.GetObjects()); err != nil {
+		return nil, wrapErr(err)
+	}
+	return list, nil
 }
 
 // ListTournaments retrieves a list of current or upcoming tournaments.
 func (c *Client) ListTournaments(session *Session, categoryStart *int, categoryEnd *int, startTime *int64, endTime *int64, limit int, cursor string) (*api.TournamentList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.ListTournaments(session.Token, categoryStart, categoryEnd, startTime, endTime, limit, cursor, make(map[string]string))
+	return c.apiFor(session).ListTournaments(session.Token, categoryStart, categoryEnd, startTime, endTime, limit, cursor, make(map[string]string))
 }
 
 // ListSubscriptions lists user subscriptions.
 func (c *Client) ListSubscriptions(session *Session, cursor string, limit int32) (*api.SubscriptionList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.ListSubscriptions(
+	return c.apiFor(session).ListSubscriptions(
 		&session.Token, &api.ListSubscriptionsRequest{
 			Cursor: cursor,
 			Limit:  wrapperspb.Int32(limit),
@@ -712,11 +891,11 @@ func (c *Client) ListTournamentRecords(
 	expiry string,
 ) (*api.TournamentRecordList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	// Call the API to list tournament records.
-	return c.ApiClient.ListTournamentRecords(
+	return c.apiFor(session).ListTournamentRecords(
 		session.Token,
 		tournamentId,
 		ownerIds,
@@ -737,11 +916,11 @@ func (c *Client) ListTournamentRecordsAroundOwner(
 	cursor string,
 ) (*api.TournamentRecordList, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	// Call the API to get tournament records around owner.
-	return c.ApiClient.ListTournamentRecordsAroundOwner(
+	return c.apiFor(session).ListTournamentRecordsAroundOwner(
 		session.Token,
 		tournamentId,
 		ownerId,
@@ -752,28 +931,35 @@ func (c *Client) ListTournamentRecordsAroundOwner(
 	)
 }
 
-// PromoteGroupUsers promotes the users in a group to the next role up.
-func (c *Client) PromoteGroupUsers(session *Session, groupId string, ids []string) error {
-	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
-	}
-
-	return c.ApiClient.PromoteGroupUsers(session.Token, groupId, ids, make(map[string]string))
+// PromoteGroupUsers promotes the users in a group to the next role up and returns which users have changed.
+// It fails with ErrGroupPermissionDenied when the current user isn't an admin or superadmin of the group.
+func (c *Client) PromoteGroupUsers(session *Session, groupId string, ids []string) (*GroupUsersResult, error) {
+	return c.changeGroupUsers(session, groupId, ids, promotedGroupState, func() error {
+		return c.apiFor(session).PromoteGroupUsers(session.Token, groupId, ids, make(map[string]string))
+	})
 }
 
 // ReadStorageObjects fetches storage objects.
 func (c *Client) ReadStorageObjects(session *Session, request *api.ReadStorageObjectsRequest) (*api.StorageObjects, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	return c.ApiClient.ReadStorageObjects(session.Token, request, make(map[string]string))
+	objects, err := c.apiFor(session).ReadStorageObjects(session.Token, request, make(map[string]string))
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	c.indexStorageObjects(objects.GetObjects())
+	if err := c.decodeStorageObjects(objects.GetObjects()); err != nil {
+		return nil, wrapErr(err)
+	}
+	return objects, nil
 }
 
 // Rpc executes an RPC function on the server.
 func (c *Client) Rpc(session *Session, id string, input map[string]interface{}) (*api.Rpc, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	// Serialize the input to JSON
@@ -784,7 +970,7 @@ func (c *Client) Rpc(session *Session, id string, input map[string]interface{})
 	jsonStr := string(inputJson)
 
 	// Execute the RPC function on the API client
-	return c.ApiClient.RpcFunc(session.Token, id, jsonStr, "", make(map[string]string))
+	return c.apiFor(session).RpcFunc(session.Token, id, jsonStr, "", make(map[string]string))
 }
 
 // RpcHttpKey executes an RPC function on the server using an HTTP key.
@@ -806,7 +992,7 @@ func (c *Client) RpcHttpKey(httpKey, id string, input map[string]interface{}) (*
 // SessionLogout logs out a session, invalidates a refresh token, or logs out all sessions/refresh tokens for a user.
 func (c *Client) SessionLogout(session *Session, token, refreshToken string) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	// Create request payload for logout
@@ -816,13 +1002,13 @@ func (c *Client) SessionLogout(session *Session, token, refreshToken string) err
 	}
 
 	// Call the API client's session logout function
-	return c.ApiClient.SessionLogout(session.Token, &logoutRequest, make(map[string]string))
+	return c.apiFor(session).SessionLogout(session.Token, &logoutRequest, make(map[string]string))
 }
 
 // SessionRefresh refreshes a user's session using a refresh token retrieved from a previous authentication request.
 func (c *Client) SessionRefresh(session *Session, vars map[string]string) (*Session, error) {
 	if session == nil {
-		return nil, fmt.Errorf("cannot refresh a null session")
+		return nil, ErrSessionNotAuthenticated
 	}
 
 	if session.ExpiresAt > 0 && session.CreatedAt > 0 && session.ExpiresAt-session.CreatedAt < 70 {
@@ -833,123 +1019,116 @@ func (c *Client) SessionRefresh(session *Session, vars map[string]string) (*Sess
 		log.Println("Session refresh lifetime too short, please set '--session.refresh_token_expiry_sec' option. See the documentation for more info: https://heroiclabs.com/docs/nakama/getting-started/configuration/#session")
 	}
 
-	apiSession, err := c.ApiClient.SessionRefresh(c.ServerKey, "", &api.SessionRefreshRequest{
-		Token: session.RefreshToken,
-		Vars:  vars,
-	}, make(map[string]string))
-
-	if err != nil {
+	if err := c.sessions.Refresh(session, vars); err != nil {
 		return nil, err
 	}
-
-	session.Update(apiSession.Token, apiSession.RefreshToken)
 	return session, nil
 }
 
 // UnlinkApple removes the Apple ID from the social profiles on the current user's account.
 func (c *Client) UnlinkApple(session *Session, request *api.AccountApple) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.UnlinkApple(session.Token, request, make(map[string]string))
+	return c.apiFor(session).UnlinkApple(session.Token, request, make(map[string]string))
 }
 
 // UnlinkCustom removes a custom ID from the social profiles on the current user's account.
 func (c *Client) UnlinkCustom(session *Session, request *api.AccountCustom) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.UnlinkCustom(session.Token, request, make(map[string]string))
+	return c.apiFor(session).UnlinkCustom(session.Token, request, make(map[string]string))
 }
 
 // UnlinkDevice removes a device ID from the social profiles on the current user's account.
 func (c *Client) UnlinkDevice(session *Session, request *api.AccountDevice) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.UnlinkDevice(session.Token, request, make(map[string]string))
+	return c.apiFor(session).UnlinkDevice(session.Token, request, make(map[string]string))
 }
 
 // UnlinkEmail removes an email+password from the social profiles on the current user's account.
 func (c *Client) UnlinkEmail(session *Session, request *api.AccountEmail) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.UnlinkEmail(session.Token, request, make(map[string]string))
+	return c.apiFor(session).UnlinkEmail(session.Token, request, make(map[string]string))
 }
 
 // UnlinkFacebook removes the Facebook ID from the social profiles on the current user's account.
 func (c *Client) UnlinkFacebook(session *Session, request *api.AccountFacebook) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
-	return c.ApiClient.UnlinkFacebook(session.Token, request, make(map[string]string))
+	return c.apiFor(session).UnlinkFacebook(session.Token, request, make(map[string]string))
 }
 
 // UnlinkFacebookInstantGame removes Facebook Instant social profiles from the current user's account.
 func (c *Client) UnlinkFacebookInstantGame(session *Session, request *api.AccountFacebookInstantGame) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.UnlinkFacebookInstantGame(session.Token, request, make(map[string]string))
+	return c.apiFor(session).UnlinkFacebookInstantGame(session.Token, request, make(map[string]string))
 }
 
 // UnlinkGoogle removes the Google ID from the social profiles on the current user's account.
 func (c *Client) UnlinkGoogle(session *Session, request *api.AccountGoogle) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.UnlinkGoogle(session.Token, request, make(map[string]string))
+	return c.apiFor(session).UnlinkGoogle(session.Token, request, make(map[string]string))
 }
 
 // UnlinkGameCenter removes GameCenter from the social profiles on the current user's account.
 func (c *Client) UnlinkGameCenter(session *Session, request *api.AccountGameCenter) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.UnlinkGameCenter(session.Token, request, make(map[string]string))
+	return c.apiFor(session).UnlinkGameCenter(session.Token, request, make(map[string]string))
 }
 
 // UnlinkSteam removes Steam from the social profiles on the current user's account.
 func (c *Client) UnlinkSteam(session *Session, request *api.AccountSteam) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.UnlinkSteam(session.Token, request, make(map[string]string))
+	return c.apiFor(session).UnlinkSteam(session.Token, request, make(map[string]string))
 }
 
 // UpdateAccount updates fields in the current user's account.
 func (c *Client) UpdateAccount(session *Session, request *api.UpdateAccountRequest) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.UpdateAccount(session.Token, request, make(map[string]string))
+	return c.apiFor(session).UpdateAccount(session.Token, request, make(map[string]string))
 }
 
 // UpdateGroup updates a group the user is part of and has permissions to update.
 func (c *Client) UpdateGroup(session *Session, groupId string, request *api.UpdateGroupRequest) error {
 	if err := c.refreshSession(session); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
-	return c.ApiClient.UpdateGroup(session.Token, &groupId, request, make(map[string]string))
+	return c.apiFor(session).UpdateGroup(session.Token, &groupId, request, make(map[string]string))
 }
 
 // ValidatePurchaseApple validates an Apple IAP receipt.
 func (c *Client) ValidatePurchaseApple(session *Session, receipt string, persist bool) (*api.ValidatePurchaseResponse, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
-	response, err := c.ApiClient.ValidatePurchaseApple(&session.Token, &api.ValidatePurchaseAppleRequest{
+	response, err := c.apiFor(session).ValidatePurchaseApple(&session.Token, &api.ValidatePurchaseAppleRequest{
 		Receipt: receipt,
 		Persist: wrapperspb.Bool(persist),
 	}, make(map[string]string))
@@ -963,10 +1142,10 @@ func (c *Client) ValidatePurchaseApple(session *Session, receipt string, persist
 // ValidatePurchaseFacebookInstant validates a Facebook Instant IAP receipt.
 func (c *Client) ValidatePurchaseFacebookInstant(session *Session, signedRequest string, persist bool) (*api.ValidatePurchaseResponse, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	response, err := c.ApiClient.ValidatePurchaseFacebookInstant(&session.Token, &api.ValidatePurchaseFacebookInstantRequest{
+	response, err := c.apiFor(session).ValidatePurchaseFacebookInstant(&session.Token, &api.ValidatePurchaseFacebookInstantRequest{
 		SignedRequest: signedRequest,
 		Persist:       wrapperspb.Bool(persist),
 	}, make(map[string]string))
@@ -980,10 +1159,10 @@ func (c *Client) ValidatePurchaseFacebookInstant(session *Session, signedRequest
 // ValidatePurchaseGoogle validates a Google IAP receipt.
 func (c *Client) ValidatePurchaseGoogle(session *Session, purchase string, persist bool) (*api.ValidatePurchaseResponse, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	response, err := c.ApiClient.ValidatePurchaseGoogle(&session.Token, &api.ValidatePurchaseGoogleRequest{
+	response, err := c.apiFor(session).ValidatePurchaseGoogle(&session.Token, &api.ValidatePurchaseGoogleRequest{
 		Purchase: purchase,
 		Persist:  wrapperspb.Bool(persist),
 	}, make(map[string]string))
@@ -997,10 +1176,10 @@ func (c *Client) ValidatePurchaseGoogle(session *Session, purchase string, persi
 // ValidatePurchaseHuawei validates a Huawei IAP receipt.
 func (c *Client) ValidatePurchaseHuawei(session *Session, purchase string, signature string, persist bool) (*api.ValidatePurchaseResponse, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	response, err := c.ApiClient.ValidatePurchaseHuawei(&session.Token, &api.ValidatePurchaseHuaweiRequest{
+	response, err := c.apiFor(session).ValidatePurchaseHuawei(&session.Token, &api.ValidatePurchaseHuaweiRequest{
 		Purchase:  purchase,
 		Signature: signature,
 		Persist:   wrapperspb.Bool(persist),
@@ -1015,10 +1194,10 @@ func (c *Client) ValidatePurchaseHuawei(session *Session, purchase string, signa
 // ValidateSubscriptionApple validates an Apple subscription receipt.
 func (c *Client) ValidateSubscriptionApple(session *Session, receipt string, persist bool) (*api.ValidateSubscriptionResponse, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	response, err := c.ApiClient.ValidateSubscriptionApple(&session.Token, &api.ValidateSubscriptionAppleRequest{
+	response, err := c.apiFor(session).ValidateSubscriptionApple(&session.Token, &api.ValidateSubscriptionAppleRequest{
 		Receipt: receipt,
 		Persist: wrapperspb.Bool(persist),
 	}, make(map[string]string))
@@ -1032,10 +1211,10 @@ func (c *Client) ValidateSubscriptionApple(session *Session, receipt string, per
 // ValidateSubscriptionGoogle validates a Google subscription receipt.
 func (c *Client) ValidateSubscriptionGoogle(session *Session, receipt string, persist bool) (*api.ValidateSubscriptionResponse, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
-	response, err := c.ApiClient.ValidateSubscriptionGoogle(&session.Token, &api.ValidateSubscriptionGoogleRequest{
+	response, err := c.apiFor(session).ValidateSubscriptionGoogle(&session.Token, &api.ValidateSubscriptionGoogleRequest{
 		Receipt: receipt,
 		Persist: wrapperspb.Bool(persist),
 	}, make(map[string]string))
@@ -1046,45 +1225,30 @@ func (c *Client) ValidateSubscriptionGoogle(session *Session, receipt string, pe
 	return response, nil
 }
 
-// WriteLeaderboardRecord writes a record to a leaderboard.
-func (c *Client) WriteLeaderboardRecord(session *Session, leaderboardId string, request *api.WriteLeaderboardRecordRequest_LeaderboardRecordWrite) (*api.LeaderboardRecord, error) {
-	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
-	}
-
-	return c.ApiClient.WriteLeaderboardRecord(
-		session.Token,
-		leaderboardId,
-		request,
-		make(map[string]string),
-	)
-}
-
-// WriteStorageObjects writes storage objects.
+// WriteStorageObjects writes storage objects, it fails with ErrStorageQuotaExceeded before writing over a quota, see WithStorageQuota.
+// The large batches can be streamed, see WithStorageStreaming.
 func (c *Client) WriteStorageObjects(session *Session, objects []*api.WriteStorageObject) (*api.StorageObjectAcks, error) {
 	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
+	objects, err := c.encodeStorageObjects(objects)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	if err := c.checkStorageQuotas(session, objects); err != nil {
+		return nil, wrapErr(err)
+	}
 	request := api.WriteStorageObjectsRequest{Objects: objects}
-	storageObjects, err := c.ApiClient.WriteStorageObjects(session.Token, &request, make(map[string]string))
+	storageObjects, err := c.apiFor(session).WriteStorageObjects(session.Token, &request, make(map[string]string))
 	if err != nil {
 		return nil, err
 	}
-
-	return storageObjects, nil
-}
-
-// WriteTournamentRecord writes a record to a tournament.
-func (c *Client) WriteTournamentRecord(session *Session, tournamentId string, request *api.WriteTournamentRecordRequest_TournamentRecordWrite) (*api.LeaderboardRecord, error) {
-	if err := c.refreshSession(session); err != nil {
-		return nil, errors.As(err)
+	ids := make([]storageIndexKey, 0, len(objects))
+	for _, object := range objects {
+		ids = append(ids, storageIndexKey{Collection: object.GetCollection(), Key: object.GetKey()})
 	}
+	c.unindexStorageObjects(session, ids)
 
-	return c.ApiClient.WriteTournamentRecord(
-		session.Token,
-		tournamentId,
-		request,
-		make(map[string]string),
-	)
+	return storageObjects, nil
 }
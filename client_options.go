@@ -0,0 +1,166 @@
+package nakama
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gwaylib/log/proto"
+)
+
+// ClientOptions is the configuration of a Client built by NewClientWithOptions.
+type ClientOptions struct {
+	ServerKey          string
+	Host               string
+	Port               string
+	UseSSL             bool
+	TimeoutMs          int
+	AttemptTimeoutMs   int
+	AutoRefreshSession bool
+	RetryPolicy        RetryPolicy
+	HttpClient         *http.Client
+	HttpTransport      http.RoundTripper // see WithHTTPTransport
+	Logger             proto.Logger
+	TLS                *TLSOptions           // see WithTLS
+	PublicStorage      *PublicStorageOptions // see WithPublicStorage
+	Clock              Clock                 // see WithClock
+	Faults             *FaultInjector        // see WithFaultInjector
+	Experimental       map[Feature]bool      // see WithExperimental
+	AdaptiveTimeout    *AdaptiveTimeout      // see WithAdaptiveTimeout
+
+	StorageTransformers   map[string][]ValueTransformer // see WithStorageTransformers
+	StorageQuotas         map[string]StorageQuota       // see WithStorageQuota
+	StorageStreamBytes    int                           // see WithStorageStreaming
+	AuthVars              map[string]string             // see WithAuthVars
+	EndpointTimeouts      EndpointTimeouts              // see WithEndpointTimeouts
+	GroupBanRpcs          *GroupBanRpcs                 // see WithGroupBanRpcs
+	ScoreDedupWindow      *time.Duration                // see WithScoreDedupWindow
+	AccountSessionRpcs    *AccountSessionRpcs           // see WithAccountSessionRpcs
+	StorageIndex          *StorageIndexOptions          // see WithStorageIndex
+	Middlewares           []Middleware                  // see WithMiddleware
+	SocketFormat          SocketFormat                  // see WithSocketFormat
+	Proxy                 ProxyFunc                     // see WithProxy
+	MaxConcurrentRequests int                           // see WithMaxConcurrentRequests
+}
+
+// ClientOption sets a field of the ClientOptions.
+type ClientOption func(opts *ClientOptions) error
+
+// WithServerKey sets the server key, DefaultServerKey is used by default.
+func WithServerKey(serverKey string) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.ServerKey = serverKey
+		return nil
+	}
+}
+
+// WithURL sets the host, the port and the scheme of the server from an url like "https://nakama.example.com:7350".
+func WithURL(rawUrl string) ClientOption {
+	return func(opts *ClientOptions) error {
+		u, err := url.Parse(rawUrl)
+		if err != nil {
+			return wrapErr(err, rawUrl)
+		}
+		switch u.Scheme {
+		case "http":
+			opts.UseSSL = false
+		case "https":
+			opts.UseSSL = true
+		default:
+			return newError("unsupported url scheme").With(rawUrl)
+		}
+		opts.Host = u.Hostname()
+		if port := u.Port(); port != "" {
+			opts.Port = port
+		} else if opts.UseSSL {
+			opts.Port = "443"
+		} else {
+			opts.Port = "80"
+		}
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of the http calls in milliseconds, DefaultTimeoutMs is used by default.
+// It bounds a call with all its retries.
+func WithTimeout(timeoutMs int) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.TimeoutMs = timeoutMs
+		return nil
+	}
+}
+
+// WithClock sets the clock of the session expiry checks and of the score dedup window, SystemClock is used by default.
+// The ServerClock of the client can be used to check the expiry in the server time.
+func WithClock(clock Clock) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.Clock = clock
+		return nil
+	}
+}
+
+// WithAttemptTimeout sets the timeout of each attempt of the http calls in milliseconds,
+// so a hanging attempt leaves time for a retry. By default an attempt can use all the remaining timeout.
+func WithAttemptTimeout(attemptTimeoutMs int) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.AttemptTimeoutMs = attemptTimeoutMs
+		return nil
+	}
+}
+
+// WithAutoRefreshSession enables the refresh of the sessions close to their expiry before the calls,
+// and after a call rejected with a 401, e.g. a token revoked early, which is then sent again with the new token.
+func WithAutoRefreshSession(autoRefreshSession bool) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.AutoRefreshSession = autoRefreshSession
+		return nil
+	}
+}
+
+// WithRetryPolicy sets the retries of the http calls failing with a transient error.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.RetryPolicy = policy
+		return nil
+	}
+}
+
+// WithHTTPClient sets the http.Client used for the calls. The clients without it share a client
+// pooling the connections with NewHttpTransport.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.HttpClient = client
+		return nil
+	}
+}
+
+// WithHTTPTransport sets the transport of the calls, e.g. a NewHttpTransport with a proxy or a tracing RoundTripper.
+// It's ignored when WithHTTPClient is used, and replaces the transport of WithTLS.
+func WithHTTPTransport(transport http.RoundTripper) ClientOption {
+	return func(opts *ClientOptions) error {
+		if transport == nil {
+			return newError("nil http transport")
+		}
+		opts.HttpTransport = transport
+		return nil
+	}
+}
+
+// WithLogger sets the logger of the client, the package logger is used by default.
+func WithLogger(logger proto.Logger) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.Logger = logger
+		return nil
+	}
+}
+
+// NewClientWithOptions creates a new instance of Client configured by the options.
+func NewClientWithOptions(opts ...ClientOption) (*Client, error) {
+	options := &ClientOptions{}
+	for _, opt := range opts {
+		if err := opt(options); err != nil {
+			return nil, wrapErr(err)
+		}
+	}
+	return newClient(options), nil
+}
@@ -0,0 +1,103 @@
+package nakama
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// Clock gives the current time to the session expiry checks, it can be replaced in the tests.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock is the local clock, used by default.
+var SystemClock Clock = systemClock{}
+
+// serverClockAlpha is the weight of a new sample in the offset estimation.
+const serverClockAlpha = 0.25
+
+// ServerClock estimates the offset between the local clock and the server clock,
+// it's fed by the Date header of the http responses and the timestamps of the socket acks.
+type ServerClock struct {
+	mu      sync.RWMutex
+	offset  time.Duration
+	samples int
+}
+
+// NewServerClock creates a ServerClock without any sample, Now returns the local time until the first sample.
+func NewServerClock() *ServerClock {
+	return &ServerClock{}
+}
+
+// Observe adds a sample of the server time taken between sentAt and receivedAt in the local time.
+func (c *ServerClock) Observe(serverTime, sentAt, receivedAt time.Time) {
+	if c == nil || serverTime.IsZero() {
+		return
+	}
+	middle := sentAt.Add(receivedAt.Sub(sentAt) / 2)
+	sample := serverTime.Sub(middle)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.samples == 0 {
+		c.offset = sample
+	} else {
+		c.offset += time.Duration(float64(sample-c.offset) * serverClockAlpha)
+	}
+	c.samples++
+}
+
+// ObserveHttpDate adds a sample from the Date header of a http response.
+func (c *ServerClock) ObserveHttpDate(date string, sentAt, receivedAt time.Time) error {
+	if c == nil || date == "" {
+		return nil
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return wrapErr(err, date)
+	}
+	// the header is truncated to the second, use the middle of it.
+	c.Observe(serverTime.Add(500*time.Millisecond), sentAt, receivedAt)
+	return nil
+}
+
+// observeEnvelope adds a sample from the server timestamps carried by a socket response.
+func (c *ServerClock) observeEnvelope(envelope *rtapi.Envelope, sentAt, receivedAt time.Time) {
+	if c == nil || envelope == nil {
+		return
+	}
+	if ack := envelope.GetChannelMessageAck(); ack != nil && ack.CreateTime != nil {
+		c.Observe(ack.CreateTime.AsTime(), sentAt, receivedAt)
+	}
+}
+
+// Offset returns the estimated server time minus the local time.
+func (c *ServerClock) Offset() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.offset
+}
+
+// Synced reports whether at least one sample has been observed.
+func (c *ServerClock) Synced() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.samples > 0
+}
+
+// Now returns the estimated server time.
+func (c *ServerClock) Now() time.Time {
+	return time.Now().Add(c.Offset())
+}
+
+// Until returns the duration until t in the server time, e.g. the end of a tournament.
+func (c *ServerClock) Until(t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
@@ -0,0 +1,33 @@
+package nakama
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerClock_Observe(t *testing.T) {
+	clock := NewServerClock()
+	assert.False(t, clock.Synced())
+
+	sentAt := time.Now()
+	receivedAt := sentAt.Add(200 * time.Millisecond)
+	clock.Observe(sentAt.Add(100*time.Millisecond+time.Hour), sentAt, receivedAt)
+
+	assert.True(t, clock.Synced())
+	assert.Equal(t, time.Hour, clock.Offset())
+	assert.InDelta(t, float64(time.Hour), float64(clock.Until(time.Now().Add(2*time.Hour))), float64(time.Second))
+}
+
+func TestServerClock_ObserveHttpDate(t *testing.T) {
+	clock := NewServerClock()
+	now := time.Now()
+
+	err := clock.ObserveHttpDate(now.Add(-time.Minute).UTC().Format(http.TimeFormat), now, now)
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(-time.Minute), float64(clock.Offset()), float64(time.Second))
+
+	assert.Error(t, clock.ObserveHttpDate("not a date", now, now))
+}
@@ -0,0 +1,135 @@
+package nakama
+
+import (
+	"slices"
+	"sync"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/search"
+)
+
+// UserCollator sorts the user lists by name in the order of a locale, e.g. "Émile" next to "Emma",
+// and searches them ignoring the case and the accents, for the lists rendered by the UI.
+// The users are named by their display name, or their username when they have none.
+// It's safe for concurrent use.
+type UserCollator struct {
+	tag language.Tag
+
+	mu       sync.Mutex // the collator and the matcher aren't safe for concurrent use
+	collator *collate.Collator
+	matcher  *search.Matcher
+}
+
+// NewUserCollator creates a collator for the locale, e.g. "sv-SE" or the POSIX "sv_SE.UTF-8".
+// It's best effort: an unknown locale gets the root collation, and the digits are compared as numbers.
+func NewUserCollator(locale string) *UserCollator {
+	tag, err := language.Parse(normalizeLocale(locale))
+	if err != nil {
+		tag = language.Und
+	}
+	return &UserCollator{
+		tag:      tag,
+		collator: collate.New(tag, collate.Numeric),
+		matcher:  search.New(tag, search.IgnoreCase, search.IgnoreDiacritics),
+	}
+}
+
+// Locale returns the BCP 47 tag of the collation, "und" for the root collation.
+func (c *UserCollator) Locale() string {
+	return c.tag.String()
+}
+
+// Compare compares two names, it returns -1, 0 or 1.
+func (c *UserCollator) Compare(a, b string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.collator.CompareString(a, b)
+}
+
+// Contains reports whether the name contains the query, ignoring the case and the accents. An empty query matches.
+func (c *UserCollator) Contains(name, query string) bool {
+	if query == "" {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	start, _ := c.matcher.IndexString(name, query)
+	return start >= 0
+}
+
+// UserName returns the name of the user shown by the UI, its display name or its username.
+func UserName(user *api.User) string {
+	if name := user.GetDisplayName(); name != "" {
+		return name
+	}
+	return user.GetUsername()
+}
+
+// sortByName sorts the items by name, stable so the equal names keep their order.
+func sortByName[T any](c *UserCollator, items []T, name func(T) string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	slices.SortStableFunc(items, func(a, b T) int {
+		return c.collator.CompareString(name(a), name(b))
+	})
+}
+
+// searchByName returns the items whose name contains the query, sorted by name.
+func searchByName[T any](c *UserCollator, items []T, query string, name func(T) string) []T {
+	found := []T{}
+	for _, item := range items {
+		if c.Contains(name(item), query) {
+			found = append(found, item)
+		}
+	}
+	sortByName(c, found, name)
+	return found
+}
+
+func friendName(friend *api.Friend) string                   { return UserName(friend.GetUser()) }
+func groupUserName(user *api.GroupUserList_GroupUser) string { return UserName(user.GetUser()) }
+func presenceName(presence *rtapi.UserPresence) string       { return presence.GetUsername() }
+func friendOfFriendName(friend *FriendOfFriend) string       { return UserName(friend.GetUser()) }
+
+// SortUsers sorts the users by name.
+func (c *UserCollator) SortUsers(users []*api.User) {
+	sortByName(c, users, UserName)
+}
+
+// SortFriends sorts the friends by name.
+func (c *UserCollator) SortFriends(friends []*api.Friend) {
+	sortByName(c, friends, friendName)
+}
+
+// SortFriendsOfFriends sorts the friends of friends by name.
+func (c *UserCollator) SortFriendsOfFriends(friends []*FriendOfFriend) {
+	sortByName(c, friends, friendOfFriendName)
+}
+
+// SortGroupUsers sorts the members of a group by name, e.g. within each state.
+func (c *UserCollator) SortGroupUsers(users []*api.GroupUserList_GroupUser) {
+	sortByName(c, users, groupUserName)
+}
+
+// SortPresences sorts the presences by username, the presences have no display name.
+func (c *UserCollator) SortPresences(presences []*rtapi.UserPresence) {
+	sortByName(c, presences, presenceName)
+}
+
+// SearchFriends returns the friends whose name contains the query, sorted by name.
+func (c *UserCollator) SearchFriends(friends []*api.Friend, query string) []*api.Friend {
+	return searchByName(c, friends, query, friendName)
+}
+
+// SearchGroupUsers returns the members of a group whose name contains the query, sorted by name.
+func (c *UserCollator) SearchGroupUsers(users []*api.GroupUserList_GroupUser, query string) []*api.GroupUserList_GroupUser {
+	return searchByName(c, users, query, groupUserName)
+}
+
+// Search returns the presences of the roster whose username contains the query, sorted with the collator.
+func (r *Roster) Search(id, query string, collator *UserCollator) []*rtapi.UserPresence {
+	return searchByName(collator, r.Current(id), query, presenceName)
+}
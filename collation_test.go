@@ -0,0 +1,53 @@
+package nakama
+
+import (
+	"testing"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserCollator(t *testing.T) {
+	names := func(users []*api.User) []string {
+		list := []string{}
+		for _, user := range users {
+			list = append(list, UserName(user))
+		}
+		return list
+	}
+	users := func() []*api.User {
+		return []*api.User{
+			{Username: "zed"}, {Username: "u1", DisplayName: "Örjan"}, {Username: "oscar"},
+			{Username: "player10"}, {Username: "player2"}, {Username: "Émile"}, {Username: "emma"},
+		}
+	}
+
+	root := NewUserCollator("")
+	assert.Equal(t, "und", root.Locale())
+	list := users()
+	root.SortUsers(list)
+	assert.Equal(t, []string{"Émile", "emma", "Örjan", "oscar", "player2", "player10", "zed"}, names(list))
+
+	// ö sorts after z in Swedish
+	swedish := NewUserCollator("sv_SE.UTF-8")
+	assert.Equal(t, "sv-SE", swedish.Locale())
+	list = users()
+	swedish.SortUsers(list)
+	assert.Equal(t, []string{"Émile", "emma", "oscar", "player2", "player10", "zed", "Örjan"}, names(list))
+
+	assert.True(t, root.Contains("Émile", "emi"))
+	assert.True(t, root.Contains("player10", ""))
+	assert.False(t, root.Contains("oscar", "orj"))
+
+	friends := []*api.Friend{{User: &api.User{Username: "Zoë"}}, {User: &api.User{Username: "ANNA"}}, {User: &api.User{Username: "zoe2"}}}
+	found := root.SearchFriends(friends, "zoe")
+	assert.Len(t, found, 2)
+	assert.Equal(t, "Zoë", found[0].GetUser().GetUsername())
+
+	roster := NewRoster(nil)
+	roster.Seed("party:p", []*rtapi.UserPresence{{UserId: "1", SessionId: "a", Username: "Bob"}, {UserId: "2", SessionId: "b", Username: "bea"}, {UserId: "3", SessionId: "c", Username: "al"}})
+	presences := roster.Search("party:p", "B", root)
+	assert.Equal(t, []string{"bea", "Bob"}, []string{presences[0].GetUsername(), presences[1].GetUsername()})
+	assert.Empty(t, roster.Search("channel:none", "", root))
+}
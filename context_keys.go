@@ -0,0 +1,49 @@
+package nakama
+
+import (
+	"context"
+)
+
+// contextKey is the type of the keys of the values set by the client on the contexts of the calls.
+type contextKey int
+
+const (
+	sessionContextKey contextKey = iota
+	endpointContextKey
+	attemptContextKey
+)
+
+// ContextWithSession returns a copy of ctx carrying the session, see SessionFromContext.
+func ContextWithSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey, session)
+}
+
+// SessionFromContext returns the session of the call, nil if none.
+//
+// The context of the http requests of the client carries the session, the endpoint and the attempt of the call,
+// e.g. for the http.RoundTripper of WithHTTPClient:
+//
+//	func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+//		ctx := req.Context()
+//		observe(nakama.EndpointFromContext(ctx), nakama.AttemptFromContext(ctx))
+//		return t.next.RoundTrip(req)
+//	}
+//
+// The context of a socket message carries the session of the socket and the message type, see RspResult.Context.
+func SessionFromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionContextKey).(*Session)
+	return session
+}
+
+// EndpointFromContext returns the endpoint of the call, the method name of NakamaApi like "ListGroups",
+// or the message type of a socket message like "match_data". Empty if none.
+func EndpointFromContext(ctx context.Context) string {
+	endpoint, _ := ctx.Value(endpointContextKey).(string)
+	return endpoint
+}
+
+// AttemptFromContext returns the attempt of the http call from 1, the retries of RetryPolicy count up. 0 if none.
+func AttemptFromContext(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptContextKey).(int)
+	return attempt
+}
@@ -0,0 +1,100 @@
+package nakama
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// contextTransport records the values of the contexts of the requests.
+type contextTransport struct {
+	mu       sync.Mutex
+	calls    []string
+	sessions []*Session
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	t.mu.Lock()
+	t.calls = append(t.calls, EndpointFromContext(ctx)+"#"+strconv.Itoa(AttemptFromContext(ctx)))
+	t.sessions = append(t.sessions, SessionFromContext(ctx))
+	t.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestContextValues(t *testing.T) {
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"user":{"id":"user"}}`))
+	}))
+	defer server.Close()
+
+	transport := &contextTransport{}
+	client, err := NewClientWithOptions(
+		WithURL(server.URL),
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, Interval: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+
+	_, err = client.WithContext(context.Background()).GetAccount(session)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GetAccount#1", "GetAccount#2"}, transport.calls)
+	assert.Equal(t, []*Session{session, session}, transport.sessions)
+
+	// the socket messages carry the session of the socket and their type
+	results := make(chan *RspResult, 1)
+	socket := NewDefaultSocket(func(event EventType, data *RspResult) { results <- data }, "127.0.0.1", "7350", "", false, false, nil, nil)
+	socket.SetContext(ContextWithSession(context.Background(), session))
+	assert.NoError(t, socket.handleMessage(1, []byte(`{"match_data":{"match_id":"m1"}}`)))
+	result := <-results
+	assert.Equal(t, session, SessionFromContext(result.Context()))
+	assert.Equal(t, "match_data", EndpointFromContext(result.Context()))
+	assert.Equal(t, 0, AttemptFromContext(result.Context()))
+}
+
+func TestContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewClientWithOptions(WithURL(server.URL), WithTimeout(10000))
+	assert.NoError(t, err)
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = client.WithContext(ctx).GetAccount(session)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// the iterators cancel the page in flight
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	for _, err := range client.Friends(ctx, session, nil) {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, context.Background(), client.Context())
+}
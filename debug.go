@@ -0,0 +1,77 @@
+package nakama
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gwaylib/log"
+	"github.com/gwaylib/log/proto"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var (
+	debugMode atomic.Bool
+
+	loggerMu sync.RWMutex
+	logger   proto.Logger = log.New("nakama")
+
+	// prettyEnvelope is used to dump the envelopes in debug mode.
+	prettyEnvelope = protojson.MarshalOptions{Multiline: true, Indent: "  "}
+)
+
+// SetDebug turns the debug mode on or off, it can be toggled at runtime.
+// When enabled, socket envelopes are dumped as pretty-printed JSON and every HTTP call logs a summary with its timing.
+func SetDebug(on bool) {
+	debugMode.Store(on)
+}
+
+// IsDebug reports whether the debug mode is enabled.
+func IsDebug() bool {
+	return debugMode.Load()
+}
+
+// SetLogger replaces the logger used for the debug output.
+// Levels are color-coded by the gwaylib stdio adapter, plug in another adapter to redirect the output.
+func SetLogger(l proto.Logger) {
+	if l == nil {
+		return
+	}
+	loggerMu.Lock()
+	logger = l
+	loggerMu.Unlock()
+}
+
+// GetLogger returns the logger used for the debug output.
+func GetLogger() proto.Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	return logger
+}
+
+// dumpEnvelope prints an envelope as pretty JSON, direction is something like "send" or "recv".
+func dumpEnvelope(direction string, envelope *rtapi.Envelope) {
+	if envelope == nil {
+		return
+	}
+	data, err := prettyEnvelope.Marshal(envelope)
+	if err != nil {
+		GetLogger().Warnf("%s envelope: %s", direction, err.Error())
+		return
+	}
+	GetLogger().Debugf("%s envelope cid=%q:\n%s", direction, envelope.Cid, string(data))
+}
+
+// dumpHttp prints the summary of a http call.
+func dumpHttp(l proto.Logger, req *http.Request, resp *http.Response, elapsed time.Duration, err error) {
+	switch {
+	case err != nil:
+		l.Errorf("%s %s failed after %s: %s", req.Method, req.URL.Path, elapsed, err.Error())
+	case resp.StatusCode >= 400:
+		l.Warnf("%s %s -> %s in %s", req.Method, req.URL.Path, resp.Status, elapsed)
+	default:
+		l.Debugf("%s %s -> %s in %s", req.Method, req.URL.Path, resp.Status, elapsed)
+	}
+}
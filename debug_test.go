@@ -0,0 +1,102 @@
+package nakama
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	logadapter "github.com/gwaylib/log/logger"
+	"github.com/gwaylib/log/proto"
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureAdapter records the messages of a logger.
+type captureAdapter struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (a *captureAdapter) Put(log *proto.Proto) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, data := range log.Data {
+		a.messages = append(a.messages, strings.TrimSuffix(string(data.Msg), "\n"))
+	}
+}
+
+func (a *captureAdapter) Close() {}
+
+// take returns the messages recorded and forgets them.
+func (a *captureAdapter) take() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	messages := a.messages
+	a.messages = nil
+	return messages
+}
+
+// containing returns the messages matching pattern.
+func containing(messages []string, pattern string) []string {
+	matched := []string{}
+	for _, message := range messages {
+		if regexp.MustCompile(pattern).MatchString(message) {
+			matched = append(matched, message)
+		}
+	}
+	return matched
+}
+
+func TestDebugOutput(t *testing.T) {
+	previous := GetLogger()
+	logs := &captureAdapter{}
+	SetLogger(logadapter.New(&logadapter.DefaultContext, "test", 0, proto.LevelDebug, logs))
+	t.Cleanup(func() {
+		SetDebug(false)
+		SetLogger(previous)
+	})
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer httpServer.Close()
+	client, err := NewClientWithOptions(WithURL(httpServer.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		if rpc := req.GetRpc(); rpc != nil {
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Rpc{Rpc: rpc}})
+		}
+	})
+	socket, _ := server.socket(nil)
+	assert.NoError(t, socket.Connect())
+	calls := func() {
+		assert.NoError(t, client.ApiClient.Healthcheck("", nil))
+		_, ok := socket.Send(&rtapi.Envelope{Message: &rtapi.Envelope_Rpc{Rpc: &api.Rpc{Id: "echo"}}}, nil).(*RspResult)
+		assert.True(t, ok)
+	}
+
+	// the http calls are summed up with their timing, the envelopes are dumped as pretty JSON
+	SetDebug(true)
+	assert.True(t, IsDebug())
+	logs.take()
+	calls()
+	messages := logs.take()
+	assert.Len(t, containing(messages, `^GET /healthcheck -> 200 OK in [0-9.]+[nµm]?s$`), 1, messages)
+	for _, direction := range []string{"send", "recv"} {
+		// protojson varies its spaces on purpose, e.g. with a no-break space
+		pretty := strings.ReplaceAll(`^`+direction+` envelope cid="[0-9]+":\n\{\n_"cid":_"[0-9]+",\n_"rpc":_\{\n_"id":_"echo"\n`, "_", `[\s\x{a0}]+`)
+		assert.Len(t, containing(messages, pretty), 1, messages)
+	}
+
+	// the debug mode is turned off at runtime
+	SetDebug(false)
+	assert.False(t, IsDebug())
+	calls()
+	assert.Empty(t, logs.take())
+}
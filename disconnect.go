@@ -0,0 +1,99 @@
+package nakama
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/coder/websocket"
+)
+
+// ErrConnectionLost is returned by the requests waiting for a response when the connection ends.
+var ErrConnectionLost = newError("connection lost")
+
+// DisconnectKind classifies the reason of a disconnect.
+type DisconnectKind int
+
+const (
+	DisconnectUnknown        DisconnectKind = iota // the connection was lost without a close frame, e.g. a network failure
+	DisconnectNormal                               // the server closed the connection without a specific reason
+	DisconnectByClient                             // the client called Disconnect
+	DisconnectServerShutdown                       // the server is shutting down or restarting
+	DisconnectSessionExpired                       // the token of the socket has expired, a new session is needed
+	DisconnectDuplicate                            // another connection of the same user has replaced this one
+	DisconnectMessageTooBig                        // an inbound message has exceeded the MaxMessageSize
+	DisconnectStale                                // the client has dropped the connection found dead after a sleep of the device
+)
+
+func (k DisconnectKind) String() string {
+	switch k {
+	case DisconnectNormal:
+		return "Normal"
+	case DisconnectByClient:
+		return "ByClient"
+	case DisconnectServerShutdown:
+		return "ServerShutdown"
+	case DisconnectSessionExpired:
+		return "SessionExpired"
+	case DisconnectDuplicate:
+		return "Duplicate"
+	case DisconnectMessageTooBig:
+		return "MessageTooBig"
+	case DisconnectStale:
+		return "Stale"
+	}
+	return "Unknown"
+}
+
+// DisconnectReason describes why a socket has been disconnected.
+type DisconnectReason struct {
+	Kind   DisconnectKind
+	Code   int    // the status code of the close frame, -1 without close frame
+	Reason string // the reason of the close frame
+	Err    error  // the read error ending the connection
+}
+
+// Reconnectable reports whether reconnecting with the same token can succeed.
+func (r *DisconnectReason) Reconnectable() bool {
+	switch r.Kind {
+	case DisconnectByClient, DisconnectSessionExpired, DisconnectDuplicate:
+		return false
+	}
+	return true
+}
+
+// The close reasons of the server for a connection replaced by a new one of the same user,
+// e.g. with the session.single_socket option of Nakama.
+var duplicateCloseReasons = map[string]bool{
+	"server-side session disconnect": true,
+	"duplicate connection":           true,
+}
+
+// disconnectReasonOf parses the close frame carried by the read error of a connection.
+func disconnectReasonOf(err error) *DisconnectReason {
+	reason := &DisconnectReason{Kind: DisconnectUnknown, Code: -1, Err: err}
+	var closeErr websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		if isReadLimited(err) {
+			reason.Kind = DisconnectMessageTooBig
+		}
+		return reason
+	}
+	reason.Code = int(closeErr.Code)
+	reason.Reason = closeErr.Reason
+
+	text := strings.ToLower(closeErr.Reason)
+	switch {
+	case closeErr.Code == websocket.StatusMessageTooBig:
+		reason.Kind = DisconnectMessageTooBig
+	case strings.Contains(text, "expired"):
+		reason.Kind = DisconnectSessionExpired
+	case duplicateCloseReasons[strings.TrimSpace(text)]:
+		reason.Kind = DisconnectDuplicate
+	case closeErr.Code == websocket.StatusGoingAway || closeErr.Code == websocket.StatusServiceRestart ||
+		strings.Contains(text, "shutdown") || strings.Contains(text, "shutting down"):
+		reason.Kind = DisconnectServerShutdown
+	default:
+		reason.Kind = DisconnectNormal
+	}
+	return reason
+}
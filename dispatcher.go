@@ -0,0 +1,105 @@
+package nakama
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// DefaultStreamQueueSize is the number of messages a stream can queue before dropping the new ones.
+const DefaultStreamQueueSize = 256
+
+// streamKey returns the stream of a message, the messages of a stream are handled in the order received.
+// Each chat channel, match, party and custom stream label is a stream, the other messages share the "" stream.
+func streamKey(envelope *rtapi.Envelope) string {
+	switch msg := envelope.GetMessage().(type) {
+	case *rtapi.Envelope_ChannelMessage:
+		return "channel:" + msg.ChannelMessage.GetChannelId()
+	case *rtapi.Envelope_ChannelPresenceEvent:
+		return "channel:" + msg.ChannelPresenceEvent.GetChannelId()
+	case *rtapi.Envelope_MatchData:
+		return "match:" + msg.MatchData.GetMatchId()
+	case *rtapi.Envelope_MatchPresenceEvent:
+		return "match:" + msg.MatchPresenceEvent.GetMatchId()
+	case *rtapi.Envelope_PartyData:
+		return "party:" + msg.PartyData.GetPartyId()
+	case *rtapi.Envelope_PartyPresenceEvent:
+		return "party:" + msg.PartyPresenceEvent.GetPartyId()
+	case *rtapi.Envelope_PartyLeader:
+		return "party:" + msg.PartyLeader.GetPartyId()
+	case *rtapi.Envelope_PartyJoinRequest:
+		return "party:" + msg.PartyJoinRequest.GetPartyId()
+	case *rtapi.Envelope_PartyClose:
+		return "party:" + msg.PartyClose.GetPartyId()
+	case *rtapi.Envelope_StreamData:
+		return "stream:" + msg.StreamData.GetStream().GetLabel()
+	case *rtapi.Envelope_StreamPresenceEvent:
+		return "stream:" + msg.StreamPresenceEvent.GetStream().GetLabel()
+	}
+	return ""
+}
+
+// eventDispatcher delivers the messages to the EventHandler through a FIFO queue per stream,
+// so a slow handler delays its own stream only.
+type eventDispatcher struct {
+	handle    EventHandler
+	queueSize int
+	dropped   atomic.Int64
+
+	mu      sync.Mutex
+	streams map[string]chan *RspResult // stream key:queue, removed when drained
+}
+
+func newEventDispatcher(handle EventHandler, queueSize int) *eventDispatcher {
+	if queueSize <= 0 {
+		queueSize = DefaultStreamQueueSize
+	}
+	return &eventDispatcher{
+		handle:    handle,
+		queueSize: queueSize,
+		streams:   map[string]chan *RspResult{},
+	}
+}
+
+// dispatch queues the message on its stream, it never blocks and reports false when the queue is full.
+func (d *eventDispatcher) dispatch(result *RspResult) bool {
+	key := ""
+	if result.Decoded != nil {
+		key = streamKey(result.Decoded)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	queue, ok := d.streams[key]
+	if !ok {
+		queue = make(chan *RspResult, d.queueSize)
+		d.streams[key] = queue
+		go d.run(key, queue)
+	}
+	select {
+	case queue <- result:
+		return true
+	default:
+		d.dropped.Add(1)
+		return false
+	}
+}
+
+// run handles the messages of a stream until its queue is drained.
+func (d *eventDispatcher) run(key string, queue chan *RspResult) {
+	for {
+		select {
+		case result := <-queue:
+			d.handle(EventTypeMessage, result)
+		default:
+			d.mu.Lock()
+			if len(queue) == 0 {
+				delete(d.streams, key)
+				d.mu.Unlock()
+				return
+			}
+			d.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package nakama
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+func chatResult(channelId, messageId string) *RspResult {
+	return &RspResult{Decoded: &rtapi.Envelope{Message: &rtapi.Envelope_ChannelMessage{
+		ChannelMessage: &api.ChannelMessage{ChannelId: channelId, MessageId: messageId},
+	}}}
+}
+
+func TestEventDispatcherOrder(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	received := map[string][]string{}
+	done := make(chan struct{}, 1)
+
+	d := newEventDispatcher(func(event EventType, data *RspResult) {
+		msg := data.Decoded.GetChannelMessage()
+		if msg.ChannelId == "slow" {
+			<-release
+		}
+		mu.Lock()
+		received[msg.ChannelId] = append(received[msg.ChannelId], msg.MessageId)
+		if msg.ChannelId == "fast" && len(received["fast"]) == 100 {
+			done <- struct{}{}
+		}
+		mu.Unlock()
+	}, 200)
+
+	for i := 0; i < 100; i++ {
+		id := string(rune('a' + i%26))
+		d.dispatch(chatResult("slow", id))
+		d.dispatch(chatResult("fast", id))
+	}
+
+	// the fast channel is not blocked by the slow one
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fast stream blocked by the slow one")
+	}
+	close(release)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(received["slow"])
+		mu.Unlock()
+		if n == 100 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("slow stream got %d messages", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for _, channel := range []string{"slow", "fast"} {
+		for i, id := range received[channel] {
+			if id != string(rune('a'+i%26)) {
+				t.Fatalf("%s message %d out of order: %s", channel, i, id)
+			}
+		}
+	}
+}
+
+func TestEventDispatcherDrop(t *testing.T) {
+	release := make(chan struct{})
+	d := newEventDispatcher(func(event EventType, data *RspResult) { <-release }, 1)
+	defer close(release)
+
+	dropped := 0
+	for i := 0; i < 5; i++ {
+		if !d.dispatch(chatResult("room", "m")) {
+			dropped++
+		}
+	}
+	// one message is handled, one is queued
+	if dropped < 3 || d.dropped.Load() != int64(dropped) {
+		t.Fatalf("dropped %d, counted %d", dropped, d.dropped.Load())
+	}
+}
@@ -0,0 +1,213 @@
+package nakama
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// DoctorMaxClockSkew is the offset with the server clock above which Doctor warns.
+const DoctorMaxClockSkew = 30 * time.Second
+
+// doctorProbeDeviceId is the device authenticated without creation by the server key check.
+const doctorProbeDeviceId = "nakama-go-doctor-probe"
+
+// Doctor check names
+const (
+	DoctorCheckConnectivity = "connectivity"
+	DoctorCheckTLS          = "tls"
+	DoctorCheckServerKey    = "server_key"
+	DoctorCheckClockSkew    = "clock_skew"
+	DoctorCheckWebSocket    = "websocket"
+)
+
+// DoctorStatus is the result of a Doctor check.
+type DoctorStatus int
+
+const (
+	DoctorOk DoctorStatus = iota
+	DoctorWarning
+	DoctorFailed
+	DoctorSkipped
+)
+
+func (s DoctorStatus) String() string {
+	switch s {
+	case DoctorOk:
+		return "ok"
+	case DoctorWarning:
+		return "warning"
+	case DoctorFailed:
+		return "failed"
+	}
+	return "skipped"
+}
+
+// DoctorCheck is the result of a check of Doctor.
+type DoctorCheck struct {
+	Name     string
+	Status   DoctorStatus
+	Detail   string
+	Duration time.Duration
+	Err      error
+}
+
+// DoctorReport is the result of Doctor, the checks are in the order run.
+type DoctorReport struct {
+	Checks []*DoctorCheck
+}
+
+// Ok reports whether no check has failed, the warnings are allowed.
+func (r *DoctorReport) Ok() bool {
+	for _, check := range r.Checks {
+		if check.Status == DoctorFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// Check returns the check with the name, nil if not run.
+func (r *DoctorReport) Check(name string) *DoctorCheck {
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	return nil
+}
+
+// String formats the report with a line per check.
+func (r *DoctorReport) String() string {
+	b := strings.Builder{}
+	for _, check := range r.Checks {
+		fmt.Fprintf(&b, "%-13s %-8s %-10s %s", check.Name, check.Status, check.Duration.Round(time.Millisecond), check.Detail)
+		if check.Err != nil {
+			fmt.Fprintf(&b, ": %s", check.Err)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Doctor verifies the configuration of the client against the server, e.g. when integrating a new environment:
+// the connectivity, the TLS certificate, the acceptance of the server key, the clock skew and the websocket upgrade.
+// The checks don't need a session and don't create any account. ctx bounds all the checks.
+func (c *Client) Doctor(ctx context.Context) *DoctorReport {
+	report := &DoctorReport{}
+	run := func(name string, fn func() (DoctorStatus, string, error)) {
+		startTime := time.Now()
+		status, detail, err := fn()
+		report.Checks = append(report.Checks, &DoctorCheck{
+			Name:     name,
+			Status:   status,
+			Detail:   detail,
+			Duration: time.Since(startTime),
+			Err:      err,
+		})
+	}
+	apiClient := c.ApiClient.WithContext(ctx)
+
+	run(DoctorCheckConnectivity, func() (DoctorStatus, string, error) {
+		if err := apiClient.Healthcheck("", make(map[string]string)); err != nil {
+			return DoctorFailed, "healthcheck of " + c.basePath, err
+		}
+		return DoctorOk, "healthcheck of " + c.basePath, nil
+	})
+	run(DoctorCheckTLS, func() (DoctorStatus, string, error) {
+		return c.doctorTLS(ctx)
+	})
+	run(DoctorCheckServerKey, func() (DoctorStatus, string, error) {
+		create := false
+		_, err := apiClient.AuthenticateDevice(c.ServerKey, "", &api.AccountDevice{Id: doctorProbeDeviceId}, &create, "", make(map[string]string))
+		switch httpStatusOf(err) {
+		case 0:
+			if err != nil {
+				return DoctorFailed, "auth probe", err
+			}
+			return DoctorOk, "auth probe accepted", nil
+		case http.StatusNotFound:
+			// the probe account doesn't exist, the key has been accepted
+			return DoctorOk, "auth probe accepted", nil
+		case http.StatusUnauthorized:
+			return DoctorFailed, "server key rejected", err
+		}
+		return DoctorFailed, "auth probe", err
+	})
+	run(DoctorCheckClockSkew, func() (DoctorStatus, string, error) {
+		if !c.Clock.Synced() {
+			return DoctorSkipped, "no server time observed", nil
+		}
+		offset := c.Clock.Offset()
+		detail := fmt.Sprintf("server clock offset %s", offset.Round(time.Millisecond))
+		if offset > DoctorMaxClockSkew || offset < -DoctorMaxClockSkew {
+			return DoctorWarning, detail + ", the session expiry checks use the local clock", nil
+		}
+		return DoctorOk, detail, nil
+	})
+	run(DoctorCheckWebSocket, func() (DoctorStatus, string, error) {
+		return c.doctorWebSocket(ctx)
+	})
+	return report
+}
+
+func (c *Client) doctorTLS(ctx context.Context) (DoctorStatus, string, error) {
+	if !c.UseSSL {
+		return DoctorSkipped, "plain http", nil
+	}
+	config := &tls.Config{}
+	if c.tls != nil {
+		config = c.tls.Config()
+	}
+	if config.ServerName == "" {
+		config.ServerName = c.Host
+	}
+	dialer := &tls.Dialer{Config: config}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(c.Host, c.Port))
+	if err != nil {
+		return DoctorFailed, "handshake", wrapErr(err, c.Host)
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	detail := tls.VersionName(state.Version)
+	if len(state.PeerCertificates) > 0 {
+		notAfter := state.PeerCertificates[0].NotAfter
+		detail += ", certificate expires " + notAfter.Format(time.RFC3339)
+		if time.Until(notAfter) < 14*24*time.Hour {
+			return DoctorWarning, detail, nil
+		}
+	}
+	return DoctorOk, detail, nil
+}
+
+func (c *Client) doctorWebSocket(ctx context.Context) (DoctorStatus, string, error) {
+	scheme := "ws://"
+	if c.UseSSL {
+		scheme = "wss://"
+	}
+	uri := scheme + net.JoinHostPort(c.Host, c.Port) + "/ws?lang=en&status=false&token="
+	dialOptions := &websocket.DialOptions{}
+	if c.tls != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = c.tls.socketConfig()
+		dialOptions.HTTPClient = &http.Client{Transport: transport}
+	}
+	conn, resp, err := websocket.Dial(ctx, uri, dialOptions)
+	if err == nil {
+		conn.Close(websocket.StatusNormalClosure, "doctor")
+		return DoctorOk, "upgraded", nil
+	}
+	// the server checks the token before the upgrade, a 401 comes from the socket handler of the server
+	if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+		return DoctorOk, "upgrade route reached, token required", nil
+	}
+	return DoctorFailed, "upgrade", wrapErr(err, uri)
+}
@@ -0,0 +1,52 @@
+package nakama
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoctor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+		switch r.URL.Path {
+		case "/healthcheck":
+			w.Write([]byte(`{}`))
+		case "/v2/account/authenticate/device":
+			if user, _, _ := r.BasicAuth(); user != "good-key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"code":16,"message":"Server key invalid"}`))
+				return
+			}
+			assert.Equal(t, "false", r.URL.Query().Get("create"))
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":5,"message":"User account not found."}`))
+		case "/ws":
+			http.Error(w, "Missing or invalid token", http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	client, err := NewClientWithOptions(WithURL(server.URL), WithServerKey("good-key"))
+	assert.NoError(t, err)
+	report := client.Doctor(ctx)
+	assert.True(t, report.Ok(), report.String())
+	assert.Equal(t, DoctorOk, report.Check(DoctorCheckConnectivity).Status)
+	assert.Equal(t, DoctorSkipped, report.Check(DoctorCheckTLS).Status)
+	assert.Equal(t, DoctorOk, report.Check(DoctorCheckServerKey).Status)
+	assert.Equal(t, DoctorWarning, report.Check(DoctorCheckClockSkew).Status)
+	assert.Equal(t, DoctorOk, report.Check(DoctorCheckWebSocket).Status)
+
+	client, err = NewClientWithOptions(WithURL(server.URL), WithServerKey("bad-key"))
+	assert.NoError(t, err)
+	report = client.Doctor(ctx)
+	assert.False(t, report.Ok())
+	assert.Equal(t, DoctorFailed, report.Check(DoctorCheckServerKey).Status)
+}
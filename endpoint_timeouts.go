@@ -0,0 +1,43 @@
+package nakama
+
+import (
+	"maps"
+)
+
+// DefaultSlowEndpointTimeoutMs is the timeout of the endpoints of DefaultEndpointTimeouts.
+const DefaultSlowEndpointTimeoutMs = 30000
+
+// EndpointTimeouts maps the endpoints to their timeout in ms, the endpoints are the method names of NakamaApi
+// like "ValidatePurchaseApple", see EndpointFromContext.
+type EndpointTimeouts map[string]int
+
+// DefaultEndpointTimeouts returns the timeouts of the endpoints known to be slow, the validations waiting for the stores.
+func DefaultEndpointTimeouts() EndpointTimeouts {
+	return EndpointTimeouts{
+		"ValidatePurchaseApple":           DefaultSlowEndpointTimeoutMs,
+		"ValidatePurchaseFacebookInstant": DefaultSlowEndpointTimeoutMs,
+		"ValidatePurchaseGoogle":          DefaultSlowEndpointTimeoutMs,
+		"ValidatePurchaseHuawei":          DefaultSlowEndpointTimeoutMs,
+		"ValidateSubscriptionApple":       DefaultSlowEndpointTimeoutMs,
+		"ValidateSubscriptionGoogle":      DefaultSlowEndpointTimeoutMs,
+	}
+}
+
+// WithEndpointTimeouts merges the timeouts into the endpoint timeouts of the client, e.g.
+// WithEndpointTimeouts(DefaultEndpointTimeouts()). The timeout of an endpoint replaces the timeout of the client
+// for its calls including the retries, WithTimeout, WithAdaptiveTimeout and WithAttemptTimeout, a timeout of 0 removes it.
+func WithEndpointTimeouts(timeouts EndpointTimeouts) ClientOption {
+	return func(opts *ClientOptions) error {
+		for endpoint, timeoutMs := range timeouts {
+			if _, ok := LookupEndpoint(endpoint); !ok || timeoutMs < 0 {
+				return newError("invalid endpoint timeout").With(endpoint, timeoutMs)
+			}
+		}
+		if opts.EndpointTimeouts == nil {
+			opts.EndpointTimeouts = EndpointTimeouts{}
+		}
+		maps.Copy(opts.EndpointTimeouts, timeouts)
+		maps.DeleteFunc(opts.EndpointTimeouts, func(_ string, timeoutMs int) bool { return timeoutMs == 0 })
+		return nil
+	}
+}
@@ -0,0 +1,38 @@
+package nakama
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(
+		WithURL(server.URL),
+		WithTimeout(50),
+		WithAttemptTimeout(50),
+		WithEndpointTimeouts(EndpointTimeouts{"GetAccount": 1000}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+
+	_, err = client.GetAccount(session)
+	assert.NoError(t, err, "the slow endpoint has its own timeout")
+	_, err = client.FetchUsers(session, []string{"user"}, nil, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "the other endpoints keep the timeout of the client")
+
+	_, err = NewClientWithOptions(WithEndpointTimeouts(EndpointTimeouts{"GetAcount": 1000}))
+	assert.Error(t, err, "a misspelled endpoint")
+}
@@ -0,0 +1,189 @@
+package nakama
+
+import (
+	"slices"
+	"strings"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// The authentications of the endpoints, the securities of the OpenAPI spec of Nakama.
+const (
+	EndpointAuthBasic  = "BasicAuth" // the server key, the authenticate and refresh calls
+	EndpointAuthBearer = "BearerJwt" // the session token
+)
+
+// Endpoint describes a call of NakamaApiInterface like an operation of the OpenAPI spec of Nakama,
+// for the tooling introspecting the client, e.g. the mock servers, the usage analytics and the doc generators.
+type Endpoint struct {
+	Name     string                   // the method of NakamaApiInterface, also the endpoint of EndpointFromContext
+	Method   string                   // the http method
+	Path     string                   // the path template, e.g. "/v2/group/{groupId}"
+	Query    []string                 // the query parameters
+	Auth     string                   // EndpointAuthBasic or EndpointAuthBearer
+	Request  protoreflect.MessageType // the message of the body, nil without body or for the raw payload of an rpc
+	Response protoreflect.MessageType // the message of the response, nil without
+}
+
+// PathParams returns the names of the parameters of the path template.
+func (e Endpoint) PathParams() []string {
+	params := []string{}
+	for _, segment := range strings.Split(e.Path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params = append(params, segment[1:len(segment)-1])
+		}
+	}
+	return params
+}
+
+// Match reports whether the request is a call of the endpoint and returns the values of the path parameters.
+func (e Endpoint) Match(method, path string) (map[string]string, bool) {
+	if method != e.Method {
+		return nil, false
+	}
+	template, segments := strings.Split(e.Path, "/"), strings.Split(path, "/")
+	if len(template) != len(segments) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, segment := range template {
+		switch {
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			if segments[i] == "" {
+				return nil, false
+			}
+			params[segment[1:len(segment)-1]] = segments[i]
+		case segment != segments[i]:
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func typeOf(m proto.Message) protoreflect.MessageType {
+	return m.ProtoReflect().Type()
+}
+
+// endpoints are the calls of NakamaApi in the order of api.go, keep them in sync with it.
+var endpoints = []Endpoint{
+	{Name: "Healthcheck", Method: "GET", Path: "/healthcheck", Auth: EndpointAuthBearer},
+	{Name: "DeleteAccount", Method: "DELETE", Path: "/v2/account", Auth: EndpointAuthBearer},
+	{Name: "GetAccount", Method: "GET", Path: "/v2/account", Auth: EndpointAuthBearer, Response: typeOf(&api.Account{})},
+	{Name: "UpdateAccount", Method: "PUT", Path: "/v2/account", Auth: EndpointAuthBearer, Request: typeOf(&api.UpdateAccountRequest{})},
+	{Name: "AuthenticateApple", Method: "POST", Path: "/v2/account/authenticate/apple", Query: []string{"create", "username"}, Auth: EndpointAuthBasic, Request: typeOf(&api.AccountApple{}), Response: typeOf(&api.Session{})},
+	{Name: "AuthenticateCustom", Method: "POST", Path: "/v2/account/authenticate/custom", Query: []string{"create", "username"}, Auth: EndpointAuthBasic, Request: typeOf(&api.AccountCustom{}), Response: typeOf(&api.Session{})},
+	{Name: "AuthenticateDevice", Method: "POST", Path: "/v2/account/authenticate/device", Query: []string{"create", "username"}, Auth: EndpointAuthBasic, Request: typeOf(&api.AccountDevice{}), Response: typeOf(&api.Session{})},
+	{Name: "AuthenticateEmail", Method: "POST", Path: "/v2/account/authenticate/email", Query: []string{"create", "username"}, Auth: EndpointAuthBasic, Request: typeOf(&api.AccountEmail{}), Response: typeOf(&api.Session{})},
+	{Name: "AuthenticateFacebook", Method: "POST", Path: "/v2/account/authenticate/facebook", Query: []string{"create", "username", "sync"}, Auth: EndpointAuthBasic, Request: typeOf(&api.AccountFacebook{}), Response: typeOf(&api.Session{})},
+	{Name: "AuthenticateFacebookInstantGame", Method: "POST", Path: "/v2/account/authenticate/facebookinstantgame", Query: []string{"create", "username"}, Auth: EndpointAuthBasic, Request: typeOf(&api.AccountFacebookInstantGame{}), Response: typeOf(&api.Session{})},
+	{Name: "AuthenticateGameCenter", Method: "POST", Path: "/v2/account/authenticate/gamecenter", Query: []string{"create", "username"}, Auth: EndpointAuthBasic, Request: typeOf(&api.AccountGameCenter{}), Response: typeOf(&api.Session{})},
+	{Name: "AuthenticateGoogle", Method: "POST", Path: "/v2/account/authenticate/google", Query: []string{"create", "username"}, Auth: EndpointAuthBasic, Request: typeOf(&api.AccountGoogle{}), Response: typeOf(&api.Session{})},
+	{Name: "AuthenticateSteam", Method: "POST", Path: "/v2/account/authenticate/steam", Query: []string{"create", "username", "sync"}, Auth: EndpointAuthBasic, Request: typeOf(&api.AccountSteam{}), Response: typeOf(&api.Session{})},
+	{Name: "LinkApple", Method: "POST", Path: "/v2/account/link/apple", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountApple{})},
+	{Name: "LinkCustom", Method: "POST", Path: "/v2/account/link/custom", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountCustom{})},
+	{Name: "LinkDevice", Method: "POST", Path: "/v2/account/link/device", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountDevice{})},
+	{Name: "LinkEmail", Method: "POST", Path: "/v2/account/link/email", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountEmail{})},
+	{Name: "LinkFacebook", Method: "POST", Path: "/v2/account/link/facebook", Query: []string{"sync"}, Auth: EndpointAuthBearer, Request: typeOf(&api.AccountFacebook{})},
+	{Name: "LinkFacebookInstantGame", Method: "POST", Path: "/v2/account/link/facebookinstantgame", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountFacebookInstantGame{})},
+	{Name: "LinkGameCenter", Method: "POST", Path: "/v2/account/link/gamecenter", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountGameCenter{})},
+	{Name: "LinkGoogle", Method: "POST", Path: "/v2/account/link/google", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountGoogle{})},
+	{Name: "LinkSteam", Method: "POST", Path: "/v2/account/link/steam", Auth: EndpointAuthBearer, Request: typeOf(&api.LinkSteamRequest{})},
+	{Name: "SessionRefresh", Method: "POST", Path: "/v2/account/session/refresh", Auth: EndpointAuthBasic, Request: typeOf(&api.SessionRefreshRequest{}), Response: typeOf(&api.Session{})},
+	{Name: "UnlinkApple", Method: "POST", Path: "/v2/account/unlink/apple", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountApple{})},
+	{Name: "UnlinkCustom", Method: "POST", Path: "/v2/account/unlink/custom", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountCustom{})},
+	{Name: "UnlinkDevice", Method: "POST", Path: "/v2/account/unlink/device", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountDevice{})},
+	{Name: "UnlinkEmail", Method: "POST", Path: "/v2/account/unlink/email", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountEmail{})},
+	{Name: "UnlinkFacebook", Method: "POST", Path: "/v2/account/unlink/facebook", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountFacebook{})},
+	{Name: "UnlinkFacebookInstantGame", Method: "POST", Path: "/v2/account/unlink/facebookinstantgame", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountFacebookInstantGame{})},
+	{Name: "UnlinkGameCenter", Method: "POST", Path: "/v2/account/unlink/gamecenter", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountGameCenter{})},
+	{Name: "UnlinkGoogle", Method: "POST", Path: "/v2/account/unlink/google", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountGoogle{})},
+	{Name: "UnlinkSteam", Method: "POST", Path: "/v2/account/unlink/steam", Auth: EndpointAuthBearer, Request: typeOf(&api.AccountSteam{})},
+	{Name: "ListChannelMessages", Method: "GET", Path: "/v2/channel/{channelId}", Query: []string{"limit", "forward", "cursor"}, Auth: EndpointAuthBearer, Response: typeOf(&api.ChannelMessageList{})},
+	{Name: "Event", Method: "POST", Path: "/v2/event", Auth: EndpointAuthBearer, Request: typeOf(&api.Event{})},
+	{Name: "DeleteFriends", Method: "DELETE", Path: "/v2/friend", Query: []string{"ids", "usernames"}, Auth: EndpointAuthBearer},
+	{Name: "ListFriends", Method: "GET", Path: "/v2/friend", Query: []string{"limit", "state", "cursor"}, Auth: EndpointAuthBearer, Response: typeOf(&api.FriendList{})},
+	{Name: "AddFriends", Method: "POST", Path: "/v2/friend", Query: []string{"ids", "usernames"}, Auth: EndpointAuthBearer},
+	{Name: "BlockFriends", Method: "POST", Path: "/v2/friend/block", Query: []string{"ids", "usernames"}, Auth: EndpointAuthBearer},
+	{Name: "ImportFacebookFriends", Method: "POST", Path: "/v2/friend/facebook", Query: []string{"reset"}, Auth: EndpointAuthBearer, Request: typeOf(&api.AccountFacebook{})},
+	{Name: "ListFriendsOfFriends", Method: "GET", Path: "/v2/friend/friends", Query: []string{"limit", "cursor"}, Auth: EndpointAuthBearer, Response: typeOf(&api.FriendsOfFriendsList{})},
+	{Name: "ImportSteamFriends", Method: "POST", Path: "/v2/friend/steam", Query: []string{"reset"}, Auth: EndpointAuthBearer, Request: typeOf(&api.AccountSteam{})},
+	{Name: "ListGroups", Method: "GET", Path: "/v2/group", Query: []string{"name", "cursor", "limit", "lang_tag", "members", "open"}, Auth: EndpointAuthBearer, Response: typeOf(&api.GroupList{})},
+	{Name: "CreateGroup", Method: "POST", Path: "/v2/group", Auth: EndpointAuthBearer, Request: typeOf(&api.CreateGroupRequest{}), Response: typeOf(&api.Group{})},
+	{Name: "DeleteGroup", Method: "DELETE", Path: "/v2/group/{groupId}", Auth: EndpointAuthBearer},
+	{Name: "UpdateGroup", Method: "PUT", Path: "/v2/group/{groupId}", Auth: EndpointAuthBearer, Request: typeOf(&api.UpdateGroupRequest{})},
+	{Name: "AddGroupUsers", Method: "POST", Path: "/v2/group/{groupId}/add", Query: []string{"user_ids"}, Auth: EndpointAuthBearer},
+	{Name: "BanGroupUsers", Method: "POST", Path: "/v2/group/{groupId}/ban", Query: []string{"user_ids"}, Auth: EndpointAuthBearer},
+	{Name: "DemoteGroupUsers", Method: "POST", Path: "/v2/group/{groupId}/demote", Query: []string{"user_ids"}, Auth: EndpointAuthBearer},
+	{Name: "JoinGroup", Method: "POST", Path: "/v2/group/{groupId}/join", Auth: EndpointAuthBearer},
+	{Name: "KickGroupUsers", Method: "POST", Path: "/v2/group/{groupId}/kick", Query: []string{"user_ids"}, Auth: EndpointAuthBearer},
+	{Name: "LeaveGroup", Method: "POST", Path: "/v2/group/{groupId}/leave", Auth: EndpointAuthBearer},
+	{Name: "PromoteGroupUsers", Method: "POST", Path: "/v2/group/{groupId}/promote", Query: []string{"user_ids"}, Auth: EndpointAuthBearer},
+	{Name: "ListGroupUsers", Method: "GET", Path: "/v2/group/{groupId}/user", Query: []string{"limit", "state", "cursor"}, Auth: EndpointAuthBearer, Response: typeOf(&api.GroupUserList{})},
+	{Name: "ValidatePurchaseApple", Method: "POST", Path: "/v2/iap/purchase/apple", Auth: EndpointAuthBearer, Request: typeOf(&api.ValidatePurchaseAppleRequest{}), Response: typeOf(&api.ValidatePurchaseResponse{})},
+	{Name: "ValidatePurchaseFacebookInstant", Method: "POST", Path: "/v2/iap/purchase/facebookinstant", Auth: EndpointAuthBearer, Request: typeOf(&api.ValidatePurchaseFacebookInstantRequest{}), Response: typeOf(&api.ValidatePurchaseResponse{})},
+	{Name: "ValidatePurchaseGoogle", Method: "POST", Path: "/v2/iap/purchase/google", Auth: EndpointAuthBearer, Request: typeOf(&api.ValidatePurchaseGoogleRequest{}), Response: typeOf(&api.ValidatePurchaseResponse{})},
+	{Name: "ValidatePurchaseHuawei", Method: "POST", Path: "/v2/iap/purchase/huawei", Auth: EndpointAuthBearer, Request: typeOf(&api.ValidatePurchaseHuaweiRequest{}), Response: typeOf(&api.ValidatePurchaseResponse{})},
+	{Name: "ListSubscriptions", Method: "POST", Path: "/v2/iap/subscription", Auth: EndpointAuthBearer, Request: typeOf(&api.ListSubscriptionsRequest{}), Response: typeOf(&api.SubscriptionList{})},
+	{Name: "ValidateSubscriptionApple", Method: "POST", Path: "/v2/iap/subscription/apple", Auth: EndpointAuthBearer, Request: typeOf(&api.ValidateSubscriptionAppleRequest{}), Response: typeOf(&api.ValidateSubscriptionResponse{})},
+	{Name: "ValidateSubscriptionGoogle", Method: "POST", Path: "/v2/iap/subscription/google", Auth: EndpointAuthBearer, Request: typeOf(&api.ValidateSubscriptionGoogleRequest{}), Response: typeOf(&api.ValidateSubscriptionResponse{})},
+	{Name: "GetSubscription", Method: "GET", Path: "/v2/iap/subscription/{productId}", Auth: EndpointAuthBearer, Response: typeOf(&api.ValidatedSubscription{})},
+	{Name: "DeleteLeaderboardRecord", Method: "DELETE", Path: "/v2/leaderboard/{leaderboardId}", Auth: EndpointAuthBearer},
+	{Name: "ListLeaderboardRecords", Method: "GET", Path: "/v2/leaderboard/{leaderboardId}", Query: []string{"owner_ids", "limit", "cursor", "expiry"}, Auth: EndpointAuthBearer, Response: typeOf(&api.LeaderboardRecordList{})},
+	{Name: "WriteLeaderboardRecord", Method: "POST", Path: "/v2/leaderboard/{leaderboardId}", Auth: EndpointAuthBearer, Request: typeOf(&api.WriteLeaderboardRecordRequest_LeaderboardRecordWrite{}), Response: typeOf(&api.LeaderboardRecord{})},
+	{Name: "ListLeaderboardRecordsAroundOwner", Method: "GET", Path: "/v2/leaderboard/{leaderboardId}/owner/{ownerId}", Query: []string{"limit", "expiry", "cursor"}, Auth: EndpointAuthBearer, Response: typeOf(&api.LeaderboardRecordList{})},
+	{Name: "ListMatches", Method: "GET", Path: "/v2/match", Query: []string{"limit", "authoritative", "label", "min_size", "max_size", "query"}, Auth: EndpointAuthBearer, Response: typeOf(&api.MatchList{})},
+	{Name: "DeleteNotifications", Method: "DELETE", Path: "/v2/notification", Query: []string{"ids"}, Auth: EndpointAuthBearer},
+	{Name: "ListNotifications", Method: "GET", Path: "/v2/notification", Query: []string{"limit", "cacheable_cursor"}, Auth: EndpointAuthBearer, Response: typeOf(&api.NotificationList{})},
+	{Name: "RpcFunc2", Method: "GET", Path: "/v2/rpc/{id}", Query: []string{"payload", "http_key"}, Auth: EndpointAuthBearer, Response: typeOf(&api.Rpc{})},
+	{Name: "RpcFunc", Method: "POST", Path: "/v2/rpc/{id}", Query: []string{"http_key"}, Auth: EndpointAuthBearer, Response: typeOf(&api.Rpc{})},
+	{Name: "SessionLogout", Method: "POST", Path: "/v2/session/logout", Auth: EndpointAuthBearer, Request: typeOf(&api.SessionLogoutRequest{})},
+	{Name: "ReadStorageObjects", Method: "POST", Path: "/v2/storage", Auth: EndpointAuthBearer, Request: typeOf(&api.ReadStorageObjectsRequest{}), Response: typeOf(&api.StorageObjects{})},
+	{Name: "WriteStorageObjects", Method: "PUT", Path: "/v2/storage", Auth: EndpointAuthBearer, Request: typeOf(&api.WriteStorageObjectsRequest{}), Response: typeOf(&api.StorageObjectAcks{})},
+	{Name: "DeleteStorageObjects", Method: "PUT", Path: "/v2/storage/delete", Auth: EndpointAuthBearer, Request: typeOf(&api.DeleteStorageObjectsRequest{})},
+	{Name: "ListStorageObjects", Method: "GET", Path: "/v2/storage/{collection}", Query: []string{"user_id", "limit", "cursor"}, Auth: EndpointAuthBearer, Response: typeOf(&api.StorageObjectList{})},
+	{Name: "ListStorageObjects2", Method: "GET", Path: "/v2/storage/{collection}/{userId}", Query: []string{"limit", "cursor"}, Auth: EndpointAuthBearer, Response: typeOf(&api.StorageObjectList{})},
+	{Name: "ListTournaments", Method: "GET", Path: "/v2/tournament", Query: []string{"category_start", "category_end", "start_time", "end_time", "limit", "cursor"}, Auth: EndpointAuthBearer, Response: typeOf(&api.TournamentList{})},
+	{Name: "DeleteTournamentRecord", Method: "DELETE", Path: "/v2/tournament/{tournamentId}", Auth: EndpointAuthBearer},
+	{Name: "ListTournamentRecords", Method: "GET", Path: "/v2/tournament/{tournamentId}", Query: []string{"owner_ids", "limit", "cursor", "expiry"}, Auth: EndpointAuthBearer, Response: typeOf(&api.TournamentRecordList{})},
+	{Name: "WriteTournamentRecord2", Method: "POST", Path: "/v2/tournament/{tournamentId}", Auth: EndpointAuthBearer, Request: typeOf(&api.WriteTournamentRecordRequest{}), Response: typeOf(&api.LeaderboardRecord{})},
+	{Name: "WriteTournamentRecord", Method: "PUT", Path: "/v2/tournament/{tournamentId}", Auth: EndpointAuthBearer, Request: typeOf(&api.WriteTournamentRecordRequest_TournamentRecordWrite{}), Response: typeOf(&api.LeaderboardRecord{})},
+	{Name: "JoinTournament", Method: "POST", Path: "/v2/tournament/{tournamentId}/join", Auth: EndpointAuthBearer},
+	{Name: "ListTournamentRecordsAroundOwner", Method: "GET", Path: "/v2/tournament/{tournamentId}/owner/{ownerId}", Query: []string{"limit", "expiry", "cursor"}, Auth: EndpointAuthBearer, Response: typeOf(&api.TournamentRecordList{})},
+	{Name: "GetUsers", Method: "GET", Path: "/v2/user", Query: []string{"ids", "usernames", "facebook_ids"}, Auth: EndpointAuthBearer, Response: typeOf(&api.Users{})},
+	{Name: "ListUserGroups", Method: "GET", Path: "/v2/user/{userId}/group", Query: []string{"limit", "state", "cursor"}, Auth: EndpointAuthBearer, Response: typeOf(&api.UserGroupList{})},
+}
+
+// Endpoints returns the descriptions of the calls of the client, grouped by path.
+func Endpoints() []Endpoint {
+	list := make([]Endpoint, len(endpoints))
+	for i, endpoint := range endpoints {
+		endpoint.Query = slices.Clone(endpoint.Query)
+		list[i] = endpoint
+	}
+	return list
+}
+
+// LookupEndpoint returns the description of a call by name, e.g. "GetAccount".
+func LookupEndpoint(name string) (Endpoint, bool) {
+	for _, endpoint := range endpoints {
+		if endpoint.Name == name {
+			endpoint.Query = slices.Clone(endpoint.Query)
+			return endpoint, true
+		}
+	}
+	return Endpoint{}, false
+}
+
+// MatchEndpoint returns the call of an http request to the server and the values of its path parameters,
+// the path is without the base path, e.g. "/v2/group/abc/join".
+func MatchEndpoint(method, path string) (Endpoint, map[string]string, bool) {
+	for _, endpoint := range endpoints {
+		if params, ok := endpoint.Match(method, path); ok {
+			endpoint.Query = slices.Clone(endpoint.Query)
+			return endpoint, params, true
+		}
+	}
+	return Endpoint{}, nil, false
+}
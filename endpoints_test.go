@@ -0,0 +1,82 @@
+package nakama
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// endpointArg returns a valid argument of a call of NakamaApi, so the call reaches the server.
+func endpointArg(t reflect.Type) reflect.Value {
+	switch {
+	case t.Kind() == reflect.String:
+		return reflect.ValueOf("x").Convert(t)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		return reflect.ValueOf([]string{"x"}).Convert(t)
+	case t.Kind() == reflect.Pointer && t.Elem().Kind() != reflect.Struct:
+		v := reflect.New(t.Elem())
+		v.Elem().Set(endpointArg(t.Elem()))
+		return v
+	case t.Kind() == reflect.Pointer:
+		return reflect.New(t.Elem())
+	}
+	return reflect.Zero(t)
+}
+
+func TestEndpoints(t *testing.T) {
+	api := reflect.TypeFor[NakamaApiInterface]()
+	names := map[string]bool{}
+	for _, endpoint := range Endpoints() {
+		assert.False(t, names[endpoint.Name], "duplicate %s", endpoint.Name)
+		names[endpoint.Name] = true
+		_, ok := api.MethodByName(endpoint.Name)
+		assert.True(t, ok, "%s isn't a call of NakamaApiInterface", endpoint.Name)
+	}
+	for i := range api.NumMethod() {
+		if name := api.Method(i).Name; !strings.HasPrefix(name, "With") {
+			assert.True(t, names[name], "%s has no endpoint", name)
+		}
+	}
+
+	// each call is sent as described
+	var method, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	client := reflect.ValueOf(&NakamaApi{BasePath: server.URL})
+	for _, endpoint := range Endpoints() {
+		call := client.MethodByName(endpoint.Name)
+		args := []reflect.Value{}
+		for i := range call.Type().NumIn() {
+			args = append(args, endpointArg(call.Type().In(i)))
+		}
+		method, path = "", ""
+		call.Call(args)
+		params, ok := endpoint.Match(method, path)
+		assert.True(t, ok, "%s sent %s %s", endpoint.Name, method, path)
+		for _, param := range endpoint.PathParams() {
+			assert.Equal(t, "x", params[param], endpoint.Name)
+		}
+	}
+
+	endpoint, params, ok := MatchEndpoint("POST", "/v2/group/g1/join")
+	assert.True(t, ok)
+	assert.Equal(t, "JoinGroup", endpoint.Name)
+	assert.Equal(t, map[string]string{"groupId": "g1"}, params)
+	endpoint, _, ok = MatchEndpoint("POST", "/v2/iap/subscription/apple")
+	assert.True(t, ok)
+	assert.Equal(t, "ValidateSubscriptionApple", endpoint.Name)
+	assert.Equal(t, "nakama.api.ValidateSubscriptionAppleRequest", string(endpoint.Request.Descriptor().FullName()))
+
+	endpoint, ok = LookupEndpoint("AuthenticateDevice")
+	assert.True(t, ok)
+	assert.Equal(t, EndpointAuthBasic, endpoint.Auth)
+	assert.Equal(t, []string{"create", "username"}, endpoint.Query)
+	assert.NotNil(t, endpoint.Response.New().Interface())
+}
@@ -0,0 +1,130 @@
+package nakama
+
+import (
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrInvalidEnvelope is returned by ValidateEnvelope for an envelope the server would reject.
+var ErrInvalidEnvelope = newError("invalid envelope")
+
+// envelopeRequests are the messages sent by the clients with their required fields, by proto name.
+// A required oneof is set by any of its fields.
+var envelopeRequests = map[string][]protoreflect.Name{
+	"channel_join":            {"target", "type"},
+	"channel_leave":           {"channel_id"},
+	"channel_message_send":    {"channel_id", "content"},
+	"channel_message_update":  {"channel_id", "message_id", "content"},
+	"channel_message_remove":  {"channel_id", "message_id"},
+	"match_create":            {},
+	"match_data_send":         {"match_id"},
+	"match_join":              {"id"},
+	"match_leave":             {"match_id"},
+	"matchmaker_add":          {"min_count", "max_count"},
+	"matchmaker_remove":       {"ticket"},
+	"rpc":                     {"id"},
+	"status_follow":           {},
+	"status_unfollow":         {"user_ids"},
+	"status_update":           {},
+	"ping":                    {},
+	"pong":                    {},
+	"party_create":            {},
+	"party_join":              {"party_id"},
+	"party_leave":             {"party_id"},
+	"party_promote":           {"party_id", "presence"},
+	"party_accept":            {"party_id", "presence"},
+	"party_remove":            {"party_id", "presence"},
+	"party_close":             {"party_id"},
+	"party_join_request_list": {"party_id"},
+	"party_matchmaker_add":    {"party_id", "min_count", "max_count"},
+	"party_matchmaker_remove": {"party_id", "ticket"},
+	"party_data_send":         {"party_id"},
+	"party_update":            {"party_id"},
+}
+
+// envelopeNoReply are the requests the server doesn't answer.
+var envelopeNoReply = map[string]bool{
+	"match_data_send": true,
+	"party_data_send": true,
+	"pong":            true,
+}
+
+// NewEnvelope returns the envelope of a request message, e.g. &rtapi.Rpc{Id: "reward"}, validated by ValidateEnvelope.
+// It's the way to send the messages without a method of DefaultSocket, e.g. the fields added by a newer server,
+// see SendEnvelope.
+func NewEnvelope(message proto.Message) (*rtapi.Envelope, error) {
+	if message == nil {
+		return nil, ErrInvalidEnvelope.With("nil message")
+	}
+	envelope := &rtapi.Envelope{}
+	reflected := envelope.ProtoReflect()
+	name := message.ProtoReflect().Descriptor().FullName()
+	fields := reflected.Descriptor().Oneofs().ByName("message").Fields()
+	for i := range fields.Len() {
+		field := fields.Get(i)
+		if field.Message().FullName() == name {
+			reflected.Set(field, protoreflect.ValueOfMessage(message.ProtoReflect()))
+			if err := ValidateEnvelope(envelope); err != nil {
+				return nil, err
+			}
+			return envelope, nil
+		}
+	}
+	return nil, ErrInvalidEnvelope.With("not an envelope message", name)
+}
+
+// ValidateEnvelope checks the envelope is a request of the clients with its required fields set,
+// a zero number or an empty string being unset.
+func ValidateEnvelope(envelope *rtapi.Envelope) error {
+	name := envelopeType(envelope)
+	if name == "" {
+		return ErrInvalidEnvelope.With("no message")
+	}
+	required, ok := envelopeRequests[name]
+	if !ok {
+		return ErrInvalidEnvelope.With("not a request", name)
+	}
+	message := envelope.ProtoReflect()
+	message = message.Get(message.WhichOneof(message.Descriptor().Oneofs().ByName("message"))).Message()
+	for _, field := range required {
+		if oneof := message.Descriptor().Oneofs().ByName(field); oneof != nil {
+			if message.WhichOneof(oneof) == nil {
+				return ErrInvalidEnvelope.With("missing field", name, field)
+			}
+			continue
+		}
+		descriptor := message.Descriptor().Fields().ByName(field)
+		if descriptor == nil || !message.Has(descriptor) {
+			return ErrInvalidEnvelope.With("missing field", name, field)
+		}
+	}
+	return nil
+}
+
+// SendEnvelope validates the envelope and sends it like the methods of the socket, reconnecting the socket
+// when needed, it returns the reply of the server. The cid of the envelope is set by the socket.
+// The match and party data aren't answered by the server, the reply is then nil.
+func (socket *DefaultSocket) SendEnvelope(envelope *rtapi.Envelope) (*rtapi.Envelope, error) {
+	if err := ValidateEnvelope(envelope); err != nil {
+		return nil, err
+	}
+	if feature := experimentalOf(envelopeType(envelope)); feature != "" {
+		if err := socket.requireExperimental(feature); err != nil {
+			return nil, wrapErr(err)
+		}
+	}
+	if envelopeNoReply[envelopeType(envelope)] {
+		envelope.Cid = ""
+		return nil, socket.SendNoReply(envelope)
+	}
+	result := socket.Send(envelope, nil)
+	if err, ok := result.(error); ok {
+		return nil, wrapErr(err)
+	}
+	rsp, ok := result.(*RspResult)
+	if !ok {
+		return nil, newError("unknow protocal").With(result)
+	}
+	return rsp.Decoded, nil
+}
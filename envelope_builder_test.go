@@ -0,0 +1,61 @@
+package nakama
+
+import (
+	"errors"
+	"testing"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEnvelope(t *testing.T) {
+	envelope, err := NewEnvelope(&api.Rpc{Id: "reward", Payload: "{}"})
+	assert.NoError(t, err)
+	assert.Equal(t, "reward", envelope.GetRpc().GetId())
+
+	envelope, err = NewEnvelope(&rtapi.MatchJoin{Id: &rtapi.MatchJoin_Token{Token: "token"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "token", envelope.GetMatchJoin().GetToken())
+
+	for _, invalid := range []*rtapi.Envelope{
+		{},
+		{Message: &rtapi.Envelope_MatchJoin{MatchJoin: &rtapi.MatchJoin{}}},
+		{Message: &rtapi.Envelope_ChannelJoin{ChannelJoin: &rtapi.ChannelJoin{Target: "lobby"}}},
+		{Message: &rtapi.Envelope_PartyAccept{PartyAccept: &rtapi.PartyAccept{PartyId: "p1"}}},
+		// sent by the server only
+		{Message: &rtapi.Envelope_Match{Match: &rtapi.Match{MatchId: "m1"}}},
+	} {
+		assert.True(t, errors.Is(ValidateEnvelope(invalid), ErrInvalidEnvelope), envelopeType(invalid))
+	}
+	_, err = NewEnvelope(&api.Account{})
+	assert.True(t, errors.Is(err, ErrInvalidEnvelope))
+	_, err = NewEnvelope(&api.Rpc{})
+	assert.Error(t, err)
+}
+
+func TestSendEnvelope(t *testing.T) {
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		if rpc := req.GetRpc(); rpc != nil {
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Rpc{Rpc: &api.Rpc{Id: rpc.GetId(), Payload: `{"ok":true}`}}})
+		}
+	})
+	socket, _ := server.socket(nil)
+	assert.NoError(t, socket.Connect())
+
+	envelope, err := NewEnvelope(&api.Rpc{Id: "reward"})
+	assert.NoError(t, err)
+	reply, err := socket.SendEnvelope(envelope)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, reply.GetRpc().GetPayload())
+
+	// the data isn't answered
+	reply, err = socket.SendEnvelope(&rtapi.Envelope{Message: &rtapi.Envelope_MatchDataSend{MatchDataSend: &rtapi.MatchDataSend{MatchId: "m1", OpCode: 1}}})
+	assert.NoError(t, err)
+	assert.Nil(t, reply)
+	eventually(t, func() bool { return len(server.requestsOf("match_data_send")) == 1 }, "the match data has not been sent")
+
+	_, err = socket.SendEnvelope(&rtapi.Envelope{Message: &rtapi.Envelope_MatchLeave{MatchLeave: &rtapi.MatchLeave{}}})
+	assert.True(t, errors.Is(err, ErrInvalidEnvelope))
+	assert.Empty(t, server.requestsOf("match_leave"))
+}
@@ -0,0 +1,96 @@
+package nakama
+
+import (
+	"encoding/base64"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// maxPooledEnvelopeSize bounds the buffers kept by envelopeBuffers, so a single big message doesn't pin its buffer.
+const maxPooledEnvelopeSize = 64 * 1024
+
+// envelopeMarshal is shared by the sends, the options are read only.
+var envelopeMarshal = protojson.MarshalOptions{}
+
+// envelopeBuffers are the buffers of the envelopes sent.
+var envelopeBuffers = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 1024)
+		return &buf
+	},
+}
+
+// marshalEnvelope marshals the envelope into a pooled buffer, put it back with releaseEnvelope once sent.
+// The match data sends without presences, e.g. the state updates at 60 Hz, skip protojson.
+func marshalEnvelope(envelope *rtapi.Envelope) (*[]byte, error) {
+	buf := envelopeBuffers.Get().(*[]byte)
+	if send := envelope.GetMatchDataSend(); send != nil && len(send.GetPresences()) == 0 &&
+		utf8.ValidString(send.GetMatchId()) && utf8.ValidString(envelope.GetCid()) {
+		*buf = appendMatchDataSend((*buf)[:0], envelope.GetCid(), send)
+		return buf, nil
+	}
+	data, err := envelopeMarshal.MarshalAppend((*buf)[:0], envelope)
+	if err != nil {
+		releaseEnvelope(buf)
+		return nil, wrapErr(err, envelopeType(envelope))
+	}
+	*buf = data
+	return buf, nil
+}
+
+// releaseEnvelope puts the buffer of marshalEnvelope back.
+func releaseEnvelope(buf *[]byte) {
+	if cap(*buf) > maxPooledEnvelopeSize {
+		return
+	}
+	*buf = (*buf)[:0]
+	envelopeBuffers.Put(buf)
+}
+
+// appendMatchDataSend appends the JSON of a match data send envelope like protojson, with the proto names.
+func appendMatchDataSend(b []byte, cid string, send *rtapi.MatchDataSend) []byte {
+	b = append(b, '{')
+	if cid != "" {
+		b = append(b, `"cid":`...)
+		b = appendJSONString(b, cid)
+		b = append(b, ',')
+	}
+	b = append(b, `"match_data_send":{"match_id":`...)
+	b = appendJSONString(b, send.GetMatchId())
+	if send.GetOpCode() != 0 {
+		// int64 are strings in protojson
+		b = append(b, `,"op_code":"`...)
+		b = strconv.AppendInt(b, send.GetOpCode(), 10)
+		b = append(b, '"')
+	}
+	if len(send.GetData()) > 0 {
+		b = append(b, `,"data":"`...)
+		b = base64.StdEncoding.AppendEncode(b, send.GetData())
+		b = append(b, '"')
+	}
+	if send.GetReliable() {
+		b = append(b, `,"reliable":true`...)
+	}
+	return append(b, '}', '}')
+}
+
+// appendJSONString appends s as a JSON string, s must be valid UTF-8.
+func appendJSONString(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	b = append(b, '"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b = append(b, '\\', c)
+		case c < 0x20:
+			b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+		default:
+			b = append(b, c)
+		}
+	}
+	return append(b, '"')
+}
@@ -0,0 +1,63 @@
+package nakama
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMarshalEnvelope(t *testing.T) {
+	envelopes := []*rtapi.Envelope{
+		{Cid: "1f", Message: &rtapi.Envelope_MatchDataSend{MatchDataSend: &rtapi.MatchDataSend{
+			MatchId: "match\"\\\n.node", OpCode: -3, Data: []byte{0, 1, 254, 255}, Reliable: true,
+		}}},
+		{Message: &rtapi.Envelope_MatchDataSend{MatchDataSend: &rtapi.MatchDataSend{MatchId: "match"}}},
+		{Message: &rtapi.Envelope_MatchDataSend{MatchDataSend: &rtapi.MatchDataSend{
+			MatchId: "match", Presences: []*rtapi.UserPresence{{UserId: "user"}},
+		}}},
+		{Cid: "2", Message: &rtapi.Envelope_ChannelMessageSend{ChannelMessageSend: &rtapi.ChannelMessageSend{
+			ChannelId: "channel", Content: `{"msg":"hi"}`,
+		}}},
+	}
+	for _, envelope := range envelopes {
+		buf, err := marshalEnvelope(envelope)
+		assert.NoError(t, err)
+		decoded := &rtapi.Envelope{}
+		assert.NoError(t, protojson.Unmarshal(*buf, decoded), string(*buf))
+		assert.True(t, proto.Equal(envelope, decoded), string(*buf))
+		releaseEnvelope(buf)
+	}
+}
+
+// a 256 bytes state sent at 60 Hz
+func matchStateEnvelope() *rtapi.Envelope {
+	return &rtapi.Envelope{Message: &rtapi.Envelope_MatchDataSend{MatchDataSend: &rtapi.MatchDataSend{
+		MatchId: "0b5b1a5e-3c4d-4f2a-9d6e-7a8b9c0d1e2f.nakama", OpCode: 1, Data: bytes.Repeat([]byte{42}, 256),
+	}}}
+}
+
+func BenchmarkMarshalMatchDataProtojson(b *testing.B) {
+	envelope := matchStateEnvelope()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := protojson.Marshal(envelope); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalMatchDataPooled(b *testing.B) {
+	envelope := matchStateEnvelope()
+	b.ReportAllocs()
+	for b.Loop() {
+		buf, err := marshalEnvelope(envelope)
+		if err != nil {
+			b.Fatal(err)
+		}
+		releaseEnvelope(buf)
+	}
+}
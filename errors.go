@@ -0,0 +1,112 @@
+package nakama
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// Error is a sentinel error of the package, match it with errors.Is, e.g. errors.Is(err, ErrSessionExpired).
+type Error struct {
+	msg string
+}
+
+func newError(msg string) *Error {
+	return &Error{msg: msg}
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// With returns e with the context of the call, errors.Is still matches e.
+func (e *Error) With(args ...any) error {
+	return wrap(e, args)
+}
+
+// wrappedError is an error with the context of a call, and its caller in debug mode.
+type wrappedError struct {
+	err    error
+	args   []any
+	caller string
+}
+
+func (e *wrappedError) Error() string {
+	if len(e.args) == 0 {
+		return e.err.Error()
+	}
+	args := make([]string, 0, len(e.args))
+	for _, arg := range e.args {
+		args = append(args, fmt.Sprint(arg))
+	}
+	return e.err.Error() + " [" + strings.Join(args, ", ") + "]"
+}
+
+func (e *wrappedError) Unwrap() error {
+	return e.err
+}
+
+// wrapErr adds the context of the call to err, nil if err is nil.
+// The caller is recorded in debug mode only, see SetDebug and ErrorStack.
+func wrapErr(err error, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	return wrap(err, args)
+}
+
+func wrap(err error, args []any) error {
+	caller := ""
+	if IsDebug() {
+		// skip wrap and its exported caller
+		if _, file, line, ok := runtime.Caller(2); ok {
+			caller = fmt.Sprintf("%s:%d", file[strings.LastIndexByte(file, '/')+1:], line)
+		}
+	}
+	if len(args) == 0 && caller == "" {
+		return err
+	}
+	return &wrappedError{err: err, args: args, caller: caller}
+}
+
+// ErrorStack returns the callers which have wrapped err, the outermost first.
+// They are only recorded in debug mode, see SetDebug.
+func ErrorStack(err error) []string {
+	stack := []string{}
+	for err != nil {
+		if w, ok := err.(*wrappedError); ok && w.caller != "" {
+			stack = append(stack, w.caller)
+		}
+		err = errors.Unwrap(err)
+	}
+	return stack
+}
+
+// HTTPError is returned by the api calls answered with an error status, get it with errors.As.
+type HTTPError struct {
+	StatusCode int
+	Status     string        // e.g. "404 Not Found"
+	Message    string        // the message of the server, if any
+	RetryAfter time.Duration // the wait asked by the Retry-After header, e.g. of a 429 or a 503, 0 if none
+}
+
+func (e *HTTPError) Error() string {
+	if e.Message == "" {
+		return e.Status
+	}
+	return e.Status + ": " + e.Message
+}
+
+// Error implements error for the error envelopes returned by the socket calls, get it with errors.As.
+func (e *SocketError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// socketErrorOf converts an error envelope.
+func socketErrorOf(e *rtapi.Error) *SocketError {
+	return &SocketError{Code: int(e.GetCode()), Message: e.GetMessage(), Context: e.GetContext()}
+}
@@ -0,0 +1,36 @@
+package nakama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorWrapping(t *testing.T) {
+	err := wrapErr(ErrSessionExpired.With("token"), "call")
+	assert.True(t, errors.Is(err, ErrSessionExpired))
+	assert.Equal(t, "session and refresh token expired, authenticate again [token] [call]", err.Error())
+
+	// the errors of the stdlib are kept for errors.Is
+	assert.True(t, errors.Is(wrapErr(context.Canceled, "rpc"), context.Canceled))
+	assert.Equal(t, context.DeadlineExceeded, wrapErr(context.DeadlineExceeded))
+
+	httpErr := &HTTPError{}
+	assert.True(t, errors.As(fmt.Errorf("list: %w", wrapErr(&HTTPError{StatusCode: 401, Status: "401 Unauthorized"})), &httpErr))
+	assert.Equal(t, 401, httpErr.StatusCode)
+}
+
+func TestErrorStack(t *testing.T) {
+	assert.Empty(t, ErrorStack(wrapErr(ErrNoSession, "login")))
+
+	SetDebug(true)
+	defer SetDebug(false)
+	err := wrapErr(wrapErr(ErrNoSession))
+	assert.True(t, errors.Is(err, ErrNoSession))
+	stack := ErrorStack(err)
+	assert.Len(t, stack, 2)
+	assert.Contains(t, stack[0], "errors_test.go")
+}
@@ -0,0 +1,125 @@
+package nakama
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// DefaultEventChannelSize is the buffer size of an EventChannel when none is set.
+const DefaultEventChannelSize = 256
+
+// DropPolicy tells an EventChannel what to do with an event when its buffer is full.
+type DropPolicy int
+
+const (
+	DropNewest DropPolicy = iota // drop the event received
+	DropOldest                   // drop the oldest event buffered to make room
+	DropNever                    // wait for room, it holds the stream of the event and then its queue fills up
+)
+
+// SocketEvent is an event of an EventChannel, one of SocketStateEvent, SocketMessageEvent or SocketPingEvent.
+type SocketEvent interface {
+	Type() EventType
+}
+
+// SocketStateEvent is a change of the connection: connecting, connected, reconnecting or reconnected.
+type SocketStateEvent struct {
+	State EventType
+}
+
+func (e *SocketStateEvent) Type() EventType { return e.State }
+
+// SocketMessageEvent is a message received from the server, Envelope is nil if the message can't be decoded.
+type SocketMessageEvent struct {
+	Envelope *rtapi.Envelope
+	Data     []byte
+}
+
+func (e *SocketMessageEvent) Type() EventType { return EventTypeMessage }
+
+// SocketPingEvent is the round trip of a ping.
+type SocketPingEvent struct {
+	RoundTrip time.Duration
+}
+
+func (e *SocketPingEvent) Type() EventType { return EventTypePingPong }
+
+// EventChannel delivers the events of a socket on a buffered channel, for the select loops preferred to callbacks.
+// Chain HandleEvent in the EventHandler passed to CreateSocket, or pass it as the EventHandler.
+// The messages of a stream keep their order, except the ones dropped by DropOldest.
+type EventChannel struct {
+	events  chan SocketEvent
+	policy  DropPolicy
+	dropped atomic.Int64
+	mu      sync.Mutex // makes room and pushes at once for DropOldest
+}
+
+// NewEventChannel creates an EventChannel buffering size events, DefaultEventChannelSize when size <= 0.
+func NewEventChannel(size int, policy DropPolicy) *EventChannel {
+	if size <= 0 {
+		size = DefaultEventChannelSize
+	}
+	return &EventChannel{events: make(chan SocketEvent, size), policy: policy}
+}
+
+// Events returns the channel of the events, it's never closed.
+func (ec *EventChannel) Events() <-chan SocketEvent {
+	return ec.events
+}
+
+// Dropped returns the number of events dropped because the buffer was full.
+func (ec *EventChannel) Dropped() int64 {
+	return ec.dropped.Load()
+}
+
+// HandleEvent is an EventHandler pushing the events on the channel.
+func (ec *EventChannel) HandleEvent(event EventType, data *RspResult) {
+	var e SocketEvent
+	switch event {
+	case EventTypeMessage:
+		if data == nil {
+			return
+		}
+		e = &SocketMessageEvent{Envelope: data.Decoded, Data: data.Data}
+	case EventTypePingPong:
+		ping := &SocketPingEvent{}
+		if data != nil {
+			ping.RoundTrip, _ = time.ParseDuration(string(data.Data))
+		}
+		e = ping
+	default:
+		e = &SocketStateEvent{State: event}
+	}
+	ec.push(e)
+}
+
+func (ec *EventChannel) push(e SocketEvent) {
+	switch ec.policy {
+	case DropNever:
+		ec.events <- e
+	case DropOldest:
+		ec.mu.Lock()
+		defer ec.mu.Unlock()
+		for {
+			select {
+			case ec.events <- e:
+				return
+			default:
+			}
+			select {
+			case <-ec.events:
+				ec.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case ec.events <- e:
+		default:
+			ec.dropped.Add(1)
+		}
+	}
+}
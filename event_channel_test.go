@@ -0,0 +1,47 @@
+package nakama
+
+import (
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventChannel(t *testing.T) {
+	message := func(id string) *RspResult {
+		return &RspResult{Decoded: &rtapi.Envelope{Cid: id}}
+	}
+	cidOf := func(e SocketEvent) string {
+		return e.(*SocketMessageEvent).Envelope.Cid
+	}
+
+	newest := NewEventChannel(2, DropNewest)
+	for _, id := range []string{"1", "2", "3"} {
+		newest.HandleEvent(EventTypeMessage, message(id))
+	}
+	assert.Equal(t, int64(1), newest.Dropped())
+	assert.Equal(t, "1", cidOf(<-newest.Events()))
+	assert.Equal(t, "2", cidOf(<-newest.Events()))
+
+	oldest := NewEventChannel(2, DropOldest)
+	for _, id := range []string{"1", "2", "3"} {
+		oldest.HandleEvent(EventTypeMessage, message(id))
+	}
+	assert.Equal(t, int64(1), oldest.Dropped())
+	assert.Equal(t, "2", cidOf(<-oldest.Events()))
+	assert.Equal(t, "3", cidOf(<-oldest.Events()))
+
+	events := NewEventChannel(0, DropNever)
+	events.HandleEvent(EventTypeReconnecting, nil)
+	events.HandleEvent(EventTypePingPong, &RspResult{Data: []byte((15 * time.Millisecond).String())})
+	switch e := (<-events.Events()).(type) {
+	case *SocketStateEvent:
+		assert.Equal(t, EventTypeReconnecting, e.Type())
+	default:
+		t.Fatalf("unexpected event %T", e)
+	}
+	ping, ok := (<-events.Events()).(*SocketPingEvent)
+	assert.True(t, ok)
+	assert.Equal(t, 15*time.Millisecond, ping.RoundTrip)
+}
@@ -0,0 +1,110 @@
+package nakama
+
+import (
+	"sync"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// DomainEventKind is the kind of a DomainEvent.
+type DomainEventKind string
+
+// The domain events published by the NakamaSDK, the socket ones are mapped from the envelopes
+// and the other ones are raised by the http calls changing the state.
+const (
+	DomainEventSessionChanged     DomainEventKind = "session_changed"
+	DomainEventSocketConnected    DomainEventKind = "socket_connected"
+	DomainEventSocketReconnecting DomainEventKind = "socket_reconnecting"
+	DomainEventSocketReconnected  DomainEventKind = "socket_reconnected"
+	DomainEventChatMessage        DomainEventKind = "chat_message"
+	DomainEventChannelPresence    DomainEventKind = "channel_presence"
+	DomainEventMatchData          DomainEventKind = "match_data"
+	DomainEventMatchPresence      DomainEventKind = "match_presence"
+	DomainEventMatchmakerMatched  DomainEventKind = "matchmaker_matched"
+	DomainEventNotification       DomainEventKind = "notification"
+	DomainEventStatusPresence     DomainEventKind = "status_presence"
+	DomainEventFriendsChanged     DomainEventKind = "friends_changed"
+	DomainEventStorageWritten     DomainEventKind = "storage_written"
+	DomainEventStorageDeleted     DomainEventKind = "storage_deleted"
+)
+
+// DomainEvent is a change of state seen by the NakamaSDK.
+type DomainEvent struct {
+	Kind     DomainEventKind
+	Envelope *rtapi.Envelope // the socket message, nil for the events raised by the http calls
+	Payload  any             // e.g. *api.ChannelMessage for DomainEventChatMessage
+}
+
+// DomainEventHandler handles the events published on an EventBus.
+type DomainEventHandler func(event *DomainEvent)
+
+// EventBus dispatches the domain events to the handlers subscribed to their kind.
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers map[DomainEventKind][]DomainEventHandler
+	all      []DomainEventHandler
+}
+
+// NewEventBus creates an empty EventBus.
+func NewEventBus() *EventBus {
+	return &EventBus{handlers: map[DomainEventKind][]DomainEventHandler{}}
+}
+
+// Subscribe registers a handler for the events of the kind, an empty kind subscribes to all the events.
+func (b *EventBus) Subscribe(kind DomainEventKind, handler DomainEventHandler) {
+	if handler == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if kind == "" {
+		b.all = append(b.all, handler)
+		return
+	}
+	b.handlers[kind] = append(b.handlers[kind], handler)
+}
+
+// Publish delivers the event to the handlers of its kind, then to the handlers of all the events.
+func (b *EventBus) Publish(event *DomainEvent) {
+	if event == nil {
+		return
+	}
+	b.mu.RLock()
+	handlers := b.handlers[event.Kind]
+	all := b.all
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+	for _, handler := range all {
+		handler(event)
+	}
+}
+
+// domainEventOf maps a socket message to its domain event, nil if the message has none.
+func domainEventOf(envelope *rtapi.Envelope) *DomainEvent {
+	if envelope == nil {
+		return nil
+	}
+	event := &DomainEvent{Envelope: envelope}
+	switch msg := envelope.GetMessage().(type) {
+	case *rtapi.Envelope_ChannelMessage:
+		event.Kind, event.Payload = DomainEventChatMessage, msg.ChannelMessage
+	case *rtapi.Envelope_ChannelPresenceEvent:
+		event.Kind, event.Payload = DomainEventChannelPresence, msg.ChannelPresenceEvent
+	case *rtapi.Envelope_MatchData:
+		event.Kind, event.Payload = DomainEventMatchData, msg.MatchData
+	case *rtapi.Envelope_MatchPresenceEvent:
+		event.Kind, event.Payload = DomainEventMatchPresence, msg.MatchPresenceEvent
+	case *rtapi.Envelope_MatchmakerMatched:
+		event.Kind, event.Payload = DomainEventMatchmakerMatched, msg.MatchmakerMatched
+	case *rtapi.Envelope_Notifications:
+		event.Kind, event.Payload = DomainEventNotification, msg.Notifications
+	case *rtapi.Envelope_StatusPresenceEvent:
+		event.Kind, event.Payload = DomainEventStatusPresence, msg.StatusPresenceEvent
+	default:
+		return nil
+	}
+	return event
+}
@@ -0,0 +1,34 @@
+package nakama
+
+import (
+	"testing"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+func TestEventBus(t *testing.T) {
+	bus := NewEventBus()
+	var kinds []DomainEventKind
+	var chat int
+	bus.Subscribe("", func(event *DomainEvent) { kinds = append(kinds, event.Kind) })
+	bus.Subscribe(DomainEventChatMessage, func(event *DomainEvent) {
+		if event.Payload.(*api.ChannelMessage).MessageId != "m1" {
+			t.Fatalf("unexpected payload %v", event.Payload)
+		}
+		chat++
+	})
+
+	bus.Publish(domainEventOf(&rtapi.Envelope{Message: &rtapi.Envelope_ChannelMessage{
+		ChannelMessage: &api.ChannelMessage{MessageId: "m1"},
+	}}))
+	bus.Publish(domainEventOf(&rtapi.Envelope{Message: &rtapi.Envelope_Pong{Pong: &rtapi.Pong{}}}))
+	bus.Publish(&DomainEvent{Kind: DomainEventFriendsChanged})
+
+	if chat != 1 {
+		t.Fatalf("chat handler called %d times", chat)
+	}
+	if len(kinds) != 2 || kinds[0] != DomainEventChatMessage || kinds[1] != DomainEventFriendsChanged {
+		t.Fatalf("unexpected events %v", kinds)
+	}
+}
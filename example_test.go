@@ -0,0 +1,134 @@
+package nakama_test
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	nakama "github.com/NorthNorthGames/nakama-go"
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// The examples need a running server, they're compiled by go test but not run.
+
+func ExampleNewClientWithOptions() {
+	client, err := nakama.NewClientWithOptions(
+		nakama.WithURL("https://nakama.example.com:7350"),
+		nakama.WithServerKey("defaultkey"),
+		nakama.WithAutoRefreshSession(true),
+		nakama.WithRetryPolicy(nakama.DefaultRetryPolicy()),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Stop()
+}
+
+func ExampleClient_AuthenticateDevice() {
+	client, _ := nakama.NewClientWithOptions(nakama.WithURL("http://127.0.0.1:7350"))
+
+	// the account is created on the first login of the device
+	create := true
+	session, err := client.AuthenticateDevice("3e70fd52-7192-4b7b-9a42-3f5d4c1e9b0a", &create, "", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(session.UserID, session.Created)
+
+	// keep the tokens to restore the session at the next start
+	restored := nakama.Restore(session.Token, session.RefreshToken)
+	account, err := client.GetAccount(restored)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(account.GetUser().GetUsername())
+}
+
+func ExampleClient_WriteStorageObjects() {
+	client, _ := nakama.NewClientWithOptions(nakama.WithURL("http://127.0.0.1:7350"))
+	session := nakama.Restore("<token>", "<refresh token>")
+
+	acks, err := client.WriteStorageObjects(session, []*api.WriteStorageObject{{
+		Collection: "settings",
+		Key:        "audio",
+		Value:      `{"volume":7}`,
+	}})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// read it back with the version written, e.g. for a conditional write later
+	objects, err := client.ReadStorageObjects(session, &api.ReadStorageObjectsRequest{
+		ObjectIds: []*api.ReadStorageObjectId{{Collection: "settings", Key: "audio", UserId: session.UserID}},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(acks.GetAcks()[0].GetVersion(), objects.GetObjects()[0].GetValue())
+}
+
+func ExampleDefaultSocket_JoinChat() {
+	client, _ := nakama.NewClientWithOptions(nakama.WithURL("http://127.0.0.1:7350"))
+	session := nakama.Restore("<token>", "<refresh token>")
+
+	socket := client.CreateSocket(func(event nakama.EventType, data *nakama.RspResult) {
+		if event != nakama.EventTypeMessage {
+			return
+		}
+		if message := data.Decoded.GetChannelMessage(); message != nil {
+			fmt.Println(message.GetUsername(), message.GetContent())
+		}
+	}, session.Token, false, false, nil, nil)
+	socket.SetTokenSource(func() (string, error) { return client.Sessions().Token(session) })
+	if err := socket.Connect(); err != nil {
+		log.Fatal(err)
+	}
+	defer socket.Disconnect()
+
+	channel, err := socket.JoinChat(nakama.RoomTarget("lobby", true, false))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := socket.WriteChatMessage(channel.GetId(), `{"text":"hello"}`); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleDefaultSocket_AddMatchmaker() {
+	client, _ := nakama.NewClientWithOptions(nakama.WithURL("http://127.0.0.1:7350"))
+	session := nakama.Restore("<token>", "<refresh token>")
+
+	matched := make(chan *rtapi.MatchmakerMatched, 1)
+	socket := client.CreateSocket(func(event nakama.EventType, data *nakama.RspResult) {
+		if event != nakama.EventTypeMessage {
+			return
+		}
+		if m := data.Decoded.GetMatchmakerMatched(); m != nil {
+			matched <- m
+		}
+	}, session.Token, false, false, nil, nil)
+	if err := socket.Connect(); err != nil {
+		log.Fatal(err)
+	}
+	defer socket.Disconnect()
+
+	// 2 to 4 players of the same region
+	ticket, err := socket.AddMatchmaker("+properties.region:eu", 2, 4, map[string]string{"region": "eu"}, nil, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	select {
+	case m := <-matched:
+		match, err := socket.JoinMatchedMatch(m, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := socket.SendMatchState(match.GetMatchId(), 1, []byte(`{"ready":true}`), nil, true); err != nil {
+			log.Fatal(err)
+		}
+	case <-time.After(time.Minute):
+		socket.RemoveMatchmaker(ticket.GetTicket())
+	}
+}
@@ -0,0 +1,98 @@
+package nakama
+
+import (
+	"maps"
+	"strings"
+)
+
+// Feature names an experimental subsystem of the SDK.
+//
+// The stable core, the Client, the DefaultSocket and the NakamaSDK, follows the semantic versioning.
+// The experimental subsystems can change in a minor version, their constructors and their socket calls fail with
+// ErrExperimentalDisabled unless the feature has been enabled by WithExperimental, by EnableExperimental for
+// a socket not created by a client, or by building with the nakama_experimental tag which enables them all.
+type Feature string
+
+// Experimental features
+const (
+	FeatureParties Feature = "parties" // the party calls of DefaultSocket and NewPartyClient
+)
+
+// ErrExperimentalDisabled is returned by the constructors and the calls of the experimental subsystems not enabled.
+var ErrExperimentalDisabled = newError("experimental feature not enabled, see WithExperimental")
+
+// ExperimentalFeatures returns the experimental features of this version.
+func ExperimentalFeatures() []Feature {
+	return []Feature{FeatureParties}
+}
+
+// WithExperimental enables the experimental features, accepting that their apis can change in a minor version.
+func WithExperimental(features ...Feature) ClientOption {
+	return func(opts *ClientOptions) error {
+		for _, feature := range features {
+			if !isExperimental(feature) {
+				return newError("unknown experimental feature").With(feature)
+			}
+			if opts.Experimental == nil {
+				opts.Experimental = map[Feature]bool{}
+			}
+			opts.Experimental[feature] = true
+		}
+		return nil
+	}
+}
+
+func isExperimental(feature Feature) bool {
+	for _, f := range ExperimentalFeatures() {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// ExperimentalEnabled reports whether the experimental feature is enabled for the client.
+func (c *Client) ExperimentalEnabled(feature Feature) bool {
+	return experimentalByDefault || c.experimental[feature]
+}
+
+// requireExperimental fails with ErrExperimentalDisabled when the feature isn't enabled.
+func (c *Client) requireExperimental(feature Feature) error {
+	if !c.ExperimentalEnabled(feature) {
+		return ErrExperimentalDisabled.With(feature, Version())
+	}
+	return nil
+}
+
+// EnableExperimental enables the experimental features on the socket, the sockets created by a client
+// have the features of the client.
+func (socket *DefaultSocket) EnableExperimental(features ...Feature) error {
+	enabled := map[Feature]bool{}
+	if current := socket.experimental.Load(); current != nil {
+		maps.Copy(enabled, *current)
+	}
+	for _, feature := range features {
+		if !isExperimental(feature) {
+			return newError("unknown experimental feature").With(feature)
+		}
+		enabled[feature] = true
+	}
+	socket.experimental.Store(&enabled)
+	return nil
+}
+
+// requireExperimental fails with ErrExperimentalDisabled when the feature isn't enabled on the socket.
+func (socket *DefaultSocket) requireExperimental(feature Feature) error {
+	if enabled := socket.experimental.Load(); experimentalByDefault || (enabled != nil && (*enabled)[feature]) {
+		return nil
+	}
+	return ErrExperimentalDisabled.With(feature, Version())
+}
+
+// experimentalOf returns the feature of an envelope sent by SendEnvelope, empty if it's part of the stable core.
+func experimentalOf(envelope string) Feature {
+	if strings.HasPrefix(envelope, "party_") {
+		return FeatureParties
+	}
+	return ""
+}
@@ -0,0 +1,6 @@
+//go:build !nakama_experimental
+
+package nakama
+
+// experimentalByDefault enables all the experimental features, set by the nakama_experimental build tag.
+const experimentalByDefault = false
@@ -0,0 +1,64 @@
+package nakama
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExperimentalGating(t *testing.T) {
+	client, err := NewClientWithOptions()
+	assert.NoError(t, err)
+	if !experimentalByDefault {
+		_, err = NewPartyClient(client, nil)
+		assert.True(t, errors.Is(err, ErrExperimentalDisabled))
+	}
+
+	_, err = NewClientWithOptions(WithExperimental("teleport"))
+	assert.Error(t, err)
+
+	client, err = NewClientWithOptions(WithExperimental(FeatureParties))
+	assert.NoError(t, err)
+	assert.True(t, client.ExperimentalEnabled(FeatureParties))
+	parties, err := NewPartyClient(client, nil)
+	assert.NoError(t, err)
+
+	var leader string
+	parties.OnLeader = func(l *rtapi.PartyLeader) { leader = l.GetPresence().GetUserId() }
+	parties.HandleEvent(EventTypeMessage, &RspResult{Decoded: &rtapi.Envelope{Message: &rtapi.Envelope_PartyLeader{
+		PartyLeader: &rtapi.PartyLeader{PartyId: "party", Presence: &rtapi.UserPresence{UserId: "user"}},
+	}}})
+	assert.Equal(t, "user", leader)
+
+	assert.NotEmpty(t, Version())
+}
+
+func TestExperimentalSocketGating(t *testing.T) {
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		if req.GetPartyCreate() != nil {
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Party{Party: &rtapi.Party{PartyId: "party"}}})
+		}
+	})
+	socket, _ := server.socket(nil)
+	assert.NoError(t, socket.Connect())
+	if !experimentalByDefault {
+		_, err := socket.CreateParty(true, 4)
+		assert.True(t, errors.Is(err, ErrExperimentalDisabled))
+		_, err = socket.SendEnvelope(&rtapi.Envelope{Message: &rtapi.Envelope_PartyCreate{PartyCreate: &rtapi.PartyCreate{}}})
+		assert.True(t, errors.Is(err, ErrExperimentalDisabled))
+		assert.Empty(t, server.requestsOf("party_create"))
+	}
+	assert.Error(t, socket.EnableExperimental("teleport"))
+
+	assert.NoError(t, socket.EnableExperimental(FeatureParties))
+	party, err := socket.CreateParty(true, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "party", party.GetPartyId())
+
+	// the sockets of a client have its features
+	client, err := NewClientWithOptions(WithExperimental(FeatureParties))
+	assert.NoError(t, err)
+	assert.NoError(t, client.CreateSocket(nil, "token", false, false, nil, nil).requireExperimental(FeatureParties))
+}
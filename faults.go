@@ -0,0 +1,152 @@
+package nakama
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// FaultInjector injects faults in the http calls and the sockets of a client to test the resilience of a game,
+// e.g. in the QA builds. All the faults are off until set, and can be changed at runtime.
+// A nil FaultInjector injects nothing.
+type FaultInjector struct {
+	mu              sync.RWMutex
+	enabled         bool
+	socketDropRate  float64       // probability to drop an inbound socket message
+	httpDelay       time.Duration // added to each http response
+	disconnectEvery time.Duration // period of the forced socket disconnects, 0 means never
+}
+
+// NewFaultInjector creates an enabled FaultInjector without any fault set.
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{enabled: true}
+}
+
+// SetEnabled turns all the faults on or off, keeping their settings.
+func (f *FaultInjector) SetEnabled(enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.enabled = enabled
+}
+
+// SetSocketDropRate sets the probability in [0, 1] to drop an inbound socket message before its handler.
+func (f *FaultInjector) SetSocketDropRate(rate float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.socketDropRate = min(max(rate, 0), 1)
+}
+
+// SetHTTPDelay sets the delay added to each http response, it counts in the timeout of the call.
+func (f *FaultInjector) SetHTTPDelay(delay time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.httpDelay = delay
+}
+
+// SetDisconnectEvery forces the sockets to disconnect every period, 0 stops it.
+// It applies to the connections opened afterwards.
+func (f *FaultInjector) SetDisconnectEvery(period time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.disconnectEvery = period
+}
+
+// dropSocketMessage reports whether an inbound socket message must be dropped.
+func (f *FaultInjector) dropSocketMessage() bool {
+	if f == nil {
+		return false
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.enabled && f.socketDropRate > 0 && rand.Float64() < f.socketDropRate
+}
+
+func (f *FaultInjector) responseDelay() time.Duration {
+	if f == nil {
+		return 0
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if !f.enabled {
+		return 0
+	}
+	return f.httpDelay
+}
+
+func (f *FaultInjector) disconnectPeriod() time.Duration {
+	if f == nil {
+		return 0
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if !f.enabled {
+		return 0
+	}
+	return f.disconnectEvery
+}
+
+// RoundTripper wraps next, nil meaning the transport shared by the clients, to delay the responses.
+func (f *FaultInjector) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = defaultHttpClient.Transport
+	}
+	return &faultTransport{faults: f, next: next}
+}
+
+// httpClient returns a copy of client, nil meaning a new one, with its transport wrapped by RoundTripper.
+func (f *FaultInjector) httpClient(client *http.Client) *http.Client {
+	clone := &http.Client{}
+	if client != nil {
+		*clone = *client
+	}
+	clone.Transport = f.RoundTripper(clone.Transport)
+	return clone
+}
+
+// injectDisconnects closes conn every disconnect period until done is closed, like a lost connection.
+func (f *FaultInjector) injectDisconnects(conn *websocket.Conn, done <-chan struct{}) {
+	period := f.disconnectPeriod()
+	if period <= 0 {
+		return
+	}
+	timer := time.NewTimer(period)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		conn.CloseNow()
+	}
+}
+
+type faultTransport struct {
+	faults *FaultInjector
+	next   http.RoundTripper
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if delay := t.faults.responseDelay(); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-req.Context().Done():
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+	return resp, err
+}
+
+// WithFaultInjector injects the faults in the http calls and the sockets created by the client.
+func WithFaultInjector(faults *FaultInjector) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.Faults = faults
+		return nil
+	}
+}
@@ -0,0 +1,77 @@
+package nakama
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFaultInjectorHTTPDelay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	faults := NewFaultInjector()
+	faults.SetHTTPDelay(100 * time.Millisecond)
+	client, err := NewClientWithOptions(WithURL(server.URL), WithFaultInjector(faults))
+	assert.NoError(t, err)
+
+	startTime := time.Now()
+	assert.NoError(t, client.ApiClient.Healthcheck("", nil))
+	assert.GreaterOrEqual(t, time.Since(startTime), 100*time.Millisecond)
+
+	// the delay counts in the timeout of the call
+	client.ApiClient.(*NakamaApi).TimeoutMs = 50
+	assert.Error(t, client.ApiClient.Healthcheck("", nil))
+
+	faults.SetEnabled(false)
+	assert.NoError(t, client.ApiClient.Healthcheck("", nil))
+}
+
+func TestFaultInjectorSocket(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		for {
+			if err := conn.Write(r.Context(), websocket.MessageText, []byte(`{}`)); err != nil {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+
+	faults := NewFaultInjector()
+	faults.SetSocketDropRate(1)
+	faults.SetDisconnectEvery(100 * time.Millisecond)
+
+	messages := make(chan struct{}, 100)
+	disconnects := make(chan *DisconnectReason, 1)
+	adapter := NewWebSocketAdapterText("ws://", host, port, false, "token")
+	adapter.SetFaultInjector(faults)
+	adapter.onMessage = func(mType int, message []byte) { messages <- struct{}{} }
+	adapter.onDisconnect = func(reason *DisconnectReason) { disconnects <- reason }
+	assert.NoError(t, adapter.Connect())
+	defer adapter.Close()
+
+	select {
+	case reason := <-disconnects:
+		assert.True(t, reason.Reconnectable())
+	case <-time.After(5 * time.Second):
+		t.Fatal("no forced disconnect")
+	}
+	assert.Len(t, messages, 0, "all the messages should be dropped")
+}
@@ -0,0 +1,289 @@
+package nakama
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// The defaults of FragmentOptions.
+const (
+	DefaultFragmentOpCode       = 0x7ef0
+	DefaultServerMaxMessageSize = 4096 // the default socket.max_message_size_bytes of Nakama
+	DefaultFragmentTimeout      = 10 * time.Second
+)
+
+const (
+	// maxFragments bounds the fragments of a message, e.g. 1024 fragments of about 3KB.
+	maxFragments = 1024
+	// maxPendingFragmented bounds the messages being reassembled, the oldest is dropped beyond.
+	maxPendingFragmented = 64
+	// fragmentRelayReserve is the room kept in a fragment for the presence added by the server when it's relayed.
+	fragmentRelayReserve = 512
+	// maxFragmentHeader is the most bytes of the header of a fragment, 4 varints.
+	maxFragmentHeader = 4 * binary.MaxVarintLen64
+)
+
+// ErrFragmentTooBig is returned when the data of a match or a party can't be sent even fragmented.
+var ErrFragmentTooBig = newError("data too big to be fragmented")
+
+// FragmentOptions sets the fragmentation of the match and party data bigger than the max message size of the server,
+// which closes the connection otherwise. The fragments are sent with OpCode and reassembled by the sockets
+// with the same options, all the players of a match must enable it with the same op code.
+type FragmentOptions struct {
+	// ServerMaxMessageSize is the socket.max_message_size_bytes of the server config, DefaultServerMaxMessageSize if 0.
+	// The smaller MaxMessageSize of the WebSocketOptions of the socket applies too, so the fragments of the other
+	// players fit in it.
+	ServerMaxMessageSize int
+	// OpCode is the op code of the fragments, DefaultFragmentOpCode if 0. The games can't use it.
+	OpCode int64
+	// Timeout drops the messages not reassembled in time, e.g. after a fragment lost, DefaultFragmentTimeout if 0.
+	Timeout time.Duration
+}
+
+// fragmentKey identifies a fragmented message of a sender in a match or a party.
+type fragmentKey struct {
+	stream string
+	sender string
+	id     uint64
+}
+
+// fragmentedMessage is a message being reassembled.
+type fragmentedMessage struct {
+	opCode   int64
+	parts    [][]byte
+	received int
+	started  time.Time
+}
+
+// fragmenter splits the data sent and reassembles the data received.
+type fragmenter struct {
+	options FragmentOptions
+	nextId  atomic.Uint64
+
+	mu      sync.Mutex
+	pending map[fragmentKey]*fragmentedMessage
+}
+
+func newFragmenter(options FragmentOptions) *fragmenter {
+	if options.ServerMaxMessageSize <= 0 {
+		options.ServerMaxMessageSize = DefaultServerMaxMessageSize
+	}
+	if options.OpCode == 0 {
+		options.OpCode = DefaultFragmentOpCode
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultFragmentTimeout
+	}
+	return &fragmenter{options: options, pending: map[fragmentKey]*fragmentedMessage{}}
+}
+
+// messageSize returns the max size of a message, the smaller of the server limit and the inbound limit of the socket.
+func (f *fragmenter) messageSize(inboundLimit int64) int {
+	if inboundLimit > 0 && inboundLimit < int64(f.options.ServerMaxMessageSize) {
+		return int(inboundLimit)
+	}
+	return f.options.ServerMaxMessageSize
+}
+
+// chunkSize returns the bytes of data carried by a fragment, overhead being the size of the envelope without data.
+func (f *fragmenter) chunkSize(inboundLimit int64, overhead int) int {
+	// the data is in base64
+	return (f.messageSize(inboundLimit)-overhead-fragmentRelayReserve)/4*3 - maxFragmentHeader
+}
+
+// split returns the fragments of data, each one a header followed by at most chunk bytes of data.
+func (f *fragmenter) split(opCode int64, data []byte, chunk int) ([][]byte, error) {
+	if chunk <= 0 {
+		return nil, ErrFragmentTooBig.With("max message size too small", f.options.ServerMaxMessageSize)
+	}
+	count := (len(data) + chunk - 1) / chunk
+	if count > maxFragments {
+		return nil, ErrFragmentTooBig.With(len(data), count)
+	}
+	id := f.nextId.Add(1)
+	fragments := make([][]byte, 0, count)
+	for i := range count {
+		part := data[i*chunk : min((i+1)*chunk, len(data))]
+		fragment := make([]byte, 0, maxFragmentHeader+len(part))
+		fragment = binary.AppendVarint(fragment, opCode)
+		fragment = binary.AppendUvarint(fragment, id)
+		fragment = binary.AppendUvarint(fragment, uint64(i))
+		fragment = binary.AppendUvarint(fragment, uint64(count))
+		fragments = append(fragments, append(fragment, part...))
+	}
+	return fragments, nil
+}
+
+// add adds a fragment received from sender in the stream, it returns the op code and the data of the message
+// once all its fragments are received.
+func (f *fragmenter) add(stream, sender string, fragment []byte, now time.Time) (int64, []byte, bool, error) {
+	opCode, n := binary.Varint(fragment)
+	if n <= 0 {
+		return 0, nil, false, newError("invalid fragment header")
+	}
+	fragment = fragment[n:]
+	var header [3]uint64
+	for i := range header {
+		v, n := binary.Uvarint(fragment)
+		if n <= 0 {
+			return 0, nil, false, newError("invalid fragment header")
+		}
+		header[i], fragment = v, fragment[n:]
+	}
+	id, index, count := header[0], header[1], header[2]
+	if count == 0 || count > maxFragments || index >= count {
+		return 0, nil, false, newError("invalid fragment header").With(index, count)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.expire(now)
+	key := fragmentKey{stream: stream, sender: sender, id: id}
+	message, ok := f.pending[key]
+	if !ok {
+		if len(f.pending) >= maxPendingFragmented {
+			f.dropOldest()
+		}
+		message = &fragmentedMessage{opCode: opCode, parts: make([][]byte, count), started: now}
+		f.pending[key] = message
+	}
+	if int(count) != len(message.parts) || message.parts[index] != nil {
+		return 0, nil, false, newError("inconsistent fragment").With(stream, sender, id, index)
+	}
+	// the fragment may be a pooled buffer
+	message.parts[index] = append([]byte{}, fragment...)
+	message.received++
+	if message.received < len(message.parts) {
+		return 0, nil, false, nil
+	}
+	delete(f.pending, key)
+	size := 0
+	for _, part := range message.parts {
+		size += len(part)
+	}
+	data := make([]byte, 0, size)
+	for _, part := range message.parts {
+		data = append(data, part...)
+	}
+	return message.opCode, data, true, nil
+}
+
+// expire drops the messages not reassembled within the timeout, f.mu is held.
+func (f *fragmenter) expire(now time.Time) {
+	for key, message := range f.pending {
+		if now.Sub(message.started) >= f.options.Timeout {
+			GetLogger().Warnf("fragmented message expired, %d/%d fragments received", message.received, len(message.parts))
+			delete(f.pending, key)
+		}
+	}
+}
+
+// dropOldest drops the oldest message being reassembled, f.mu is held.
+func (f *fragmenter) dropOldest() {
+	var oldest *fragmentKey
+	for key, message := range f.pending {
+		if oldest == nil || message.started.Before(f.pending[*oldest].started) {
+			oldest = &key
+		}
+	}
+	if oldest != nil {
+		delete(f.pending, *oldest)
+	}
+}
+
+// SetFragmentation turns the fragmentation of the big match and party data on, see FragmentOptions. Nil turns it off,
+// the fragments received are then delivered as they are.
+func (socket *DefaultSocket) SetFragmentation(options *FragmentOptions) {
+	if options == nil {
+		socket.fragments.Store(nil)
+		return
+	}
+	socket.fragments.Store(newFragmenter(*options))
+}
+
+// fragment returns the fragments of the data of the envelope and their op code, nil when the data fits
+// in a message or the fragmentation is off. The envelope is a match data send or a party data send.
+func (socket *DefaultSocket) fragment(envelope *rtapi.Envelope, opCode int64, data []byte) (int64, [][]byte, error) {
+	f := socket.fragments.Load()
+	if f == nil {
+		return 0, nil, nil
+	}
+	inboundLimit := socket.adapter.maxMessageSize()
+	if base64.StdEncoding.EncodedLen(len(data)) < f.messageSize(inboundLimit)/2 {
+		// small enough whatever the envelope
+		return 0, nil, nil
+	}
+	buf, err := marshalEnvelope(envelope)
+	if err != nil {
+		return 0, nil, wrapErr(err)
+	}
+	size := len(*buf)
+	releaseEnvelope(buf)
+	if size+fragmentRelayReserve <= f.messageSize(inboundLimit) {
+		return 0, nil, nil
+	}
+	chunk := f.chunkSize(inboundLimit, size-base64.StdEncoding.EncodedLen(len(data)))
+	fragments, err := f.split(opCode, data, chunk)
+	if err != nil {
+		return 0, nil, wrapErr(err)
+	}
+	return f.options.OpCode, fragments, nil
+}
+
+// fragmentedEnvelope is the marshaling of the reassembled messages, with the proto names of the server messages.
+var fragmentedEnvelope = protojson.MarshalOptions{UseProtoNames: true}
+
+// reassemble adds the fragment received, the whole message is handled as if it had been received once complete.
+// handled is false when the data isn't a fragment.
+func (socket *DefaultSocket) reassemble(envelope *rtapi.Envelope) (handled bool) {
+	f := socket.fragments.Load()
+	if f == nil {
+		return false
+	}
+	var stream string
+	var presence *rtapi.UserPresence
+	var fragment []byte
+	switch {
+	case envelope.GetMatchData().GetOpCode() == f.options.OpCode:
+		data := envelope.GetMatchData()
+		stream, presence, fragment = data.GetMatchId(), data.GetPresence(), data.GetData()
+	case envelope.GetPartyData().GetOpCode() == f.options.OpCode:
+		data := envelope.GetPartyData()
+		stream, presence, fragment = data.GetPartyId(), data.GetPresence(), data.GetData()
+	default:
+		return false
+	}
+	opCode, data, complete, err := f.add(stream, presence.GetSessionId(), fragment, time.Now())
+	if err != nil {
+		GetLogger().Warn(wrapErr(err, "fragment dropped"))
+		return true
+	}
+	if !complete {
+		return true
+	}
+	if match := envelope.GetMatchData(); match != nil {
+		envelope = &rtapi.Envelope{Message: &rtapi.Envelope_MatchData{MatchData: &rtapi.MatchData{
+			MatchId: match.GetMatchId(), Presence: presence, OpCode: opCode, Data: data, Reliable: match.GetReliable(),
+		}}}
+	} else {
+		envelope = &rtapi.Envelope{Message: &rtapi.Envelope_PartyData{PartyData: &rtapi.PartyData{
+			PartyId: stream, Presence: presence, OpCode: opCode, Data: data,
+		}}}
+	}
+	message, err := fragmentedEnvelope.Marshal(envelope)
+	if err != nil {
+		GetLogger().Warn(wrapErr(err, "fragmented message dropped"))
+		return true
+	}
+	if err := socket.handleMessage(int(websocket.MessageText), message); err != nil {
+		GetLogger().Warn(wrapErr(err))
+	}
+	return true
+}
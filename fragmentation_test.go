@@ -0,0 +1,132 @@
+package nakama
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFragmentation(t *testing.T) {
+	server := newScriptedServer(t, nil)
+	var mu sync.Mutex
+	received := []*rtapi.MatchData{}
+	party := []*rtapi.PartyData{}
+	socket, _ := server.socket(func(event EventType, data *RspResult) {
+		if event != EventTypeMessage || data.Decoded == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if match := data.Decoded.GetMatchData(); match != nil {
+			received = append(received, match)
+		}
+		if p := data.Decoded.GetPartyData(); p != nil {
+			party = append(party, p)
+		}
+	})
+	socket.SetFragmentation(&FragmentOptions{ServerMaxMessageSize: 4096})
+	assert.NoError(t, socket.EnableExperimental(FeatureParties))
+	assert.NoError(t, socket.Connect())
+
+	snapshot := bytes.Repeat([]byte("0123456789abcdef"), 1500)
+	assert.NoError(t, socket.SendMatchState("m1", 7, snapshot, nil, false))
+	assert.NoError(t, socket.SendMatchState("m1", 8, []byte("small"), nil, false))
+	eventually(t, func() bool { return len(server.requestsOf("match_data_send")) == 11 }, "the fragments have not been sent")
+	sends := server.requestsOf("match_data_send")
+	for _, send := range sends[:10] {
+		buf, err := marshalEnvelope(send)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(*buf)+fragmentRelayReserve, 4096)
+		assert.Equal(t, int64(DefaultFragmentOpCode), send.GetMatchDataSend().GetOpCode())
+		assert.True(t, send.GetMatchDataSend().GetReliable(), "a lost fragment would lose the message")
+	}
+	assert.Equal(t, int64(8), sends[10].GetMatchDataSend().GetOpCode())
+
+	// the fragments relayed by the server are delivered once reassembled
+	presence := &rtapi.UserPresence{UserId: "u1", SessionId: "s1", Username: "alice"}
+	relay := func() {
+		for _, send := range sends {
+			data := send.GetMatchDataSend()
+			// with the proto names of the server, for the fast path
+			message, err := fragmentedEnvelope.Marshal(&rtapi.Envelope{Message: &rtapi.Envelope_MatchData{MatchData: &rtapi.MatchData{
+				MatchId: data.MatchId, OpCode: data.OpCode, Data: data.Data, Presence: presence, Reliable: data.Reliable,
+			}}})
+			assert.NoError(t, err)
+			server.conn(0).WriteRaw(message)
+		}
+	}
+	relay()
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 2
+	}, "the match data have not been reassembled")
+	assert.Equal(t, int64(7), received[0].GetOpCode())
+	assert.Equal(t, snapshot, received[0].GetData())
+	assert.Equal(t, "u1", received[0].GetPresence().GetUserId())
+	assert.Equal(t, int64(8), received[1].GetOpCode())
+
+	// the fast path gets the reassembled data too
+	frames := make(chan []byte, 2)
+	socket.SetOnMatchData(func(frame *MatchDataFrame) { frames <- frame.Retain() })
+	relay()
+	assert.Equal(t, snapshot, <-frames)
+	assert.Equal(t, []byte("small"), <-frames)
+
+	// the party data
+	assert.NoError(t, socket.SendPartyData("p1", 3, snapshot))
+	eventually(t, func() bool { return len(server.requestsOf("party_data_send")) == 10 }, "the party fragments have not been sent")
+	for _, send := range server.requestsOf("party_data_send") {
+		data := send.GetPartyDataSend()
+		push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_PartyData{PartyData: &rtapi.PartyData{
+			PartyId: data.PartyId, OpCode: data.OpCode, Data: data.Data, Presence: presence,
+		}}})
+	}
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(party) == 1
+	}, "the party data have not been reassembled")
+	assert.Equal(t, int64(3), party[0].GetOpCode())
+	assert.Equal(t, snapshot, party[0].GetData())
+}
+
+func TestFragmenter(t *testing.T) {
+	f := newFragmenter(FragmentOptions{})
+	start := time.Unix(1000, 0)
+	data := bytes.Repeat([]byte{1, 2, 3}, 100)
+	fragments, err := f.split(-5, data, 64)
+	assert.NoError(t, err)
+	assert.Len(t, fragments, 5)
+
+	// out of order, with a duplicate
+	for _, i := range []int{4, 0, 2, 1} {
+		_, _, complete, err := f.add("m", "s", fragments[i], start)
+		assert.NoError(t, err)
+		assert.False(t, complete)
+	}
+	_, _, _, err = f.add("m", "s", fragments[1], start)
+	assert.Error(t, err)
+	opCode, whole, complete, err := f.add("m", "s", fragments[3], start)
+	assert.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, int64(-5), opCode)
+	assert.Equal(t, data, whole)
+
+	// an incomplete message expires
+	fragments, _ = f.split(1, data, 64)
+	f.add("m", "s", fragments[0], start)
+	for _, fragment := range fragments[1:] {
+		_, _, complete, _ = f.add("m", "s", fragment, start.Add(DefaultFragmentTimeout))
+	}
+	assert.False(t, complete)
+
+	_, err = f.split(1, make([]byte, maxFragments*64+1), 64)
+	assert.ErrorIs(t, err, ErrFragmentTooBig)
+	_, _, _, err = f.add("m", "s", []byte{0x80}, start)
+	assert.Error(t, err)
+}
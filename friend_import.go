@@ -0,0 +1,121 @@
+package nakama
+
+import (
+	"context"
+
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// FriendImportChunkSize is the count of ids per GetUsers and AddFriends call of ImportPlatformFriends.
+const FriendImportChunkSize = 100
+
+// ErrFriendLookupUnsupported is returned by ImportPlatformFriends when the server can't look up the ids
+// of the platform and no FriendLookup is given.
+var ErrFriendLookupUnsupported = newError("no user lookup for the platform ids")
+
+// FriendPlatform is the platform of the contact ids imported by ImportPlatformFriends.
+type FriendPlatform int
+
+const (
+	FriendPlatformFacebook FriendPlatform = iota
+	FriendPlatformSteam
+)
+
+// idOf returns the id of the user on the platform.
+func (p FriendPlatform) idOf(user *api.User) string {
+	switch p {
+	case FriendPlatformFacebook:
+		return user.GetFacebookId()
+	case FriendPlatformSteam:
+		return user.GetSteamId()
+	}
+	return ""
+}
+
+// FriendLookup finds the users of a chunk of platform ids, e.g. through an rpc for the platforms
+// GetUsers can't look up.
+type FriendLookup func(session *Session, platformIds []string) ([]*api.User, error)
+
+// FriendImportResult is the result of ImportPlatformFriends.
+type FriendImportResult struct {
+	Added     []*api.User // users the friend requests were sent to
+	Unmatched []string    // platform ids without a user
+	Failed    []*api.User // users whose AddFriends call has failed
+	Errors    []error     // errors of the failed AddFriends calls
+}
+
+// facebookLookup looks up the users by facebook ids with GetUsers.
+func (c *Client) facebookLookup(session *Session, platformIds []string) ([]*api.User, error) {
+	users, err := c.FetchUsers(session, nil, nil, platformIds)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return users.GetUsers(), nil
+}
+
+// ImportPlatformFriends matches the contact ids of a platform to the users and sends them friend requests,
+// for the "find your friends" screens. The ids are looked up and added by chunks of FriendImportChunkSize.
+// lookup defaults to GetUsers for Facebook, the other platforms need one.
+// A failed lookup stops the import and returns the error with the users already added,
+// the failed AddFriends calls are reported in the result and the import goes on.
+func (c *Client) ImportPlatformFriends(ctx context.Context, session *Session, platform FriendPlatform, platformIds []string, lookup FriendLookup) (*FriendImportResult, error) {
+	if lookup == nil {
+		if platform != FriendPlatformFacebook {
+			return nil, ErrFriendLookupUnsupported.With(platform)
+		}
+		lookup = c.facebookLookup
+	}
+
+	ids := make([]string, 0, len(platformIds))
+	seen := make(map[string]bool, len(platformIds))
+	for _, id := range platformIds {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	result := &FriendImportResult{}
+	matched := map[string]bool{}
+	for start := 0; start < len(ids); start += FriendImportChunkSize {
+		if err := ctx.Err(); err != nil {
+			return result, wrapErr(err)
+		}
+		chunk := ids[start:min(start+FriendImportChunkSize, len(ids))]
+		users, err := lookup(session, chunk)
+		if err != nil {
+			return result, wrapErr(err, platform)
+		}
+
+		toAdd := make([]*api.User, 0, len(users))
+		userIds := make([]string, 0, len(users))
+		for _, user := range users {
+			id := platform.idOf(user)
+			if !seen[id] || matched[id] {
+				continue
+			}
+			matched[id] = true
+			if user.GetId() == session.UserID {
+				continue
+			}
+			toAdd = append(toAdd, user)
+			userIds = append(userIds, user.GetId())
+		}
+		if len(userIds) == 0 {
+			continue
+		}
+		if err := c.AddFriends(session, userIds, nil); err != nil {
+			result.Failed = append(result.Failed, toAdd...)
+			result.Errors = append(result.Errors, wrapErr(err, userIds))
+			continue
+		}
+		result.Added = append(result.Added, toAdd...)
+	}
+
+	for _, id := range ids {
+		if !matched[id] {
+			result.Unmatched = append(result.Unmatched, id)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,108 @@
+package nakama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportPlatformFriends(t *testing.T) {
+	var adds atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/user":
+			users := []*api.User{}
+			for _, id := range r.URL.Query()["facebook_ids"] {
+				n, _ := strconv.Atoi(id)
+				if n%2 == 0 {
+					users = append(users, &api.User{Id: "user" + id, FacebookId: id})
+				}
+			}
+			if len(users) > 0 && users[0].FacebookId == "0" {
+				// the current user has a contact list with itself
+				users[0].Id = "user"
+			}
+			data, _ := json.Marshal(map[string]any{"users": users})
+			w.Write(data)
+		case "/v2/friend":
+			assert.LessOrEqual(t, len(r.URL.Query()["ids"]), FriendImportChunkSize)
+			if adds.Add(1) == 2 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+
+	ids := []string{"0", "0"}
+	for i := 1; i < 150; i++ {
+		ids = append(ids, strconv.Itoa(i))
+	}
+	result, err := client.ImportPlatformFriends(context.Background(), session, FriendPlatformFacebook, ids, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), adds.Load())
+	assert.Len(t, result.Added, 49, "the current user isn't added")
+	assert.Len(t, result.Failed, 25)
+	assert.Len(t, result.Errors, 1)
+	assert.Len(t, result.Unmatched, 75)
+
+	_, err = client.ImportPlatformFriends(context.Background(), session, FriendPlatformSteam, ids, nil)
+	assert.True(t, errors.Is(err, ErrFriendLookupUnsupported))
+}
+
+func TestLinkFacebookSync(t *testing.T) {
+	var linked atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/account/link/facebook":
+			if r.URL.Query().Get("sync") == "true" {
+				linked.Store(true)
+			}
+			w.Write([]byte(`{}`))
+		case "/v2/friend":
+			assert.Equal(t, "0", r.URL.Query().Get("state"))
+			if linked.Load() {
+				w.Write([]byte(`{"friends":[{"user":{"id":"a"}},{"user":{"id":"fb","facebook_id":"1"}}]}`))
+				return
+			}
+			w.Write([]byte(`{"friends":[{"user":{"id":"a"}}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+
+	assert.NoError(t, client.LinkFacebook(session, &api.AccountFacebook{Token: "fb-token"}))
+	assert.False(t, linked.Load())
+
+	assert.NoError(t, client.LinkFacebookSync(session, &api.AccountFacebook{Token: "fb-token"}))
+	assert.True(t, linked.Load())
+
+	summary, err := client.FacebookImportSummary(session)
+	assert.NoError(t, err)
+	assert.Len(t, summary.Imported, 1)
+	assert.Equal(t, "fb", summary.Imported[0].Id)
+}
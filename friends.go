@@ -0,0 +1,52 @@
+package nakama
+
+import (
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// The friend states used by the state filter of ListFriends.
+const (
+	FriendStateMutual         = 0 // Both users are friends.
+	FriendStateInviteSent     = 1 // The current user has sent an invite pending acceptance.
+	FriendStateInviteReceived = 2 // The current user has received an invite pending acceptance.
+	FriendStateBlocked        = 3 // The current user has blocked the other user.
+)
+
+// FriendOfFriend is a user friend of a friend of the current user, Referrer is the username of the friend.
+type FriendOfFriend = api.FriendsOfFriendsList_FriendOfFriend
+
+// ListBlockedUsers lists the users blocked by the current user.
+func (c *Client) ListBlockedUsers(session *Session, limit *int, cursor *string) (*api.FriendList, error) {
+	state := FriendStateBlocked
+	return c.ListFriends(session, &state, limit, cursor)
+}
+
+// UnblockFriends unblocks users by ID or username, the blocked users are removed from the friend list.
+func (c *Client) UnblockFriends(session *Session, ids []string, usernames []string) error {
+	if len(ids) == 0 && len(usernames) == 0 {
+		return newError("'ids' or 'usernames' is required but both are empty")
+	}
+	return c.DeleteFriends(session, ids, usernames)
+}
+
+// FacebookImportSummary is the result of the friend import of LinkFacebookSync.
+// The server returns none, Imported are the mutual friends with a Facebook ID,
+// so the friends of a previous import or added by another way are listed too.
+type FacebookImportSummary struct {
+	Imported []*api.User
+}
+
+// FacebookImportSummary lists the mutual friends with a Facebook ID, it's a call of its own after LinkFacebookSync.
+func (c *Client) FacebookImportSummary(session *Session) (*FacebookImportSummary, error) {
+	summary := &FacebookImportSummary{}
+	state := FriendStateMutual
+	for friend, err := range c.Friends(c.Context(), session, &state) {
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+		if user := friend.GetUser(); user.GetFacebookId() != "" {
+			summary.Imported = append(summary.Imported, user)
+		}
+	}
+	return summary, nil
+}
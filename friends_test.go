@@ -0,0 +1,41 @@
+package nakama
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockedUsers(t *testing.T) {
+	requests := []*http.Request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"friends":[{"user":{"id":"u1"},"state":3}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+
+	blocked, err := client.ListBlockedUsers(session, nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, blocked.Friends, 1)
+	assert.Equal(t, "/v2/friend", requests[0].URL.Path)
+	assert.Equal(t, "3", requests[0].URL.Query().Get("state"))
+
+	assert.NoError(t, client.UnblockFriends(session, []string{"u1"}, []string{"troll"}))
+	assert.Equal(t, http.MethodDelete, requests[1].Method)
+	assert.Equal(t, []string{"u1"}, requests[1].URL.Query()["ids"])
+	assert.Equal(t, []string{"troll"}, requests[1].URL.Query()["usernames"])
+
+	assert.Error(t, client.UnblockFriends(session, nil, nil))
+	assert.Len(t, requests, 2, "nothing to unblock isn't sent")
+}
@@ -4,20 +4,20 @@ go 1.25.0
 
 require (
 	github.com/coder/websocket v1.8.12
-	github.com/gwaylib/errors v0.0.4
-	github.com/gwaylib/eweb v1.0.6
 	github.com/gwaylib/log v0.0.6
 	github.com/heroiclabs/nakama-common v1.42.1
-	github.com/panjf2000/ants/v2 v2.11.3
+	go.uber.org/goleak v1.3.0
+	golang.org/x/sync v0.11.0
+	golang.org/x/text v0.22.0
+	google.golang.org/protobuf v1.36.10
 )
 
 require (
+	github.com/kr/text v0.2.0 // indirect
 	github.com/labstack/gommon v0.4.2 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	golang.org/x/sync v0.11.0 // indirect
 	golang.org/x/sys v0.18.0 // indirect
-	google.golang.org/protobuf v1.36.10 // indirect
 )
 
 require (
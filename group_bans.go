@@ -0,0 +1,109 @@
+package nakama
+
+import (
+	"context"
+	"errors"
+	"iter"
+
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// GroupStateBanned is the state of the banned users of a group, the group users of ListGroupBans have it.
+const GroupStateBanned = 4
+
+// The default ids of the rpcs of the group bans, see WithGroupBanRpcs.
+const (
+	DefaultGroupBansListRpc = "group_bans_list"
+	DefaultGroupBansLiftRpc = "group_bans_lift"
+)
+
+// ErrGroupBansUnsupported is returned by the group ban calls when the server doesn't register their rpcs.
+var ErrGroupBansUnsupported = newError("group bans unsupported by the server")
+
+// GroupBanRpcs are the ids of the rpcs listing and lifting the bans of a group. The public API of Nakama can't,
+// the server registers them in its runtime with this contract:
+//
+//   - List gets {"group_id", "limit", "cursor"} and returns the banned users as an api.GroupUserList in protojson,
+//     e.g. the result of GroupUsersList with the state 4 of the Go runtime.
+//   - Lift gets {"group_id", "user_ids"} and removes the bans, the users can join the group again.
+//
+// Both check the caller is an admin or a superadmin of the group, and fail with PERMISSION_DENIED otherwise.
+type GroupBanRpcs struct {
+	List string
+	Lift string
+}
+
+// WithGroupBanRpcs sets the ids of the rpcs of the group bans when they're not the default ones.
+func WithGroupBanRpcs(list, lift string) ClientOption {
+	return func(opts *ClientOptions) error {
+		if list == "" || lift == "" {
+			return newError("invalid group ban rpcs").With(list, lift)
+		}
+		opts.GroupBanRpcs = &GroupBanRpcs{List: list, Lift: lift}
+		return nil
+	}
+}
+
+// groupBansRequest is the payload of the rpcs of the group bans.
+type groupBansRequest struct {
+	GroupId string   `json:"group_id"`
+	Limit   int      `json:"limit,omitempty"`
+	Cursor  string   `json:"cursor,omitempty"`
+	UserIds []string `json:"user_ids,omitempty"`
+}
+
+// groupBansRpc calls a rpc of the group bans, the servers without it get ErrGroupBansUnsupported.
+func (c *Client) groupBansRpc(session *Session, id string, request *groupBansRequest) (*api.Rpc, error) {
+	rpc, err := c.capabilityRpc(session, CapabilityGroupBans, id, request)
+	if err != nil && !errors.Is(err, ErrGroupBansUnsupported) {
+		return nil, groupError(err, request.GroupId)
+	}
+	return rpc, err
+}
+
+// ListGroupBans lists the users banned from the group with the list rpc of GroupBanRpcs, limit and cursor are optional.
+func (c *Client) ListGroupBans(session *Session, groupId string, limit *int, cursor *string) (*api.GroupUserList, error) {
+	request := &groupBansRequest{GroupId: groupId}
+	if limit != nil {
+		request.Limit = *limit
+	}
+	if cursor != nil {
+		request.Cursor = *cursor
+	}
+	rpc, err := c.groupBansRpc(session, c.groupBanRpcs.List, request)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	list := &api.GroupUserList{}
+	if rpc.GetPayload() == "" {
+		return list, nil
+	}
+	if err := responseUnmarshal.Unmarshal([]byte(rpc.GetPayload()), list); err != nil {
+		return nil, wrapErr(err, groupId, rpc.GetPayload())
+	}
+	return list, nil
+}
+
+// LiftGroupBans lifts the bans of the users with the lift rpc of GroupBanRpcs, they can join the group again.
+func (c *Client) LiftGroupBans(session *Session, groupId string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := c.groupBansRpc(session, c.groupBanRpcs.Lift, &groupBansRequest{GroupId: groupId, UserIds: ids}); err != nil {
+		return wrapErr(err)
+	}
+	return nil
+}
+
+// GroupBans iterates over the users banned from the group.
+func (c *Client) GroupBans(ctx context.Context, session *Session, groupId string) iter.Seq2[*api.GroupUserList_GroupUser, error] {
+	c = c.WithContext(ctx)
+	limit := DefaultPageSize
+	return paginate(ctx, func(cursor string) ([]*api.GroupUserList_GroupUser, string, error) {
+		list, err := c.ListGroupBans(session, groupId, &limit, optionalString(cursor))
+		if err != nil {
+			return nil, "", err
+		}
+		return list.GetGroupUsers(), list.GetCursor(), nil
+	})
+}
@@ -0,0 +1,83 @@
+package nakama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestGroupBans(t *testing.T) {
+	var lifted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		payload := ""
+		json.Unmarshal(body, &payload)
+		request := groupBansRequest{}
+		assert.NoError(t, json.Unmarshal([]byte(payload), &request))
+		assert.Equal(t, "g1", request.GroupId)
+
+		rpc := &api.Rpc{}
+		switch r.URL.Path {
+		case "/v2/rpc/bans":
+			list := &api.GroupUserList{GroupUsers: []*api.GroupUserList_GroupUser{
+				{User: &api.User{Id: "u1", Username: "troll"}, State: wrapperspb.Int32(GroupStateBanned)},
+			}, Cursor: "next"}
+			if request.Cursor == "next" {
+				list = &api.GroupUserList{GroupUsers: []*api.GroupUserList_GroupUser{
+					{User: &api.User{Id: "u2", Username: "spammer"}, State: wrapperspb.Int32(GroupStateBanned)},
+				}}
+			}
+			data, _ := protojson.Marshal(list)
+			rpc.Payload = string(data)
+		case "/v2/rpc/unban":
+			lifted = request.UserIds
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		data, _ := protojson.Marshal(rpc)
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL), WithGroupBanRpcs("bans", "unban"))
+	assert.NoError(t, err)
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+
+	banned := []string{}
+	for user, err := range client.GroupBans(context.Background(), session, "g1") {
+		assert.NoError(t, err)
+		assert.Equal(t, int32(GroupStateBanned), user.GetState().GetValue())
+		banned = append(banned, user.GetUser().GetUsername())
+	}
+	assert.Equal(t, []string{"troll", "spammer"}, banned)
+
+	assert.NoError(t, client.LiftGroupBans(session, "g1", []string{"u1"}))
+	assert.Equal(t, []string{"u1"}, lifted)
+
+	// a server without the rpcs
+	var calls atomic.Int32
+	older := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer older.Close()
+	client, err = NewClientWithOptions(WithURL(older.URL))
+	assert.NoError(t, err)
+	_, err = client.ListGroupBans(session, "g1", nil, nil)
+	assert.True(t, errors.Is(err, ErrGroupBansUnsupported))
+	assert.False(t, client.Supports(CapabilityGroupBans))
+	assert.True(t, errors.Is(client.LiftGroupBans(session, "g1", []string{"u1"}), ErrGroupBansUnsupported))
+	assert.Equal(t, int32(1), calls.Load())
+}
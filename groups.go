@@ -0,0 +1,180 @@
+package nakama
+
+import (
+	"net/http"
+
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// The group user states used by the state filter of ListGroupUsers.
+const (
+	GroupStateSuperadmin  = 0
+	GroupStateAdmin       = 1
+	GroupStateMember      = 2
+	GroupStateJoinRequest = 3
+)
+
+var (
+	// ErrGroupPermissionDenied is returned when the group doesn't exist or the current user isn't an admin/superadmin of it.
+	ErrGroupPermissionDenied = newError("group not found or permission denied")
+	// ErrGroupInvalidRequest is returned when the server rejects the users of the request, e.g. demoting the last superadmin.
+	ErrGroupInvalidRequest = newError("invalid group request")
+)
+
+// GroupQuery holds the filters of ListGroups besides the name, a nil filter isn't sent.
+// The server can't combine the name with the other filters.
+type GroupQuery struct {
+	LangTag *string
+	Members *int // groups with at most this count of members
+	Open    *bool
+}
+
+// GroupQueryOption sets a filter of ListGroups.
+type GroupQueryOption func(query *GroupQuery)
+
+// WithGroupLangTag lists the groups of the language tag.
+func WithGroupLangTag(langTag string) GroupQueryOption {
+	return func(query *GroupQuery) { query.LangTag = &langTag }
+}
+
+// WithGroupMembers lists the groups having at most members members.
+func WithGroupMembers(members int) GroupQueryOption {
+	return func(query *GroupQuery) { query.Members = &members }
+}
+
+// WithGroupOpen lists the open groups, or the closed groups when open is false.
+func WithGroupOpen(open bool) GroupQueryOption {
+	return func(query *GroupQuery) { query.Open = &open }
+}
+
+// GroupInfo is a group with the size fields of Nakama 3.x typed, they're zero when the server doesn't send them.
+type GroupInfo struct {
+	*api.Group
+}
+
+// Members returns the count of members of the group, the edge count.
+func (g GroupInfo) Members() int {
+	return int(g.GetEdgeCount())
+}
+
+// MaxMembers returns the max count of members of the group, 0 when unknown.
+func (g GroupInfo) MaxMembers() int {
+	return int(g.GetMaxCount())
+}
+
+// IsOpen reports whether the users can join the group without a join request.
+func (g GroupInfo) IsOpen() bool {
+	return g.GetOpen().GetValue()
+}
+
+// Vacancies returns the count of members the group can still take, -1 when its max count is unknown.
+func (g GroupInfo) Vacancies() int {
+	if g.MaxMembers() <= 0 {
+		return -1
+	}
+	return max(g.MaxMembers()-g.Members(), 0)
+}
+
+// Full reports whether the group has reached its max count, a group of unknown max count isn't full.
+func (g GroupInfo) Full() bool {
+	return g.Vacancies() == 0
+}
+
+// groupError maps the http errors of the group management calls to the typed errors.
+func groupError(err error, groupId string) error {
+	switch httpStatusOf(err) {
+	case http.StatusForbidden, http.StatusNotFound:
+		return ErrGroupPermissionDenied.With(groupId, err)
+	case http.StatusBadRequest:
+		return ErrGroupInvalidRequest.With(groupId, err)
+	}
+	return wrapErr(err, groupId)
+}
+
+// GroupUserChange is the state of a user before and after a promotion or a demotion.
+type GroupUserChange struct {
+	UserId   string
+	Username string
+	OldState int32
+	NewState int32
+}
+
+// GroupUsersResult is the result of PromoteGroupUsers and DemoteGroupUsers.
+type GroupUsersResult struct {
+	GroupId   string
+	Changed   []*GroupUserChange
+	Unchanged []string // requested user ids whose state hasn't changed, or not in the group
+}
+
+// promotedGroupState returns the state of a user promoted to the next role up, a superadmin stays superadmin.
+func promotedGroupState(state int32) int32 {
+	return max(state-1, GroupStateSuperadmin)
+}
+
+// demotedGroupState returns the state of a user demoted to the next role down, a member stays member
+// and a join request isn't changed.
+func demotedGroupState(state int32) int32 {
+	if state >= GroupStateMember {
+		return state
+	}
+	return state + 1
+}
+
+// groupUserStates lists the users of the group until all the users of ids are found, indexed by user id.
+func (c *Client) groupUserStates(session *Session, groupId string, ids []string) (map[string]*api.GroupUserList_GroupUser, error) {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	states := map[string]*api.GroupUserList_GroupUser{}
+	limit := 100
+	var cursor *string
+	for {
+		list, err := c.apiFor(session).ListGroupUsers(&session.Token, &groupId, &limit, nil, cursor, make(map[string]string))
+		if err != nil {
+			return nil, groupError(err, groupId)
+		}
+		for _, user := range list.GroupUsers {
+			if wanted[user.GetUser().GetId()] {
+				states[user.GetUser().GetId()] = user
+			}
+		}
+		if list.Cursor == "" || len(states) == len(wanted) {
+			return states, nil
+		}
+		cursor = &list.Cursor
+	}
+}
+
+// changeGroupUsers runs a promotion or a demotion, the states of the users are listed before it
+// and changed by next once the server has accepted the call, which fails for all the users or none.
+func (c *Client) changeGroupUsers(session *Session, groupId string, ids []string, next func(state int32) int32, call func() error) (*GroupUsersResult, error) {
+	if err := c.refreshSession(session); err != nil {
+		return nil, wrapErr(err)
+	}
+
+	states, err := c.groupUserStates(session, groupId, ids)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	if err := call(); err != nil {
+		return nil, groupError(err, groupId)
+	}
+
+	result := &GroupUsersResult{GroupId: groupId}
+	for _, id := range ids {
+		user, ok := states[id]
+		if !ok {
+			result.Unchanged = append(result.Unchanged, id)
+			continue
+		}
+		change := &GroupUserChange{UserId: id, Username: user.GetUser().GetUsername(), OldState: user.GetState().GetValue()}
+		change.NewState = next(change.OldState)
+		if change.OldState == change.NewState {
+			result.Unchanged = append(result.Unchanged, id)
+			continue
+		}
+		result.Changed = append(result.Changed, change)
+	}
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package nakama
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListUserGroupsQuery(t *testing.T) {
+	queries := make(chan url.Values, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/user/user%201/group", r.URL.EscapedPath())
+		queries <- r.URL.Query()
+		w.Write([]byte(`{"user_groups":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+
+	_, err = client.ListUserGroups(session, "user 1", nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, <-queries, "the unset parameters should be omitted")
+
+	state, limit, cursor := GroupStateMember, 20, "next"
+	_, err = client.ListUserGroups(session, "user 1", &state, &limit, &cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"cursor": {"next"}, "limit": {"20"}, "state": {"2"}}, <-queries)
+
+	// the zero values are filters too
+	state, limit, cursor = GroupStateSuperadmin, 0, ""
+	_, err = client.ListUserGroups(session, "user 1", &state, &limit, &cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"cursor": {""}, "limit": {"0"}, "state": {"0"}}, <-queries)
+}
+
+func TestChangeGroupUsers(t *testing.T) {
+	var lists, promotes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/group/g1/user":
+			// two pages, the second one isn't needed when the users are on the first one
+			if lists.Add(1); r.URL.Query().Get("cursor") == "" {
+				w.Write([]byte(`{"group_users":[{"user":{"id":"a","username":"alice"},"state":2},{"user":{"id":"b"},"state":0}],"cursor":"next"}`))
+				return
+			}
+			w.Write([]byte(`{"group_users":[{"user":{"id":"c"},"state":1}]}`))
+		case "/v2/group/g1/promote", "/v2/group/g1/demote":
+			promotes.Add(1)
+			w.Write([]byte(`{}`))
+		case "/v2/group/closed/promote":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"code":7,"message":"not an admin"}`))
+		default:
+			w.Write([]byte(`{"group_users":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	assert.NoError(t, err)
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+
+	result, err := client.PromoteGroupUsers(session, "g1", []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), lists.Load())
+	assert.Equal(t, []*GroupUserChange{{UserId: "a", Username: "alice", OldState: GroupStateMember, NewState: GroupStateAdmin}}, result.Changed)
+	assert.Equal(t, []string{"b"}, result.Unchanged, "a superadmin can't be promoted")
+
+	groupId := "g1"
+	result, err = client.DemoteGroupUsers(session, &groupId, []string{"c", "missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), lists.Load())
+	assert.Equal(t, []*GroupUserChange{{UserId: "c", OldState: GroupStateAdmin, NewState: GroupStateMember}}, result.Changed)
+	assert.Equal(t, []string{"missing"}, result.Unchanged)
+	assert.Equal(t, int32(2), promotes.Load())
+
+	_, err = client.PromoteGroupUsers(session, "closed", []string{"a"})
+	assert.True(t, errors.Is(err, ErrGroupPermissionDenied))
+	_, err = client.DemoteGroupUsers(session, nil, []string{"a"})
+	assert.Error(t, err)
+}
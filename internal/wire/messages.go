@@ -0,0 +1,170 @@
+// Package wire holds the representative messages of the wire-format compatibility tests.
+package wire
+
+import (
+	"runtime/debug"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// CommonModule is the module path of the proto definitions.
+const CommonModule = "github.com/heroiclabs/nakama-common"
+
+// CommonVersion returns the version of nakama-common linked in the binary, "unknown" if it can't be read.
+func CommonVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != CommonModule {
+			continue
+		}
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			return dep.Replace.Version
+		}
+		return dep.Version
+	}
+	return "unknown"
+}
+
+var at = &timestamppb.Timestamp{Seconds: 1700000000}
+
+// Messages returns the messages round-tripped by the tests keyed by their fixture name.
+// Keep the names stable, the fixtures of the older versions are looked up by them.
+func Messages() map[string]proto.Message {
+	presence := &rtapi.UserPresence{
+		UserId:    "8f4d52c7-bcb5-4a2b-bb25-1b5d6c6f1e54",
+		SessionId: "a1b2c3d4-0000-4000-8000-000000000001",
+		Username:  "player1",
+		Status:    wrapperspb.String("online"),
+	}
+	return map[string]proto.Message{
+		"api_session": &api.Session{
+			Created:      true,
+			Token:        "eyJhbGciOiJIUzI1NiJ9.e30.sig",
+			RefreshToken: "eyJhbGciOiJIUzI1NiJ9.e30.refresh",
+		},
+		"api_account": &api.Account{
+			User: &api.User{
+				Id:          presence.UserId,
+				Username:    presence.Username,
+				DisplayName: "Player One",
+				Metadata:    `{"level":12}`,
+				EdgeCount:   3,
+				CreateTime:  at,
+				UpdateTime:  at,
+			},
+			Wallet:  `{"coins":9007199254740993}`,
+			Devices: []*api.AccountDevice{{Id: "device-1", Vars: map[string]string{"os": "linux"}}},
+		},
+		"api_leaderboard_record_list": &api.LeaderboardRecordList{
+			Records: []*api.LeaderboardRecord{{
+				LeaderboardId: "weekly",
+				OwnerId:       presence.UserId,
+				Username:      wrapperspb.String(presence.Username),
+				Score:         9007199254740993,
+				Subscore:      -1,
+				NumScore:      2,
+				Metadata:      "{}",
+				CreateTime:    at,
+				UpdateTime:    at,
+				ExpiryTime:    at,
+				Rank:          1,
+				MaxNumScore:   10,
+			}},
+			NextCursor: "next",
+			RankCount:  42,
+		},
+		"api_channel_message_list": &api.ChannelMessageList{
+			Messages: []*api.ChannelMessage{{
+				ChannelId:  "2...room",
+				MessageId:  "b8b1b7c6-6c46-4ef6-8d0b-2b0c0b2a1f00",
+				Code:       wrapperspb.Int32(0),
+				SenderId:   presence.UserId,
+				Username:   presence.Username,
+				Content:    `{"text":"hello"}`,
+				CreateTime: at,
+				UpdateTime: at,
+				Persistent: wrapperspb.Bool(true),
+				RoomName:   "room",
+			}},
+			CacheableCursor: "cacheable",
+		},
+		"api_storage_objects": &api.StorageObjects{
+			Objects: []*api.StorageObject{{
+				Collection:      "saves",
+				Key:             "slot1",
+				UserId:          presence.UserId,
+				Value:           `{"hp":10}`,
+				Version:         "v1",
+				PermissionRead:  1,
+				PermissionWrite: 1,
+				CreateTime:      at,
+				UpdateTime:      at,
+			}},
+		},
+		"api_rpc": &api.Rpc{Id: "echo", Payload: `{"ok":true}`},
+		"rtapi_channel_message": &rtapi.Envelope{
+			Cid: "1",
+			Message: &rtapi.Envelope_ChannelMessage{ChannelMessage: &api.ChannelMessage{
+				ChannelId:  "2...room",
+				MessageId:  "m1",
+				SenderId:   presence.UserId,
+				Content:    `{"text":"hi"}`,
+				Persistent: wrapperspb.Bool(false),
+			}},
+		},
+		"rtapi_match_data": &rtapi.Envelope{
+			Message: &rtapi.Envelope_MatchData{MatchData: &rtapi.MatchData{
+				MatchId:  "match.node",
+				Presence: presence,
+				OpCode:   7,
+				Data:     []byte{0x00, 0x01, 0xfe, 0xff},
+				Reliable: true,
+			}},
+		},
+		"rtapi_matchmaker_matched": &rtapi.Envelope{
+			Message: &rtapi.Envelope_MatchmakerMatched{MatchmakerMatched: &rtapi.MatchmakerMatched{
+				Ticket: "ticket",
+				Id:     &rtapi.MatchmakerMatched_Token{Token: "token"},
+				Users: []*rtapi.MatchmakerMatched_MatchmakerUser{{
+					Presence:          presence,
+					StringProperties:  map[string]string{"region": "eu"},
+					NumericProperties: map[string]float64{"skill": 1500},
+				}},
+				Self: &rtapi.MatchmakerMatched_MatchmakerUser{Presence: presence},
+			}},
+		},
+		"rtapi_notifications": &rtapi.Envelope{
+			Message: &rtapi.Envelope_Notifications{Notifications: &rtapi.Notifications{
+				Notifications: []*api.Notification{{
+					Id:         "n1",
+					Subject:    "welcome",
+					Content:    `{"gift":1}`,
+					Code:       100,
+					SenderId:   presence.UserId,
+					CreateTime: at,
+					Persistent: true,
+				}},
+			}},
+		},
+		"rtapi_status_presence_event": &rtapi.Envelope{
+			Message: &rtapi.Envelope_StatusPresenceEvent{StatusPresenceEvent: &rtapi.StatusPresenceEvent{
+				Joins: []*rtapi.UserPresence{presence},
+			}},
+		},
+		"rtapi_error": &rtapi.Envelope{
+			Cid: "2",
+			Message: &rtapi.Envelope_Error{Error: &rtapi.Error{
+				Code:    3,
+				Message: "Match not found",
+				Context: map[string]string{"match_id": "match.node"},
+			}},
+		},
+	}
+}
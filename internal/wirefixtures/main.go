@@ -0,0 +1,49 @@
+// Command wirefixtures writes the wire-format fixtures of the linked nakama-common version,
+// it's run by go generate from the root package:
+//
+//	go generate -run wirefixtures .
+//
+// The fixtures of a version are written once, keep them when bumping the dependency
+// so the new version is checked against the messages of the older ones.
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/NorthNorthGames/nakama-go/internal/wire"
+	"github.com/gwaylib/log"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func main() {
+	out := flag.String("out", "testdata/wire", "the directory of the fixtures")
+	flag.Parse()
+
+	version := wire.CommonVersion()
+	dir := filepath.Join(*out, version)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Fatal(err)
+	}
+
+	messages := wire.Messages()
+	names := make([]string, 0, len(messages))
+	for name := range messages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	marshaler := protojson.MarshalOptions{Multiline: true, Indent: "  "}
+	for _, name := range names {
+		data, err := marshaler.Marshal(messages[name])
+		if err != nil {
+			log.Fatal(name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".json"), append(data, '\n'), 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+	log.Infof("%d fixtures written to %s", len(names), dir)
+}
@@ -0,0 +1,143 @@
+package nakama
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"sync"
+
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// RankedRecord is a leaderboard record with its changes since the previous fetch.
+type RankedRecord struct {
+	*api.LeaderboardRecord
+	RankDelta  int64 // positive when the owner has moved up, e.g. 5 for "you moved up 5 places"
+	ScoreDelta int64
+	IsNew      bool // the owner wasn't in the previous fetch, the deltas are zero
+}
+
+// The default bounds of a LeaderboardCache.
+const (
+	DefaultLeaderboardCacheSize   = 32   // the leaderboards kept
+	DefaultLeaderboardCacheOwners = 1000 // the owners kept per leaderboard
+)
+
+// LeaderboardCache keeps the last fetched page of each leaderboard to compute the rank and score deltas.
+// The least recently updated leaderboards and owners are evicted past MaxLeaderboards and MaxOwners, 0 keeps them all.
+type LeaderboardCache struct {
+	MaxLeaderboards int
+	MaxOwners       int
+
+	mu           sync.Mutex
+	updates      uint64 // the count of the updates, orders the entries for the eviction
+	leaderboards map[string]*cachedLeaderboard
+}
+
+// cachedLeaderboard is the state of a leaderboard in a LeaderboardCache.
+type cachedLeaderboard struct {
+	records map[string]*cachedRecord // owner id:record
+	list    *api.LeaderboardRecordList
+	update  uint64
+}
+
+// cachedRecord is the last record fetched of an owner.
+type cachedRecord struct {
+	record *api.LeaderboardRecord
+	update uint64
+}
+
+// NewLeaderboardCache creates an empty LeaderboardCache with the default bounds.
+func NewLeaderboardCache() *LeaderboardCache {
+	return &LeaderboardCache{
+		MaxLeaderboards: DefaultLeaderboardCacheSize,
+		MaxOwners:       DefaultLeaderboardCacheOwners,
+		leaderboards:    map[string]*cachedLeaderboard{},
+	}
+}
+
+// Update stores the page and returns its records and owner records compared with the previous fetch.
+func (lc *LeaderboardCache) Update(leaderboardId string, list *api.LeaderboardRecordList) []*RankedRecord {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	lc.updates++
+	board, ok := lc.leaderboards[leaderboardId]
+	if !ok {
+		board = &cachedLeaderboard{records: map[string]*cachedRecord{}}
+		lc.leaderboards[leaderboardId] = board
+	}
+	seen := map[string]bool{}
+	ranked := []*RankedRecord{}
+	for _, records := range [][]*api.LeaderboardRecord{list.GetRecords(), list.GetOwnerRecords()} {
+		for _, record := range records {
+			if seen[record.OwnerId] {
+				continue
+			}
+			seen[record.OwnerId] = true
+
+			r := &RankedRecord{LeaderboardRecord: record, IsNew: true}
+			if old, ok := board.records[record.OwnerId]; ok {
+				r.IsNew = false
+				r.RankDelta = old.record.Rank - record.Rank
+				r.ScoreDelta = record.Score - old.record.Score
+			}
+			ranked = append(ranked, r)
+			// the owners of the previous pages unseen in this one are kept
+			board.records[record.OwnerId] = &cachedRecord{record: record, update: lc.updates}
+		}
+	}
+	board.list = list
+	board.update = lc.updates
+	lc.evictLocked(board)
+	return ranked
+}
+
+// evictLocked drops the oldest owners of the board and the oldest leaderboards past the bounds of the cache.
+func (lc *LeaderboardCache) evictLocked(board *cachedLeaderboard) {
+	if lc.MaxOwners > 0 && len(board.records) > lc.MaxOwners {
+		owners := slices.Collect(maps.Keys(board.records))
+		slices.SortFunc(owners, func(a, b string) int {
+			return cmp.Compare(board.records[a].update, board.records[b].update)
+		})
+		for _, ownerId := range owners[:len(owners)-lc.MaxOwners] {
+			delete(board.records, ownerId)
+		}
+	}
+	for lc.MaxLeaderboards > 0 && len(lc.leaderboards) > lc.MaxLeaderboards {
+		oldest := ""
+		for id, b := range lc.leaderboards {
+			if oldest == "" || b.update < lc.leaderboards[oldest].update {
+				oldest = id
+			}
+		}
+		delete(lc.leaderboards, oldest)
+	}
+}
+
+// Last returns the last page fetched for the leaderboard, nil if none.
+func (lc *LeaderboardCache) Last(leaderboardId string) *api.LeaderboardRecordList {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if board, ok := lc.leaderboards[leaderboardId]; ok {
+		return board.list
+	}
+	return nil
+}
+
+// Clear drops the cached records of the leaderboard.
+func (lc *LeaderboardCache) Clear(leaderboardId string) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	delete(lc.leaderboards, leaderboardId)
+}
+
+// ListLeaderboardRecordsCached lists the leaderboard records like ListLeaderboardRecords
+// and returns them with their deltas since the previous call with the same cache.
+func (c *Client) ListLeaderboardRecordsCached(session *Session, cache *LeaderboardCache, leaderboardId string, ownerIds []string, limit *int, cursor *string, expiry *string) ([]*RankedRecord, *api.LeaderboardRecordList, error) {
+	list, err := c.ListLeaderboardRecords(session, leaderboardId, ownerIds, limit, cursor, expiry)
+	if err != nil {
+		return nil, nil, wrapErr(err, leaderboardId)
+	}
+	return cache.Update(leaderboardId, list), list, nil
+}
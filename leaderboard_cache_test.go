@@ -0,0 +1,96 @@
+package nakama
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// recordList returns a page of records of the owners, ranked in order with the given scores.
+func recordList(owners []string, scores []int64) *api.LeaderboardRecordList {
+	list := &api.LeaderboardRecordList{}
+	for i, owner := range owners {
+		list.Records = append(list.Records, &api.LeaderboardRecord{OwnerId: owner, Rank: int64(i + 1), Score: scores[i]})
+	}
+	return list
+}
+
+func TestLeaderboardCacheDeltas(t *testing.T) {
+	cache := NewLeaderboardCache()
+	ranked := cache.Update("lb", recordList([]string{"a", "b", "c"}, []int64{30, 20, 10}))
+	assert.Len(t, ranked, 3)
+	assert.True(t, ranked[0].IsNew)
+
+	// c moves up 2 places, a is also an owner record and is counted once
+	list := recordList([]string{"c", "a", "b"}, []int64{45, 30, 20})
+	list.OwnerRecords = []*api.LeaderboardRecord{list.Records[1]}
+	ranked = cache.Update("lb", list)
+	assert.Len(t, ranked, 3)
+	assert.Equal(t, "c", ranked[0].OwnerId)
+	assert.False(t, ranked[0].IsNew)
+	assert.Equal(t, int64(2), ranked[0].RankDelta)
+	assert.Equal(t, int64(35), ranked[0].ScoreDelta)
+	assert.Equal(t, int64(-1), ranked[1].RankDelta)
+	assert.Same(t, list, cache.Last("lb"))
+
+	// the owners of a previous page are kept
+	cache.Update("lb", recordList([]string{"d"}, []int64{5}))
+	ranked = cache.Update("lb", recordList([]string{"b"}, []int64{25}))
+	assert.False(t, ranked[0].IsNew)
+	assert.Equal(t, int64(5), ranked[0].ScoreDelta)
+
+	cache.Clear("lb")
+	assert.Nil(t, cache.Last("lb"))
+	assert.True(t, cache.Update("lb", recordList([]string{"b"}, []int64{25}))[0].IsNew)
+}
+
+func TestLeaderboardCacheEviction(t *testing.T) {
+	cache := NewLeaderboardCache()
+	cache.MaxLeaderboards = 2
+	cache.MaxOwners = 2
+
+	// the owners least recently fetched are evicted
+	cache.Update("lb", recordList([]string{"a"}, []int64{1}))
+	cache.Update("lb", recordList([]string{"b", "c"}, []int64{2, 1}))
+	ranked := cache.Update("lb", recordList([]string{"a", "b", "c"}, []int64{3, 2, 1}))
+	assert.True(t, ranked[0].IsNew, "a is evicted")
+	assert.False(t, ranked[1].IsNew)
+
+	// the leaderboards least recently updated are evicted
+	cache.Update("other", recordList([]string{"a"}, []int64{1}))
+	cache.Update("lb", recordList([]string{"a"}, []int64{1}))
+	cache.Update("third", recordList([]string{"a"}, []int64{1}))
+	assert.Nil(t, cache.Last("other"))
+	assert.NotNil(t, cache.Last("lb"))
+	assert.NotNil(t, cache.Last("third"))
+}
+
+func TestListLeaderboardRecordsCached(t *testing.T) {
+	score := int64(10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/leaderboard/weekly", r.URL.Path)
+		data, _ := protojson.Marshal(recordList([]string{"a"}, []int64{score}))
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+	cache := NewLeaderboardCache()
+
+	_, _, err = client.ListLeaderboardRecordsCached(session, cache, "weekly", nil, nil, nil, nil)
+	assert.NoError(t, err)
+	score = 25
+	ranked, list, err := client.ListLeaderboardRecordsCached(session, cache, "weekly", nil, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(25), list.Records[0].Score)
+	assert.Equal(t, int64(15), ranked[0].ScoreDelta)
+}
@@ -0,0 +1,133 @@
+package nakama
+
+import (
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// Operator is how a score is combined with the record of the user, see RecordWrite.
+type Operator int32
+
+// The operators, mapped to the api.Operator values.
+const (
+	// OperatorDefault keeps the operator of the leaderboard or the tournament.
+	OperatorDefault Operator = Operator(api.Operator_NO_OVERRIDE)
+	// OperatorBest keeps the best of the scores, by the sort order of the leaderboard.
+	OperatorBest Operator = Operator(api.Operator_BEST)
+	// OperatorSet replaces the score.
+	OperatorSet Operator = Operator(api.Operator_SET)
+	// OperatorIncrement adds the score to the record.
+	OperatorIncrement Operator = Operator(api.Operator_INCREMENT)
+	// OperatorDecrement subtracts the score from the record.
+	OperatorDecrement Operator = Operator(api.Operator_DECREMENT)
+)
+
+// OperatorOf returns the operator of the proto value, e.g. the operator of an api.Leaderboard.
+func OperatorOf(operator api.Operator) Operator {
+	return Operator(operator)
+}
+
+// String returns the proto name of the operator.
+func (o Operator) String() string {
+	return api.Operator(o).String()
+}
+
+// proto returns the proto value of the operator, or an error when it isn't one of the operators.
+func (o Operator) proto() (api.Operator, error) {
+	if _, ok := api.Operator_name[int32(o)]; !ok {
+		return 0, newError("unknown operator").With(int32(o))
+	}
+	return api.Operator(o), nil
+}
+
+// The categories of the tournaments, the server accepts 0 to MaxTournamentCategory.
+const (
+	MinTournamentCategory = 0
+	MaxTournamentCategory = 127
+)
+
+// CategoryRange is an inclusive range of tournament categories, e.g. a game keeping 0-9 for its daily
+// tournaments and 10-19 for its weekly ones lists them with CategoryRange{0, 9} and CategoryRange{10, 19}.
+type CategoryRange struct {
+	Start int
+	End   int
+}
+
+// AllCategories is the range of all the tournament categories.
+var AllCategories = CategoryRange{Start: MinTournamentCategory, End: MaxTournamentCategory}
+
+// Category returns the range of the single category.
+func Category(category int) CategoryRange {
+	return CategoryRange{Start: category, End: category}
+}
+
+// Contains tells if the category is in the range.
+func (r CategoryRange) Contains(category int) bool {
+	return category >= r.Start && category <= r.End
+}
+
+// bounds returns the query bounds of the range, or an error when it's out of the categories of the server.
+func (r CategoryRange) bounds() (*int, *int, error) {
+	if r.Start < MinTournamentCategory || r.End > MaxTournamentCategory || r.Start > r.End {
+		return nil, nil, newError("invalid category range").With(r.Start, r.End)
+	}
+	return &r.Start, &r.End, nil
+}
+
+// RecordWrite is a score written by WriteLeaderboardScore or WriteTournamentScore.
+type RecordWrite struct {
+	Score    int64
+	Subscore int64
+	// Metadata is the JSON metadata of the record, none if empty.
+	Metadata string
+	// Operator overrides the operator of the leaderboard or the tournament, OperatorDefault keeps it.
+	Operator Operator
+}
+
+// leaderboardWrite returns the leaderboard request of the write.
+func (w RecordWrite) leaderboardWrite() (*api.WriteLeaderboardRecordRequest_LeaderboardRecordWrite, error) {
+	operator, err := w.Operator.proto()
+	if err != nil {
+		return nil, err
+	}
+	return &api.WriteLeaderboardRecordRequest_LeaderboardRecordWrite{
+		Score: w.Score, Subscore: w.Subscore, Metadata: w.Metadata, Operator: operator,
+	}, nil
+}
+
+// tournamentWrite returns the tournament request of the write.
+func (w RecordWrite) tournamentWrite() (*api.WriteTournamentRecordRequest_TournamentRecordWrite, error) {
+	operator, err := w.Operator.proto()
+	if err != nil {
+		return nil, err
+	}
+	return &api.WriteTournamentRecordRequest_TournamentRecordWrite{
+		Score: w.Score, Subscore: w.Subscore, Metadata: w.Metadata, Operator: operator,
+	}, nil
+}
+
+// WriteLeaderboardScore writes a score to a leaderboard like WriteLeaderboardRecord.
+func (c *Client) WriteLeaderboardScore(session *Session, leaderboardId string, write RecordWrite) (*api.LeaderboardRecord, error) {
+	request, err := write.leaderboardWrite()
+	if err != nil {
+		return nil, wrapErr(err, leaderboardId)
+	}
+	return c.WriteLeaderboardRecord(session, leaderboardId, request)
+}
+
+// WriteTournamentScore writes a score to a tournament like WriteTournamentRecord.
+func (c *Client) WriteTournamentScore(session *Session, tournamentId string, write RecordWrite) (*api.LeaderboardRecord, error) {
+	request, err := write.tournamentWrite()
+	if err != nil {
+		return nil, wrapErr(err, tournamentId)
+	}
+	return c.WriteTournamentRecord(session, tournamentId, request)
+}
+
+// ListTournamentsIn lists the tournaments of the categories like ListTournaments.
+func (c *Client) ListTournamentsIn(session *Session, categories CategoryRange, startTime *int64, endTime *int64, limit int, cursor string) (*api.TournamentList, error) {
+	start, end, err := categories.bounds()
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return c.ListTournaments(session, start, end, startTime, endTime, limit, cursor)
+}
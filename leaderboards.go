@@ -0,0 +1,78 @@
+package nakama
+
+import (
+	"net/http"
+	"strings"
+
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+var (
+	// ErrLeaderboardNotFound is returned when the leaderboard or the tournament doesn't exist.
+	ErrLeaderboardNotFound = newError("leaderboard or tournament not found")
+	// ErrLeaderboardAuthoritative is returned when the scores can only be written by the server.
+	ErrLeaderboardAuthoritative = newError("leaderboard or tournament is authoritative")
+	// ErrTournamentOutsideWindow is returned when the tournament isn't active, the score is for a closed or future window.
+	ErrTournamentOutsideWindow = newError("tournament not active")
+	// ErrTournamentMaxAttempts is returned when the user has used all the score attempts of the tournament.
+	ErrTournamentMaxAttempts = newError("tournament max score attempts reached")
+	// ErrTournamentFull is returned when the tournament has reached its max size.
+	ErrTournamentFull = newError("tournament max size reached")
+	// ErrTournamentJoinRequired is returned when the tournament must be joined before writing a score.
+	ErrTournamentJoinRequired = newError("tournament join required")
+	// ErrRecordInvalid is returned when the server rejects the record, e.g. an invalid operator.
+	ErrRecordInvalid = newError("invalid leaderboard record")
+)
+
+// recordError maps the http errors of the record writes to the typed errors.
+func recordError(err error, id string) error {
+	switch httpStatusOf(err) {
+	case http.StatusNotFound:
+		return ErrLeaderboardNotFound.With(id, err)
+	case http.StatusForbidden:
+		return ErrLeaderboardAuthoritative.With(id, err)
+	case http.StatusBadRequest:
+		// the 400s are told apart by the message of the server
+		msg := err.Error()
+		switch {
+		case strings.Contains(msg, "not active"):
+			return ErrTournamentOutsideWindow.With(id, err)
+		case strings.Contains(msg, "max number of score"):
+			return ErrTournamentMaxAttempts.With(id, err)
+		case strings.Contains(msg, "max size"):
+			return ErrTournamentFull.With(id, err)
+		case strings.Contains(msg, "Must join"):
+			return ErrTournamentJoinRequired.With(id, err)
+		}
+		return ErrRecordInvalid.With(id, err)
+	}
+	return wrapErr(err, id)
+}
+
+// WriteLeaderboardRecord writes a record to a leaderboard.
+// The server rejections are returned as ErrLeaderboardNotFound, ErrLeaderboardAuthoritative or ErrRecordInvalid.
+func (c *Client) WriteLeaderboardRecord(session *Session, leaderboardId string, request *api.WriteLeaderboardRecordRequest_LeaderboardRecordWrite) (*api.LeaderboardRecord, error) {
+	if err := c.refreshSession(session); err != nil {
+		return nil, wrapErr(err)
+	}
+
+	record, err := c.apiFor(session).WriteLeaderboardRecord(session.Token, leaderboardId, request, make(map[string]string))
+	if err != nil {
+		return nil, recordError(err, leaderboardId)
+	}
+	return record, nil
+}
+
+// WriteTournamentRecord writes a record to a tournament.
+// The server rejections are returned as the typed errors of this file, e.g. ErrTournamentOutsideWindow.
+func (c *Client) WriteTournamentRecord(session *Session, tournamentId string, request *api.WriteTournamentRecordRequest_TournamentRecordWrite) (*api.LeaderboardRecord, error) {
+	if err := c.refreshSession(session); err != nil {
+		return nil, wrapErr(err)
+	}
+
+	record, err := c.apiFor(session).WriteTournamentRecord(session.Token, tournamentId, request, make(map[string]string))
+	if err != nil {
+		return nil, recordError(err, tournamentId)
+	}
+	return record, nil
+}
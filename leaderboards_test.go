@@ -0,0 +1,99 @@
+package nakama
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestRecordErrors(t *testing.T) {
+	responses := map[string]struct {
+		status  int
+		message string
+	}{
+		"/v2/tournament/closed":   {http.StatusBadRequest, "Tournament is not active and cannot accept new scores."},
+		"/v2/tournament/attempts": {http.StatusBadRequest, "Reached allowed max number of score attempts."},
+		"/v2/tournament/join":     {http.StatusBadRequest, "Must join tournament before attempting to write value."},
+		"/v2/tournament/missing":  {http.StatusNotFound, "Tournament not found."},
+		"/v2/leaderboard/server":  {http.StatusForbidden, "Writing to authoritative leaderboard not allowed."},
+		"/v2/leaderboard/bad":     {http.StatusBadRequest, "Invalid operator."},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rsp := responses[r.URL.Path]
+		w.WriteHeader(rsp.status)
+		w.Write([]byte(`{"code":3,"message":"` + rsp.message + `"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	assert.NoError(t, err)
+	session := &Session{Token: "token"}
+
+	tournament := func(id string) error {
+		_, err := client.WriteTournamentRecord(session, id, &api.WriteTournamentRecordRequest_TournamentRecordWrite{Score: 1})
+		return err
+	}
+	leaderboard := func(id string) error {
+		_, err := client.WriteLeaderboardRecord(session, id, &api.WriteLeaderboardRecordRequest_LeaderboardRecordWrite{Score: 1})
+		return err
+	}
+	assert.True(t, errors.Is(tournament("closed"), ErrTournamentOutsideWindow))
+	assert.True(t, errors.Is(tournament("attempts"), ErrTournamentMaxAttempts))
+	assert.True(t, errors.Is(tournament("join"), ErrTournamentJoinRequired))
+	assert.True(t, errors.Is(tournament("missing"), ErrLeaderboardNotFound))
+	assert.True(t, errors.Is(leaderboard("server"), ErrLeaderboardAuthoritative))
+	assert.True(t, errors.Is(leaderboard("bad"), ErrRecordInvalid))
+}
+
+func TestRecordWrites(t *testing.T) {
+	var body []byte
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		query = r.URL.Query()
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	assert.NoError(t, err)
+	session := &Session{Token: "token"}
+
+	_, err = client.WriteLeaderboardScore(session, "coins", RecordWrite{Score: 5, Operator: OperatorIncrement})
+	assert.NoError(t, err)
+	write := &api.WriteLeaderboardRecordRequest_LeaderboardRecordWrite{}
+	assert.NoError(t, protojson.Unmarshal(body, write))
+	assert.Equal(t, api.Operator_INCREMENT, write.GetOperator())
+	assert.Equal(t, int64(5), write.GetScore())
+
+	_, err = client.WriteTournamentScore(session, "daily", RecordWrite{Score: 7, Subscore: 2, Operator: OperatorBest})
+	assert.NoError(t, err)
+	tournamentWrite := &api.WriteTournamentRecordRequest_TournamentRecordWrite{}
+	assert.NoError(t, protojson.Unmarshal(body, tournamentWrite))
+	assert.Equal(t, api.Operator_BEST, tournamentWrite.GetOperator())
+	assert.Equal(t, OperatorBest, OperatorOf(tournamentWrite.GetOperator()))
+
+	// an unknown operator isn't sent
+	body = nil
+	_, err = client.WriteLeaderboardScore(session, "coins", RecordWrite{Score: 5, Operator: Operator(42)})
+	assert.Error(t, err)
+	assert.Nil(t, body)
+
+	_, err = client.ListTournamentsIn(session, CategoryRange{Start: 10, End: 19}, nil, nil, 10, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "10", query.Get("category_start"))
+	assert.Equal(t, "19", query.Get("category_end"))
+	for _, invalid := range []CategoryRange{{Start: -1, End: 3}, {Start: 5, End: 4}, {Start: 0, End: 128}} {
+		_, err = client.ListTournamentsIn(session, invalid, nil, nil, 10, "")
+		assert.Error(t, err, invalid)
+	}
+	assert.True(t, AllCategories.Contains(127))
+	assert.False(t, Category(3).Contains(4))
+}
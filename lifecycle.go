@@ -0,0 +1,125 @@
+package nakama
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrLifecycleStopping is returned by Lifecycle.Go while Stop waits for the goroutines.
+var ErrLifecycleStopping = newError("lifecycle stopping")
+
+// Lifecycle runs the background goroutines of a client or a socket, e.g. the heartbeat, the read loops,
+// the reconnects and the pollers, so they can be stopped and waited for together.
+// It's started by Start or the first Go, Stop cancels the context of the goroutines and waits for them,
+// and it can be started again afterwards. An error returned by a goroutine stops the others.
+// The EventHandler calls aren't part of it, a handler may stop its socket.
+type Lifecycle struct {
+	mu       sync.Mutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	group    *errgroup.Group
+	stopping bool
+}
+
+// Start binds the goroutines to ctx, it's a no-op when the lifecycle is running.
+func (l *Lifecycle) Start(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.start(ctx)
+}
+
+// start starts a run, l.mu is held.
+func (l *Lifecycle) start(ctx context.Context) {
+	if l.group != nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, l.cancel = context.WithCancel(ctx)
+	l.group, l.ctx = errgroup.WithContext(ctx)
+}
+
+// Go runs fn in the background until Stop, fn returns when its context is done.
+func (l *Lifecycle) Go(fn func(ctx context.Context) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stopping {
+		return ErrLifecycleStopping
+	}
+	l.start(nil)
+	ctx := l.ctx
+	l.group.Go(func() error { return fn(ctx) })
+	return nil
+}
+
+// Context returns the context of the goroutines, it's done once stopped.
+func (l *Lifecycle) Context() context.Context {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.ctx == nil {
+		return context.Background()
+	}
+	return l.ctx
+}
+
+// Stop cancels the goroutines and waits for them, it returns the first error of a goroutine other than
+// its context being canceled. It must not be called by one of the goroutines.
+func (l *Lifecycle) Stop() error {
+	l.mu.Lock()
+	if l.group == nil || l.stopping {
+		l.mu.Unlock()
+		return nil
+	}
+	group, cancel := l.group, l.cancel
+	l.stopping = true
+	l.mu.Unlock()
+
+	cancel()
+	err := group.Wait()
+
+	l.mu.Lock()
+	l.group, l.ctx, l.cancel = nil, nil, nil
+	l.stopping = false
+	l.mu.Unlock()
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return wrapErr(err)
+}
+
+// sleep waits for d, it returns early when the lifecycle is stopped.
+func (l *Lifecycle) sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-l.Context().Done():
+	}
+}
+
+// Start binds the background components of the client to ctx, e.g. the context of the app.
+// Without it they run until Stop.
+func (c *Client) Start(ctx context.Context) {
+	c.lifecycle.Start(ctx)
+}
+
+// Go runs a background component owned by the client until Stop, e.g. PollingFallback.Run.
+func (c *Client) Go(fn func(ctx context.Context) error) error {
+	return c.lifecycle.Go(fn)
+}
+
+// Stop disconnects the sockets of the client still connected, then stops its background components and waits for them.
+func (c *Client) Stop() error {
+	for _, socket := range c.sockets.list() {
+		socket.Disconnect()
+	}
+	return c.lifecycle.Stop()
+}
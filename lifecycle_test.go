@@ -0,0 +1,94 @@
+package nakama
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+)
+
+func TestLifecycle(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	l := &Lifecycle{}
+	var stopped atomic.Int32
+	for range 2 {
+		assert.NoError(t, l.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			stopped.Add(1)
+			return ctx.Err()
+		}))
+	}
+	assert.NoError(t, l.Stop(), "the cancellation isn't an error")
+	assert.Equal(t, int32(2), stopped.Load(), "Stop waits for the goroutines")
+	assert.NoError(t, l.Stop())
+
+	// an error stops the others, and is returned by Stop
+	failure := errors.New("failure")
+	assert.NoError(t, l.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}))
+	assert.NoError(t, l.Go(func(ctx context.Context) error { return failure }))
+	eventually(t, func() bool { return l.Context().Err() != nil }, "the failure has not stopped the lifecycle")
+	assert.ErrorIs(t, l.Stop(), failure)
+
+	// bound to the context of Start, and no new goroutine while stopping
+	ctx, cancel := context.WithCancel(context.Background())
+	l.Start(ctx)
+	assert.NoError(t, l.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		assert.ErrorIs(t, l.Go(func(ctx context.Context) error { return nil }), ErrLifecycleStopping)
+		return nil
+	}))
+	cancel()
+	eventually(t, func() bool { return l.Context().Err() != nil }, "the lifecycle isn't bound to the context of Start")
+	assert.NoError(t, l.Stop())
+}
+
+func TestSocketNoLeaks(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		if req.GetPing() != nil {
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Pong{Pong: &rtapi.Pong{}}})
+		}
+	})
+	socket, _ := server.socket(nil)
+	assert.NoError(t, socket.Connect())
+	socket.SetPingIntervalMs(10)
+	server.conn(0).Drop()
+	eventually(t, func() bool { return server.accepted.Load() == 2 }, "the socket has not reconnected")
+
+	// a request waiting for its response fails with the disconnect
+	result := make(chan any, 1)
+	go func() {
+		result <- socket.Send(&rtapi.Envelope{Message: &rtapi.Envelope_StatusUpdate{StatusUpdate: &rtapi.StatusUpdate{}}}, nil)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	socket.Disconnect()
+	err, ok := (<-result).(error)
+	assert.True(t, ok)
+	assert.Contains(t, err.Error(), "closed")
+	server.server.Close()
+}
+
+func TestClientStop(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	client, err := NewClientWithOptions()
+	assert.NoError(t, err)
+	poll := NewPollingFallback(client, func() *Session { return nil }, nil)
+	poll.SetPushActive(true)
+	assert.NoError(t, poll.Start())
+	assert.NoError(t, client.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}))
+	assert.NoError(t, client.Stop())
+}
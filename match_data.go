@@ -0,0 +1,201 @@
+package nakama
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// MatchDataFrame is a match data message received on the fast path of SetOnMatchData.
+// The frame and its Data are pooled and reused once the handler returns, the handler must not retain them,
+// use Retain to keep a copy of Data.
+type MatchDataFrame struct {
+	MatchId  string
+	OpCode   int64
+	Data     []byte
+	Presence *rtapi.UserPresence
+	Reliable bool
+}
+
+// Retain returns a copy of Data the caller can keep.
+func (f *MatchDataFrame) Retain() []byte {
+	return bytes.Clone(f.Data)
+}
+
+// MatchDataHandler handles the match data of the fast path, it runs on the read loop of the socket.
+type MatchDataHandler func(frame *MatchDataFrame)
+
+// matchDataBuffers are the buffers of the decoded match data.
+var matchDataBuffers = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 1024)
+		return &buf
+	},
+}
+
+// matchDataFrames are the frames passed to the match data handlers.
+var matchDataFrames = sync.Pool{
+	New: func() any { return &MatchDataFrame{} },
+}
+
+// matchDataFields are the fields of a match data message but the data, decoded without the envelope.
+type matchDataFields struct {
+	MatchData struct {
+		MatchId  string              `json:"match_id"`
+		OpCode   jsonInt64           `json:"op_code"`
+		Presence *rtapi.UserPresence `json:"presence"`
+		Reliable bool                `json:"reliable"`
+	} `json:"match_data"`
+}
+
+// jsonInt64 reads an int64 written as a number or as a string like protojson does.
+type jsonInt64 int64
+
+func (i *jsonInt64) UnmarshalJSON(data []byte) error {
+	v, err := strconv.ParseInt(string(bytes.Trim(data, `"`)), 10, 64)
+	if err != nil {
+		return wrapErr(err, string(data))
+	}
+	*i = jsonInt64(v)
+	return nil
+}
+
+var (
+	matchDataKey = []byte(`"match_data"`)
+	dataKey      = []byte(`"data"`)
+)
+
+// isMatchData reports whether the message is a match data envelope without decoding it.
+// The fast path reads the proto names written by the server, the other spellings take the envelope path.
+func isMatchData(message []byte) bool {
+	message = bytes.TrimLeft(message, " \t\r\n{")
+	return bytes.HasPrefix(message, matchDataKey)
+}
+
+// base64Field returns the base64 value of the "data" field of a match data message.
+// The message is a single envelope, "data" can only be unescaped as a key or as a whole string value.
+func base64Field(message []byte) ([]byte, bool) {
+	for {
+		i := bytes.Index(message, dataKey)
+		if i < 0 {
+			return nil, false
+		}
+		message = message[i+len(dataKey):]
+		rest := bytes.TrimLeft(message, " \t\r\n")
+		if len(rest) == 0 || rest[0] != ':' {
+			// a string value
+			continue
+		}
+		rest = bytes.TrimLeft(rest[1:], " \t\r\n")
+		if len(rest) == 0 || rest[0] != '"' {
+			return nil, false
+		}
+		end := bytes.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return nil, false
+		}
+		return rest[1 : 1+end], true
+	}
+}
+
+// decodeMatchData decodes a match data message into frame, the data into a pooled buffer
+// returned to put back in matchDataBuffers, nil without data.
+func decodeMatchData(message []byte, frame *MatchDataFrame) (*[]byte, error) {
+	fields := matchDataFields{}
+	if err := json.Unmarshal(message, &fields); err != nil {
+		return nil, wrapErr(err)
+	}
+	frame.MatchId = fields.MatchData.MatchId
+	frame.OpCode = int64(fields.MatchData.OpCode)
+	frame.Presence = fields.MatchData.Presence
+	frame.Reliable = fields.MatchData.Reliable
+	frame.Data = nil
+
+	encoded, ok := base64Field(message)
+	if !ok {
+		return nil, nil
+	}
+	buf := matchDataBuffers.Get().(*[]byte)
+	size := base64.StdEncoding.DecodedLen(len(encoded))
+	if cap(*buf) < size {
+		*buf = make([]byte, size)
+	}
+	n, err := base64.StdEncoding.Decode((*buf)[:size], encoded)
+	if err != nil {
+		matchDataBuffers.Put(buf)
+		return nil, wrapErr(err)
+	}
+	frame.Data = (*buf)[:n]
+	return buf, nil
+}
+
+// SetOnMatchData sets the handler of a fast path for the match data, e.g. the state updates at 30-60 Hz.
+// The match data skip the envelope decoding and the EventHandler, and are decoded into pooled buffers.
+// The handler runs on the read loop of the socket in the order received, it must return quickly
+// and must not retain frame.Data, see MatchDataFrame.Retain. Nil restores the EventHandler path.
+// The fast path is off while the socket is verbose, so the envelopes can be dumped.
+func (socket *DefaultSocket) SetOnMatchData(handler MatchDataHandler) {
+	if handler == nil {
+		socket.onMatchData.Store(nil)
+		return
+	}
+	socket.onMatchData.Store(&handler)
+}
+
+// handleMatchData delivers the message to the match data handler, handled is false when it's not a match data.
+func (socket *DefaultSocket) handleMatchData(message []byte) (handled bool) {
+	handler := socket.onMatchData.Load()
+	if handler == nil || socket.IsVerbose() || !isMatchData(message) {
+		return false
+	}
+	frame := matchDataFrames.Get().(*MatchDataFrame)
+	defer matchDataFrames.Put(frame)
+	buf, err := decodeMatchData(message, frame)
+	if err != nil {
+		// let the envelope path report it
+		return false
+	}
+	if f := socket.fragments.Load(); f != nil && frame.OpCode == f.options.OpCode {
+		// reassembled by the envelope path
+		if buf != nil {
+			matchDataBuffers.Put(buf)
+		}
+		return false
+	}
+	socket.replay.Load().record(frame.MatchId, false, frame.OpCode, frame.Presence.GetUserId(), frame.Data)
+	(*handler)(frame)
+	if buf != nil {
+		frame.Data = nil
+		matchDataBuffers.Put(buf)
+	}
+	return true
+}
+
+// handleDecodedMatchData delivers the match data of a binary message to the match data handler,
+// handled is false when there's no handler or the data is a fragment.
+func (socket *DefaultSocket) handleDecodedMatchData(data *rtapi.MatchData) (handled bool) {
+	handler := socket.onMatchData.Load()
+	if handler == nil || data == nil || socket.IsVerbose() {
+		return false
+	}
+	if f := socket.fragments.Load(); f != nil && data.GetOpCode() == f.options.OpCode {
+		return false
+	}
+	frame := matchDataFrames.Get().(*MatchDataFrame)
+	defer matchDataFrames.Put(frame)
+	*frame = MatchDataFrame{
+		MatchId:  data.GetMatchId(),
+		OpCode:   data.GetOpCode(),
+		Data:     data.GetData(),
+		Presence: data.GetPresence(),
+		Reliable: data.GetReliable(),
+	}
+	socket.replay.Load().record(frame.MatchId, false, frame.OpCode, frame.Presence.GetUserId(), frame.Data)
+	(*handler)(frame)
+	frame.Data, frame.Presence = nil, nil
+	return true
+}
@@ -0,0 +1,74 @@
+package nakama
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func matchDataMessage(tb testing.TB, data []byte) []byte {
+	// the server writes the proto names
+	message, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(&rtapi.Envelope{Message: &rtapi.Envelope_MatchData{MatchData: &rtapi.MatchData{
+		MatchId:  "match",
+		OpCode:   7,
+		Data:     data,
+		Presence: &rtapi.UserPresence{UserId: "user", Username: "data"},
+		Reliable: true,
+	}}})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return message
+}
+
+func TestMatchDataFastPath(t *testing.T) {
+	data := bytes.Repeat([]byte{1, 2, 3, 250}, 100)
+	message := matchDataMessage(t, data)
+
+	socket := NewDefaultSocket(nil, "127.0.0.1", "7350", "token", false, false, nil, nil)
+	var retained []byte
+	var frames int
+	socket.SetOnMatchData(func(frame *MatchDataFrame) {
+		frames++
+		assert.Equal(t, "match", frame.MatchId)
+		assert.Equal(t, int64(7), frame.OpCode)
+		assert.Equal(t, "user", frame.Presence.GetUserId())
+		assert.True(t, frame.Reliable)
+		retained = frame.Retain()
+	})
+	assert.NoError(t, socket.handleMessage(1, message))
+	assert.NoError(t, socket.handleMessage(1, message))
+	assert.Equal(t, 2, frames)
+	assert.Equal(t, data, retained)
+
+	// the other messages take the envelope path
+	assert.False(t, socket.handleMatchData([]byte(`{"channel_message":{"content":"{\"data\":\"x\"}"}}`)))
+	socket.SetOnMatchData(nil)
+	assert.False(t, socket.handleMatchData(message))
+}
+
+func BenchmarkMatchDataEnvelope(b *testing.B) {
+	message := matchDataMessage(b, bytes.Repeat([]byte{42}, 256))
+	b.ReportAllocs()
+	for b.Loop() {
+		envelope := &rtapi.Envelope{}
+		if err := protojson.Unmarshal(message, envelope); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMatchDataFastPath(b *testing.B) {
+	message := matchDataMessage(b, bytes.Repeat([]byte{42}, 256))
+	socket := NewDefaultSocket(nil, "127.0.0.1", "7350", "token", false, false, nil, nil)
+	socket.SetOnMatchData(func(frame *MatchDataFrame) {})
+	b.ReportAllocs()
+	for b.Loop() {
+		if !socket.handleMatchData(message) {
+			b.Fatal("not handled")
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package nakama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// DefaultMatchReplayChunkBytes is the size of the chunks of a replay uploaded to the storage, before base64.
+const DefaultMatchReplayChunkBytes = 256 * 1024
+
+// matchReplayMagic starts a replay, its last byte is the version of the format.
+var matchReplayMagic = []byte("NKRP\x01")
+
+// maxReplayFrameBytes bounds the sizes read, so a corrupted replay doesn't allocate gigabytes.
+const maxReplayFrameBytes = 4 << 20
+
+// matchReplayBatch is the count of chunks written by a storage call of UploadMatchReplay.
+const matchReplayBatch = 8
+
+var (
+	// ErrMatchReplayFormat is returned when reading data which isn't a replay of this version.
+	ErrMatchReplayFormat = newError("invalid match replay")
+	// ErrMatchReplayNotFound is returned by DownloadMatchReplay when the replay isn't in the storage.
+	ErrMatchReplayNotFound = newError("match replay not found")
+)
+
+// ReplayFrame is a match data of a replay.
+type ReplayFrame struct {
+	Offset   time.Duration // since the start of the recording
+	Outgoing bool          // sent by the recording socket, UserId is empty
+	OpCode   int64
+	UserId   string // the sender of an incoming data, empty for the match handler
+	Data     []byte
+}
+
+// MatchReplay records the match data sent and received by a socket for a match, see DefaultSocket.SetMatchReplay.
+// A frame is the offset in ms, the direction, the op code, the sender and the data, as varints and length prefixed bytes,
+// in memory or handed off to a writer. The game messages aren't changed, e.g. a spectator replays them with ReadMatchReplay.
+type MatchReplay struct {
+	MatchId string
+
+	mu     sync.Mutex
+	start  time.Time
+	w      io.Writer
+	buf    *bytes.Buffer // the recording in memory, nil when handed off to a writer
+	frames int
+	err    error // the first write error, the recording stops
+	rec    []byte
+}
+
+// NewMatchReplay creates a replay recording the match in memory, see Bytes.
+func NewMatchReplay(matchId string) *MatchReplay {
+	buf := &bytes.Buffer{}
+	replay := &MatchReplay{MatchId: matchId, start: time.Now(), w: buf, buf: buf}
+	replay.write(matchReplayMagic)
+	return replay
+}
+
+// NewMatchReplayWriter creates a replay writing the match to w as it's played, e.g. a file.
+// The writes happen on the read loop of the socket, w should be buffered.
+func NewMatchReplayWriter(matchId string, w io.Writer) *MatchReplay {
+	replay := &MatchReplay{MatchId: matchId, start: time.Now(), w: w}
+	replay.write(matchReplayMagic)
+	return replay
+}
+
+// Frames returns the count of frames recorded.
+func (r *MatchReplay) Frames() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.frames
+}
+
+// Err returns the write error which has stopped the recording, if any.
+func (r *MatchReplay) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Bytes returns a copy of the recording in memory, nil for a replay handed off to a writer.
+func (r *MatchReplay) Bytes() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.buf == nil {
+		return nil
+	}
+	return bytes.Clone(r.buf.Bytes())
+}
+
+// record appends a frame of the match, the data of the other matches are ignored.
+func (r *MatchReplay) record(matchId string, outgoing bool, opCode int64, userId string, data []byte) {
+	if r == nil || matchId != r.MatchId {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	direction := byte(0)
+	if outgoing {
+		direction = 1
+	}
+	b := r.rec[:0]
+	b = binary.AppendUvarint(b, uint64(time.Since(r.start).Milliseconds()))
+	b = append(b, direction)
+	b = binary.AppendVarint(b, opCode)
+	b = binary.AppendUvarint(b, uint64(len(userId)))
+	b = append(b, userId...)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	b = append(b, data...)
+	r.rec = b
+	r.write(b)
+	r.frames++
+}
+
+// write writes to the recording, r.mu is held or r is new.
+func (r *MatchReplay) write(b []byte) {
+	if _, err := r.w.Write(b); err != nil {
+		r.err = wrapErr(err, r.MatchId)
+		GetLogger().Warnf("match replay %s stopped: %s", r.MatchId, err.Error())
+	}
+}
+
+// ReadMatchReplay iterates over the frames of a replay, the iteration stops after yielding an error.
+func ReadMatchReplay(reader io.Reader) iter.Seq2[*ReplayFrame, error] {
+	return func(yield func(*ReplayFrame, error) bool) {
+		br := bufio.NewReader(reader)
+		magic := make([]byte, len(matchReplayMagic))
+		if _, err := io.ReadFull(br, magic); err != nil || !bytes.Equal(magic, matchReplayMagic) {
+			yield(nil, ErrMatchReplayFormat.With("header"))
+			return
+		}
+		for {
+			frame, err := readReplayFrame(br)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(nil, ErrMatchReplayFormat.With(err))
+				return
+			}
+			if !yield(frame, nil) {
+				return
+			}
+		}
+	}
+}
+
+// readReplayFrame reads a frame, io.EOF at the end of the replay only.
+func readReplayFrame(br *bufio.Reader) (*ReplayFrame, error) {
+	offset, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	direction, err := br.ReadByte()
+	if err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	opCode, err := binary.ReadVarint(br)
+	if err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	userId, err := readReplayBytes(br)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readReplayBytes(br)
+	if err != nil {
+		return nil, err
+	}
+	return &ReplayFrame{
+		Offset:   time.Duration(offset) * time.Millisecond,
+		Outgoing: direction == 1,
+		OpCode:   opCode,
+		UserId:   string(userId),
+		Data:     data,
+	}, nil
+}
+
+func readReplayBytes(br *bufio.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if size > maxReplayFrameBytes {
+		return nil, newError("replay frame too big").With(size)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(br, b); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return b, nil
+}
+
+// SetMatchReplay records the match data of the replay match sent by SendMatchState and received by the socket,
+// on the fast path of SetOnMatchData too. Nil stops the recording.
+func (socket *DefaultSocket) SetMatchReplay(replay *MatchReplay) {
+	socket.replay.Store(replay)
+}
+
+// matchReplayManifest is the storage object listing the chunks of a replay.
+type matchReplayManifest struct {
+	MatchId string `json:"match_id"`
+	Chunks  int    `json:"chunks"`
+	Frames  int    `json:"frames"`
+	Bytes   int    `json:"bytes"`
+}
+
+// matchReplayChunk is a storage object holding a chunk of a replay.
+type matchReplayChunk struct {
+	Data []byte `json:"data"` // base64
+}
+
+// matchReplayChunkKey returns the storage key of a chunk of a replay, the manifest is keyed by the match id.
+func matchReplayChunkKey(matchId string, i int) string {
+	return fmt.Sprintf("%s:%04d", matchId, i)
+}
+
+// UploadMatchReplay writes a replay recorded in memory to the collection of the user, in chunks of
+// DefaultMatchReplayChunkBytes and a manifest keyed by the match id written last, readable by everyone
+// so the spectators can download it, see DownloadMatchReplay. The chunks of a previous upload are overwritten.
+func (c *Client) UploadMatchReplay(ctx context.Context, session *Session, collection string, replay *MatchReplay) error {
+	data := replay.Bytes()
+	if data == nil {
+		return newError("match replay not in memory").With(replay.MatchId)
+	}
+	publicRead, ownerWrite := wrapperspb.Int32(2), wrapperspb.Int32(1)
+	objects := []*api.WriteStorageObject{}
+	chunks := 0
+	for offset := 0; offset < len(data); offset += DefaultMatchReplayChunkBytes {
+		value, err := json.Marshal(&matchReplayChunk{Data: data[offset:min(offset+DefaultMatchReplayChunkBytes, len(data))]})
+		if err != nil {
+			return wrapErr(err)
+		}
+		objects = append(objects, &api.WriteStorageObject{
+			Collection:      collection,
+			Key:             matchReplayChunkKey(replay.MatchId, chunks),
+			Value:           string(value),
+			PermissionRead:  publicRead,
+			PermissionWrite: ownerWrite,
+		})
+		chunks++
+	}
+	manifest, err := json.Marshal(&matchReplayManifest{MatchId: replay.MatchId, Chunks: chunks, Frames: replay.Frames(), Bytes: len(data)})
+	if err != nil {
+		return wrapErr(err)
+	}
+	objects = append(objects, &api.WriteStorageObject{
+		Collection:      collection,
+		Key:             replay.MatchId,
+		Value:           string(manifest),
+		PermissionRead:  publicRead,
+		PermissionWrite: ownerWrite,
+	})
+
+	client := c.WithContext(ctx)
+	for len(objects) > 0 {
+		batch := objects[:min(matchReplayBatch, len(objects))]
+		if _, err := client.WriteStorageObjects(session, batch); err != nil {
+			return wrapErr(err, replay.MatchId)
+		}
+		objects = objects[len(batch):]
+	}
+	return nil
+}
+
+// DownloadMatchReplay reads a replay uploaded by the user with UploadMatchReplay, e.g. to spectate it with ReadMatchReplay.
+func (c *Client) DownloadMatchReplay(ctx context.Context, session *Session, collection, userId, matchId string) ([]byte, error) {
+	client := c.WithContext(ctx)
+	objects, err := client.ReadStorageObjects(session, &api.ReadStorageObjectsRequest{ObjectIds: []*api.ReadStorageObjectId{
+		{Collection: collection, Key: matchId, UserId: userId},
+	}})
+	if err != nil {
+		return nil, wrapErr(err, matchId)
+	}
+	if len(objects.GetObjects()) == 0 {
+		return nil, ErrMatchReplayNotFound.With(matchId)
+	}
+	manifest := matchReplayManifest{}
+	if err := json.Unmarshal([]byte(objects.GetObjects()[0].GetValue()), &manifest); err != nil {
+		return nil, ErrMatchReplayFormat.With(matchId, err)
+	}
+
+	data := make([]byte, 0, manifest.Bytes)
+	for first := 0; first < manifest.Chunks; first += matchReplayBatch {
+		ids := []*api.ReadStorageObjectId{}
+		for i := first; i < min(first+matchReplayBatch, manifest.Chunks); i++ {
+			ids = append(ids, &api.ReadStorageObjectId{Collection: collection, Key: matchReplayChunkKey(matchId, i), UserId: userId})
+		}
+		objects, err := client.ReadStorageObjects(session, &api.ReadStorageObjectsRequest{ObjectIds: ids})
+		if err != nil {
+			return nil, wrapErr(err, matchId)
+		}
+		chunks := map[string][]byte{}
+		for _, object := range objects.GetObjects() {
+			chunk := matchReplayChunk{}
+			if err := json.Unmarshal([]byte(object.GetValue()), &chunk); err != nil {
+				return nil, ErrMatchReplayFormat.With(object.GetKey(), err)
+			}
+			chunks[object.GetKey()] = chunk.Data
+		}
+		// the server doesn't keep the order of the ids
+		for _, id := range ids {
+			chunk, ok := chunks[id.Key]
+			if !ok {
+				return nil, ErrMatchReplayNotFound.With(id.Key)
+			}
+			data = append(data, chunk...)
+		}
+	}
+	if len(data) != manifest.Bytes {
+		return nil, ErrMatchReplayFormat.With(matchId, len(data), manifest.Bytes)
+	}
+	return data, nil
+}
@@ -0,0 +1,107 @@
+package nakama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestMatchReplayRecording(t *testing.T) {
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		if send := req.GetMatchDataSend(); send != nil {
+			// the server echoes the data of another player, and the data of another match
+			conn.WriteRaw([]byte(`{"match_data":{"match_id":"m1","op_code":"2","data":"cG9uZw==","presence":{"user_id":"u2"}}}`))
+			conn.WriteRaw([]byte(`{"match_data":{"match_id":"m2","op_code":"2","data":"cG9uZw=="}}`))
+		}
+	})
+	socket, _ := server.socket(func(event EventType, data *RspResult) {})
+	assert.NoError(t, socket.Connect())
+	replay := NewMatchReplay("m1")
+	socket.SetMatchReplay(replay)
+
+	assert.NoError(t, socket.SendMatchState("m1", 1, []byte("ping"), nil, false))
+	eventually(t, func() bool { return replay.Frames() == 2 }, "the match data have not been recorded")
+
+	frames := []*ReplayFrame{}
+	for frame, err := range ReadMatchReplay(bytes.NewReader(replay.Bytes())) {
+		assert.NoError(t, err)
+		frames = append(frames, frame)
+	}
+	assert.Len(t, frames, 2)
+	assert.Equal(t, &ReplayFrame{Offset: frames[0].Offset, Outgoing: true, OpCode: 1, Data: []byte("ping")}, frames[0])
+	assert.Equal(t, &ReplayFrame{Offset: frames[1].Offset, OpCode: 2, UserId: "u2", Data: []byte("pong")}, frames[1])
+
+	// a truncated replay
+	data := replay.Bytes()
+	for _, err := range ReadMatchReplay(bytes.NewReader(data[:len(data)-1])) {
+		if err != nil {
+			assert.ErrorIs(t, err, ErrMatchReplayFormat)
+		}
+	}
+}
+
+// storageServer is an in-memory storage of a single user.
+func storageServer(t *testing.T) *httptest.Server {
+	mu := sync.Mutex{}
+	objects := map[string]*api.StorageObject{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			request := struct {
+				Objects []*api.WriteStorageObject `json:"objects"`
+			}{}
+			assert.NoError(t, json.Unmarshal(body, &request))
+			for _, object := range request.Objects {
+				objects[object.Collection+"/"+object.Key] = &api.StorageObject{Collection: object.Collection, Key: object.Key, Value: object.Value}
+			}
+			w.Write([]byte(`{}`))
+		case http.MethodPost:
+			request := &api.ReadStorageObjectsRequest{}
+			assert.NoError(t, protojson.Unmarshal(body, request))
+			found := &api.StorageObjects{}
+			for _, id := range request.ObjectIds {
+				if object, ok := objects[id.Collection+"/"+id.Key]; ok {
+					found.Objects = append([]*api.StorageObject{object}, found.Objects...)
+				}
+			}
+			data, _ := protojson.Marshal(found)
+			w.Write(data)
+		}
+	}))
+}
+
+func TestMatchReplayUpload(t *testing.T) {
+	server := storageServer(t)
+	defer server.Close()
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+
+	replay := NewMatchReplay("m1")
+	replay.record("m1", true, 1, "", bytes.Repeat([]byte{7}, 2*DefaultMatchReplayChunkBytes+10))
+	replay.record("m1", false, 2, "u2", []byte("end"))
+	assert.NoError(t, client.UploadMatchReplay(context.Background(), session, "replays", replay))
+
+	data, err := client.DownloadMatchReplay(context.Background(), session, "replays", "", "m1")
+	assert.NoError(t, err)
+	assert.Equal(t, replay.Bytes(), data, "the chunks are joined in order")
+
+	_, err = client.DownloadMatchReplay(context.Background(), session, "replays", "", "m2")
+	assert.ErrorIs(t, err, ErrMatchReplayNotFound)
+}
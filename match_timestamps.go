@@ -0,0 +1,72 @@
+package nakama
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// TimestampHeaderSize is the size of the header put before the data of the time-sensitive op codes:
+// a version byte, then the local send time and the offset of the ServerClock in microseconds,
+// both int64 big-endian, so the server adds them to get the send time in its own clock.
+const TimestampHeaderSize = 17
+
+// timestampVersion is the version byte of the header.
+const timestampVersion = 1
+
+// TimestampedData is the data of a time-sensitive op code, see SetTimeSensitiveOpCodes.
+type TimestampedData struct {
+	SentAt time.Time     // the local time of the sender when it sent the data
+	Offset time.Duration // the estimated server time minus the local time of the sender, 0 if its clock wasn't synced
+	Data   []byte        // the data without the header
+}
+
+// ServerSentAt returns the send time in the server clock, e.g. to rewind the state of the match for the lag compensation.
+func (d TimestampedData) ServerSentAt() time.Time {
+	return d.SentAt.Add(d.Offset)
+}
+
+// ParseTimestampedData returns the header and the data of a time-sensitive op code, the data isn't copied.
+func ParseTimestampedData(data []byte) (TimestampedData, error) {
+	if len(data) < TimestampHeaderSize || data[0] != timestampVersion {
+		return TimestampedData{}, newError("no timestamp header").With(len(data))
+	}
+	sentAt := int64(binary.BigEndian.Uint64(data[1:9]))
+	offset := int64(binary.BigEndian.Uint64(data[9:17]))
+	return TimestampedData{
+		SentAt: time.UnixMicro(sentAt),
+		Offset: time.Duration(offset) * time.Microsecond,
+		Data:   data[TimestampHeaderSize:],
+	}, nil
+}
+
+// SetTimeSensitiveOpCodes puts a timestamp header before the data of the match op codes sent by SendMatchState,
+// with the local send time and the offset of the server clock, see ParseTimestampedData. The header is kept
+// on the data received, the match handler of the server and the other players parse it. No op code turns it off.
+func (socket *DefaultSocket) SetTimeSensitiveOpCodes(opCodes ...int64) {
+	if len(opCodes) == 0 {
+		socket.timeSensitive.Store(nil)
+		return
+	}
+	set := make(map[int64]bool, len(opCodes))
+	for _, opCode := range opCodes {
+		set[opCode] = true
+	}
+	socket.timeSensitive.Store(&set)
+}
+
+// timestamp returns the data with the timestamp header when the op code is time-sensitive, the data otherwise.
+func (socket *DefaultSocket) timestamp(opCode int64, data []byte) []byte {
+	set := socket.timeSensitive.Load()
+	if set == nil || !(*set)[opCode] {
+		return data
+	}
+	var offset time.Duration
+	if socket.clock != nil {
+		offset = socket.clock.Offset()
+	}
+	stamped := make([]byte, TimestampHeaderSize, TimestampHeaderSize+len(data))
+	stamped[0] = timestampVersion
+	binary.BigEndian.PutUint64(stamped[1:9], uint64(time.Now().UnixMicro()))
+	binary.BigEndian.PutUint64(stamped[9:17], uint64(offset.Microseconds()))
+	return append(stamped, data...)
+}
@@ -0,0 +1,35 @@
+package nakama
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeSensitiveOpCodes(t *testing.T) {
+	server := newScriptedServer(t, nil)
+	socket, _ := server.socket(nil)
+	clock := NewServerClock()
+	now := time.Now()
+	clock.Observe(now.Add(2*time.Second), now, now)
+	socket.SetServerClock(clock)
+	socket.SetTimeSensitiveOpCodes(5)
+	assert.NoError(t, socket.Connect())
+
+	before := time.Now().Truncate(time.Microsecond)
+	assert.NoError(t, socket.SendMatchState("m1", 5, []byte("shot"), nil, false))
+	assert.NoError(t, socket.SendMatchState("m1", 6, []byte("chat"), nil, false))
+	eventually(t, func() bool { return len(server.requestsOf("match_data_send")) == 2 }, "the match data have not been sent")
+
+	sends := server.requestsOf("match_data_send")
+	stamped, err := ParseTimestampedData(sends[0].GetMatchDataSend().GetData())
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("shot"), stamped.Data)
+	assert.False(t, stamped.SentAt.Before(before))
+	assert.Equal(t, 2*time.Second, stamped.Offset)
+	assert.Equal(t, stamped.SentAt.Add(2*time.Second), stamped.ServerSentAt())
+	assert.Equal(t, []byte("chat"), sends[1].GetMatchDataSend().GetData(), "the other op codes are sent as they are")
+	_, err = ParseTimestampedData([]byte("chat"))
+	assert.Error(t, err)
+}
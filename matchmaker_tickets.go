@@ -0,0 +1,127 @@
+package nakama
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// DefaultCancelTicketTimeoutMs is the timeout of each ticket removal sent by Disconnect.
+const DefaultCancelTicketTimeoutMs = 2000
+
+// MaxMatchmakerCount is the most players of a match the server matchmaker accepts.
+const MaxMatchmakerCount = 100
+
+// checkMatchmakerCounts checks the counts of a matchmaker ticket like the server, before the network.
+func checkMatchmakerCounts(minCount, maxCount int32, countMultiple *int32) error {
+	switch {
+	case minCount < 2:
+		return newError("'minCount' must be at least 2").With(minCount)
+	case maxCount > MaxMatchmakerCount:
+		return newError("'maxCount' must be at most MaxMatchmakerCount").With(maxCount)
+	case minCount > maxCount:
+		return newError("'minCount' must not be greater than 'maxCount'").With(minCount, maxCount)
+	case countMultiple != nil && (*countMultiple < 1 || *countMultiple > maxCount):
+		return newError("'countMultiple' must be between 1 and 'maxCount'").With(*countMultiple, maxCount)
+	}
+	return nil
+}
+
+// ActiveTicket is a matchmaker ticket of the socket waiting for a match.
+type ActiveTicket struct {
+	Ticket  string
+	PartyId string // set for the tickets of a party
+	AddedAt time.Time
+}
+
+// matchmakerTickets tracks the matchmaker tickets of a socket until they're matched or removed.
+type matchmakerTickets struct {
+	mu      sync.Mutex
+	tickets map[string]ActiveTicket
+}
+
+func (mt *matchmakerTickets) add(ticket, partyId string) {
+	if ticket == "" {
+		return
+	}
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	if mt.tickets == nil {
+		mt.tickets = map[string]ActiveTicket{}
+	}
+	mt.tickets[ticket] = ActiveTicket{Ticket: ticket, PartyId: partyId, AddedAt: time.Now()}
+}
+
+func (mt *matchmakerTickets) remove(ticket string) {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	delete(mt.tickets, ticket)
+}
+
+// clear forgets the tickets and returns them.
+func (mt *matchmakerTickets) clear() []ActiveTicket {
+	mt.mu.Lock()
+	tickets := mt.tickets
+	mt.tickets = nil
+	mt.mu.Unlock()
+	return sortedTickets(tickets)
+}
+
+func (mt *matchmakerTickets) list() []ActiveTicket {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	return sortedTickets(mt.tickets)
+}
+
+// observe forgets the ticket of a match found.
+func (mt *matchmakerTickets) observe(envelope *rtapi.Envelope) {
+	if matched := envelope.GetMatchmakerMatched(); matched != nil {
+		mt.remove(matched.GetTicket())
+	}
+}
+
+// sortedTickets returns the tickets, the oldest first.
+func sortedTickets(tickets map[string]ActiveTicket) []ActiveTicket {
+	list := make([]ActiveTicket, 0, len(tickets))
+	for _, ticket := range tickets {
+		list = append(list, ticket)
+	}
+	slices.SortFunc(list, func(a, b ActiveTicket) int { return a.AddedAt.Compare(b.AddedAt) })
+	return list
+}
+
+// ActiveTickets returns the matchmaker tickets added by the socket and not matched or removed yet, the oldest first.
+// The server drops the tickets of a session when it disconnects, so they're forgotten on disconnect.
+func (socket *DefaultSocket) ActiveTickets() []ActiveTicket {
+	return socket.tickets.list()
+}
+
+// SetCancelTicketsOnDisconnect makes Disconnect remove the active tickets before closing the connection,
+// e.g. so the party tickets don't match a player who has quit the queue screen.
+func (socket *DefaultSocket) SetCancelTicketsOnDisconnect(on bool) {
+	socket.cancelTickets.Store(on)
+}
+
+// cancelActiveTickets removes the active tickets from the matchmaker, the errors are logged only.
+func (socket *DefaultSocket) cancelActiveTickets() {
+	timeout := DefaultCancelTicketTimeoutMs
+	for _, ticket := range socket.tickets.list() {
+		var req *rtapi.Envelope
+		if ticket.PartyId != "" {
+			req = &rtapi.Envelope{Message: &rtapi.Envelope_PartyMatchmakerRemove{
+				PartyMatchmakerRemove: &rtapi.PartyMatchmakerRemove{PartyId: ticket.PartyId, Ticket: ticket.Ticket},
+			}}
+		} else {
+			req = &rtapi.Envelope{Message: &rtapi.Envelope_MatchmakerRemove{
+				MatchmakerRemove: &rtapi.MatchmakerRemove{Ticket: ticket.Ticket},
+			}}
+		}
+		if err, ok := socket.Send(req, &timeout).(error); ok {
+			GetLogger().Warnf("cancel ticket %s: %s", ticket.Ticket, err.Error())
+			continue
+		}
+		socket.tickets.remove(ticket.Ticket)
+	}
+}
@@ -0,0 +1,117 @@
+package nakama
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestMatchmakerTickets(t *testing.T) {
+	removed := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		for {
+			_, data, err := conn.Read(r.Context())
+			if err != nil {
+				return
+			}
+			req := &rtapi.Envelope{}
+			if err := protojson.Unmarshal(data, req); err != nil {
+				return
+			}
+			rsp := &rtapi.Envelope{Cid: req.Cid}
+			switch {
+			case req.GetMatchmakerAdd() != nil:
+				rsp.Message = &rtapi.Envelope_MatchmakerTicket{MatchmakerTicket: &rtapi.MatchmakerTicket{Ticket: "solo"}}
+			case req.GetPartyMatchmakerAdd() != nil:
+				rsp.Message = &rtapi.Envelope_PartyMatchmakerTicket{PartyMatchmakerTicket: &rtapi.PartyMatchmakerTicket{
+					PartyId: req.GetPartyMatchmakerAdd().GetPartyId(), Ticket: "party",
+				}}
+			case req.GetMatchmakerRemove() != nil:
+				removed <- req.GetMatchmakerRemove().GetTicket()
+			case req.GetPartyMatchmakerRemove() != nil:
+				removed <- req.GetPartyMatchmakerRemove().GetTicket()
+			}
+			data, _ = protojson.Marshal(rsp)
+			conn.Write(r.Context(), websocket.MessageText, data)
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+	socket := NewDefaultSocket(nil, host, port, "token", false, false, nil, nil)
+	assert.NoError(t, socket.EnableExperimental(FeatureParties))
+	assert.NoError(t, socket.Connect())
+
+	ticket, err := socket.AddMatchmaker("*", 2, 4, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "solo", ticket.GetTicket())
+	_, err = socket.AddMatchmakerParty("party-1", "*", 2, 4, nil, nil, nil)
+	assert.NoError(t, err)
+	tickets := socket.ActiveTickets()
+	assert.Len(t, tickets, 2)
+	assert.Equal(t, "party-1", tickets[1].PartyId)
+
+	// the match found consumes the ticket
+	socket.handleMessage(int(websocket.MessageText), []byte(`{"matchmaker_matched":{"ticket":"solo","match_id":"match"}}`))
+	assert.Equal(t, []string{"party"}, ticketIds(socket.ActiveTickets()))
+
+	socket.SetCancelTicketsOnDisconnect(true)
+	socket.Disconnect()
+	select {
+	case ticket := <-removed:
+		assert.Equal(t, "party", ticket)
+	case <-time.After(time.Second):
+		t.Fatal("the party ticket has not been removed")
+	}
+	assert.Empty(t, socket.ActiveTickets())
+}
+
+func ticketIds(tickets []ActiveTicket) []string {
+	ids := []string{}
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.Ticket)
+	}
+	return ids
+}
+
+func TestMatchmakerCounts(t *testing.T) {
+	multiple := int32(3)
+	assert.NoError(t, checkMatchmakerCounts(2, 2, nil))
+	assert.NoError(t, checkMatchmakerCounts(2, 6, &multiple))
+	assert.Error(t, checkMatchmakerCounts(1, 4, nil))
+	assert.Error(t, checkMatchmakerCounts(4, 2, nil))
+	assert.Error(t, checkMatchmakerCounts(2, MaxMatchmakerCount+1, nil))
+	multiple = 0
+	assert.Error(t, checkMatchmakerCounts(2, 4, &multiple))
+
+	// rejected before the network
+	server := newScriptedServer(t, nil)
+	socket, _ := server.socket(nil)
+	assert.NoError(t, socket.Connect())
+	_, err := socket.AddMatchmaker("*", 1, 4, nil, nil, nil)
+	assert.Error(t, err)
+	assert.Error(t, socket.RemoveMatchmaker(""))
+
+	// the match found is received by the callback
+	matched := make(chan *rtapi.MatchmakerMatched, 1)
+	socket.OnMatchmakerMatched(func(m *rtapi.MatchmakerMatched) { matched <- m })
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_MatchmakerMatched{MatchmakerMatched: &rtapi.MatchmakerMatched{
+		Ticket: "t1", Id: &rtapi.MatchmakerMatched_Token{Token: "token"},
+	}}})
+	assert.Equal(t, "token", (<-matched).GetToken())
+	assert.Empty(t, server.requestsOf("matchmaker_add"))
+}
@@ -0,0 +1,43 @@
+package nakama
+
+import (
+	"net/http"
+	"slices"
+)
+
+// RoundTripFunc sends a http request of the api client and returns its response.
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps the sending of the http requests of the api client, e.g. to log, to measure, to add headers
+// or to sign the requests. It runs for each attempt of a call, the context of the request carries the session,
+// the endpoint and the attempt, see SessionFromContext. A middleware returning a response must not close its body.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// Use adds middlewares to the calls of the api client, the first added is the outermost.
+// It's not safe to call concurrently with the calls, add the middlewares before.
+func (napi *NakamaApi) Use(middlewares ...Middleware) {
+	// the copies of WithContext keep their own chain
+	napi.middlewares = append(slices.Clip(napi.middlewares), middlewares...)
+}
+
+// roundTrip sends the request through the middlewares and the http client.
+func (napi *NakamaApi) roundTrip(client *http.Client, req *http.Request) (*http.Response, error) {
+	send := RoundTripFunc(client.Do)
+	for i := len(napi.middlewares) - 1; i >= 0; i-- {
+		send = napi.middlewares[i](send)
+	}
+	return send(req)
+}
+
+// WithMiddleware adds middlewares to the http calls of the client, see NakamaApi.Use.
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(opts *ClientOptions) error {
+		for _, middleware := range middlewares {
+			if middleware == nil {
+				return newError("nil middleware")
+			}
+		}
+		opts.Middlewares = append(opts.Middlewares, middlewares...)
+		return nil
+	}
+}
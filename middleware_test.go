@@ -0,0 +1,58 @@
+package nakama
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	headers := make(chan http.Header, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	order := []string{}
+	trace := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name+" "+EndpointFromContext(req.Context()))
+				req.Header.Set("X-"+name, "1")
+				return next(req)
+			}
+		}
+	}
+	client, err := NewClientWithOptions(WithURL(server.URL), WithMiddleware(trace("Outer"), trace("Inner")))
+	assert.NoError(t, err)
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+	_, err = client.GetAccount(session)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Outer GetAccount", "Inner GetAccount"}, order)
+	header := <-headers
+	assert.Equal(t, "1", header.Get("X-Outer"))
+	assert.Equal(t, "1", header.Get("X-Inner"))
+
+	// a middleware can answer without the network, the copies of WithContext keep their chain
+	order = nil
+	scoped := client.WithContext(context.Background())
+	scoped.ApiClient.(*NakamaApi).Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return nil, newError("offline")
+		}
+	})
+	_, err = scoped.GetAccount(session)
+	assert.ErrorContains(t, err, "offline")
+	assert.Len(t, headers, 0)
+	_, err = client.GetAccount(session)
+	assert.NoError(t, err)
+	assert.Len(t, headers, 1)
+
+	_, err = NewClientWithOptions(WithMiddleware(nil))
+	assert.Error(t, err)
+}
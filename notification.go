@@ -0,0 +1,117 @@
+package nakama
+
+import (
+	"encoding/json"
+	"sync"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultNotificationSendRpcId is the rpc id used by SendNotification when none is set.
+const DefaultNotificationSendRpcId = "notification_send"
+
+// NotificationHandler handles a notification received from the server or echoed locally.
+type NotificationHandler func(notification *api.Notification)
+
+// NotificationCenter dispatches the notifications to the registered handlers.
+type NotificationCenter struct {
+	mu       sync.RWMutex
+	handlers []NotificationHandler
+}
+
+// NewNotificationCenter creates an empty NotificationCenter.
+func NewNotificationCenter() *NotificationCenter {
+	return &NotificationCenter{}
+}
+
+// Subscribe registers a handler for the incoming notifications.
+func (nc *NotificationCenter) Subscribe(handler NotificationHandler) {
+	if handler == nil {
+		return
+	}
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	nc.handlers = append(nc.handlers, handler)
+}
+
+// Dispatch delivers the notifications to all the handlers.
+func (nc *NotificationCenter) Dispatch(notifications ...*api.Notification) {
+	nc.mu.RLock()
+	handlers := nc.handlers
+	nc.mu.RUnlock()
+
+	for _, n := range notifications {
+		for _, handler := range handlers {
+			handler(n)
+		}
+	}
+}
+
+// HandleEvent is an EventHandler picking the notifications out of the socket messages,
+// chain it in the EventHandler passed to CreateSocket.
+func (nc *NotificationCenter) HandleEvent(event EventType, data *RspResult) {
+	if event != EventTypeMessage || data == nil || data.Decoded == nil {
+		return
+	}
+	msg, ok := data.Decoded.GetMessage().(*rtapi.Envelope_Notifications)
+	if !ok {
+		return
+	}
+	nc.Dispatch(msg.Notifications.GetNotifications()...)
+}
+
+// OutgoingNotification is the payload sent to the notification rpc.
+// The server side rpc is expected to check the permission and call nk.NotificationsSend with the fields.
+type OutgoingNotification struct {
+	UserIds    []string    `json:"user_ids"`
+	Subject    string      `json:"subject"`
+	Content    interface{} `json:"content"`
+	Code       int32       `json:"code"`
+	Persistent bool        `json:"persistent"`
+}
+
+// SendNotification sends a notification to other users through the rpc registered on the server.
+// rpcId defaults to DefaultNotificationSendRpcId when empty.
+// If echo is not nil, a copy of the notification is dispatched to it once the rpc succeeds.
+func (c *Client) SendNotification(session *Session, rpcId string, notification *OutgoingNotification, echo *NotificationCenter) (*api.Rpc, error) {
+	if notification == nil {
+		return nil, newError("'notification' is a required parameter but is null")
+	}
+	if len(notification.UserIds) == 0 {
+		return nil, newError("'notification.UserIds' is a required parameter but is empty")
+	}
+	if rpcId == "" {
+		rpcId = DefaultNotificationSendRpcId
+	}
+	if err := c.refreshSession(session); err != nil {
+		return nil, wrapErr(err)
+	}
+
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	content, err := json.Marshal(notification.Content)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	result, err := c.apiFor(session).RpcFunc(session.Token, rpcId, string(payload), "", make(map[string]string))
+	if err != nil {
+		return nil, wrapErr(err, rpcId)
+	}
+
+	if echo != nil {
+		echo.Dispatch(&api.Notification{
+			Subject:    notification.Subject,
+			Content:    string(content),
+			Code:       notification.Code,
+			SenderId:   session.UserID,
+			CreateTime: timestamppb.Now(),
+			Persistent: notification.Persistent,
+		})
+	}
+	return result, nil
+}
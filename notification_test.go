@@ -0,0 +1,81 @@
+package nakama
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestSendNotification(t *testing.T) {
+	var sent *OutgoingNotification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/rpc/"+DefaultNotificationSendRpcId {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		payload := ""
+		json.Unmarshal(body, &payload)
+		sent = &OutgoingNotification{}
+		assert.NoError(t, json.Unmarshal([]byte(payload), sent))
+		data, _ := protojson.Marshal(&api.Rpc{Id: DefaultNotificationSendRpcId})
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+	echo := NewNotificationCenter()
+	echoed := []*api.Notification{}
+	echo.Subscribe(func(notification *api.Notification) {
+		echoed = append(echoed, notification)
+	})
+
+	_, err = client.SendNotification(session, "", nil, echo)
+	assert.Error(t, err)
+	_, err = client.SendNotification(session, "", &OutgoingNotification{Subject: "gift"}, echo)
+	assert.Error(t, err, "no user")
+
+	notification := &OutgoingNotification{UserIds: []string{"u1"}, Subject: "gift", Content: map[string]int{"coins": 10}, Code: 101}
+	_, err = client.SendNotification(session, "", notification, echo)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, sent.UserIds)
+	assert.Equal(t, map[string]any{"coins": float64(10)}, sent.Content)
+	assert.Len(t, echoed, 1)
+	assert.Equal(t, "gift", echoed[0].Subject)
+	assert.Equal(t, `{"coins":10}`, echoed[0].Content)
+	assert.Equal(t, int32(101), echoed[0].Code)
+	assert.Equal(t, session.UserID, echoed[0].SenderId)
+
+	// a notification refused by the rpc isn't echoed
+	_, err = client.SendNotification(session, "denied", notification, echo)
+	assert.Error(t, err)
+	assert.Len(t, echoed, 1)
+}
+
+func TestNotificationCenterHandleEvent(t *testing.T) {
+	center := NewNotificationCenter()
+	subjects := []string{}
+	center.Subscribe(func(notification *api.Notification) {
+		subjects = append(subjects, notification.Subject)
+	})
+	center.Subscribe(nil)
+
+	center.HandleEvent(EventTypeMessage, &RspResult{Decoded: &rtapi.Envelope{Message: &rtapi.Envelope_Notifications{
+		Notifications: &rtapi.Notifications{Notifications: []*api.Notification{{Subject: "a"}, {Subject: "b"}}},
+	}}})
+	center.HandleEvent(EventTypeMessage, &RspResult{Decoded: &rtapi.Envelope{Message: &rtapi.Envelope_Pong{Pong: &rtapi.Pong{}}}})
+	center.HandleEvent(EventTypeConnected, nil)
+	assert.Equal(t, []string{"a", "b"}, subjects)
+}
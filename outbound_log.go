@@ -0,0 +1,343 @@
+package nakama
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// OutboundLogFile is the name of the current file of an OutboundLog, the rotated ones get a .1, .2... suffix.
+	OutboundLogFile = "outbound.log"
+
+	DefaultOutboundLogMaxBytes  = 1 << 20
+	DefaultOutboundLogMaxFiles  = 3
+	DefaultOutboundLogQueueSize = 4096
+)
+
+// DefaultRedactedFields are the fields redacted by an OutboundLog when OutboundLogOptions.RedactFields is nil.
+var DefaultRedactedFields = []string{"token", "http_key"}
+
+// redactedValue replaces the values of the redacted fields.
+const redactedValue = "REDACTED"
+
+// OutboundLogOptions configures an OutboundLog.
+type OutboundLogOptions struct {
+	Dir      string // the directory of the log files, created if missing
+	MaxBytes int64  // the size rotating the current file, 0 uses DefaultOutboundLogMaxBytes
+	MaxFiles int    // the count of rotated files kept, 0 uses DefaultOutboundLogMaxFiles
+	// QueueSize is the count of entries waiting to be written, the entries past it are dropped,
+	// 0 uses DefaultOutboundLogQueueSize.
+	QueueSize int
+
+	// RedactFields are the proto names of the fields redacted at any depth of the envelopes, e.g. "content".
+	// nil uses DefaultRedactedFields, an empty slice redacts nothing.
+	RedactFields []string
+	// Redact is an extra rule run on a copy of each envelope before it's logged, it may be nil.
+	Redact func(envelope *rtapi.Envelope)
+}
+
+// OutboundLogEntry is an envelope logged by an OutboundLog.
+type OutboundLogEntry struct {
+	Time     time.Time       `json:"time"`
+	Cid      string          `json:"cid,omitempty"`
+	Type     string          `json:"type"` // e.g. "match_data_send"
+	Envelope json.RawMessage `json:"envelope"`
+}
+
+// OutboundLog writes the envelopes sent by a socket to disk, one JSON entry per line,
+// to reproduce the desyncs of the authoritative matches after a crash. See DefaultSocket.SetOutboundLog.
+// The entries are queued by the send and written by a goroutine of the log until Close,
+// the files are synced when they rotate and on Close. Only the last MaxFiles+1 files are kept.
+type OutboundLog struct {
+	mu     sync.Mutex // the files
+	opts   OutboundLogOptions
+	redact map[protoreflect.Name]bool
+	file   *os.File
+	size   int64
+
+	queueMu sync.Mutex
+	queue   [][]byte // the lines appended, not written yet
+	dropped int64
+	closed  bool
+	wake    chan struct{} // wakes the writer
+	stop    chan struct{} // closed by Close
+	stopped chan struct{} // closed when the writer has returned
+}
+
+// NewOutboundLog opens the log of the directory, appending to its current file.
+func NewOutboundLog(opts OutboundLogOptions) (*OutboundLog, error) {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultOutboundLogMaxBytes
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = DefaultOutboundLogMaxFiles
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultOutboundLogQueueSize
+	}
+	if opts.RedactFields == nil {
+		opts.RedactFields = DefaultRedactedFields
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, wrapErr(err, opts.Dir)
+	}
+	l := &OutboundLog{
+		opts:    opts,
+		redact:  map[protoreflect.Name]bool{},
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	for _, name := range opts.RedactFields {
+		l.redact[protoreflect.Name(name)] = true
+	}
+	if err := l.open(); err != nil {
+		return nil, wrapErr(err)
+	}
+	go l.write()
+	return l, nil
+}
+
+// write writes the lines queued until Close.
+func (l *OutboundLog) write() {
+	defer close(l.stopped)
+	for {
+		select {
+		case <-l.wake:
+			if err := l.Flush(); err != nil {
+				GetLogger().Warnf("outbound log: %s", err.Error())
+			}
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// path returns the path of the file, 0 is the current one.
+func (l *OutboundLog) path(i int) string {
+	name := OutboundLogFile
+	if i > 0 {
+		name += "." + strconv.Itoa(i)
+	}
+	return filepath.Join(l.opts.Dir, name)
+}
+
+func (l *OutboundLog) open() error {
+	file, err := os.OpenFile(l.path(0), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return wrapErr(err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return wrapErr(err)
+	}
+	l.file, l.size = file, info.Size()
+	return nil
+}
+
+// rotate shifts the files by one and opens a new current file, the oldest file is removed.
+func (l *OutboundLog) rotate() error {
+	if err := l.file.Sync(); err != nil {
+		return wrapErr(err)
+	}
+	if err := l.file.Close(); err != nil {
+		return wrapErr(err)
+	}
+	os.Remove(l.path(l.opts.MaxFiles))
+	for i := l.opts.MaxFiles - 1; i >= 0; i-- {
+		if err := os.Rename(l.path(i), l.path(i+1)); err != nil && !os.IsNotExist(err) {
+			return wrapErr(err)
+		}
+	}
+	return l.open()
+}
+
+// redactMessage redacts the fields of the message and its sub-messages.
+func (l *OutboundLog) redactMessage(msg protoreflect.Message) {
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case l.redact[fd.Name()] && !fd.IsList() && !fd.IsMap() && fd.Kind() == protoreflect.StringKind:
+			msg.Set(fd, protoreflect.ValueOfString(redactedValue))
+		case l.redact[fd.Name()] && !fd.IsList() && !fd.IsMap() && fd.Kind() == protoreflect.BytesKind:
+			msg.Set(fd, protoreflect.ValueOfBytes([]byte(redactedValue)))
+		case l.redact[fd.Name()]:
+			msg.Clear(fd)
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				l.redactMessage(list.Get(i).Message())
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				l.redactMessage(mv.Message())
+				return true
+			})
+		case !fd.IsMap() && fd.Message() != nil:
+			l.redactMessage(v.Message())
+		}
+		return true
+	})
+}
+
+// Append queues the envelope, redacted, for the writer of the log. The envelope itself is left unchanged.
+// It doesn't wait for the disk, the entries past OutboundLogOptions.QueueSize are dropped, see Dropped.
+func (l *OutboundLog) Append(envelope *rtapi.Envelope) error {
+	if l == nil || envelope == nil {
+		return nil
+	}
+	clone := proto.Clone(envelope).(*rtapi.Envelope)
+	l.redactMessage(clone.ProtoReflect())
+	if l.opts.Redact != nil {
+		l.opts.Redact(clone)
+	}
+	data, err := protojson.Marshal(clone)
+	if err != nil {
+		return wrapErr(err)
+	}
+	line, err := json.Marshal(&OutboundLogEntry{
+		Time:     time.Now(),
+		Cid:      envelope.Cid,
+		Type:     envelopeType(envelope),
+		Envelope: data,
+	})
+	if err != nil {
+		return wrapErr(err)
+	}
+	line = append(line, '\n')
+
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+	if l.closed {
+		return newError("outbound log closed").With(l.opts.Dir)
+	}
+	if len(l.queue) >= l.opts.QueueSize {
+		l.dropped++
+		return nil
+	}
+	l.queue = append(l.queue, line)
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Dropped returns the count of entries dropped because the queue was full.
+func (l *OutboundLog) Dropped() int64 {
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+	return l.dropped
+}
+
+// Flush writes the entries queued.
+func (l *OutboundLog) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.flushLocked()
+}
+
+func (l *OutboundLog) flushLocked() error {
+	l.queueMu.Lock()
+	lines := l.queue
+	l.queue = nil
+	l.queueMu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	for _, line := range lines {
+		if l.size > 0 && l.size+int64(len(line)) > l.opts.MaxBytes {
+			if err := l.rotate(); err != nil {
+				return wrapErr(err, l.opts.Dir)
+			}
+		}
+		n, err := l.file.Write(line)
+		l.size += int64(n)
+		if err != nil {
+			return wrapErr(err, l.opts.Dir)
+		}
+	}
+	return nil
+}
+
+// Dump writes the lines of the log kept, the oldest first, the entries queued are written before.
+func (l *OutboundLog) Dump(w io.Writer) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.flushLocked(); err != nil {
+		return wrapErr(err)
+	}
+	for i := l.opts.MaxFiles; i >= 0; i-- {
+		file, err := os.Open(l.path(i))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return wrapErr(err)
+		}
+		_, err = io.Copy(w, file)
+		file.Close()
+		if err != nil {
+			return wrapErr(err)
+		}
+	}
+	return nil
+}
+
+// Entries returns the entries of the log kept, the oldest first.
+// A line torn by a crash is skipped.
+func (l *OutboundLog) Entries() ([]OutboundLogEntry, error) {
+	buf := &bytes.Buffer{}
+	if err := l.Dump(buf); err != nil {
+		return nil, wrapErr(err)
+	}
+	entries := []OutboundLogEntry{}
+	for {
+		line, err := buf.ReadBytes('\n')
+		entry := OutboundLogEntry{}
+		if len(line) > 0 && json.Unmarshal(line, &entry) == nil {
+			entries = append(entries, entry)
+		}
+		if err != nil {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// Close writes the entries queued, syncs and closes the current file and stops the writer,
+// the entries can still be dumped.
+func (l *OutboundLog) Close() error {
+	l.queueMu.Lock()
+	closed := l.closed
+	l.closed = true
+	l.queueMu.Unlock()
+	if closed {
+		return nil
+	}
+	close(l.stop)
+	<-l.stopped
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	err := l.flushLocked()
+	if syncErr := l.file.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return wrapErr(err)
+}
@@ -0,0 +1,85 @@
+package nakama
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestOutboundLog(t *testing.T) {
+	outbound, err := NewOutboundLog(OutboundLogOptions{Dir: t.TempDir(), MaxBytes: 300, MaxFiles: 1})
+	assert.NoError(t, err)
+	defer outbound.Close()
+
+	join := &rtapi.Envelope{Cid: "1", Message: &rtapi.Envelope_MatchJoin{MatchJoin: &rtapi.MatchJoin{
+		Id: &rtapi.MatchJoin_Token{Token: "secret"},
+	}}}
+	assert.NoError(t, outbound.Append(join))
+	assert.Equal(t, "secret", join.GetMatchJoin().GetToken(), "the envelope sent is unchanged")
+
+	for i := range 5 {
+		assert.NoError(t, outbound.Append(&rtapi.Envelope{Message: &rtapi.Envelope_MatchDataSend{MatchDataSend: &rtapi.MatchDataSend{
+			MatchId: "match", OpCode: int64(i), Data: []byte("state"),
+		}}}))
+	}
+
+	entries, err := outbound.Entries()
+	assert.NoError(t, err)
+	assert.Less(t, len(entries), 6, "the oldest file is removed")
+	last := &rtapi.Envelope{}
+	assert.NoError(t, protojson.Unmarshal(entries[len(entries)-1].Envelope, last))
+	assert.Equal(t, "match_data_send", entries[len(entries)-1].Type)
+	assert.Equal(t, int64(4), last.GetMatchDataSend().GetOpCode())
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, outbound.Dump(buf))
+	assert.Equal(t, len(entries), strings.Count(buf.String(), "\n"))
+}
+
+func TestOutboundLogRedact(t *testing.T) {
+	outbound, err := NewOutboundLog(OutboundLogOptions{Dir: t.TempDir(), RedactFields: []string{"token", "content"}})
+	assert.NoError(t, err)
+	defer outbound.Close()
+
+	assert.NoError(t, outbound.Append(&rtapi.Envelope{Message: &rtapi.Envelope_MatchJoin{MatchJoin: &rtapi.MatchJoin{
+		Id: &rtapi.MatchJoin_Token{Token: "secret"},
+	}}}))
+	assert.NoError(t, outbound.Append(&rtapi.Envelope{Message: &rtapi.Envelope_ChannelMessageSend{ChannelMessageSend: &rtapi.ChannelMessageSend{
+		ChannelId: "channel", Content: `{"msg":"hi"}`,
+	}}}))
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, outbound.Dump(buf))
+	assert.NotContains(t, buf.String(), "secret")
+	assert.NotContains(t, buf.String(), "hi")
+	assert.Contains(t, buf.String(), "channel")
+	assert.Equal(t, 2, strings.Count(buf.String(), redactedValue))
+}
+
+func TestOutboundLogQueue(t *testing.T) {
+	outbound, err := NewOutboundLog(OutboundLogOptions{Dir: t.TempDir(), QueueSize: 2})
+	assert.NoError(t, err)
+	ping := &rtapi.Envelope{Message: &rtapi.Envelope_Ping{Ping: &rtapi.Ping{}}}
+
+	// the appends don't wait for the files, the entries past the queue are dropped
+	outbound.mu.Lock()
+	for range 3 {
+		assert.NoError(t, outbound.Append(ping))
+	}
+	outbound.mu.Unlock()
+	assert.Equal(t, int64(1), outbound.Dropped())
+
+	// Close writes the entries queued
+	assert.NoError(t, outbound.Flush())
+	assert.NoError(t, outbound.Append(ping))
+	assert.NoError(t, outbound.Close())
+	assert.NoError(t, outbound.Close())
+	assert.Error(t, outbound.Append(ping))
+	entries, err := outbound.Entries()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
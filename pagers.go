@@ -0,0 +1,214 @@
+package nakama
+
+import (
+	"context"
+	"iter"
+
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// DefaultPageSize is the limit of the pages fetched by the iterators.
+const DefaultPageSize = 100
+
+// paginate yields the items of the pages returned by fetch until the cursor is empty,
+// the iteration stops after yielding an error. The iterators fetch with c.WithContext(ctx), canceling ctx aborts the page in flight.
+func paginate[T any](ctx context.Context, fetch func(cursor string) ([]T, string, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		cursor := ""
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, wrapErr(err))
+				return
+			}
+			items, next, err := fetch(cursor)
+			if err != nil {
+				yield(zero, wrapErr(err))
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if next == "" || next == cursor || len(items) == 0 {
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+func optionalString(cursor string) *string {
+	if cursor == "" {
+		return nil
+	}
+	return &cursor
+}
+
+// LeaderboardRecords iterates over all the records of a leaderboard, e.g.
+//
+//	for record, err := range client.LeaderboardRecords(ctx, session, "weekly") {
+//	}
+func (c *Client) LeaderboardRecords(ctx context.Context, session *Session, leaderboardId string) iter.Seq2[*api.LeaderboardRecord, error] {
+	c = c.WithContext(ctx)
+	limit := DefaultPageSize
+	return paginate(ctx, func(cursor string) ([]*api.LeaderboardRecord, string, error) {
+		list, err := c.ListLeaderboardRecords(session, leaderboardId, nil, &limit, optionalString(cursor), nil)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Records, list.NextCursor, nil
+	})
+}
+
+// TournamentRecords iterates over all the records of a tournament.
+func (c *Client) TournamentRecords(ctx context.Context, session *Session, tournamentId string) iter.Seq2[*api.LeaderboardRecord, error] {
+	c = c.WithContext(ctx)
+	return paginate(ctx, func(cursor string) ([]*api.LeaderboardRecord, string, error) {
+		list, err := c.ListTournamentRecords(session, tournamentId, nil, DefaultPageSize, cursor, "")
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Records, list.NextCursor, nil
+	})
+}
+
+// Tournaments iterates over the current and upcoming tournaments in the categories.
+func (c *Client) Tournaments(ctx context.Context, session *Session, categoryStart *int, categoryEnd *int) iter.Seq2[*api.Tournament, error] {
+	c = c.WithContext(ctx)
+	return paginate(ctx, func(cursor string) ([]*api.Tournament, string, error) {
+		list, err := c.ListTournaments(session, categoryStart, categoryEnd, nil, nil, DefaultPageSize, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Tournaments, list.Cursor, nil
+	})
+}
+
+// Friends iterates over the friends of the current user, state filters them when not nil.
+func (c *Client) Friends(ctx context.Context, session *Session, state *int) iter.Seq2[*api.Friend, error] {
+	c = c.WithContext(ctx)
+	limit := DefaultPageSize
+	return paginate(ctx, func(cursor string) ([]*api.Friend, string, error) {
+		list, err := c.ListFriends(session, state, &limit, optionalString(cursor))
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Friends, list.Cursor, nil
+	})
+}
+
+// FriendsOfFriends iterates over the friends of friends of the current user,
+// a user friend of several friends is yielded once per friend. It yields nothing on the servers without CapabilityFriendsOfFriends.
+func (c *Client) FriendsOfFriends(ctx context.Context, session *Session) iter.Seq2[*FriendOfFriend, error] {
+	c = c.WithContext(ctx)
+	limit := DefaultPageSize
+	return paginate(ctx, func(cursor string) ([]*FriendOfFriend, string, error) {
+		list, err := c.ListFriendsOfFriends(session, &limit, optionalString(cursor))
+		if err != nil {
+			return nil, "", err
+		}
+		return list.GetFriendsOfFriends(), list.GetCursor(), nil
+	})
+}
+
+// Groups iterates over the groups matching the name filter and the query options.
+func (c *Client) Groups(ctx context.Context, session *Session, name *string, opts ...GroupQueryOption) iter.Seq2[*api.Group, error] {
+	c = c.WithContext(ctx)
+	limit := DefaultPageSize
+	return paginate(ctx, func(cursor string) ([]*api.Group, string, error) {
+		list, err := c.ListGroups(session, name, optionalString(cursor), &limit, opts...)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.GetGroups(), list.GetCursor(), nil
+	})
+}
+
+// GroupInfos iterates over the groups like Groups, with their sizes typed.
+func (c *Client) GroupInfos(ctx context.Context, session *Session, name *string, opts ...GroupQueryOption) iter.Seq2[GroupInfo, error] {
+	c = c.WithContext(ctx)
+	return func(yield func(GroupInfo, error) bool) {
+		for group, err := range c.Groups(ctx, session, name, opts...) {
+			if !yield(GroupInfo{group}, err) {
+				return
+			}
+		}
+	}
+}
+
+// GroupUsers iterates over the users of a group, state filters them when not nil.
+func (c *Client) GroupUsers(ctx context.Context, session *Session, groupId string, state *int) iter.Seq2[*api.GroupUserList_GroupUser, error] {
+	c = c.WithContext(ctx)
+	limit := DefaultPageSize
+	return paginate(ctx, func(cursor string) ([]*api.GroupUserList_GroupUser, string, error) {
+		list, err := c.ListGroupUsers(session, groupId, state, &limit, optionalString(cursor))
+		if err != nil {
+			return nil, "", err
+		}
+		return list.GroupUsers, list.Cursor, nil
+	})
+}
+
+// UserGroups iterates over the groups of a user, state filters them when not nil.
+func (c *Client) UserGroups(ctx context.Context, session *Session, userId string, state *int) iter.Seq2[*api.UserGroupList_UserGroup, error] {
+	c = c.WithContext(ctx)
+	limit := DefaultPageSize
+	return paginate(ctx, func(cursor string) ([]*api.UserGroupList_UserGroup, string, error) {
+		list, err := c.ListUserGroups(session, userId, state, &limit, optionalString(cursor))
+		if err != nil {
+			return nil, "", err
+		}
+		return list.UserGroups, list.Cursor, nil
+	})
+}
+
+// ChannelMessages iterates over the message history of a channel.
+func (c *Client) ChannelMessages(ctx context.Context, session *Session, channelId string, forward bool) iter.Seq2[*api.ChannelMessage, error] {
+	c = c.WithContext(ctx)
+	limit := DefaultPageSize
+	return paginate(ctx, func(cursor string) ([]*api.ChannelMessage, string, error) {
+		list, err := c.ListChannelMessages(session, channelId, &limit, &forward, optionalString(cursor))
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Messages, list.NextCursor, nil
+	})
+}
+
+// Notifications iterates over the notifications of the current user.
+func (c *Client) Notifications(ctx context.Context, session *Session) iter.Seq2[*api.Notification, error] {
+	c = c.WithContext(ctx)
+	return paginate(ctx, func(cursor string) ([]*api.Notification, string, error) {
+		list, err := c.ListNotifications(session, DefaultPageSize, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Notifications, list.CacheableCursor, nil
+	})
+}
+
+// StorageObjects iterates over the objects of a collection, userId filters the owner when not nil.
+func (c *Client) StorageObjects(ctx context.Context, session *Session, collection string, userId *string) iter.Seq2[*api.StorageObject, error] {
+	c = c.WithContext(ctx)
+	return paginate(ctx, func(cursor string) ([]*api.StorageObject, string, error) {
+		list, err := c.ListStorageObjects(session, collection, userId, DefaultPageSize, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Objects, list.Cursor, nil
+	})
+}
+
+// Subscriptions iterates over the subscriptions of the current user.
+func (c *Client) Subscriptions(ctx context.Context, session *Session) iter.Seq2[*api.ValidatedSubscription, error] {
+	c = c.WithContext(ctx)
+	return paginate(ctx, func(cursor string) ([]*api.ValidatedSubscription, string, error) {
+		list, err := c.ListSubscriptions(session, cursor, DefaultPageSize)
+		if err != nil {
+			return nil, "", err
+		}
+		return list.ValidatedSubscriptions, list.Cursor, nil
+	})
+}
@@ -0,0 +1,63 @@
+package nakama
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	pages := map[string][]int{"": {1, 2}, "a": {3}, "b": {}}
+	next := map[string]string{"": "a", "a": "b", "b": ""}
+	fetch := func(cursor string) ([]int, string, error) {
+		return pages[cursor], next[cursor], nil
+	}
+
+	items := []int{}
+	for item, err := range paginate(context.Background(), fetch) {
+		assert.NoError(t, err)
+		items = append(items, item)
+	}
+	assert.Equal(t, []int{1, 2, 3}, items)
+
+	// stop early
+	for item := range paginate(context.Background(), fetch) {
+		assert.Equal(t, 1, item)
+		break
+	}
+
+	failing := func(cursor string) ([]int, string, error) { return nil, "", errors.New("failed") }
+	for _, err := range paginate(context.Background(), failing) {
+		assert.Error(t, err)
+	}
+}
+
+func TestGroupsQuery(t *testing.T) {
+	queries := make(chan url.Values, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries <- r.URL.Query()
+		w.Write([]byte(`{"groups":[{"id":"group"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+	for group, err := range client.Groups(context.Background(), session, nil, WithGroupLangTag("fr"), WithGroupMembers(10), WithGroupOpen(false)) {
+		assert.NoError(t, err)
+		assert.Equal(t, "group", group.Id)
+	}
+	query := <-queries
+	assert.Equal(t, "fr", query.Get("lang_tag"))
+	assert.Equal(t, "10", query.Get("members"))
+	assert.Equal(t, "false", query.Get("open"))
+	assert.False(t, query.Has("name"))
+}
@@ -0,0 +1,114 @@
+package nakama
+
+import (
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// PartyClient groups the party calls of a socket and routes the party messages to its callbacks.
+// Experimental: its api can change in a minor version, it needs FeatureParties.
+type PartyClient struct {
+	// OnData is called with the data sent by the members.
+	OnData func(data *rtapi.PartyData)
+	// OnPresence is called when the members join or leave.
+	OnPresence func(event *rtapi.PartyPresenceEvent)
+	// OnLeader is called when the leader changes.
+	OnLeader func(leader *rtapi.PartyLeader)
+	// OnJoinRequest is called on the leader when a user asks to join a closed party.
+	OnJoinRequest func(request *rtapi.PartyJoinRequest)
+	// OnClose is called when the party is closed.
+	OnClose func(close *rtapi.PartyClose)
+
+	socket *DefaultSocket
+}
+
+// NewPartyClient creates a PartyClient calling through the socket, it fails with ErrExperimentalDisabled
+// unless FeatureParties is enabled on the client. It enables FeatureParties on the socket.
+func NewPartyClient(client *Client, socket *DefaultSocket) (*PartyClient, error) {
+	if err := client.requireExperimental(FeatureParties); err != nil {
+		return nil, wrapErr(err)
+	}
+	if socket != nil {
+		socket.EnableExperimental(FeatureParties)
+	}
+	return &PartyClient{socket: socket}, nil
+}
+
+// Create creates a party led by the current user.
+func (pc *PartyClient) Create(open bool, maxSize int32) (*rtapi.Party, error) {
+	return pc.socket.CreateParty(open, maxSize)
+}
+
+// Join joins a party, or asks its leader when the party is closed.
+func (pc *PartyClient) Join(partyId string) error {
+	return pc.socket.JoinParty(partyId)
+}
+
+// Leave leaves a party.
+func (pc *PartyClient) Leave(partyId string) error {
+	return pc.socket.LeaveParty(partyId)
+}
+
+// JoinRequests lists the pending join requests of a closed party, for its leader.
+func (pc *PartyClient) JoinRequests(partyId string) (*rtapi.PartyJoinRequest, error) {
+	return pc.socket.ListPartyJoinRequests(partyId)
+}
+
+// Promote makes a member the leader of the party.
+func (pc *PartyClient) Promote(partyId string, member *rtapi.UserPresence) (*rtapi.PartyLeader, error) {
+	return pc.socket.PromotePartyMember(partyId, member)
+}
+
+// Accept accepts the join request of a user to a closed party, for its leader.
+func (pc *PartyClient) Accept(partyId string, member *rtapi.UserPresence) error {
+	return pc.socket.AcceptPartyMember(partyId, member)
+}
+
+// Update sets the label of the party and opens or closes it, for its leader.
+func (pc *PartyClient) Update(partyId, label string, open bool) error {
+	return pc.socket.UpdateParty(partyId, label, open)
+}
+
+// Close closes the party, for its leader.
+func (pc *PartyClient) Close(partyId string) error {
+	return pc.socket.CloseParty(partyId)
+}
+
+// Remove kicks a member out of the party, or rejects its join request.
+func (pc *PartyClient) Remove(partyId string, member *rtapi.UserPresence) error {
+	return pc.socket.RemovePartyMember(partyId, member)
+}
+
+// Send sends data to the members of the party.
+func (pc *PartyClient) Send(partyId string, opCode int64, data []byte) error {
+	return pc.socket.SendPartyData(partyId, opCode, data)
+}
+
+// HandleEvent is an EventHandler routing the party messages to the callbacks,
+// chain it in the EventHandler passed to CreateSocket.
+func (pc *PartyClient) HandleEvent(event EventType, data *RspResult) {
+	if event != EventTypeMessage || data == nil || data.Decoded == nil {
+		return
+	}
+	switch msg := data.Decoded.GetMessage().(type) {
+	case *rtapi.Envelope_PartyData:
+		if pc.OnData != nil {
+			pc.OnData(msg.PartyData)
+		}
+	case *rtapi.Envelope_PartyPresenceEvent:
+		if pc.OnPresence != nil {
+			pc.OnPresence(msg.PartyPresenceEvent)
+		}
+	case *rtapi.Envelope_PartyLeader:
+		if pc.OnLeader != nil {
+			pc.OnLeader(msg.PartyLeader)
+		}
+	case *rtapi.Envelope_PartyJoinRequest:
+		if pc.OnJoinRequest != nil {
+			pc.OnJoinRequest(msg.PartyJoinRequest)
+		}
+	case *rtapi.Envelope_PartyClose:
+		if pc.OnClose != nil {
+			pc.OnClose(msg.PartyClose)
+		}
+	}
+}
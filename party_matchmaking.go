@@ -0,0 +1,250 @@
+package nakama
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// DefaultPartyReadyOpCode is the op code of the party data confirming a party ticket, reserved by PartyMatchmaking.
+const DefaultPartyReadyOpCode int64 = 0x7ea0
+
+// partyReady is the payload of a readiness confirmation.
+type partyReady struct {
+	Ticket string `json:"ticket"`
+}
+
+// PartyMatchmaking runs the matchmaking of a party on top of a PartyClient: the leader queues the party,
+// the members receive its ticket and confirm it with a party data of ReadyOpCode, the readiness of the members
+// is tracked, and OnPartyMatched is called once per ticket on every member with the match to join.
+// Chain HandleEvent in the EventHandler of the socket, the ready data also reach PartyClient.OnData.
+// Experimental like PartyClient.
+type PartyMatchmaking struct {
+	// OnTicket is called when the party is queued, with the ticket of the party.
+	OnTicket func(ticket string)
+	// OnReady is called when a member confirms the ticket, with the readiness of the members by user id.
+	OnReady func(userId string, ready map[string]bool)
+	// OnPartyMatched is called once per ticket when the party is matched, join with DefaultSocket.JoinMatchedMatch.
+	OnPartyMatched func(matched *rtapi.MatchmakerMatched)
+	// ReadyOpCode is the op code of the confirmations, DefaultPartyReadyOpCode unless set before queuing.
+	ReadyOpCode int64
+	// AutoConfirm makes the members confirm the tickets as they're received, on by default.
+	AutoConfirm bool
+
+	party   *PartyClient
+	partyId string
+	self    string // the user id of the current user
+
+	mu     sync.Mutex
+	leader string
+	ticket string
+	ready  map[string]bool // user id:ticket confirmed, the members of the party
+}
+
+// NewPartyMatchmaking creates the matchmaking of the party joined or created through pc.
+func NewPartyMatchmaking(pc *PartyClient, party *rtapi.Party) *PartyMatchmaking {
+	pm := &PartyMatchmaking{
+		ReadyOpCode: DefaultPartyReadyOpCode,
+		AutoConfirm: true,
+		party:       pc,
+		partyId:     party.GetPartyId(),
+		self:        party.GetSelf().GetUserId(),
+		leader:      party.GetLeader().GetUserId(),
+		ready:       map[string]bool{},
+	}
+	for _, presence := range party.GetPresences() {
+		pm.ready[presence.GetUserId()] = false
+	}
+	pm.ready[pm.self] = false
+	return pm
+}
+
+// Queue adds the party to the matchmaker, the current user must be the leader. The leader is ready at once.
+func (pm *PartyMatchmaking) Queue(query string, minCount, maxCount int32, stringProperties map[string]string, numericProperties map[string]float64) (string, error) {
+	ticket, err := pm.party.socket.AddMatchmakerParty(pm.partyId, query, minCount, maxCount, stringProperties, numericProperties, nil)
+	if err != nil {
+		return "", wrapErr(err, pm.partyId)
+	}
+	pm.onTicket(ticket.GetTicket())
+	return ticket.GetTicket(), nil
+}
+
+// Cancel removes the ticket of the party from the matchmaker, the current user must be the leader.
+func (pm *PartyMatchmaking) Cancel() error {
+	pm.mu.Lock()
+	ticket := pm.ticket
+	pm.mu.Unlock()
+	if ticket == "" {
+		return nil
+	}
+	if err := pm.party.socket.RemoveMatchmakerParty(pm.partyId, ticket); err != nil {
+		return wrapErr(err, pm.partyId)
+	}
+	pm.mu.Lock()
+	if pm.ticket == ticket {
+		pm.ticket = ""
+	}
+	pm.mu.Unlock()
+	return nil
+}
+
+// Confirm tells the party the current user is ready for the ticket of the party, see AutoConfirm.
+func (pm *PartyMatchmaking) Confirm() error {
+	pm.mu.Lock()
+	ticket := pm.ticket
+	pm.mu.Unlock()
+	if ticket == "" {
+		return newError("party not queued").With(pm.partyId)
+	}
+	data, err := json.Marshal(&partyReady{Ticket: ticket})
+	if err != nil {
+		return wrapErr(err)
+	}
+	if err := pm.party.Send(pm.partyId, pm.ReadyOpCode, data); err != nil {
+		return wrapErr(err, pm.partyId)
+	}
+	pm.markReady(pm.self, ticket)
+	return nil
+}
+
+// Ticket returns the ticket of the party, empty when the party isn't queued.
+func (pm *PartyMatchmaking) Ticket() string {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.ticket
+}
+
+// Ready returns the readiness of the members of the party by user id.
+func (pm *PartyMatchmaking) Ready() map[string]bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	ready := make(map[string]bool, len(pm.ready))
+	for userId, ok := range pm.ready {
+		ready[userId] = ok
+	}
+	return ready
+}
+
+// AllReady reports whether the party is queued and all its members have confirmed the ticket.
+func (pm *PartyMatchmaking) AllReady() bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.ticket == "" {
+		return false
+	}
+	for _, ok := range pm.ready {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// HandleEvent is an EventHandler tracking the tickets, the members and the matches of the party.
+func (pm *PartyMatchmaking) HandleEvent(event EventType, data *RspResult) {
+	if event != EventTypeMessage || data == nil || data.Decoded == nil {
+		return
+	}
+	switch msg := data.Decoded.GetMessage().(type) {
+	case *rtapi.Envelope_PartyMatchmakerTicket:
+		if msg.PartyMatchmakerTicket.GetPartyId() == pm.partyId {
+			pm.onTicket(msg.PartyMatchmakerTicket.GetTicket())
+		}
+	case *rtapi.Envelope_PartyData:
+		if msg.PartyData.GetPartyId() != pm.partyId || msg.PartyData.GetOpCode() != pm.ReadyOpCode {
+			return
+		}
+		ready := partyReady{}
+		if err := json.Unmarshal(msg.PartyData.GetData(), &ready); err != nil {
+			GetLogger().Warnf("party %s: invalid ready data: %s", pm.partyId, err.Error())
+			return
+		}
+		pm.markReady(msg.PartyData.GetPresence().GetUserId(), ready.Ticket)
+	case *rtapi.Envelope_PartyPresenceEvent:
+		if msg.PartyPresenceEvent.GetPartyId() == pm.partyId {
+			pm.onPresence(msg.PartyPresenceEvent)
+		}
+	case *rtapi.Envelope_PartyLeader:
+		if msg.PartyLeader.GetPartyId() == pm.partyId {
+			pm.mu.Lock()
+			pm.leader = msg.PartyLeader.GetPresence().GetUserId()
+			pm.mu.Unlock()
+		}
+	case *rtapi.Envelope_MatchmakerMatched:
+		pm.onMatched(msg.MatchmakerMatched)
+	}
+}
+
+// onTicket starts the readiness of a new ticket, the leader is ready.
+func (pm *PartyMatchmaking) onTicket(ticket string) {
+	pm.mu.Lock()
+	if ticket == "" || ticket == pm.ticket {
+		pm.mu.Unlock()
+		return
+	}
+	pm.ticket = ticket
+	for userId := range pm.ready {
+		pm.ready[userId] = userId == pm.leader
+	}
+	isLeader := pm.self == pm.leader
+	pm.mu.Unlock()
+
+	if pm.OnTicket != nil {
+		pm.OnTicket(ticket)
+	}
+	if !isLeader && pm.AutoConfirm {
+		if err := pm.Confirm(); err != nil {
+			GetLogger().Warn(wrapErr(err, "party ticket confirmation"))
+		}
+	}
+}
+
+// markReady marks the member ready when it has confirmed the current ticket.
+func (pm *PartyMatchmaking) markReady(userId, ticket string) {
+	pm.mu.Lock()
+	if ticket != pm.ticket || userId == "" {
+		pm.mu.Unlock()
+		return
+	}
+	pm.ready[userId] = true
+	ready := make(map[string]bool, len(pm.ready))
+	for id, ok := range pm.ready {
+		ready[id] = ok
+	}
+	pm.mu.Unlock()
+
+	if pm.OnReady != nil {
+		pm.OnReady(userId, ready)
+	}
+}
+
+// onPresence tracks the members, a member joining a queued party has to confirm its ticket.
+func (pm *PartyMatchmaking) onPresence(event *rtapi.PartyPresenceEvent) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	for _, presence := range event.GetJoins() {
+		if _, ok := pm.ready[presence.GetUserId()]; !ok {
+			pm.ready[presence.GetUserId()] = false
+		}
+	}
+	for _, presence := range event.GetLeaves() {
+		delete(pm.ready, presence.GetUserId())
+	}
+}
+
+// onMatched calls OnPartyMatched once for the ticket of the party.
+func (pm *PartyMatchmaking) onMatched(matched *rtapi.MatchmakerMatched) {
+	pm.mu.Lock()
+	if pm.ticket == "" || matched.GetTicket() != pm.ticket {
+		pm.mu.Unlock()
+		return
+	}
+	// the ticket is consumed by the match
+	pm.ticket = ""
+	pm.mu.Unlock()
+
+	if pm.OnPartyMatched != nil {
+		pm.OnPartyMatched(matched)
+	}
+}
@@ -0,0 +1,104 @@
+package nakama
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// push sends a message of the server, not correlated to a request.
+func push(t *testing.T, conn *scriptedConn, envelope *rtapi.Envelope) {
+	data, err := protojson.Marshal(envelope)
+	assert.NoError(t, err)
+	conn.WriteRaw(data)
+}
+
+func newTestPartyMatchmaking(t *testing.T, server *scriptedServer, self string) (*PartyMatchmaking, *DefaultSocket) {
+	client, err := NewClientWithOptions(WithExperimental(FeatureParties))
+	assert.NoError(t, err)
+	var pm *PartyMatchmaking
+	socket, _ := server.socket(func(event EventType, data *RspResult) {
+		if pm != nil {
+			pm.HandleEvent(event, data)
+		}
+	})
+	parties, err := NewPartyClient(client, socket)
+	assert.NoError(t, err)
+	pm = NewPartyMatchmaking(parties, &rtapi.Party{
+		PartyId:   "party",
+		Self:      &rtapi.UserPresence{UserId: self},
+		Leader:    &rtapi.UserPresence{UserId: "leader"},
+		Presences: []*rtapi.UserPresence{{UserId: "leader"}, {UserId: "member"}},
+	})
+	assert.NoError(t, socket.Connect())
+	return pm, socket
+}
+
+func TestPartyMatchmakingLeader(t *testing.T) {
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		if add := req.GetPartyMatchmakerAdd(); add != nil {
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_PartyMatchmakerTicket{
+				PartyMatchmakerTicket: &rtapi.PartyMatchmakerTicket{PartyId: add.PartyId, Ticket: "ticket-1"},
+			}})
+		}
+	})
+	pm, _ := newTestPartyMatchmaking(t, server, "leader")
+
+	ticket, err := pm.Queue("*", 2, 4, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "ticket-1", ticket)
+	assert.Equal(t, map[string]bool{"leader": true, "member": false}, pm.Ready())
+	assert.False(t, pm.AllReady())
+
+	// a confirmation of another ticket is ignored
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_PartyData{PartyData: &rtapi.PartyData{
+		PartyId: "party", Presence: &rtapi.UserPresence{UserId: "member"}, OpCode: DefaultPartyReadyOpCode, Data: []byte(`{"ticket":"ticket-0"}`),
+	}}})
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_PartyData{PartyData: &rtapi.PartyData{
+		PartyId: "party", Presence: &rtapi.UserPresence{UserId: "member"}, OpCode: DefaultPartyReadyOpCode, Data: []byte(`{"ticket":"ticket-1"}`),
+	}}})
+	eventually(t, pm.AllReady, "the member has not confirmed")
+
+	// a member joining the queued party has to confirm the ticket
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_PartyPresenceEvent{PartyPresenceEvent: &rtapi.PartyPresenceEvent{
+		PartyId: "party", Joins: []*rtapi.UserPresence{{UserId: "late"}},
+	}}})
+	eventually(t, func() bool { _, ok := pm.Ready()["late"]; return ok }, "the join has not been tracked")
+	assert.False(t, pm.AllReady())
+}
+
+func TestPartyMatchmakingMember(t *testing.T) {
+	server := newScriptedServer(t, nil)
+	pm, _ := newTestPartyMatchmaking(t, server, "member")
+	var matched atomic.Int32
+	var token atomic.Value
+	pm.OnPartyMatched = func(m *rtapi.MatchmakerMatched) {
+		matched.Add(1)
+		token.Store(m.GetToken())
+	}
+
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_PartyMatchmakerTicket{
+		PartyMatchmakerTicket: &rtapi.PartyMatchmakerTicket{PartyId: "party", Ticket: "ticket-1"},
+	}})
+	// the member confirms the ticket to the party
+	eventually(t, func() bool { return len(server.requestsOf("party_data_send")) == 1 }, "the ticket has not been confirmed")
+	send := server.requestsOf("party_data_send")[0].GetPartyDataSend()
+	assert.Equal(t, DefaultPartyReadyOpCode, send.GetOpCode())
+	assert.JSONEq(t, `{"ticket":"ticket-1"}`, string(send.GetData()))
+	assert.Equal(t, "ticket-1", pm.Ticket())
+	assert.True(t, pm.AllReady())
+
+	// the match of the ticket is reported once
+	for range 2 {
+		push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_MatchmakerMatched{
+			MatchmakerMatched: &rtapi.MatchmakerMatched{Ticket: "ticket-1", Id: &rtapi.MatchmakerMatched_Token{Token: "join"}},
+		}})
+	}
+	eventually(t, func() bool { return matched.Load() == 1 }, "the match has not been reported")
+	assert.Equal(t, "join", token.Load())
+	assert.Empty(t, pm.Ticket())
+	assert.Equal(t, int32(1), matched.Load())
+}
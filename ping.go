@@ -0,0 +1,132 @@
+package nakama
+
+import (
+	"context"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// pingStats are the round trips of the pings of a socket.
+type pingStats struct {
+	count int64
+	last  time.Duration
+	total time.Duration
+}
+
+// SetPingIntervalMs sets the period of the pings checking the connection, it applies at once.
+// 0 restores the default, the heartbeat timeout, and a negative ms stops the pings.
+func (socket *DefaultSocket) SetPingIntervalMs(ms int) {
+	socket.pingIntervalMs.Store(int64(ms))
+	select {
+	case socket.pingWake <- struct{}{}:
+	default:
+	}
+}
+
+// GetPingIntervalMs returns the period of the pings, a negative value when they are stopped.
+func (socket *DefaultSocket) GetPingIntervalMs() int {
+	ms := int(socket.pingIntervalMs.Load())
+	if ms == 0 {
+		return socket.heartbeatTimeoutMs
+	}
+	return ms
+}
+
+// PingLatency returns the round trip of the last ping and the average of all the pings, zero before the first pong.
+func (socket *DefaultSocket) PingLatency() (last, average time.Duration) {
+	socket.pingMu.Lock()
+	defer socket.pingMu.Unlock()
+	if socket.pings.count == 0 {
+		return 0, 0
+	}
+	return socket.pings.last, socket.pings.total / time.Duration(socket.pings.count)
+}
+
+func (socket *DefaultSocket) observePong(rtt time.Duration) {
+	socket.pingMu.Lock()
+	socket.pings.count++
+	socket.pings.last = rtt
+	socket.pings.total += rtt
+	socket.pingMu.Unlock()
+	if socket.onPong != nil {
+		socket.onPong(rtt)
+	}
+}
+
+// SendNoReply sends a status-only envelope without waiting for a response, e.g. a pong.
+// The cid of the message is sent as set.
+func (socket *DefaultSocket) SendNoReply(message *rtapi.Envelope) error {
+	if socket.IsVerbose() {
+		dumpEnvelope("send", message)
+	}
+	if err := socket.adapter.Send(message); err != nil {
+		return wrapErr(err, envelopeType(message))
+	}
+	return nil
+}
+
+// handlePing answers a ping of the server with a pong of the same cid, handled is false when it's not a ping.
+func (socket *DefaultSocket) handlePing(decoded *rtapi.Envelope) (handled bool) {
+	if decoded.GetPing() == nil {
+		return false
+	}
+	pong := &rtapi.Envelope{Cid: decoded.Cid, Message: &rtapi.Envelope_Pong{Pong: &rtapi.Pong{}}}
+	if err := socket.SendNoReply(pong); err != nil {
+		GetLogger().Warn(wrapErr(err))
+	}
+	return true
+}
+
+// waitPing waits for the next ping, it returns false when the interval has changed or ctx is done.
+func (socket *DefaultSocket) waitPing(ctx context.Context) bool {
+	var tick <-chan time.Time
+	if interval := socket.GetPingIntervalMs(); interval > 0 {
+		t := socket.newTimer(time.Duration(interval) * time.Millisecond)
+		defer t.Stop()
+		tick = t.C()
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-socket.pingWake:
+		return false
+	case <-tick:
+		return true
+	}
+}
+
+// pingPong does a periodic ping-pong check with the server, see SetPingIntervalMs.
+func (socket *DefaultSocket) pingPong(ctx context.Context) {
+	pingReq := &rtapi.Envelope{
+		Message: &rtapi.Envelope_Ping{
+			Ping: &rtapi.Ping{},
+		},
+	}
+
+	last := time.Now()
+	for ctx.Err() == nil {
+		if !socket.waitPing(ctx) {
+			continue
+		}
+		if socket.userClosed.Load() {
+			// user closed
+			return
+		}
+		now := time.Now()
+		socket.checkResume(last, now)
+		last = now
+		startTime := time.Now()
+		timeoutMs := socket.heartbeatTimeoutMs
+		result := socket.Send(pingReq, &timeoutMs)
+		if err, ok := result.(error); ok {
+			GetLogger().Warn(wrapErr(err, "ping"))
+			continue
+		}
+		rtt := time.Since(startTime)
+		socket.observePong(rtt)
+		if socket.eventHandle != nil {
+			go socket.eventHandle(EventTypePingPong, &RspResult{Data: []byte(rtt.String())})
+		}
+	}
+}
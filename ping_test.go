@@ -0,0 +1,65 @@
+package nakama
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestSocketPingPong(t *testing.T) {
+	pongs := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		data, _ := protojson.Marshal(&rtapi.Envelope{Cid: "server", Message: &rtapi.Envelope_Ping{Ping: &rtapi.Ping{}}})
+		conn.Write(r.Context(), websocket.MessageText, data)
+		for {
+			_, data, err := conn.Read(r.Context())
+			if err != nil {
+				return
+			}
+			req := &rtapi.Envelope{}
+			if err := protojson.Unmarshal(data, req); err != nil {
+				return
+			}
+			if req.GetPong() != nil {
+				pongs <- req.Cid
+				continue
+			}
+			data, _ = protojson.Marshal(&rtapi.Envelope{Cid: req.Cid, Message: &rtapi.Envelope_Pong{Pong: &rtapi.Pong{}}})
+			conn.Write(r.Context(), websocket.MessageText, data)
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+	socket := NewDefaultSocket(nil, host, port, "token", false, false, nil, nil)
+	assert.Equal(t, DefaultHeartbeatTimeoutMs, socket.GetPingIntervalMs())
+	socket.SetPingIntervalMs(10)
+	assert.NoError(t, socket.Connect())
+	defer socket.Disconnect()
+
+	select {
+	case cid := <-pongs:
+		assert.Equal(t, "server", cid, "the ping of the server should be answered")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no pong")
+	}
+
+	assert.Eventually(t, func() bool {
+		last, average := socket.PingLatency()
+		return last > 0 && average > 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
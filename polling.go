@@ -0,0 +1,256 @@
+package nakama
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NorthNorthGames/nakama-go/backoff"
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// Polling fallback defaults
+const (
+	DefaultPollMinInterval = 5 * time.Second
+	DefaultPollMaxInterval = time.Minute
+	DefaultPollLimit       = 100
+)
+
+// PollingFallback polls the notifications and the presence of the friends while the socket is unavailable,
+// e.g. on the platforms restricting the background sockets. It stops polling while the socket pushes them,
+// chain HandleEvent in the EventHandler of the socket to switch back to push when it reconnects.
+// The interval grows from MinInterval to MaxInterval while nothing changes, and follows the Retry-After of a 429.
+type PollingFallback struct {
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	Limit       int
+
+	// OnFriendPresence is called when a friend goes online or offline between two polls.
+	OnFriendPresence func(friend *api.User, online bool)
+	// OnError is called with the errors of the polls, the polling goes on.
+	OnError func(err error)
+
+	client        *Client
+	session       func() *Session
+	notifications *NotificationCenter
+
+	push atomic.Bool
+	wake chan struct{}
+
+	mu         sync.Mutex
+	interval   time.Duration
+	cursor     string          // cacheable cursor of the notifications
+	friendsTag string          // ETag of the friend list
+	online     map[string]bool // user id:online, nil before the first friend poll
+}
+
+// NewPollingFallback creates a PollingFallback dispatching the notifications to notifications,
+// session returns the session of the polls, e.g. NakamaSDK.Session.
+func NewPollingFallback(client *Client, session func() *Session, notifications *NotificationCenter) *PollingFallback {
+	return &PollingFallback{
+		MinInterval:   DefaultPollMinInterval,
+		MaxInterval:   DefaultPollMaxInterval,
+		Limit:         DefaultPollLimit,
+		client:        client,
+		session:       session,
+		notifications: notifications,
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// NotificationCursor returns the cacheable cursor of the last notification poll, persist it to skip
+// the notifications already seen on the next start.
+func (p *PollingFallback) NotificationCursor() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cursor
+}
+
+// SetNotificationCursor sets the cursor of the next notification poll, all the notifications are polled when empty.
+func (p *PollingFallback) SetNotificationCursor(cursor string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cursor = cursor
+}
+
+// SetPushActive tells whether the socket delivers the notifications, the polling pauses while it does.
+func (p *PollingFallback) SetPushActive(active bool) {
+	if p.push.Swap(active) == active {
+		return
+	}
+	if !active {
+		// catch up at once what the socket has missed
+		p.mu.Lock()
+		p.interval = 0
+		p.mu.Unlock()
+	}
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// HandleEvent is an EventHandler switching between push and polling with the state of the socket,
+// chain it in the EventHandler passed to CreateSocket.
+func (p *PollingFallback) HandleEvent(event EventType, data *RspResult) {
+	switch event {
+	case EventTypeConnected, EventTypeReConnected:
+		p.SetPushActive(true)
+	case EventTypeReconnecting:
+		p.SetPushActive(false)
+	}
+}
+
+// Start runs the polling in the background until the client is stopped, see Client.Stop.
+func (p *PollingFallback) Start() error {
+	return p.client.Go(p.Run)
+}
+
+// Run polls until ctx is done, it returns the error of ctx.
+func (p *PollingFallback) Run(ctx context.Context) error {
+	for {
+		if p.push.Load() {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-p.wake:
+			}
+			continue
+		}
+
+		p.mu.Lock()
+		wait := p.interval
+		p.mu.Unlock()
+		if wait > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-p.wake:
+				continue
+			case <-time.After(wait):
+			}
+		}
+		if p.push.Load() {
+			continue
+		}
+		if _, err := p.poll(ctx); err != nil && p.OnError != nil {
+			p.OnError(err)
+		}
+	}
+}
+
+// poll polls the notifications and the friends once and sets the next interval, changed reports new data.
+func (p *PollingFallback) poll(ctx context.Context) (changed bool, err error) {
+	session := p.session()
+	if err := p.client.refreshSession(session); err != nil {
+		p.nextInterval(false, 0)
+		return false, wrapErr(err)
+	}
+
+	info := ResponseInfo{}
+	apiClient := p.client.ApiClient.WithContext(ContextWithSession(ctx, session)).WithResponseInfo(&info)
+	notified, err := p.pollNotifications(apiClient, session)
+	if err != nil {
+		p.nextInterval(false, retryAfterOf(err, p.MaxInterval))
+		return false, wrapErr(err)
+	}
+	presence, err := p.pollFriends(apiClient, &info, session)
+	if err != nil {
+		p.nextInterval(notified, retryAfterOf(err, p.MaxInterval))
+		return notified, wrapErr(err)
+	}
+	changed = notified || presence
+	p.nextInterval(changed, 0)
+	return changed, nil
+}
+
+func (p *PollingFallback) pollNotifications(apiClient NakamaApiInterface, session *Session) (bool, error) {
+	list, err := apiClient.ListNotifications(session.Token, p.Limit, p.NotificationCursor(), make(map[string]string))
+	if err != nil {
+		return false, wrapErr(err)
+	}
+	if list.CacheableCursor != "" {
+		p.SetNotificationCursor(list.CacheableCursor)
+	}
+	if p.notifications != nil {
+		p.notifications.Dispatch(list.Notifications...)
+	}
+	return len(list.Notifications) > 0, nil
+}
+
+func (p *PollingFallback) pollFriends(apiClient NakamaApiInterface, info *ResponseInfo, session *Session) (bool, error) {
+	p.mu.Lock()
+	options := map[string]string{}
+	if p.friendsTag != "" {
+		options["If-None-Match"] = p.friendsTag
+	}
+	p.mu.Unlock()
+
+	friendState := 0 // mutual friends only
+	list, err := apiClient.ListFriends(&session.Token, &p.Limit, &friendState, nil, options)
+	if httpStatusOf(err) == http.StatusNotModified {
+		return false, nil
+	}
+	if err != nil {
+		return false, wrapErr(err)
+	}
+
+	type change struct {
+		user   *api.User
+		online bool
+	}
+	changes := []change{}
+	p.mu.Lock()
+	p.friendsTag = info.Header.Get("ETag")
+	first := p.online == nil
+	online := make(map[string]bool, len(list.Friends))
+	for _, friend := range list.Friends {
+		user := friend.GetUser()
+		if user == nil {
+			continue
+		}
+		online[user.Id] = user.Online
+		if was, ok := p.online[user.Id]; !first && (ok && was != user.Online || !ok && user.Online) {
+			changes = append(changes, change{user, user.Online})
+		}
+	}
+	p.online = online
+	p.mu.Unlock()
+
+	if p.OnFriendPresence != nil {
+		for _, c := range changes {
+			p.OnFriendPresence(c.user, c.online)
+		}
+	}
+	return len(changes) > 0, nil
+}
+
+// nextInterval resets the interval when something has changed, or doubles it up to MaxInterval.
+// A positive retryAfter of the server overrides it.
+func (p *PollingFallback) nextInterval(changed bool, retryAfter time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case retryAfter > 0:
+		p.interval = retryAfter
+	case changed || p.interval == 0:
+		p.interval = p.MinInterval
+	default:
+		p.interval = backoff.Exponential{Initial: p.interval, Max: p.MaxInterval, Multiplier: 2}.Delay(2)
+	}
+}
+
+// retryAfterOf returns the delay asked by a 429 response, fallback is used when the header is missing.
+func retryAfterOf(err error, fallback time.Duration) time.Duration {
+	httpErr := &HTTPError{}
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusTooManyRequests {
+		return 0
+	}
+	if httpErr.RetryAfter > 0 {
+		return httpErr.RetryAfter
+	}
+	return fallback
+}
@@ -0,0 +1,85 @@
+package nakama
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollingFallback(t *testing.T) {
+	var friendPolls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/notification":
+			if r.URL.Query().Get("cacheable_cursor") == "" {
+				w.Write([]byte(`{"notifications":[{"id":"n1","subject":"hello"}],"cacheable_cursor":"c1"}`))
+				return
+			}
+			w.Write([]byte(`{"cacheable_cursor":"c1"}`))
+		case "/v2/friend":
+			switch friendPolls.Add(1) {
+			case 1:
+				w.Header().Set("ETag", `"v1"`)
+				w.Write([]byte(`{"friends":[{"user":{"id":"a","online":false}}]}`))
+			case 2:
+				assert.Equal(t, `"v1"`, r.Header.Get("If-None-Match"))
+				w.WriteHeader(http.StatusNotModified)
+			case 3:
+				w.Header().Set("ETag", `"v2"`)
+				w.Write([]byte(`{"friends":[{"user":{"id":"a","online":true}}]}`))
+			default:
+				w.Header().Set("Retry-After", "42")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	assert.NoError(t, err)
+	session := &Session{Token: "token"}
+	notifications := NewNotificationCenter()
+	received := []string{}
+	notifications.Subscribe(func(n *api.Notification) { received = append(received, n.Id) })
+
+	p := NewPollingFallback(client, func() *Session { return session }, notifications)
+	presence := map[string]bool{}
+	p.OnFriendPresence = func(friend *api.User, online bool) { presence[friend.Id] = online }
+
+	changed, err := p.poll(ctx)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{"n1"}, received)
+	assert.Equal(t, "c1", p.NotificationCursor())
+	assert.Equal(t, DefaultPollMinInterval, p.interval)
+
+	// nothing new, the interval grows
+	changed, err = p.poll(ctx)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 2*DefaultPollMinInterval, p.interval)
+
+	changed, err = p.poll(ctx)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, map[string]bool{"a": true}, presence)
+	assert.Equal(t, DefaultPollMinInterval, p.interval)
+
+	_, err = p.poll(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 42*time.Second, p.interval)
+
+	// the polling pauses while the socket pushes
+	p.HandleEvent(EventTypeConnected, nil)
+	runCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.Run(runCtx), context.DeadlineExceeded)
+	assert.Equal(t, int32(4), friendPolls.Load())
+}
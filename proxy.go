@@ -0,0 +1,40 @@
+package nakama
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// ProxyFunc returns the proxy of a request like http.Transport.Proxy, nil for a direct connection.
+//
+// The http calls and the sockets use the proxy of the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables
+// by default, see http.ProxyFromEnvironment, so the players behind a proxy connect without any code.
+type ProxyFunc func(req *http.Request) (*url.URL, error)
+
+// DirectConnection is a ProxyFunc ignoring the proxy of the environment.
+var DirectConnection ProxyFunc = func(*http.Request) (*url.URL, error) { return nil, nil }
+
+// WithProxy replaces the proxy of the environment for the http calls and the sockets created by the client,
+// e.g. http.ProxyURL(u) for a fixed proxy or DirectConnection. The http calls ignore it when WithHTTPClient
+// or WithHTTPTransport is used, configure that transport instead.
+func WithProxy(proxy ProxyFunc) ClientOption {
+	return func(opts *ClientOptions) error {
+		if proxy == nil {
+			return newError("nil proxy, use DirectConnection to ignore the proxy of the environment")
+		}
+		opts.Proxy = proxy
+		return nil
+	}
+}
+
+// SetProxy replaces the proxy of the environment for the next connection, nil restores it.
+func (socket *DefaultSocket) SetProxy(proxy ProxyFunc) {
+	socket.adapter.SetProxy(proxy)
+}
+
+// SetProxy sets the proxy of the next connection, nil uses the proxy of the environment.
+func (w *WebSocketAdapter) SetProxy(proxy ProxyFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.proxy = proxy
+}
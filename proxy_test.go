@@ -0,0 +1,38 @@
+package nakama
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxy(t *testing.T) {
+	requests := make(chan string, 2)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r.Host + r.URL.Path
+		if r.URL.Path == "/ws" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer proxy.Close()
+	proxyUrl, _ := url.Parse(proxy.URL)
+
+	// the server is only reachable through the proxy
+	client, err := NewClientWithOptions(WithURL("http://nakama.invalid:7350"), WithProxy(http.ProxyURL(proxyUrl)))
+	assert.NoError(t, err)
+	assert.NoError(t, client.ApiClient.Healthcheck("", nil))
+	assert.Equal(t, "nakama.invalid:7350/healthcheck", <-requests)
+
+	socket := client.CreateSocket(nil, "token", false, false, nil, nil)
+	defer socket.Disconnect()
+	assert.Error(t, socket.Connect())
+	assert.Equal(t, "nakama.invalid:7350/ws", <-requests)
+
+	_, err = NewClientWithOptions(WithProxy(nil))
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package nakama
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// DefaultPublicStorageRpcId is the rpc id used by ReadPublicStorage with a http key when none is set.
+const DefaultPublicStorageRpcId = "storage_list_public"
+
+// ErrPublicStorageDisabled is returned by ReadPublicStorage when WithPublicStorage hasn't been used.
+var ErrPublicStorageDisabled = newError("public storage reads are not enabled")
+
+// PublicStorageOptions enables the reads of the public storage objects before the login, e.g. news or a MOTD.
+// The storage api of the server needs a session, so the reads go through an rpc called with HttpKey,
+// or through the storage api with the server key when UseServerKey is set, for the gateways accepting it.
+type PublicStorageOptions struct {
+	HttpKey string
+	// RpcId is the rpc listing the objects, DefaultPublicStorageRpcId when empty.
+	// It receives {"collection","user_id","limit","cursor"} and returns a StorageObjectList with the public objects only.
+	RpcId        string
+	UseServerKey bool
+}
+
+// WithPublicStorage enables ReadPublicStorage.
+func WithPublicStorage(publicStorage PublicStorageOptions) ClientOption {
+	return func(opts *ClientOptions) error {
+		if publicStorage.HttpKey == "" && !publicStorage.UseServerKey {
+			return newError("public storage needs a http key or the server key")
+		}
+		opts.PublicStorage = &publicStorage
+		return nil
+	}
+}
+
+// ReadPublicStorage lists the public objects of a collection without session, userId filters the owner when not empty.
+// The values are decoded by the ValueTransformer of the collection like the other reads.
+func (c *Client) ReadPublicStorage(ctx context.Context, collection, userId string, limit int, cursor string) (*api.StorageObjectList, error) {
+	list, err := c.readPublicStorage(ctx, collection, userId, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.decodeStorageObjects(list.GetObjects()); err != nil {
+		return nil, wrapErr(err, collection)
+	}
+	return list, nil
+}
+
+// readPublicStorage lists the public objects with the server key or through the rpc.
+func (c *Client) readPublicStorage(ctx context.Context, collection, userId string, limit int, cursor string) (*api.StorageObjectList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrapErr(err, collection)
+	}
+	opts := c.publicStorage
+	if opts == nil {
+		return nil, ErrPublicStorageDisabled.With(collection)
+	}
+	apiClient := c.ApiClient.WithContext(ctx)
+
+	if opts.UseServerKey {
+		basic := base64.StdEncoding.EncodeToString([]byte(c.ServerKey + ":"))
+		list, err := apiClient.ListStorageObjects("", collection, optionalString(userId), limit, cursor, map[string]string{
+			"Authorization": "Basic " + basic,
+		})
+		if err != nil {
+			return nil, wrapErr(err, collection)
+		}
+		return list, nil
+	}
+
+	rpcId := opts.RpcId
+	if rpcId == "" {
+		rpcId = DefaultPublicStorageRpcId
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"collection": collection,
+		"user_id":    userId,
+		"limit":      limit,
+		"cursor":     cursor,
+	})
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	rpc, err := apiClient.RpcFunc("", rpcId, string(payload), opts.HttpKey, make(map[string]string))
+	if err != nil {
+		return nil, wrapErr(err, collection)
+	}
+	list := &api.StorageObjectList{}
+	if rpc.GetPayload() == "" {
+		return list, nil
+	}
+	if err := responseUnmarshal.Unmarshal([]byte(rpc.Payload), list); err != nil {
+		return nil, wrapErr(err, rpc.Payload)
+	}
+	return list, nil
+}
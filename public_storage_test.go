@@ -0,0 +1,65 @@
+package nakama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadPublicStorage(t *testing.T) {
+	transformed, err := NewClientWithOptions(WithStorageTransformers("news", GzipTransformer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := transformed.encodeStorageValue("news", `{"title":"patch notes"}`)
+	assert.NoError(t, err)
+	// a field added by a newer server is ignored
+	objects, _ := json.Marshal(map[string]any{
+		"objects":   []map[string]any{{"collection": "news", "key": "motd", "value": value}},
+		"new_field": 1,
+	})
+	rpc, _ := json.Marshal(map[string]any{"id": "storage_list_public", "payload": string(objects)})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/rpc/storage_list_public":
+			assert.Equal(t, "http-key", r.URL.Query().Get("http_key"))
+			w.Write(rpc)
+		case "/v2/storage/news":
+			user, _, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "server-key", user)
+			w.Write([]byte(`{"objects":[{"collection":"news","key":"patch"}],"cursor":"next"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	assert.NoError(t, err)
+	_, err = client.ReadPublicStorage(ctx, "news", "", 10, "")
+	assert.True(t, errors.Is(err, ErrPublicStorageDisabled))
+
+	// the values are decoded like the other reads
+	client, err = NewClientWithOptions(WithURL(server.URL), WithPublicStorage(PublicStorageOptions{HttpKey: "http-key"}),
+		WithStorageTransformers("news", GzipTransformer()))
+	assert.NoError(t, err)
+	list, err := client.ReadPublicStorage(ctx, "news", "", 10, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "motd", list.Objects[0].Key)
+	assert.Equal(t, `{"title":"patch notes"}`, list.Objects[0].Value)
+
+	client, err = NewClientWithOptions(WithURL(server.URL), WithServerKey("server-key"), WithPublicStorage(PublicStorageOptions{UseServerKey: true}))
+	assert.NoError(t, err)
+	list, err = client.ReadPublicStorage(ctx, "news", "", 10, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "patch", list.Objects[0].Key)
+	assert.Equal(t, "next", list.Cursor)
+}
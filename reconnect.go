@@ -0,0 +1,64 @@
+package nakama
+
+import (
+	"time"
+
+	"github.com/NorthNorthGames/nakama-go/backoff"
+)
+
+// Reconnect defaults
+const (
+	DefaultReconnectInterval      = 3 * time.Second
+	DefaultReconnectInitialJitter = 250 * time.Millisecond
+	// DefaultReconnectHerdJitter is the initial jitter of WithHerdJitter.
+	DefaultReconnectHerdJitter = 5 * time.Second
+)
+
+// ReconnectPolicy configures how a DefaultSocket reconnects after losing the connection.
+type ReconnectPolicy struct {
+	// InitialDelay is waited before the first attempt after the connection has been lost.
+	InitialDelay time.Duration
+	// InitialJitter adds a random delay in [0, InitialJitter) to InitialDelay,
+	// so the clients dropped by a server restart don't reconnect at the same time.
+	InitialJitter time.Duration
+	// Interval is waited between two failed attempts.
+	Interval time.Duration
+	// Budget limits the rate of the attempts, share it between the sockets of a process. Nil means no limit.
+	Budget *ReconnectBudget
+}
+
+// DefaultReconnectPolicy returns the policy used by the sockets when none is set.
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		InitialJitter: DefaultReconnectInitialJitter,
+		Interval:      DefaultReconnectInterval,
+	}
+}
+
+// WithHerdJitter returns a copy of the policy spreading the first attempts over DefaultReconnectHerdJitter,
+// e.g. for the large deployments where the clients dropped by a server restart would reconnect at the same time.
+func (p ReconnectPolicy) WithHerdJitter() ReconnectPolicy {
+	p.InitialJitter = DefaultReconnectHerdJitter
+	return p
+}
+
+// initialWait returns the delay before the first attempt.
+func (p ReconnectPolicy) initialWait() time.Duration {
+	return backoff.Jitter(p.InitialDelay, p.InitialJitter)
+}
+
+// interval returns the delay between two failed attempts.
+func (p ReconnectPolicy) interval() time.Duration {
+	if p.Interval <= 0 {
+		return DefaultReconnectInterval
+	}
+	return p.Interval
+}
+
+// ReconnectBudget is a token bucket limiting the reconnect attempts.
+type ReconnectBudget = backoff.Budget
+
+// NewReconnectBudget creates a budget allowing perSecond attempts with bursts of burst attempts.
+func NewReconnectBudget(perSecond float64, burst int) *ReconnectBudget {
+	return backoff.NewBudget(perSecond, burst)
+}
@@ -0,0 +1,23 @@
+package nakama
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconnectPolicyJitter(t *testing.T) {
+	policy := DefaultReconnectPolicy()
+	herd := policy.WithHerdJitter()
+	assert.Equal(t, DefaultReconnectInitialJitter, policy.InitialJitter, "the copy isn't changed")
+	assert.Equal(t, DefaultReconnectHerdJitter, herd.InitialJitter)
+	for range 100 {
+		assert.Less(t, policy.initialWait(), DefaultReconnectInitialJitter)
+		assert.Less(t, herd.initialWait(), DefaultReconnectHerdJitter)
+	}
+
+	policy = ReconnectPolicy{InitialDelay: time.Second}
+	assert.Equal(t, time.Second, policy.initialWait())
+	assert.Equal(t, DefaultReconnectInterval, policy.interval())
+}
@@ -0,0 +1,183 @@
+package nakama
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultRegionCacheTtl is how long the ping results of a RegionSelector are reused.
+const DefaultRegionCacheTtl = 5 * time.Minute
+
+var (
+	ErrNoRegionAvailable = newError("no region available")
+)
+
+// RegionEndpoint describes a Nakama deployment in a region.
+type RegionEndpoint struct {
+	Name   string
+	Host   string
+	Port   string
+	UseSSL bool
+}
+
+// RegionLatency is the ping result of a RegionEndpoint.
+type RegionLatency struct {
+	Endpoint  RegionEndpoint
+	Latency   time.Duration // latency of the healthcheck request
+	SocketRtt time.Duration // last socket round trip observed, zero if unknown
+	Err       error
+	CheckedAt time.Time
+}
+
+// Effective returns the latency used to rank the endpoint, the socket rtt is preferred when known.
+func (l *RegionLatency) Effective() time.Duration {
+	if l.SocketRtt > 0 {
+		return l.SocketRtt
+	}
+	return l.Latency
+}
+
+// RegionSelector pings the configured endpoints and selects the one with the lowest latency.
+type RegionSelector struct {
+	ServerKey string
+	TimeoutMs int
+	CacheTtl  time.Duration
+
+	endpoints []RegionEndpoint
+
+	mu          sync.Mutex
+	results     []*RegionLatency
+	evaluatedAt time.Time
+}
+
+// NewRegionSelector creates a RegionSelector for the endpoints.
+func NewRegionSelector(serverKey string, timeoutMs int, endpoints ...RegionEndpoint) *RegionSelector {
+	return &RegionSelector{
+		ServerKey: serverKey,
+		TimeoutMs: timeoutMs,
+		CacheTtl:  DefaultRegionCacheTtl,
+		endpoints: endpoints,
+	}
+}
+
+// Ping checks all the endpoints concurrently and returns the results sorted by latency, failed endpoints come last.
+func (r *RegionSelector) Ping() []*RegionLatency {
+	results := make([]*RegionLatency, len(r.endpoints))
+	wg := sync.WaitGroup{}
+	for i, endpoint := range r.endpoints {
+		wg.Add(1)
+		go func(i int, endpoint RegionEndpoint) {
+			defer wg.Done()
+			client := NewClient(r.ServerKey, endpoint.Host, endpoint.Port, endpoint.UseSSL, r.TimeoutMs, false)
+			startTime := time.Now()
+			err := client.ApiClient.Healthcheck("", make(map[string]string))
+			results[i] = &RegionLatency{
+				Endpoint:  endpoint,
+				Latency:   time.Since(startTime),
+				Err:       err,
+				CheckedAt: time.Now(),
+			}
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// keep the socket rtt observed before
+	for _, result := range results {
+		for _, old := range r.results {
+			if old.Endpoint.Name == result.Endpoint.Name {
+				result.SocketRtt = old.SocketRtt
+			}
+		}
+	}
+	r.results = results
+	r.evaluatedAt = time.Now()
+	r.sortLocked()
+	return r.copyLocked()
+}
+
+// Results returns the cached ping results.
+func (r *RegionSelector) Results() []*RegionLatency {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.copyLocked()
+}
+
+// ObserveSocketRtt records a socket round trip of the endpoint, the sockets of the clients created by NewClient record theirs.
+func (r *RegionSelector) ObserveSocketRtt(name string, rtt time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, result := range r.results {
+		if result.Endpoint.Name == name {
+			result.SocketRtt = rtt
+		}
+	}
+	r.sortLocked()
+}
+
+// Best returns the endpoint with the lowest latency, the endpoints are pinged again when the cache has expired.
+func (r *RegionSelector) Best() (*RegionLatency, error) {
+	r.mu.Lock()
+	expired := r.results == nil || time.Since(r.evaluatedAt) > r.CacheTtl
+	r.mu.Unlock()
+	if expired {
+		return r.Reevaluate()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bestLocked()
+}
+
+// Reevaluate forces a new ping of all the endpoints and returns the best one.
+func (r *RegionSelector) Reevaluate() (*RegionLatency, error) {
+	r.Ping()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bestLocked()
+}
+
+// NewClient creates a Client connected to the best endpoint, call it before the login.
+// The pings of the sockets created by the client are recorded as the socket rtt of the endpoint.
+func (r *RegionSelector) NewClient(autoRefreshSession bool) (*Client, error) {
+	best, err := r.Best()
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	endpoint := best.Endpoint
+	client := NewClient(r.ServerKey, endpoint.Host, endpoint.Port, endpoint.UseSSL, r.TimeoutMs, autoRefreshSession)
+	client.onSocketRtt = func(rtt time.Duration) {
+		r.ObserveSocketRtt(endpoint.Name, rtt)
+	}
+	return client, nil
+}
+
+func (r *RegionSelector) bestLocked() (*RegionLatency, error) {
+	if len(r.results) == 0 || r.results[0].Err != nil {
+		return nil, ErrNoRegionAvailable.With(len(r.endpoints))
+	}
+	best := *r.results[0]
+	return &best, nil
+}
+
+func (r *RegionSelector) sortLocked() {
+	sort.SliceStable(r.results, func(i, j int) bool {
+		a, b := r.results[i], r.results[j]
+		if (a.Err == nil) != (b.Err == nil) {
+			return a.Err == nil
+		}
+		return a.Effective() < b.Effective()
+	})
+}
+
+func (r *RegionSelector) copyLocked() []*RegionLatency {
+	results := make([]*RegionLatency, len(r.results))
+	for i, result := range r.results {
+		c := *result
+		results[i] = &c
+	}
+	return results
+}
@@ -0,0 +1,82 @@
+package nakama
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// regionServer starts a Nakama healthcheck answering after delay, it returns the endpoint and the count of the checks.
+func regionServer(t *testing.T, name string, delay time.Duration) (RegionEndpoint, *atomic.Int32) {
+	checks := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/healthcheck", r.URL.Path)
+		checks.Add(1)
+		time.Sleep(delay)
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	u, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+	return RegionEndpoint{Name: name, Host: host, Port: port}, checks
+}
+
+func TestRegionSelector(t *testing.T) {
+	fast, fastChecks := regionServer(t, "fast", 0)
+	slow, _ := regionServer(t, "slow", 50*time.Millisecond)
+	down, _ := regionServer(t, "down", 0)
+	down.Port = "1"
+	selector := NewRegionSelector("defaultkey", 5000, down, slow, fast)
+
+	// the failed endpoints come last
+	results := selector.Ping()
+	assert.Len(t, results, 3)
+	assert.Equal(t, []string{"fast", "slow", "down"}, []string{results[0].Endpoint.Name, results[1].Endpoint.Name, results[2].Endpoint.Name})
+	assert.NoError(t, results[0].Err)
+	assert.Error(t, results[2].Err)
+
+	// the results are cached
+	best, err := selector.Best()
+	assert.NoError(t, err)
+	assert.Equal(t, "fast", best.Endpoint.Name)
+	assert.Equal(t, int32(1), fastChecks.Load())
+
+	// a socket rtt ranks the endpoint, and is kept by a new ping
+	selector.ObserveSocketRtt("slow", time.Microsecond)
+	best, _ = selector.Best()
+	assert.Equal(t, "slow", best.Endpoint.Name)
+	best, err = selector.Reevaluate()
+	assert.NoError(t, err)
+	assert.Equal(t, "slow", best.Endpoint.Name)
+	assert.Equal(t, int32(2), fastChecks.Load())
+
+	// an expired cache pings again
+	selector.CacheTtl = 0
+	_, err = selector.Best()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), fastChecks.Load())
+
+	_, err = NewRegionSelector("defaultkey", 5000, down).Best()
+	assert.True(t, errors.Is(err, ErrNoRegionAvailable))
+}
+
+func TestRegionSelectorSocketRtt(t *testing.T) {
+	fast, _ := regionServer(t, "fast", 0)
+	selector := NewRegionSelector("defaultkey", 5000, fast)
+	client, err := selector.NewClient(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the pongs of the sockets of the client are recorded for its endpoint
+	socket := client.CreateSocket(nil, "token", false, false, nil, nil)
+	socket.observePong(3 * time.Millisecond)
+	assert.Equal(t, 3*time.Millisecond, selector.Results()[0].SocketRtt)
+}
@@ -0,0 +1,62 @@
+package nakama
+
+import (
+	"context"
+	"time"
+)
+
+// RequestLimiter bounds the http requests in flight, the others wait for a slot in the order they come.
+// A slot is held by an attempt only, it's released during the backoff of the retries.
+type RequestLimiter struct {
+	slots chan struct{}
+}
+
+// NewRequestLimiter returns a limiter of max concurrent requests.
+func NewRequestLimiter(max int) *RequestLimiter {
+	return &RequestLimiter{slots: make(chan struct{}, max)}
+}
+
+// acquire waits for a slot until ctx is done, it returns the time waited.
+// A nil limiter doesn't limit anything.
+func (l *RequestLimiter) acquire(ctx context.Context) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return 0, nil
+	default:
+	}
+	start := time.Now()
+	select {
+	case l.slots <- struct{}{}:
+		return time.Since(start), nil
+	case <-ctx.Done():
+		return time.Since(start), ctx.Err()
+	}
+}
+
+// release frees the slot of an attempt.
+func (l *RequestLimiter) release() {
+	if l != nil {
+		<-l.slots
+	}
+}
+
+// InFlight returns the number of requests holding a slot.
+func (l *RequestLimiter) InFlight() int {
+	return len(l.slots)
+}
+
+// WithMaxConcurrentRequests bounds the http requests in flight of the client, e.g. for the constrained network stacks
+// of the consoles and the phones opening a connection per request in a login burst. The others wait for a slot,
+// within the timeout of the call, the wait is counted by ClientStats.QueueWait. The requests aren't limited by default.
+func WithMaxConcurrentRequests(max int) ClientOption {
+	return func(opts *ClientOptions) error {
+		if max <= 0 {
+			return newError("invalid max concurrent requests").With(max)
+		}
+		opts.MaxConcurrentRequests = max
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+package nakama
+
+import (
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// DefaultResumeThreshold is the jump of the wall clock over the monotonic clock taken for a sleep of the device.
+const DefaultResumeThreshold = 10 * time.Second
+
+// DefaultResumeProbeTimeoutMs is the timeout of the ping checking the connection after a sleep.
+const DefaultResumeProbeTimeoutMs = 2000
+
+// SetResumeThreshold sets the jump of the wall clock over the monotonic clock between two heartbeats taken for
+// a sleep of the device, e.g. a laptop lid closed or a mobile doze, 0 restores DefaultResumeThreshold and
+// a negative threshold turns the detection off. The detection runs with the pings, see SetPingIntervalMs.
+func (socket *DefaultSocket) SetResumeThreshold(threshold time.Duration) {
+	socket.resumeThreshold.Store(int64(threshold))
+}
+
+func (socket *DefaultSocket) getResumeThreshold() time.Duration {
+	threshold := time.Duration(socket.resumeThreshold.Load())
+	if threshold == 0 {
+		return DefaultResumeThreshold
+	}
+	return threshold
+}
+
+// wallJump returns how much the wall clock has advanced more than the monotonic clock between last and now,
+// the monotonic clock stops while the device sleeps.
+func wallJump(last, now time.Time) time.Duration {
+	return now.Round(0).Sub(last.Round(0)) - now.Sub(last)
+}
+
+// checkResume heals the connection when the clocks show the device has slept since last.
+func (socket *DefaultSocket) checkResume(last, now time.Time) {
+	threshold := socket.getResumeThreshold()
+	if jump := wallJump(last, now); threshold > 0 && jump > threshold {
+		socket.onResume(jump)
+	}
+}
+
+// onResume checks the connection after a sleep of jump, the connections surviving a sleep are often half-dead:
+// when the probe ping fails the connection is dropped, and the socket reconnects with a refreshed session.
+func (socket *DefaultSocket) onResume(jump time.Duration) {
+	GetLogger().Infof("resumed after %s, checking the connection", jump.Round(time.Second))
+	if socket.eventHandle != nil {
+		go socket.eventHandle(EventTypeResumed, &RspResult{Data: []byte(jump.String())})
+	}
+	timeoutMs := min(DefaultResumeProbeTimeoutMs, socket.heartbeatTimeoutMs)
+	probe := &rtapi.Envelope{Message: &rtapi.Envelope_Ping{Ping: &rtapi.Ping{}}}
+	if err, ok := socket.sendNoReconnect(probe, timeoutMs).(error); ok {
+		GetLogger().Warn(wrapErr(err, "stale connection after resume"))
+		socket.staleDrop.Store(true)
+		socket.adapter.Drop()
+	}
+}
+
+// sendNoReconnect is Send failing at once when the connection is closed, rather than reconnecting.
+func (socket *DefaultSocket) sendNoReconnect(message *rtapi.Envelope, timeoutMs int) any {
+	if !socket.adapter.IsOpen() {
+		return ErrNotConnected
+	}
+	return socket.Send(message, &timeoutMs)
+}
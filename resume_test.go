@@ -0,0 +1,53 @@
+package nakama
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSocketResume(t *testing.T) {
+	var answer atomic.Bool
+	answer.Store(true)
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		if req.GetPing() != nil && answer.Load() {
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Pong{Pong: &rtapi.Pong{}}})
+		}
+	})
+	var resumed, reconnected atomic.Int32
+	socket, _ := server.socket(func(event EventType, data *RspResult) {
+		switch event {
+		case EventTypeResumed:
+			resumed.Add(1)
+		case EventTypeReConnected:
+			reconnected.Add(1)
+		}
+	})
+	var tokens atomic.Int32
+	socket.SetTokenSource(func() (string, error) {
+		tokens.Add(1)
+		return "token", nil
+	})
+	socket.SetHeartbeatTimeoutMs(100)
+	assert.NoError(t, socket.Connect())
+
+	now := time.Now()
+	socket.checkResume(now, now.Add(time.Minute))
+	assert.Equal(t, time.Duration(0), wallJump(now, now.Add(time.Minute)), "no sleep without wall clock jump")
+
+	// the connection answers the probe, it's kept
+	socket.onResume(time.Hour)
+	assert.Equal(t, int32(1), server.accepted.Load())
+
+	// a half-dead connection is dropped, the socket reconnects with a new token
+	answer.Store(false)
+	socket.onResume(time.Hour)
+	eventually(t, func() bool { return reconnected.Load() == 1 }, "the socket has not reconnected")
+	assert.Equal(t, int32(2), server.accepted.Load())
+	assert.Equal(t, int32(2), tokens.Load())
+	assert.Equal(t, DisconnectStale, socket.LastDisconnect().Kind)
+	eventually(t, func() bool { return resumed.Load() == 2 }, "the resumes have not been reported")
+}
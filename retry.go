@@ -0,0 +1,88 @@
+package nakama
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NorthNorthGames/nakama-go/backoff"
+)
+
+// RetryPolicy configures the retries of the http calls failing with a transient error,
+// i.e. a network error or a 429, 502, 503, 504 status.
+type RetryPolicy struct {
+	MaxAttempts int           // The number of attempts including the first one, 0 or 1 disables the retries.
+	Interval    time.Duration // The delay before the first retry.
+	MaxInterval time.Duration // The upper bound of the delay, 0 means no bound.
+	Multiplier  float64       // The growth of the delay between two retries, 0 or 1 keeps it constant.
+}
+
+// DefaultRetryPolicy returns a policy retrying 3 times with an exponential delay starting at 500ms.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		Interval:    500 * time.Millisecond,
+		MaxInterval: 5 * time.Second,
+		Multiplier:  2,
+	}
+}
+
+// delay returns the delay before the retry following the attempt, attempt starts at 1.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	return backoff.Exponential{Initial: p.Interval, Max: p.MaxInterval, Multiplier: p.Multiplier}.Delay(attempt)
+}
+
+// retryableStatus reports whether a http status is worth a retry.
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// RetryAttempt is an attempt of a call, see RetryError.
+type RetryAttempt struct {
+	StatusCode int           // the http status, 0 for a network error or a timeout
+	Duration   time.Duration // the time spent in the attempt
+	Err        error
+}
+
+// RetryError is returned by the calls ending on a transient error after several attempts, once the retries
+// of the RetryPolicy or the timeout of the call are exhausted, get it with errors.As to schedule a later retry.
+// The error of a single attempt is returned as it is.
+// It unwraps to the error of the last attempt, e.g. a *HTTPError.
+type RetryError struct {
+	Attempts   []RetryAttempt
+	RetryAfter time.Duration // the wait asked by the Retry-After header of the last response, 0 if none
+}
+
+func (e *RetryError) Error() string {
+	msg := "1 attempt failed"
+	if len(e.Attempts) != 1 {
+		msg = fmt.Sprintf("%d attempts failed", len(e.Attempts))
+	}
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(", retry after %s", e.RetryAfter)
+	}
+	return msg + ": " + e.Unwrap().Error()
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Attempts[len(e.Attempts)-1].Err
+}
+
+// retryAfterHeader returns the wait of a Retry-After header in seconds or as a http date, 0 if none.
+func retryAfterHeader(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
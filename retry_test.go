@@ -0,0 +1,152 @@
+package nakama
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryDeadlineBudget(t *testing.T) {
+	var calls atomic.Int32
+	var hangFirst atomic.Bool
+	hangFirst.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 || !hangFirst.Load() {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	policy := RetryPolicy{MaxAttempts: 10, Interval: 10 * time.Millisecond}
+
+	// the hanging attempt is cut short and leaves time for a retry
+	client, err := NewClientWithOptions(WithURL(server.URL), WithTimeout(1000), WithAttemptTimeout(100), WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.ApiClient.Healthcheck("", nil); err != nil {
+		t.Fatalf("the retry should succeed: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("calls %d", calls.Load())
+	}
+
+	// all the attempts share the timeout of the call
+	hangFirst.Store(false)
+	client, err = NewClientWithOptions(WithURL(server.URL), WithTimeout(250), WithAttemptTimeout(100), WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := client.ApiClient.Healthcheck("", nil); err == nil {
+		t.Fatal("expected a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("the call took %s, more than its timeout", elapsed)
+	}
+}
+
+func TestFriendCallsRetried(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"friends":[{"user":{"id":"friend"}}]}`))
+	}))
+	defer server.Close()
+
+	policy := RetryPolicy{MaxAttempts: 3, Interval: 10 * time.Millisecond}
+	client, err := NewClientWithOptions(WithURL(server.URL), WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := "token"
+	list, err := client.ApiClient.ListFriends(&token, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 || list.Friends[0].User.Id != "friend" {
+		t.Fatalf("calls %d, list %v", calls.Load(), list)
+	}
+	if err := client.ApiClient.AddFriends(&token, []string{"friend"}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRetryError(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Interval: time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.ApiClient.Healthcheck("", nil)
+	retryErr := &RetryError{}
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected a retry error: %v", err)
+	}
+	if retryErr.RetryAfter != 30*time.Second {
+		t.Fatalf("retry after %s", retryErr.RetryAfter)
+	}
+	statuses := []int{}
+	for _, attempt := range retryErr.Attempts {
+		statuses = append(statuses, attempt.StatusCode)
+	}
+	if fmt.Sprint(statuses) != "[502 503 503]" {
+		t.Fatalf("attempts %v", statuses)
+	}
+	if httpStatusOf(err) != http.StatusServiceUnavailable {
+		t.Fatalf("the last error is unwrapped: %v", err)
+	}
+
+	// the error of a single attempt is returned as it is
+	calls.Store(0)
+	client, err = NewClientWithOptions(WithURL(server.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.ApiClient.Healthcheck("", nil)
+	if errors.As(err, &retryErr) || httpStatusOf(err) != http.StatusBadGateway {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if msg := (&RetryError{Attempts: []RetryAttempt{{Err: err}}}).Error(); !strings.HasPrefix(msg, "1 attempt failed: ") {
+		t.Fatalf("message %q", msg)
+	}
+
+	// the errors not retried are returned as they are
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	err = client.ApiClient.Healthcheck("", nil)
+	if errors.As(err, &retryErr) || httpStatusOf(err) != http.StatusNotFound {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if retryAfterHeader(time.Unix(1030, 0).UTC().Format(http.TimeFormat), time.Unix(1000, 0)) != 30*time.Second {
+		t.Fatal("the http date is not parsed")
+	}
+}
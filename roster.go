@@ -0,0 +1,187 @@
+package nakama
+
+import (
+	"sync"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"google.golang.org/protobuf/proto"
+)
+
+// RosterStatus is the roster id of the users followed with FollowUsers.
+const RosterStatus = "status"
+
+// RosterDiff is the change of a roster after a presence event, for the list widgets to redraw the changed rows only.
+type RosterDiff struct {
+	// Id of the roster, "channel:<id>", "match:<id>", "party:<id>", "stream:<label>" or RosterStatus.
+	Id      string
+	Added   []*rtapi.UserPresence
+	Removed []*rtapi.UserPresence
+	Updated []*rtapi.UserPresence // presences that left and joined again, e.g. a status change
+	Current []*rtapi.UserPresence // the roster after the change, in the order joined
+}
+
+// presenceKey identifies a presence, a user is present once per session.
+type presenceKey struct {
+	userId    string
+	sessionId string
+}
+
+func keyOfPresence(presence *rtapi.UserPresence) presenceKey {
+	return presenceKey{userId: presence.GetUserId(), sessionId: presence.GetSessionId()}
+}
+
+// roster is the presences of a chat channel, a match, a party, a stream or the followed users.
+type roster struct {
+	order     []presenceKey
+	presences map[presenceKey]*rtapi.UserPresence
+}
+
+func (r *roster) current() []*rtapi.UserPresence {
+	current := make([]*rtapi.UserPresence, 0, len(r.order))
+	for _, key := range r.order {
+		current = append(current, r.presences[key])
+	}
+	return current
+}
+
+// Roster turns the joins and leaves of the presence events into diffs of the presence lists.
+// Chain HandleEvent in the EventHandler of the socket, and seed the rosters with the presences of the joins.
+type Roster struct {
+	// OnDiff is called after each presence event changing a roster.
+	OnDiff func(diff *RosterDiff)
+
+	mu      sync.Mutex
+	rosters map[string]*roster
+}
+
+// NewRoster creates a Roster calling onDiff.
+func NewRoster(onDiff func(diff *RosterDiff)) *Roster {
+	return &Roster{OnDiff: onDiff, rosters: map[string]*roster{}}
+}
+
+// Seed replaces the presences of the roster, e.g. with the presences of the channel joined.
+func (r *Roster) Seed(id string, presences []*rtapi.UserPresence) *RosterDiff {
+	r.mu.Lock()
+	delete(r.rosters, id)
+	r.mu.Unlock()
+	return r.Apply(id, presences, nil)
+}
+
+// SeedChannel seeds the roster of a chat channel joined.
+func (r *Roster) SeedChannel(channel *rtapi.Channel) *RosterDiff {
+	return r.Seed("channel:"+channel.GetId(), channel.GetPresences())
+}
+
+// SeedMatch seeds the roster of a match joined.
+func (r *Roster) SeedMatch(match *rtapi.Match) *RosterDiff {
+	return r.Seed("match:"+match.GetMatchId(), match.GetPresences())
+}
+
+// SeedParty seeds the roster of a party joined.
+func (r *Roster) SeedParty(party *rtapi.Party) *RosterDiff {
+	return r.Seed("party:"+party.GetPartyId(), party.GetPresences())
+}
+
+// Current returns the presences of the roster, in the order joined.
+func (r *Roster) Current(id string) []*rtapi.UserPresence {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ros, ok := r.rosters[id]; ok {
+		return ros.current()
+	}
+	return []*rtapi.UserPresence{}
+}
+
+// Forget drops the roster, e.g. after leaving its channel.
+func (r *Roster) Forget(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rosters, id)
+}
+
+// Apply applies the joins and the leaves of a presence event to the roster and calls OnDiff when it has changed.
+// A presence both leaving and joining is updated, the leaves of unknown presences are ignored.
+func (r *Roster) Apply(id string, joins, leaves []*rtapi.UserPresence) *RosterDiff {
+	r.mu.Lock()
+	if r.rosters == nil {
+		r.rosters = map[string]*roster{}
+	}
+	ros, ok := r.rosters[id]
+	if !ok {
+		ros = &roster{presences: map[presenceKey]*rtapi.UserPresence{}}
+		r.rosters[id] = ros
+	}
+
+	diff := &RosterDiff{Id: id}
+	joined := make(map[presenceKey]*rtapi.UserPresence, len(joins))
+	for _, presence := range joins {
+		joined[keyOfPresence(presence)] = presence
+	}
+	removed := map[presenceKey]bool{}
+	for _, presence := range leaves {
+		key := keyOfPresence(presence)
+		old, ok := ros.presences[key]
+		if !ok {
+			continue
+		}
+		if _, rejoined := joined[key]; rejoined {
+			continue
+		}
+		removed[key] = true
+		delete(ros.presences, key)
+		diff.Removed = append(diff.Removed, old)
+	}
+	if len(removed) > 0 {
+		order := ros.order[:0]
+		for _, key := range ros.order {
+			if !removed[key] {
+				order = append(order, key)
+			}
+		}
+		ros.order = order
+	}
+	for _, presence := range joins {
+		key := keyOfPresence(presence)
+		old, ok := ros.presences[key]
+		switch {
+		case !ok:
+			ros.order = append(ros.order, key)
+			diff.Added = append(diff.Added, presence)
+		case !proto.Equal(old, presence):
+			diff.Updated = append(diff.Updated, presence)
+		default:
+			continue
+		}
+		ros.presences[key] = presence
+	}
+	diff.Current = ros.current()
+	onDiff := r.OnDiff
+	r.mu.Unlock()
+
+	if onDiff != nil && len(diff.Added)+len(diff.Removed)+len(diff.Updated) > 0 {
+		onDiff(diff)
+	}
+	return diff
+}
+
+// HandleEvent is an EventHandler applying the presence events received on the socket,
+// chain it in the EventHandler passed to CreateSocket.
+func (r *Roster) HandleEvent(event EventType, data *RspResult) {
+	if event != EventTypeMessage || data == nil || data.Decoded == nil {
+		return
+	}
+	switch msg := data.Decoded.GetMessage().(type) {
+	case *rtapi.Envelope_ChannelPresenceEvent:
+		r.Apply(streamKey(data.Decoded), msg.ChannelPresenceEvent.GetJoins(), msg.ChannelPresenceEvent.GetLeaves())
+	case *rtapi.Envelope_MatchPresenceEvent:
+		r.Apply(streamKey(data.Decoded), msg.MatchPresenceEvent.GetJoins(), msg.MatchPresenceEvent.GetLeaves())
+	case *rtapi.Envelope_PartyPresenceEvent:
+		r.Apply(streamKey(data.Decoded), msg.PartyPresenceEvent.GetJoins(), msg.PartyPresenceEvent.GetLeaves())
+	case *rtapi.Envelope_StreamPresenceEvent:
+		r.Apply(streamKey(data.Decoded), msg.StreamPresenceEvent.GetJoins(), msg.StreamPresenceEvent.GetLeaves())
+	case *rtapi.Envelope_StatusPresenceEvent:
+		r.Apply(RosterStatus, msg.StatusPresenceEvent.GetJoins(), msg.StatusPresenceEvent.GetLeaves())
+	case *rtapi.Envelope_PartyClose:
+		r.Forget(streamKey(data.Decoded))
+	}
+}
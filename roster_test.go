@@ -0,0 +1,54 @@
+package nakama
+
+import (
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRosterDiffs(t *testing.T) {
+	presence := func(userId string) *rtapi.UserPresence {
+		return &rtapi.UserPresence{UserId: userId, SessionId: "s-" + userId, Username: userId}
+	}
+	diffs := []*RosterDiff{}
+	roster := NewRoster(func(diff *RosterDiff) { diffs = append(diffs, diff) })
+	roster.SeedChannel(&rtapi.Channel{Id: "room", Presences: []*rtapi.UserPresence{presence("a"), presence("b")}})
+	assert.Len(t, roster.Current("channel:room"), 2)
+
+	event := func(joins, leaves []*rtapi.UserPresence) *RspResult {
+		return &RspResult{Decoded: &rtapi.Envelope{Message: &rtapi.Envelope_ChannelPresenceEvent{
+			ChannelPresenceEvent: &rtapi.ChannelPresenceEvent{ChannelId: "room", Joins: joins, Leaves: leaves},
+		}}}
+	}
+	renamed := presence("a")
+	renamed.Username = "alice"
+	roster.HandleEvent(EventTypeMessage, event(
+		[]*rtapi.UserPresence{presence("c"), renamed},
+		[]*rtapi.UserPresence{presence("b"), presence("a"), presence("unknown")},
+	))
+
+	diff := diffs[len(diffs)-1]
+	assert.Equal(t, "channel:room", diff.Id)
+	assert.Equal(t, []string{"c"}, userIdsOf(diff.Added))
+	assert.Equal(t, []string{"b"}, userIdsOf(diff.Removed))
+	assert.Equal(t, []string{"a"}, userIdsOf(diff.Updated))
+	assert.Equal(t, []string{"a", "c"}, userIdsOf(diff.Current))
+	assert.Equal(t, "alice", diff.Current[0].Username)
+
+	// an event changing nothing isn't reported
+	count := len(diffs)
+	roster.HandleEvent(EventTypeMessage, event([]*rtapi.UserPresence{presence("c")}, nil))
+	assert.Len(t, diffs, count)
+
+	roster.Forget("channel:room")
+	assert.Empty(t, roster.Current("channel:room"))
+}
+
+func userIdsOf(presences []*rtapi.UserPresence) []string {
+	ids := []string{}
+	for _, presence := range presences {
+		ids = append(ids, presence.UserId)
+	}
+	return ids
+}
@@ -0,0 +1,75 @@
+package nakama
+
+import (
+	"context"
+	"encoding/json"
+
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// RpcClient calls the runtime functions of the server with the http key, without session,
+// e.g. for the webhooks of a backend. The transient failures are retried with DefaultRetryPolicy by default.
+type RpcClient struct {
+	HttpKey   string
+	ApiClient NakamaApiInterface
+
+	stats *ClientStats
+}
+
+// NewRpcClient creates a RpcClient for the server at baseUrl, e.g. "https://nakama.example.com:7350".
+// The options are applied after the url, WithServerKey and WithAutoRefreshSession are ignored.
+func NewRpcClient(baseUrl, httpKey string, opts ...ClientOption) (*RpcClient, error) {
+	if httpKey == "" {
+		return nil, newError("'httpKey' is a required parameter but is empty")
+	}
+	options := &ClientOptions{RetryPolicy: DefaultRetryPolicy()}
+	for _, opt := range append([]ClientOption{WithURL(baseUrl)}, opts...) {
+		if err := opt(options); err != nil {
+			return nil, wrapErr(err)
+		}
+	}
+	client := newClient(options)
+	return &RpcClient{HttpKey: httpKey, ApiClient: client.ApiClient, stats: client.stats}, nil
+}
+
+// Stats returns the counters of the calls.
+func (rc *RpcClient) Stats() *ClientStats {
+	return rc.stats
+}
+
+// Call calls the rpc with a raw payload, an empty payload calls the rpc without body.
+func (rc *RpcClient) Call(ctx context.Context, id string, payload string) (*api.Rpc, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, wrapErr(err, id)
+	}
+	if payload == "" {
+		return rc.ApiClient.RpcFunc2("", id, "", rc.HttpKey, make(map[string]string))
+	}
+	return rc.ApiClient.RpcFunc("", id, payload, rc.HttpKey, make(map[string]string))
+}
+
+// CallRpc calls the rpc with the JSON of input and decodes the payload of the response into a Rsp,
+// input can be nil. A zero Rsp is returned when the response has no payload.
+func CallRpc[Req any, Rsp any](ctx context.Context, rc *RpcClient, id string, input *Req) (*Rsp, error) {
+	payload := ""
+	if input != nil {
+		data, err := json.Marshal(input)
+		if err != nil {
+			return nil, wrapErr(err, id)
+		}
+		payload = string(data)
+	}
+
+	rpc, err := rc.Call(ctx, id, payload)
+	if err != nil {
+		return nil, wrapErr(err, id)
+	}
+	result := new(Rsp)
+	if rpc.GetPayload() == "" {
+		return result, nil
+	}
+	if err := DecodeJSON([]byte(rpc.Payload), result); err != nil {
+		return nil, wrapErr(err, id, rpc.Payload)
+	}
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package nakama
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRpcClient(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path != "/v2/rpc/reward" || r.URL.Query().Get("http_key") != "secret" || r.Header.Get("Authorization") != "" {
+			t.Errorf("unexpected request %s %s", r.URL, r.Header.Get("Authorization"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		var payload string
+		if err := json.Unmarshal(body, &payload); err != nil || payload != `{"user_id":"u1"}` {
+			t.Errorf("unexpected body %s", body)
+		}
+		w.Write([]byte(`{"id":"reward","payload":"{\"coins\":9007199254740993}"}`))
+	}))
+	defer server.Close()
+
+	policy := RetryPolicy{MaxAttempts: 2, Interval: time.Millisecond}
+	rc, err := NewRpcClient(server.URL, "secret", WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type reward struct {
+		UserId string `json:"user_id"`
+	}
+	type rewarded struct {
+		Coins int64 `json:"coins"`
+	}
+	result, err := CallRpc[reward, rewarded](context.Background(), rc, "reward", &reward{UserId: "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Coins != 9007199254740993 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if calls != 2 || rc.Stats().Requests() != 1 || rc.Stats().Failures() != 0 {
+		t.Fatalf("calls %d, stats %d/%d", calls, rc.Stats().Requests(), rc.Stats().Failures())
+	}
+}
@@ -0,0 +1,166 @@
+package nakama
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"google.golang.org/protobuf/proto"
+)
+
+// ScoreTokenMetadataKey is the metadata key carrying the score token of a record, so the server
+// can detect the resubmits of a result too, e.g. in a before hook of the record writes.
+const ScoreTokenMetadataKey = "score_token"
+
+// DefaultScoreDedupWindow is the time a score token can't be submitted again, see WithScoreDedupWindow.
+const DefaultScoreDedupWindow = 10 * time.Second
+
+// ErrScoreDuplicate is returned by the score submissions when the token has already been submitted within the window.
+var ErrScoreDuplicate = newError("duplicate score submission")
+
+// ScoreToken identifies the result of a gameplay session, the submissions of a result share its token.
+type ScoreToken string
+
+// NewScoreToken returns a random token for the result of a gameplay session.
+func NewScoreToken() ScoreToken {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		// never happens, the time keeps the token unique enough
+		return ScoreToken(hex.EncodeToString([]byte(time.Now().String())))
+	}
+	return ScoreToken(hex.EncodeToString(token))
+}
+
+// WithScoreDedupWindow sets the time a score token can't be submitted again, DefaultScoreDedupWindow is used by default.
+// Zero turns the deduplication off, the tokens are still sent in the metadata.
+func WithScoreDedupWindow(window time.Duration) ClientOption {
+	return func(opts *ClientOptions) error {
+		if window < 0 {
+			return newError("invalid score dedup window").With(window)
+		}
+		opts.ScoreDedupWindow = &window
+		return nil
+	}
+}
+
+// scoreSubmissions are the score tokens submitted within the window.
+type scoreSubmissions struct {
+	mu        sync.Mutex
+	window    time.Duration
+	submitted map[ScoreToken]time.Time // token:time of the submission
+}
+
+func newScoreSubmissions(window time.Duration) *scoreSubmissions {
+	return &scoreSubmissions{window: window, submitted: map[ScoreToken]time.Time{}}
+}
+
+// claim records the submission of the token, false if it has been submitted within the window.
+func (s *scoreSubmissions) claim(token ScoreToken, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.window == 0 {
+		return true
+	}
+	for t, at := range s.submitted {
+		if now.Sub(at) >= s.window {
+			delete(s.submitted, t)
+		}
+	}
+	if _, ok := s.submitted[token]; ok {
+		return false
+	}
+	s.submitted[token] = now
+	return true
+}
+
+// release forgets the token, its next submission is sent.
+func (s *scoreSubmissions) release(token ScoreToken) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.submitted, token)
+}
+
+// withScoreToken adds the token to the metadata of a record, a JSON object. The other fields are kept as they are,
+// e.g. the ints past 2^53 and the html characters.
+func withScoreToken(metadata string, token ScoreToken) (string, error) {
+	fields := map[string]json.RawMessage{}
+	if metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &fields); err != nil || fields == nil {
+			return "", ErrRecordInvalid.With("metadata isn't a JSON object", err)
+		}
+	}
+	encoded, err := json.Marshal(token)
+	if err != nil {
+		return "", wrapErr(err)
+	}
+	fields[ScoreTokenMetadataKey] = encoded
+	data := &bytes.Buffer{}
+	encoder := json.NewEncoder(data)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(fields); err != nil {
+		return "", wrapErr(err)
+	}
+	return strings.TrimSuffix(data.String(), "\n"), nil
+}
+
+// submitScore claims the token and writes the record, the token is released when the write fails so it can be retried.
+func submitScore[T any](c *Client, token ScoreToken, write func() (T, error)) (T, error) {
+	var zero T
+	if token == "" {
+		return zero, newError("empty score token")
+	}
+	if !c.scores.claim(token, c.now()) {
+		return zero, ErrScoreDuplicate.With(token)
+	}
+	record, err := write()
+	if err != nil {
+		c.scores.release(token)
+		return zero, err
+	}
+	return record, nil
+}
+
+// SubmitLeaderboardScore writes a record to a leaderboard like WriteLeaderboardRecord, with the token in its metadata.
+// A token submitted again within the window, e.g. by a double tap, returns ErrScoreDuplicate without any request,
+// use ResubmitScore to submit it again on purpose. The request isn't modified.
+func (c *Client) SubmitLeaderboardScore(session *Session, leaderboardId string, token ScoreToken, request *api.WriteLeaderboardRecordRequest_LeaderboardRecordWrite) (*api.LeaderboardRecord, error) {
+	if request == nil {
+		return nil, newError("'record' is a required parameter but is null or empty.")
+	}
+	return submitScore(c, token, func() (*api.LeaderboardRecord, error) {
+		request := proto.Clone(request).(*api.WriteLeaderboardRecordRequest_LeaderboardRecordWrite)
+		metadata, err := withScoreToken(request.GetMetadata(), token)
+		if err != nil {
+			return nil, wrapErr(err, leaderboardId)
+		}
+		request.Metadata = metadata
+		return c.WriteLeaderboardRecord(session, leaderboardId, request)
+	})
+}
+
+// SubmitTournamentScore writes a record to a tournament like WriteTournamentRecord, deduplicated like SubmitLeaderboardScore.
+func (c *Client) SubmitTournamentScore(session *Session, tournamentId string, token ScoreToken, request *api.WriteTournamentRecordRequest_TournamentRecordWrite) (*api.LeaderboardRecord, error) {
+	if request == nil {
+		return nil, newError("'record' is a required parameter but is empty.")
+	}
+	return submitScore(c, token, func() (*api.LeaderboardRecord, error) {
+		request := proto.Clone(request).(*api.WriteTournamentRecordRequest_TournamentRecordWrite)
+		metadata, err := withScoreToken(request.GetMetadata(), token)
+		if err != nil {
+			return nil, wrapErr(err, tournamentId)
+		}
+		request.Metadata = metadata
+		return c.WriteTournamentRecord(session, tournamentId, request)
+	})
+}
+
+// ResubmitScore forgets the token, its next submission is sent even within the window, e.g. a legitimate resubmit
+// after the server has reset the record.
+func (c *Client) ResubmitScore(token ScoreToken) {
+	c.scores.release(token)
+}
@@ -0,0 +1,102 @@
+package nakama
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitLeaderboardScore(t *testing.T) {
+	var mu sync.Mutex
+	metadata := []string{}
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		write := struct{ Metadata string }{}
+		json.Unmarshal(body, &write)
+		mu.Lock()
+		defer mu.Unlock()
+		metadata = append(metadata, write.Metadata)
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"leaderboard_id":"weekly","score":"10"}`))
+	}))
+	defer server.Close()
+
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	client, err := NewClientWithOptions(WithURL(server.URL), WithClock(clock), WithScoreDedupWindow(time.Minute))
+	assert.NoError(t, err)
+	session := &Session{Token: "token"}
+	request := &api.WriteLeaderboardRecordRequest_LeaderboardRecordWrite{Score: 10, Metadata: `{"level":3}`}
+	token := NewScoreToken()
+
+	record, err := client.SubmitLeaderboardScore(session, "weekly", token, request)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), record.GetScore())
+	assert.JSONEq(t, `{"level":3,"score_token":"`+string(token)+`"}`, metadata[0])
+	assert.Equal(t, `{"level":3}`, request.Metadata, "the request isn't modified")
+
+	// the double tap isn't sent
+	_, err = client.SubmitLeaderboardScore(session, "weekly", token, request)
+	assert.True(t, errors.Is(err, ErrScoreDuplicate))
+	assert.Len(t, metadata, 1)
+
+	// the resubmit on purpose and the end of the window are sent
+	client.ResubmitScore(token)
+	_, err = client.SubmitLeaderboardScore(session, "weekly", token, request)
+	assert.NoError(t, err)
+	clock.now = clock.now.Add(time.Minute)
+	_, err = client.SubmitLeaderboardScore(session, "weekly", token, request)
+	assert.NoError(t, err)
+	assert.Len(t, metadata, 3)
+
+	// a failed submission can be retried
+	fail = true
+	other := NewScoreToken()
+	assert.NotEqual(t, token, other)
+	_, err = client.SubmitTournamentScore(session, "cup", other, &api.WriteTournamentRecordRequest_TournamentRecordWrite{Score: 1})
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrScoreDuplicate))
+	fail = false
+	_, err = client.SubmitTournamentScore(session, "cup", other, &api.WriteTournamentRecordRequest_TournamentRecordWrite{Score: 1})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"score_token":"`+string(other)+`"}`, metadata[len(metadata)-1])
+
+	_, err = client.SubmitLeaderboardScore(session, "weekly", NewScoreToken(), &api.WriteLeaderboardRecordRequest_LeaderboardRecordWrite{Metadata: "[]"})
+	assert.True(t, errors.Is(err, ErrRecordInvalid))
+
+	// the nil records are refused before the token is claimed
+	token = NewScoreToken()
+	_, err = client.SubmitLeaderboardScore(session, "weekly", token, nil)
+	assert.Error(t, err)
+	_, err = client.SubmitTournamentScore(session, "cup", token, nil)
+	assert.Error(t, err)
+	_, err = client.SubmitLeaderboardScore(session, "weekly", token, request)
+	assert.NoError(t, err)
+}
+
+func TestWithScoreToken(t *testing.T) {
+	// the other fields are kept as they are
+	metadata, err := withScoreToken(`{"seed":9007199254740993,"html":"<b>&</b>","nested":{"a":[1,2]}}`, "token")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"html":"<b>&</b>","nested":{"a":[1,2]},"score_token":"token","seed":9007199254740993}`, metadata)
+
+	metadata, err = withScoreToken("", "token")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"score_token":"token"}`, metadata)
+
+	for _, invalid := range []string{"[]", "null", "3", "{"} {
+		_, err = withScoreToken(invalid, "token")
+		assert.True(t, errors.Is(err, ErrRecordInvalid), invalid)
+	}
+}
@@ -0,0 +1,360 @@
+package nakama
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+var (
+	// ErrNoSession is returned by the NakamaSDK calls made before the login.
+	ErrNoSession = newError("no session, login first")
+	// ErrNotConnected is returned by the calls needing the socket before Connect.
+	ErrNotConnected = newError("socket not connected")
+)
+
+// NakamaSDK owns the client, the session, the socket and the caches of a game,
+// the state changes seen on the socket and through the http calls are published on Events.
+type NakamaSDK struct {
+	Client        *Client
+	Store         SessionStore // optional, receives the session of Login
+	Events        *EventBus
+	Notifications *NotificationCenter
+	Leaderboards  *LeaderboardCache
+
+	Chat    *ChatService
+	Matches *MatchService
+	Friends *FriendService
+	Storage *StorageService
+
+	mu      sync.RWMutex
+	session *Session
+	socket  *DefaultSocket
+}
+
+// NewNakamaSDK creates a NakamaSDK around the client, store can be nil.
+func NewNakamaSDK(client *Client, store SessionStore) *NakamaSDK {
+	sdk := &NakamaSDK{
+		Client:        client,
+		Store:         store,
+		Events:        NewEventBus(),
+		Notifications: NewNotificationCenter(),
+		Leaderboards:  NewLeaderboardCache(),
+	}
+	sdk.Chat = &ChatService{sdk: sdk}
+	sdk.Matches = &MatchService{sdk: sdk}
+	sdk.Friends = &FriendService{sdk: sdk}
+	sdk.Storage = &StorageService{sdk: sdk}
+	return sdk
+}
+
+// Login runs the auth chain and keeps the session, the session is saved in Store when the chain has none.
+func (sdk *NakamaSDK) Login(ctx context.Context, chain *AuthChain) (*Session, error) {
+	if chain.Store == nil {
+		chain.Store = sdk.Store
+	}
+	session, err := sdk.Client.Login(ctx, chain)
+	if err != nil {
+		return nil, err
+	}
+	sdk.SetSession(session)
+	return session, nil
+}
+
+// SetSession replaces the session used by the calls, e.g. one restored by the game itself.
+func (sdk *NakamaSDK) SetSession(session *Session) {
+	sdk.mu.Lock()
+	sdk.session = session
+	sdk.mu.Unlock()
+	sdk.Events.Publish(&DomainEvent{Kind: DomainEventSessionChanged, Payload: session})
+}
+
+// Session returns the current session, nil before the login.
+func (sdk *NakamaSDK) Session() *Session {
+	sdk.mu.RLock()
+	defer sdk.mu.RUnlock()
+	return sdk.session
+}
+
+// Socket returns the socket opened by Connect, nil before.
+func (sdk *NakamaSDK) Socket() *DefaultSocket {
+	sdk.mu.RLock()
+	defer sdk.mu.RUnlock()
+	return sdk.socket
+}
+
+func (sdk *NakamaSDK) requireSession() (*Session, error) {
+	session := sdk.Session()
+	if session == nil {
+		return nil, ErrNoSession
+	}
+	return session, nil
+}
+
+func (sdk *NakamaSDK) requireSocket() (*DefaultSocket, error) {
+	socket := sdk.Socket()
+	if socket == nil {
+		return nil, ErrNotConnected
+	}
+	return socket, nil
+}
+
+// Connect opens the socket with the current session, it's a no-op when the socket is already opened.
+func (sdk *NakamaSDK) Connect(createStatus bool) error {
+	session, err := sdk.requireSession()
+	if err != nil {
+		return wrapErr(err)
+	}
+
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+	if sdk.socket != nil {
+		return nil
+	}
+	socket := sdk.Client.CreateSocket(sdk.handleEvent, session.Token, sdk.Client.UseSSL, false, nil, &createStatus)
+	socket.SetTokenSource(func() (string, error) { return sdk.Client.Sessions().Token(session) })
+	socket.SetContext(ContextWithSession(context.Background(), session))
+	if err := socket.Connect(); err != nil {
+		return wrapErr(err)
+	}
+	sdk.socket = socket
+	return nil
+}
+
+// Close disconnects the socket, the session is kept.
+func (sdk *NakamaSDK) Close() {
+	sdk.mu.Lock()
+	socket := sdk.socket
+	sdk.socket = nil
+	sdk.mu.Unlock()
+	if socket != nil {
+		socket.Disconnect()
+	}
+}
+
+// Logout closes the socket, logs the session out and clears the Store.
+func (sdk *NakamaSDK) Logout() error {
+	sdk.Close()
+	session := sdk.Session()
+	if session == nil {
+		return nil
+	}
+	if err := sdk.Client.SessionLogout(session, session.Token, session.RefreshToken); err != nil {
+		return wrapErr(err)
+	}
+	if sdk.Store != nil {
+		if err := sdk.Store.Clear(); err != nil {
+			return wrapErr(err)
+		}
+	}
+	sdk.SetSession(nil)
+	return nil
+}
+
+// handleEvent is the EventHandler of the socket, it publishes the domain events.
+func (sdk *NakamaSDK) handleEvent(event EventType, data *RspResult) {
+	switch event {
+	case EventTypeConnected:
+		sdk.Events.Publish(&DomainEvent{Kind: DomainEventSocketConnected})
+	case EventTypeReconnecting:
+		sdk.Events.Publish(&DomainEvent{Kind: DomainEventSocketReconnecting})
+	case EventTypeReConnected:
+		sdk.Events.Publish(&DomainEvent{Kind: DomainEventSocketReconnected})
+	case EventTypeMessage:
+		if data == nil {
+			return
+		}
+		sdk.Notifications.HandleEvent(event, data)
+		if data.Decoded != nil && sdk.Chat.suppressEcho(data.Decoded) {
+			return
+		}
+		sdk.Events.Publish(domainEventOf(data.Decoded))
+	}
+}
+
+// ChatService groups the chat calls of the NakamaSDK.
+type ChatService struct {
+	sdk        *NakamaSDK
+	optimistic optimisticChat
+}
+
+// Join joins a chat channel, see DefaultSocket.JoinChat.
+func (s *ChatService) Join(target ChatTarget) (*rtapi.Channel, error) {
+	socket, err := s.sdk.requireSocket()
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return socket.JoinChat(target)
+}
+
+// Leave leaves a chat channel.
+func (s *ChatService) Leave(channelId string) error {
+	socket, err := s.sdk.requireSocket()
+	if err != nil {
+		return wrapErr(err)
+	}
+	return socket.LeaveChat(channelId)
+}
+
+// Send writes a message to a chat channel, content is a JSON object.
+func (s *ChatService) Send(channelId, content string) (*rtapi.ChannelMessageAck, error) {
+	socket, err := s.sdk.requireSocket()
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return socket.WriteChatMessage(channelId, content)
+}
+
+// History iterates over the message history of a channel.
+func (s *ChatService) History(ctx context.Context, channelId string, forward bool) iter.Seq2[*api.ChannelMessage, error] {
+	session, err := s.sdk.requireSession()
+	if err != nil {
+		return func(yield func(*api.ChannelMessage, error) bool) { yield(nil, err) }
+	}
+	return s.sdk.Client.ChannelMessages(ctx, session, channelId, forward)
+}
+
+// MatchService groups the match calls of the NakamaSDK.
+type MatchService struct {
+	sdk *NakamaSDK
+}
+
+// Create creates a match with an optional name.
+func (s *MatchService) Create(name *string) (*rtapi.Match, error) {
+	socket, err := s.sdk.requireSocket()
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return socket.CreateMatch(name)
+}
+
+// Join joins a match by id.
+func (s *MatchService) Join(matchId string, metadata map[string]string) (*rtapi.Match, error) {
+	socket, err := s.sdk.requireSocket()
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return socket.JoinMatch(&matchId, nil, metadata)
+}
+
+// JoinMatched joins the match found by the matchmaker.
+func (s *MatchService) JoinMatched(matched *rtapi.MatchmakerMatched, metadata map[string]string) (*rtapi.Match, error) {
+	socket, err := s.sdk.requireSocket()
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return socket.JoinMatchedMatch(matched, metadata)
+}
+
+// Leave leaves a match.
+func (s *MatchService) Leave(matchId string) error {
+	socket, err := s.sdk.requireSocket()
+	if err != nil {
+		return wrapErr(err)
+	}
+	return socket.LeaveMatch(matchId)
+}
+
+// SendState sends the match data to the presences, all the match when presences is nil.
+func (s *MatchService) SendState(matchId string, opCode int64, data []byte, presences []*rtapi.UserPresence, reliable bool) error {
+	socket, err := s.sdk.requireSocket()
+	if err != nil {
+		return wrapErr(err)
+	}
+	return socket.SendMatchState(matchId, opCode, data, presences, reliable)
+}
+
+// FriendsChange is the payload of DomainEventFriendsChanged.
+type FriendsChange struct {
+	Ids       []string
+	Usernames []string
+	State     int // the new FriendState, -1 when the users were removed
+}
+
+// FriendService groups the friend calls of the NakamaSDK.
+type FriendService struct {
+	sdk *NakamaSDK
+}
+
+// List iterates over the friends, state filters them when not nil.
+func (s *FriendService) List(ctx context.Context, state *int) iter.Seq2[*api.Friend, error] {
+	session, err := s.sdk.requireSession()
+	if err != nil {
+		return func(yield func(*api.Friend, error) bool) { yield(nil, err) }
+	}
+	return s.sdk.Client.Friends(ctx, session, state)
+}
+
+func (s *FriendService) change(call func(*Session, []string, []string) error, ids, usernames []string, state int) error {
+	session, err := s.sdk.requireSession()
+	if err != nil {
+		return wrapErr(err)
+	}
+	if err := call(session, ids, usernames); err != nil {
+		return wrapErr(err)
+	}
+	s.sdk.Events.Publish(&DomainEvent{
+		Kind:    DomainEventFriendsChanged,
+		Payload: &FriendsChange{Ids: ids, Usernames: usernames, State: state},
+	})
+	return nil
+}
+
+// Add sends friend requests, or accepts the received ones.
+func (s *FriendService) Add(ids, usernames []string) error {
+	return s.change(s.sdk.Client.AddFriends, ids, usernames, FriendStateInviteSent)
+}
+
+// Delete removes friends.
+func (s *FriendService) Delete(ids, usernames []string) error {
+	return s.change(s.sdk.Client.DeleteFriends, ids, usernames, -1)
+}
+
+// Block blocks users.
+func (s *FriendService) Block(ids, usernames []string) error {
+	return s.change(s.sdk.Client.BlockFriends, ids, usernames, FriendStateBlocked)
+}
+
+// StorageService groups the storage calls of the NakamaSDK.
+type StorageService struct {
+	sdk *NakamaSDK
+}
+
+// Read reads the storage objects.
+func (s *StorageService) Read(ids []*api.ReadStorageObjectId) (*api.StorageObjects, error) {
+	session, err := s.sdk.requireSession()
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return s.sdk.Client.ReadStorageObjects(session, &api.ReadStorageObjectsRequest{ObjectIds: ids})
+}
+
+// Write writes the storage objects and publishes their acks.
+func (s *StorageService) Write(objects []*api.WriteStorageObject) (*api.StorageObjectAcks, error) {
+	session, err := s.sdk.requireSession()
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	acks, err := s.sdk.Client.WriteStorageObjects(session, objects)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	s.sdk.Events.Publish(&DomainEvent{Kind: DomainEventStorageWritten, Payload: acks})
+	return acks, nil
+}
+
+// Delete deletes the storage objects and publishes their ids.
+func (s *StorageService) Delete(ids []*api.DeleteStorageObjectId) error {
+	session, err := s.sdk.requireSession()
+	if err != nil {
+		return wrapErr(err)
+	}
+	if err := s.sdk.Client.DeleteStorageObjects(session, &api.DeleteStorageObjectsRequest{ObjectIds: ids}); err != nil {
+		return wrapErr(err)
+	}
+	s.sdk.Events.Publish(&DomainEvent{Kind: DomainEventStorageDeleted, Payload: ids})
+	return nil
+}
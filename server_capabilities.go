@@ -0,0 +1,89 @@
+package nakama
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// Capability names an endpoint of Nakama 3.x missing on the older servers, or a rpc the server may not register.
+type Capability string
+
+// Server capabilities
+const (
+	CapabilityFriendsOfFriends Capability = "friends_of_friends" // ListFriendsOfFriends, Nakama 3.18+
+	CapabilityGroupBans        Capability = "group_bans"         // the rpcs of GroupBanRpcs, their calls fail without
+	CapabilityAccountSessions  Capability = "account_sessions"   // the rpcs of AccountSessionRpcs, their calls fail without
+)
+
+// capabilityUnsupported are the errors of the calls of the rpc capabilities on the servers without them.
+var capabilityUnsupported = map[Capability]*Error{
+	CapabilityGroupBans:       ErrGroupBansUnsupported,
+	CapabilityAccountSessions: ErrAccountSessionsUnsupported,
+}
+
+// serverCapabilities are the capabilities found missing on the server by the calls.
+type serverCapabilities struct {
+	mu      sync.Mutex
+	missing map[Capability]bool
+}
+
+func (sc *serverCapabilities) markMissing(capability Capability) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.missing == nil {
+		sc.missing = map[Capability]bool{}
+	}
+	if !sc.missing[capability] {
+		GetLogger().Infof("server capability %s missing, its calls return empty results", capability)
+	}
+	sc.missing[capability] = true
+}
+
+func (sc *serverCapabilities) isMissing(capability Capability) bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.missing[capability]
+}
+
+// Supports reports whether the server supports the capability as far as the client knows:
+// a capability is supported until a call finds its endpoint missing, its calls return empty results from then on,
+// or ErrGroupBansUnsupported for CapabilityGroupBans and ErrAccountSessionsUnsupported for CapabilityAccountSessions.
+func (c *Client) Supports(capability Capability) bool {
+	return !c.capabilities.isMissing(capability)
+}
+
+// endpointMissing reports whether err is the answer of a server without the endpoint called.
+func endpointMissing(err error) bool {
+	switch httpStatusOf(err) {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// capabilityRpc calls the rpc id of a rpc capability with request as JSON payload. The servers without the rpc
+// get the unsupported error of the capability, and the capability is marked missing.
+func (c *Client) capabilityRpc(session *Session, capability Capability, id string, request any) (*api.Rpc, error) {
+	if err := c.refreshSession(session); err != nil {
+		return nil, wrapErr(err)
+	}
+	unsupported := capabilityUnsupported[capability]
+	if !c.Supports(capability) {
+		return nil, unsupported.With(id)
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	rpc, err := c.apiFor(session).RpcFunc(session.Token, id, string(payload), "", make(map[string]string))
+	if endpointMissing(err) {
+		c.capabilities.markMissing(capability)
+		return nil, unsupported.With(id, err)
+	} else if err != nil {
+		return nil, wrapErr(err, id)
+	}
+	return rpc, nil
+}
@@ -0,0 +1,69 @@
+package nakama
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFriendsOfFriendsOnOlderServer(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+
+	list, err := client.ListFriendsOfFriends(session, nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, list.GetFriendsOfFriends())
+	assert.False(t, client.Supports(CapabilityFriendsOfFriends))
+
+	// the endpoint isn't called again
+	for _, err := range client.FriendsOfFriends(context.Background(), session) {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestGroupSizeFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the unknown field comes from a newer server
+		w.Write([]byte(`{"groups":[
+			{"id":"g1","edge_count":10,"max_count":10,"open":true,"added_later":1},
+			{"id":"g2","edge_count":3,"max_count":5,"open":false},
+			{"id":"g3"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+
+	groups := []GroupInfo{}
+	for group, err := range client.GroupInfos(context.Background(), session, nil) {
+		assert.NoError(t, err)
+		groups = append(groups, group)
+	}
+	assert.Len(t, groups, 3)
+	assert.True(t, groups[0].IsOpen())
+	assert.True(t, groups[0].Full())
+	assert.Equal(t, 2, groups[1].Vacancies())
+	assert.False(t, groups[1].IsOpen())
+	assert.Equal(t, -1, groups[2].Vacancies(), "an older server sends no size")
+	assert.False(t, groups[2].Full())
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -124,17 +125,7 @@ func (s *Session) decodeJWT(token string) (map[string]interface{}, error) {
 
 // parseInt64FromMap parses an int64 value from a map by key.
 func parseInt64FromMap(data map[string]interface{}, key string) (int64, error) {
-	value, ok := data[key]
-	if !ok {
-		return 0, errors.New("key not found in map")
-	}
-
-	floatValue, ok := value.(float64)
-	if !ok {
-		return 0, errors.New("value is not a number")
-	}
-
-	return int64(floatValue), nil
+	return GetInt64(data, key)
 }
 
 // Restore creates a Session from an existing token and refresh token.
@@ -142,3 +133,19 @@ func parseInt64FromMap(data map[string]interface{}, key string) (int64, error) {
 func Restore(token, refreshToken string) *Session {
 	return NewSession(token, refreshToken, false)
 }
+
+// stringVars returns the vars of the token as sent by the authentications, nil without vars.
+func (s *Session) stringVars() map[string]string {
+	if len(s.Vars) == 0 {
+		return nil
+	}
+	vars := make(map[string]string, len(s.Vars))
+	for key, value := range s.Vars {
+		if str, ok := value.(string); ok {
+			vars[key] = str
+		} else {
+			vars[key] = fmt.Sprint(value)
+		}
+	}
+	return vars
+}
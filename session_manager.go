@@ -0,0 +1,140 @@
+package nakama
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// SessionManager refreshes the sessions of a client with a single refresh in flight per session:
+// the calls finding the token expired while it's refreshed wait for the refresh and share its result,
+// e.g. a http call and a socket reconnect at the token expiry. See Client.Sessions.
+type SessionManager struct {
+	client *Client
+
+	mu        sync.Mutex // guards the tokens of the sessions while they're refreshed
+	flights   map[*Session]*refreshFlight
+	refreshes atomic.Int64
+}
+
+// refreshFlight is a refresh in flight.
+type refreshFlight struct {
+	done chan struct{}
+	err  error
+}
+
+func newSessionManager(client *Client) *SessionManager {
+	return &SessionManager{client: client, flights: map[*Session]*refreshFlight{}}
+}
+
+// Refreshes returns the count of the refresh calls sent to the server.
+func (m *SessionManager) Refreshes() int64 {
+	return m.refreshes.Load()
+}
+
+// Refresh refreshes the session, or waits for the refresh in flight of the session.
+// vars are ignored when joining a refresh in flight.
+func (m *SessionManager) Refresh(session *Session, vars map[string]string) error {
+	if session == nil {
+		return ErrSessionNotAuthenticated
+	}
+	m.mu.Lock()
+	return m.refreshLocked(session, vars)
+}
+
+// Ensure refreshes the session when its token expires within the ExpiredTimespanMs of the client
+// and AutoRefreshSession is set, then checks the session is valid.
+func (m *SessionManager) Ensure(session *Session) error {
+	if session == nil {
+		return ErrSessionNotAuthenticated.With()
+	}
+	c := m.client
+	now := c.now()
+
+	m.mu.Lock()
+	if session.Token == "" {
+		m.mu.Unlock()
+		return ErrSessionNotAuthenticated.With()
+	}
+	_, inFlight := m.flights[session]
+	if inFlight || c.AutoRefreshSession && session.refreshable(now.Unix()) &&
+		session.IsExpired((now.UnixMilli()+c.ExpiredTimespanMs)/1000) {
+		if err := m.refreshLocked(session, nil); err != nil {
+			return wrapErr(err)
+		}
+		m.mu.Lock()
+	}
+	defer m.mu.Unlock()
+	return session.Valid(now.Unix())
+}
+
+// Token returns the token of the session after Ensure, e.g. to reconnect a socket.
+func (m *SessionManager) Token(session *Session) (string, error) {
+	if err := m.Ensure(session); err != nil {
+		return "", wrapErr(err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return session.Token, nil
+}
+
+// refreshRejected refreshes the session of the call of ctx after the server rejected its token with a 401,
+// and returns the new token, "" when AutoRefreshSession isn't set. The calls rejected with the same token share
+// a refresh, the ones rejected with a token already replaced get the new token without any refresh.
+func (m *SessionManager) refreshRejected(ctx context.Context, rejected string) (string, error) {
+	session := SessionFromContext(ctx)
+	if session == nil || !m.client.AutoRefreshSession {
+		return "", nil
+	}
+	m.mu.Lock()
+	if session.Token != rejected {
+		defer m.mu.Unlock()
+		return session.Token, nil
+	}
+	if !session.refreshable(m.client.now().Unix()) {
+		m.mu.Unlock()
+		return "", ErrSessionExpired.With(session.UserID, session.RefreshExpiresAt)
+	}
+	if err := m.refreshLocked(session, nil); err != nil {
+		return "", wrapErr(err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return session.Token, nil
+}
+
+// refreshLocked starts a refresh of the session or joins the one in flight, m.mu is held on entry and released.
+// nil vars keep the vars of the session.
+func (m *SessionManager) refreshLocked(session *Session, vars map[string]string) error {
+	if flight, ok := m.flights[session]; ok {
+		m.mu.Unlock()
+		<-flight.done
+		return flight.err
+	}
+	flight := &refreshFlight{done: make(chan struct{})}
+	m.flights[session] = flight
+	refreshToken := session.RefreshToken
+	if vars == nil {
+		// the server replaces the vars of the session, the automatic refreshes keep the vars of the authentication
+		vars = session.stringVars()
+	}
+	m.mu.Unlock()
+
+	m.refreshes.Add(1)
+	apiSession, err := m.client.apiFor(session).SessionRefresh(m.client.ServerKey, "", &api.SessionRefreshRequest{
+		Token: refreshToken,
+		Vars:  m.client.authVars(vars),
+	}, make(map[string]string))
+
+	m.mu.Lock()
+	if err == nil {
+		err = session.Update(apiSession.GetToken(), apiSession.GetRefreshToken())
+	}
+	flight.err = wrapErr(err)
+	delete(m.flights, session)
+	m.mu.Unlock()
+	close(flight.done)
+	return flight.err
+}
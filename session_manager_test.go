@@ -0,0 +1,157 @@
+package nakama
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestSessionRefreshSingleFlight(t *testing.T) {
+	now := time.Now().Unix()
+	fresh := testToken(now + 3600)
+	var refreshes atomic.Int32
+	socketTokens := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/account/session/refresh":
+			refreshes.Add(1)
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte(`{"token":"` + fresh + `","refresh_token":"` + testToken(now+7200) + `"}`))
+		case "/ws":
+			socketTokens <- r.URL.Query().Get("token")
+			conn, err := websocket.Accept(w, r, nil)
+			if err != nil {
+				return
+			}
+			conn.CloseNow()
+		default:
+			if r.Header.Get("Authorization") != "Bearer "+fresh {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL), WithAutoRefreshSession(true))
+	assert.NoError(t, err)
+	session := Restore(testToken(now-10), testToken(now+7200))
+	socket := client.CreateSocket(nil, session.Token, false, false, nil, nil)
+	socket.SetTokenSource(func() (string, error) { return client.Sessions().Token(session) })
+	defer socket.Disconnect()
+
+	// the http calls and the socket find the token expired at the same time
+	wg := sync.WaitGroup{}
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetAccount(session)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, socket.reconnect(1))
+	}()
+	wg.Wait()
+
+	assert.Equal(t, int32(1), refreshes.Load())
+	assert.Equal(t, int64(1), client.Sessions().Refreshes())
+	assert.Equal(t, fresh, <-socketTokens)
+
+	// a refresh asked explicitly still goes to the server
+	_, err = client.SessionRefresh(session, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), refreshes.Load())
+}
+
+func TestSessionRefreshOnUnauthorized(t *testing.T) {
+	now := time.Now().Unix()
+	revoked, fresh := testToken(now+3600), testToken(now+3601)
+	var refreshes, calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/account/session/refresh":
+			refreshes.Add(1)
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte(`{"token":"` + fresh + `","refresh_token":"` + testToken(now+7200) + `"}`))
+		default:
+			calls.Add(1)
+			if r.Header.Get("Authorization") != "Bearer "+fresh {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	// the token is revoked by the server before its expiry
+	client, err := NewClientWithOptions(WithURL(server.URL), WithAutoRefreshSession(true))
+	assert.NoError(t, err)
+	session := Restore(revoked, testToken(now+7200))
+	wg := sync.WaitGroup{}
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetAccount(session)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), refreshes.Load())
+	assert.Equal(t, fresh, session.Token)
+	assert.Equal(t, int32(10), calls.Load())
+
+	// the body is sent again
+	assert.NoError(t, client.UpdateAccount(Restore(revoked, testToken(now+7200)), &api.UpdateAccountRequest{}))
+	assert.Equal(t, int32(2), refreshes.Load())
+
+	// the 401 is returned as is without AutoRefreshSession
+	client, err = NewClientWithOptions(WithURL(server.URL))
+	assert.NoError(t, err)
+	_, err = client.GetAccount(Restore(revoked, testToken(now+7200)))
+	assert.Equal(t, http.StatusUnauthorized, httpStatusOf(err))
+	assert.Equal(t, int32(2), refreshes.Load())
+}
+
+func TestSessionRefreshKeepsVars(t *testing.T) {
+	now := time.Now().Unix()
+	payload, _ := json.Marshal(map[string]any{"exp": now + 10, "uid": "user", "vrs": map[string]string{"platform": "switch"}})
+	token := "header." + base64.URLEncoding.EncodeToString(payload) + ".signature"
+	refreshes := make(chan *api.SessionRefreshRequest, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/account/session/refresh" {
+			body, _ := io.ReadAll(r.Body)
+			request := &api.SessionRefreshRequest{}
+			assert.NoError(t, protojson.Unmarshal(body, request))
+			refreshes <- request
+			w.Write([]byte(`{"token":"` + token + `","refresh_token":"` + testToken(now+7200) + `"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL), WithAutoRefreshSession(true), WithAuthVars(NewVarsBuilder().Locale("fr")))
+	assert.NoError(t, err)
+	_, err = client.GetAccount(Restore(token, testToken(now+7200)))
+	assert.NoError(t, err)
+	// the vars of the authentication aren't replaced by the defaults
+	assert.Equal(t, map[string]string{"platform": "switch", "locale": "fr"}, (<-refreshes).GetVars())
+}
@@ -0,0 +1,152 @@
+package nakama
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	ErrNoSessionStored = newError("no session stored")
+)
+
+// SessionStore persists the tokens of a session between the runs of the app.
+type SessionStore interface {
+	Save(session *Session) error
+	// Load returns ErrNoSessionStored when nothing has been saved.
+	Load() (*Session, error)
+	Clear() error
+}
+
+// TokenCipher encrypts the persisted tokens, e.g. with a key from the platform keystore.
+type TokenCipher interface {
+	Encrypt(plain []byte) ([]byte, error)
+	Decrypt(sealed []byte) ([]byte, error)
+}
+
+type storedSession struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// FileSessionStore is a SessionStore keeping the tokens in a file.
+type FileSessionStore struct {
+	Path   string
+	Cipher TokenCipher // optional, the tokens are stored in plain text when nil
+
+	mu sync.Mutex
+}
+
+// NewFileSessionStore creates a FileSessionStore, tokenCipher may be nil.
+func NewFileSessionStore(path string, tokenCipher TokenCipher) *FileSessionStore {
+	return &FileSessionStore{Path: path, Cipher: tokenCipher}
+}
+
+// Save writes the tokens of the session to the file.
+func (s *FileSessionStore) Save(session *Session) error {
+	if session == nil {
+		return newError("cannot save a null session")
+	}
+	data, err := json.Marshal(&storedSession{Token: session.Token, RefreshToken: session.RefreshToken})
+	if err != nil {
+		return wrapErr(err)
+	}
+	if s.Cipher != nil {
+		if data, err = s.Cipher.Encrypt(data); err != nil {
+			return wrapErr(err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return wrapErr(err, s.Path)
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return wrapErr(err, s.Path)
+	}
+	if err := os.Rename(tmp, s.Path); err != nil {
+		return wrapErr(err, s.Path)
+	}
+	return nil
+}
+
+// Load restores the session saved in the file.
+func (s *FileSessionStore) Load() (*Session, error) {
+	s.mu.Lock()
+	data, err := os.ReadFile(s.Path)
+	s.mu.Unlock()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoSessionStored.With(s.Path)
+		}
+		return nil, wrapErr(err, s.Path)
+	}
+	if s.Cipher != nil {
+		if data, err = s.Cipher.Decrypt(data); err != nil {
+			return nil, wrapErr(err, s.Path)
+		}
+	}
+
+	stored := &storedSession{}
+	if err := json.Unmarshal(data, stored); err != nil {
+		return nil, wrapErr(err, s.Path)
+	}
+	return Restore(stored.Token, stored.RefreshToken), nil
+}
+
+// Clear removes the file.
+func (s *FileSessionStore) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		return wrapErr(err, s.Path)
+	}
+	return nil
+}
+
+// AesGcmCipher is a TokenCipher using AES-GCM, the nonce is prepended to the sealed data.
+type AesGcmCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAesGcmCipher creates an AesGcmCipher, the key must be 16, 24 or 32 bytes long.
+func NewAesGcmCipher(key []byte) (*AesGcmCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return &AesGcmCipher{aead: aead}, nil
+}
+
+// Encrypt seals the data with a random nonce.
+func (c *AesGcmCipher) Encrypt(plain []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, wrapErr(err)
+	}
+	return c.aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+// Decrypt opens the data sealed by Encrypt.
+func (c *AesGcmCipher) Decrypt(sealed []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, newError("sealed data too short")
+	}
+	plain, err := c.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return plain, nil
+}
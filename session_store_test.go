@@ -0,0 +1,77 @@
+package nakama
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAesGcmCipher(t *testing.T) {
+	_, err := NewAesGcmCipher([]byte("short"))
+	assert.Error(t, err)
+
+	c, err := NewAesGcmCipher(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := []byte("refresh token")
+	sealed, err := c.Encrypt(plain)
+	assert.NoError(t, err)
+	assert.False(t, bytes.Contains(sealed, plain))
+	again, _ := c.Encrypt(plain)
+	assert.NotEqual(t, sealed, again, "the nonces are random")
+
+	opened, err := c.Decrypt(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, plain, opened)
+
+	// a sealed data altered or truncated isn't opened
+	tampered := append([]byte{}, sealed...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = c.Decrypt(tampered)
+	assert.Error(t, err)
+	_, err = c.Decrypt(sealed[:4])
+	assert.Error(t, err)
+
+	other, _ := NewAesGcmCipher(bytes.Repeat([]byte{2}, 32))
+	_, err = other.Decrypt(sealed)
+	assert.Error(t, err, "another key doesn't open it")
+}
+
+func TestFileSessionStore(t *testing.T) {
+	c, err := NewAesGcmCipher(bytes.Repeat([]byte{1}, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := Restore(testToken(time.Now().Add(time.Hour).Unix()), testToken(time.Now().Add(24*time.Hour).Unix()))
+
+	for name, tokenCipher := range map[string]TokenCipher{"plain": nil, "encrypted": c} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "sessions", "session.json")
+			store := NewFileSessionStore(path, tokenCipher)
+
+			_, err := store.Load()
+			assert.True(t, errors.Is(err, ErrNoSessionStored))
+
+			assert.NoError(t, store.Save(session))
+			data, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, tokenCipher == nil, bytes.Contains(data, []byte(session.RefreshToken)))
+
+			loaded, err := store.Load()
+			assert.NoError(t, err)
+			assert.Equal(t, session.Token, loaded.Token)
+			assert.Equal(t, session.RefreshToken, loaded.RefreshToken)
+
+			assert.NoError(t, store.Clear())
+			assert.NoError(t, store.Clear(), "clearing nothing isn't an error")
+			_, err = store.Load()
+			assert.True(t, errors.Is(err, ErrNoSessionStored))
+		})
+	}
+}
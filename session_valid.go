@@ -0,0 +1,34 @@
+package nakama
+
+import ()
+
+var (
+	// ErrSessionNotAuthenticated is returned when no session is set, authenticate before calling the api.
+	ErrSessionNotAuthenticated = newError("session not authenticated, call an Authenticate method first")
+	// ErrSessionExpiredRefreshable is returned when the token has expired but the refresh token is still valid,
+	// call SessionRefresh or enable AutoRefreshSession.
+	ErrSessionExpiredRefreshable = newError("session token expired, call SessionRefresh with the refresh token")
+	// ErrSessionExpired is returned when both the token and the refresh token have expired, authenticate again.
+	ErrSessionExpired = newError("session and refresh token expired, authenticate again")
+)
+
+// Valid checks the session against the unix time now in seconds before it is used with the api.
+// It returns nil, ErrSessionNotAuthenticated, ErrSessionExpiredRefreshable or ErrSessionExpired.
+// A token without a decoded expiry is assumed to be valid, the server will decide.
+func (s *Session) Valid(now int64) error {
+	if s == nil || s.Token == "" {
+		return ErrSessionNotAuthenticated.With()
+	}
+	if s.ExpiresAt == 0 || !s.IsExpired(now) {
+		return nil
+	}
+	if s.refreshable(now) {
+		return ErrSessionExpiredRefreshable.With(s.UserID, s.ExpiresAt)
+	}
+	return ErrSessionExpired.With(s.UserID, s.RefreshExpiresAt)
+}
+
+// refreshable reports whether the refresh token can still be used, its expiry is unknown when not decoded.
+func (s *Session) refreshable(now int64) bool {
+	return s.RefreshToken != "" && (s.RefreshExpiresAt == 0 || !s.IsRefreshExpired(now))
+}
@@ -0,0 +1,102 @@
+package nakama
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionValid(t *testing.T) {
+	var nilSession *Session
+	assert.True(t, errors.Is(nilSession.Valid(100), ErrSessionNotAuthenticated))
+	assert.True(t, errors.Is((&Session{}).Valid(100), ErrSessionNotAuthenticated))
+	assert.NoError(t, (&Session{Token: "token"}).Valid(100))
+	assert.NoError(t, (&Session{Token: "token", ExpiresAt: 200}).Valid(100))
+
+	expired := &Session{Token: "token", ExpiresAt: 50, RefreshToken: "refresh", RefreshExpiresAt: 200}
+	assert.True(t, errors.Is(expired.Valid(100), ErrSessionExpiredRefreshable))
+	assert.True(t, errors.Is(expired.Valid(300), ErrSessionExpired))
+	expired.RefreshToken = ""
+	assert.True(t, errors.Is(expired.Valid(100), ErrSessionExpired))
+}
+
+func TestSessionGuardBeforeNetwork(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL))
+	assert.NoError(t, err)
+	client.AutoRefreshSession = false
+
+	err = client.AddFriends(nil, []string{"id"}, nil)
+	assert.True(t, errors.Is(err, ErrSessionNotAuthenticated))
+	err = client.AddFriends(&Session{Token: "token", ExpiresAt: 1, RefreshToken: "refresh", RefreshExpiresAt: 1}, []string{"id"}, nil)
+	assert.True(t, errors.Is(err, ErrSessionExpired))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+
+	assert.NoError(t, client.AddFriends(&Session{Token: "token"}, []string{"id"}, nil))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestSessionVarsNumbers(t *testing.T) {
+	payload, _ := json.Marshal(map[string]interface{}{"exp": 2000000000, "vrs": map[string]interface{}{"level": 3}})
+	session := Restore("header."+base64.URLEncoding.EncodeToString(payload)+".signature", "")
+	assert.Equal(t, int64(2000000000), session.ExpiresAt)
+	// the numbers of the vars are float64 like json.Unmarshal
+	assert.Equal(t, float64(3), session.Vars["level"])
+}
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+// testToken builds an unsigned jwt expiring at exp, enough for Session.Update.
+func testToken(exp int64) string {
+	payload, _ := json.Marshal(map[string]interface{}{"exp": exp, "uid": "user"})
+	return "header." + base64.URLEncoding.EncodeToString(payload) + ".signature"
+}
+
+func TestAutoRefreshWithClock(t *testing.T) {
+	start := time.Unix(time.Now().Unix(), 0)
+	clock := &fakeClock{now: start}
+	var refreshes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/account/session/refresh" {
+			atomic.AddInt32(&refreshes, 1)
+			now := clock.Now().Unix()
+			w.Write([]byte(`{"token":"` + testToken(now+3600) + `","refresh_token":"` + testToken(now+7200) + `"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL), WithClock(clock))
+	assert.NoError(t, err)
+	client.AutoRefreshSession = true
+	session := Restore(testToken(start.Unix()+3600), testToken(start.Unix()+7200))
+
+	assert.NoError(t, client.AddFriends(session, []string{"id"}, nil))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&refreshes))
+
+	// inside the ExpiredTimespanMs window before the expiry
+	clock.now = start.Add(time.Hour - time.Minute)
+	assert.NoError(t, client.AddFriends(session, []string{"id"}, nil))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&refreshes))
+	assert.Equal(t, clock.now.Unix()+3600, session.ExpiresAt)
+
+	clock.now = start.Add(10 * time.Hour)
+	assert.True(t, errors.Is(client.AddFriends(session, []string{"id"}, nil), ErrSessionExpired))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&refreshes))
+}
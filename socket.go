@@ -2,6 +2,7 @@ package nakama
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"fmt"
 	"math"
@@ -10,11 +11,12 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/gwaylib/errors"
+	"github.com/coder/websocket"
 	"github.com/gwaylib/log"
 	api "github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/rtapi"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
 )
 
@@ -38,6 +40,7 @@ const (
 	EventTypeReconnecting = EventType(3)
 	EventTypeReConnected  = EventType(4)
 	EventTypePingPong     = EventType(5)
+	EventTypeResumed      = EventType(6) // the device has slept, data is the time slept, see SetResumeThreshold
 	// TODO: need closed?
 )
 
@@ -55,6 +58,8 @@ func (e EventType) String() string {
 		return "Connected"
 	case EventTypePingPong:
 		return "PingPong"
+	case EventTypeResumed:
+		return "Resumed"
 	}
 	return "Unknow"
 }
@@ -62,8 +67,24 @@ func (e EventType) String() string {
 type RspResult struct {
 	Decoded *rtapi.Envelope // try parse, maybe nil
 	Data    []byte          // origin data
+	TraceId string          // trace id of the request answered, see SetTracing
+
+	ctx context.Context // the context of the socket, see SetContext
+}
+
+// Context returns the context of the message for the handlers and the loggers,
+// it carries the session of the socket and the message type, see SessionFromContext and EndpointFromContext.
+func (r *RspResult) Context() context.Context {
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, endpointContextKey, envelopeType(r.Decoded))
 }
 
+// EventHandler receives the events of a socket.
+// The messages of a chat channel, a match or a party are handled one at a time in the order received,
+// the messages of different streams are handled concurrently. See SetStreamQueueSize.
 type EventHandler func(event EventType, data *RspResult)
 
 // Socket defines the Go struct with corresponding methods.
@@ -73,8 +94,9 @@ type Socket interface {
 
 // SocketError represents an error received from a socket message.
 type SocketError struct {
-	Code    int    `json:"code"`    // The error code
-	Message string `json:"message"` // A message in English to help developers debug the response
+	Code    int               `json:"code"`    // The error code
+	Message string            `json:"message"` // A message in English to help developers debug the response
+	Context map[string]string `json:"context"` // Additional error details which may be different for each response
 }
 
 type Message struct {
@@ -93,18 +115,63 @@ const (
 
 // DefaultSocket represents a WebSocket connection to the Nakama server
 type DefaultSocket struct {
-	verbose            bool
+	verbose            atomic.Bool
+	tracing            atomic.Bool
 	adapter            *WebSocketAdapter
 	sendTimeoutMs      int
 	heartbeatTimeoutMs int
 	eventHandle        EventHandler
+	dispatcher         *eventDispatcher
+	callbacks          socketCallbacks // see OnChannelMessage
+	clock              *ServerClock
+	reconnectPolicy    ReconnectPolicy
+	sleep              func(d time.Duration)             // the waits of the reconnects, faked by the tests
+	newTimer           func(d time.Duration) socketTimer // the timeouts and the pings, faked by the tests
 
 	cIds    sync.Map // string:chan any
 	nextCid int
 
-	userClosed atomic.Bool
+	chats             chatJoins
+	onMatchData       atomic.Pointer[MatchDataHandler]
+	onUnknownEnvelope atomic.Pointer[UnknownEnvelopeHandler]
+	replay            atomic.Pointer[MatchReplay]
+	fragments         atomic.Pointer[fragmenter]     // see SetFragmentation
+	timeSensitive     atomic.Pointer[map[int64]bool] // see SetTimeSensitiveOpCodes
+
+	tickets       matchmakerTickets
+	cancelTickets atomic.Bool
+	status        statusUpdates
+
+	pingIntervalMs  atomic.Int64 // 0 means heartbeatTimeoutMs
+	resumeThreshold atomic.Int64 // 0 means DefaultResumeThreshold
+	staleDrop       atomic.Bool  // the connection is dropped after a resume
+	pingWake        chan struct{}
+	pingMu          sync.Mutex
+	pings           pingStats
+	onPong          func(rtt time.Duration) // set by CreateSocket, nil if none
+
+	userClosed     atomic.Bool
+	onDisconnect   func(reason *DisconnectReason)
+	tokenSource    func() (string, error)
+	lastDisconnect atomic.Pointer[DisconnectReason]
+	ctx            context.Context                  // set by SetContext
+	lifecycle      *Lifecycle                       // the heartbeat, the read loops and the reconnects, stopped by Disconnect
+	registry       *socketRegistry                  // the sockets of the client reported by DebugReport, nil if none
+	experimental   atomic.Pointer[map[Feature]bool] // see EnableExperimental
 }
 
+// socketTimer is a timer of the socket, a *time.Timer unless faked by the tests.
+type socketTimer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+type systemTimer struct{ *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time { return t.Timer.C }
+
+func newSystemTimer(d time.Duration) socketTimer { return systemTimer{time.NewTimer(d)} }
+
 // NewDefaultSocket creates an instance of DefaultSocket.
 func NewDefaultSocket(eventHandle EventHandler, host, port, token string, useSSL, verbose bool, sendTimeoutMs *int, createStatus *bool) *DefaultSocket {
 	if sendTimeoutMs == nil {
@@ -122,19 +189,28 @@ func NewDefaultSocket(eventHandle EventHandler, host, port, token string, useSSL
 		scheme = "wss://"
 	}
 
+	lifecycle := &Lifecycle{}
 	socket := &DefaultSocket{
-		verbose:            verbose,
 		sendTimeoutMs:      *sendTimeoutMs,
 		heartbeatTimeoutMs: DefaultHeartbeatTimeoutMs,
 		eventHandle:        eventHandle,
+		reconnectPolicy:    DefaultReconnectPolicy(),
+		sleep:              lifecycle.sleep,
+		newTimer:           newSystemTimer,
 		cIds:               sync.Map{},
 		nextCid:            1,
+		pingWake:           make(chan struct{}, 1),
+		lifecycle:          lifecycle,
 	}
+	socket.dispatcher = newEventDispatcher(socket.deliver, DefaultStreamQueueSize)
+	socket.verbose.Store(verbose)
 	adapter := NewWebSocketAdapterText(scheme, host, port, *createStatus, token)
+	adapter.lifecycle = lifecycle
 	adapter.onError = socket.onError
+	adapter.onDisconnect = socket.handleDisconnect
 	adapter.onMessage = func(mType int, message []byte) {
 		if err := socket.handleMessage(mType, message); err != nil {
-			log.Warn(errors.As(err))
+			log.Warn(wrapErr(err))
 		}
 	}
 	socket.adapter = adapter
@@ -154,10 +230,21 @@ func (socket *DefaultSocket) Connect() error {
 		go socket.eventHandle(EventTypeConnecting, nil)
 	}
 
+	if err := socket.refreshToken(); err != nil {
+		return wrapErr(err)
+	}
 	if err := socket.adapter.Connect(); err != nil {
-		return errors.As(err)
+		return wrapErr(err)
+	}
+	socket.lastDisconnect.Store(nil)
+	socket.registry.add(socket)
+	err := socket.lifecycle.Go(func(ctx context.Context) error {
+		socket.pingPong(ctx)
+		return nil
+	})
+	if err != nil {
+		return wrapErr(err)
 	}
-	go socket.pingPong(context.TODO())
 
 	if socket.eventHandle != nil {
 		go socket.eventHandle(EventTypeConnected, nil)
@@ -166,12 +253,92 @@ func (socket *DefaultSocket) Connect() error {
 
 }
 
-// Disconnect terminates the WebSocket connection.
+// Disconnect terminates the WebSocket connection and waits for the background goroutines of the socket,
+// the requests waiting for a response fail. Don't call it from SetOnMatchData or SetOnDisconnect, they run on the read loop.
 func (socket *DefaultSocket) Disconnect() {
+	if socket.cancelTickets.Load() && socket.adapter.IsOpen() {
+		socket.cancelActiveTickets()
+	}
 	socket.userClosed.Store(true)
 	if socket.adapter.IsOpen() {
 		socket.adapter.Close()
 	}
+	if err := socket.lifecycle.Stop(); err != nil {
+		GetLogger().Warn(wrapErr(err))
+	}
+	socket.tickets.clear()
+	socket.chats.clear()
+	socket.registry.remove(socket)
+}
+
+// SetVerbose turns the envelope dumps of this socket on or off at runtime.
+func (socket *DefaultSocket) SetVerbose(verbose bool) {
+	socket.verbose.Store(verbose)
+}
+
+// IsVerbose reports whether this socket dumps its envelopes, it's also true when the debug mode is enabled.
+func (socket *DefaultSocket) IsVerbose() bool {
+	return socket.verbose.Load() || IsDebug()
+}
+
+// SetServerClock sets the clock fed by the server timestamps of the socket responses.
+func (socket *DefaultSocket) SetServerClock(clock *ServerClock) {
+	socket.clock = clock
+}
+
+// SetWebSocketOptions sets the buffer sizes, the inbound message limit, the handshake headers
+// and the subprotocols of the connection, it applies to the next connection.
+func (socket *DefaultSocket) SetWebSocketOptions(options WebSocketOptions) {
+	socket.adapter.SetOptions(options)
+}
+
+// Subprotocol returns the subprotocol negotiated by the current connection, empty if none.
+func (socket *DefaultSocket) Subprotocol() string {
+	return socket.adapter.Subprotocol()
+}
+
+// SetFaultInjector sets the faults injected in the connection, it applies to the next connection.
+func (socket *DefaultSocket) SetFaultInjector(faults *FaultInjector) {
+	socket.adapter.SetFaultInjector(faults)
+}
+
+// SetOutboundLog logs the messages sent by the socket, they're queued for the log before they're sent, nil stops the logging.
+// The messages are logged once connected only.
+func (socket *DefaultSocket) SetOutboundLog(outbound *OutboundLog) {
+	socket.adapter.SetOutboundLog(outbound)
+}
+
+// SetTLSConfig sets the TLS configuration of the wss connection, it applies to the next connection.
+func (socket *DefaultSocket) SetTLSConfig(config *tls.Config) {
+	socket.adapter.SetTLSConfig(config)
+}
+
+// SetReconnectPolicy sets how the socket reconnects after losing the connection.
+func (socket *DefaultSocket) SetReconnectPolicy(policy ReconnectPolicy) {
+	socket.reconnectPolicy = policy
+}
+
+// SetStreamQueueSize sets how many messages a stream queues while its handler is busy,
+// the messages received when the queue is full are dropped. It applies to the new streams.
+func (socket *DefaultSocket) SetStreamQueueSize(size int) {
+	if size <= 0 {
+		return
+	}
+	socket.dispatcher.mu.Lock()
+	socket.dispatcher.queueSize = size
+	socket.dispatcher.mu.Unlock()
+}
+
+// DroppedMessages returns the number of messages dropped because their stream queue was full.
+func (socket *DefaultSocket) DroppedMessages() int64 {
+	return socket.dispatcher.dropped.Load()
+}
+
+// dispatchMessage queues the message for the typed callbacks and the EventHandler.
+func (socket *DefaultSocket) dispatchMessage(result *RspResult) {
+	if !socket.dispatcher.dispatch(result) {
+		GetLogger().Warnf("stream queue full, message dropped: %s", string(result.Data))
+	}
 }
 
 // SetHeartbeatTimeoutMs sets the timeout for heartbeat pings.
@@ -185,22 +352,38 @@ func (socket *DefaultSocket) GetHeartbeatTimeoutMs() int {
 }
 
 func (socket *DefaultSocket) reconnect(tryTimes int) error {
+	// the close of Disconnect is also a reason not to reconnect, it's reported as closed by the user
+	if socket.userClosed.Load() {
+		return newError("user has closed the connection")
+	}
+	if reason := socket.lastDisconnect.Load(); reason != nil && !reason.Reconnectable() {
+		return newError("not reconnectable").With(reason.Kind.String(), reason.Reason)
+	}
 	if socket.eventHandle != nil {
 		go socket.eventHandle(EventTypeReconnecting, nil)
 	}
 	for i := tryTimes; i > 0; i-- {
 		if socket.userClosed.Load() {
-			return errors.New("user has closed the connection")
+			return newError("user has closed the connection")
 		}
 		if socket.adapter.IsOpen() {
 			return nil
 		}
+		if socket.reconnectPolicy.Budget.Wait(socket.lifecycle.Context()) != nil {
+			return newError("user has closed the connection")
+		}
 
+		if err := socket.refreshToken(); err != nil {
+			// an expired session won't get better by retrying
+			return wrapErr(err)
+		}
 		if err := socket.adapter.Connect(); err != nil {
-			log.Warn("retry failed", errors.As(err, i))
-			time.Sleep(3e9)
+			log.Warn("retry failed", wrapErr(err, i))
+			socket.sleep(socket.reconnectPolicy.interval())
 			continue
 		}
+		socket.chats.rejoin(socket.joinChat)
+		socket.resendStatus()
 
 		if socket.eventHandle != nil {
 			go socket.eventHandle(EventTypeReConnected, nil)
@@ -208,14 +391,78 @@ func (socket *DefaultSocket) reconnect(tryTimes int) error {
 
 		return nil
 	}
-	return errors.New("reconnection failed")
+	return newError("reconnection failed")
+}
+
+// SetTokenSource sets the source of the session token used by Connect and the reconnects, set it before Connect.
+// Use the SessionManager of the client, so a reconnect and the http calls share the refreshes of the session.
+func (socket *DefaultSocket) SetTokenSource(source func() (string, error)) {
+	socket.tokenSource = source
+}
+
+// refreshToken sets the token of the next connection from the token source, if any.
+func (socket *DefaultSocket) refreshToken() error {
+	if socket.tokenSource == nil {
+		return nil
+	}
+	token, err := socket.tokenSource()
+	if err != nil {
+		return wrapErr(err)
+	}
+	socket.adapter.SetToken(token)
+	return nil
+}
+
+// SetContext sets the context of the messages received, e.g. ContextWithSession, set it before Connect.
+// It's the parent of RspResult.Context, it doesn't bound the socket.
+func (socket *DefaultSocket) SetContext(ctx context.Context) {
+	socket.ctx = ctx
+}
+
+// SetOnDisconnect sets the callback receiving the reason of each disconnect, set it before Connect.
+// The socket doesn't reconnect when the reason isn't Reconnectable, e.g. the session has expired.
+func (socket *DefaultSocket) SetOnDisconnect(onDisconnect func(reason *DisconnectReason)) {
+	socket.onDisconnect = onDisconnect
+}
+
+// LastDisconnect returns the reason of the last disconnect, nil if the socket has never been disconnected.
+func (socket *DefaultSocket) LastDisconnect() *DisconnectReason {
+	return socket.lastDisconnect.Load()
+}
+
+func (socket *DefaultSocket) handleDisconnect(reason *DisconnectReason) {
+	if socket.userClosed.Load() {
+		reason.Kind = DisconnectByClient
+	} else if socket.staleDrop.Swap(false) {
+		reason.Kind = DisconnectStale
+	}
+	socket.lastDisconnect.Store(reason)
+	socket.tickets.clear()
+	socket.failPending(reason)
+	if socket.onDisconnect != nil {
+		socket.onDisconnect(reason)
+	}
+}
+
+// failPending fails the requests waiting for a response with ErrConnectionLost, the responses of the lost connection
+// won't come and the requests would wait for their timeout otherwise.
+func (socket *DefaultSocket) failPending(reason *DisconnectReason) {
+	socket.cIds.Range(func(cid, rsp any) bool {
+		select {
+		case rsp.(chan any) <- ErrConnectionLost.With(reason.Kind.String()):
+		default:
+		}
+		return true
+	})
 }
 
 // OnError handles WebSocket errors.
 func (socket *DefaultSocket) onError(evt error) {
-	if socket.verbose {
-		log.Info("OnError:", evt)
+	if socket.IsVerbose() {
+		GetLogger().Warn("OnError:", evt)
 	}
+	// spread the reconnects of the clients dropped at the same time
+	socket.sleep(socket.reconnectPolicy.initialWait())
 	socket.reconnect(math.MaxInt)
 }
 
@@ -259,17 +506,44 @@ func decodeReceivedData(msg map[string]interface{}, field string) {
 
 // HandleMessage processes incoming WebSocket messages.
 func (socket *DefaultSocket) handleMessage(mType int, message []byte) error {
-	result := &RspResult{Data: message}
+	binary := mType == int(websocket.MessageBinary)
+	if !binary && socket.handleMatchData(message) {
+		return nil
+	}
+	result := &RspResult{Data: message, ctx: socket.ctx}
 	// try find the request cid
 	decoded := &rtapi.Envelope{}
-	if err := protojson.Unmarshal(message, decoded); err != nil {
+	unmarshal := protojson.Unmarshal
+	if binary {
+		unmarshal = proto.Unmarshal
+	}
+	if err := unmarshal(message, decoded); err != nil {
+		if !binary && socket.handleUnknownEnvelope(message) {
+			return nil
+		}
 		if socket.eventHandle != nil {
-			go socket.eventHandle(EventTypeMessage, result)
+			socket.dispatchMessage(result)
 			return nil
 		}
-		return errors.As(err)
+		return wrapErr(err)
 	}
 	result.Decoded = decoded
+	if socket.IsVerbose() {
+		dumpEnvelope("recv", decoded)
+	}
+	if socket.handlePing(decoded) {
+		return nil
+	}
+	if socket.reassemble(decoded) {
+		return nil
+	}
+	if binary && socket.handleDecodedMatchData(decoded.GetMatchData()) {
+		return nil
+	}
+	socket.tickets.observe(decoded)
+	if data := decoded.GetMatchData(); data != nil {
+		socket.replay.Load().record(data.GetMatchId(), false, data.GetOpCode(), data.GetPresence().GetUserId(), data.GetData())
+	}
 
 	// Handle specific decoding logic for match_data and party_data
 	// decodeReceivedData(decoded, "match_data")
@@ -280,8 +554,7 @@ func (socket *DefaultSocket) handleMessage(mType int, message []byte) error {
 	if ok {
 		err, ok := decoded.GetMessage().(*rtapi.Envelope_Error)
 		if ok {
-			e := err.Error
-			rsp.(chan any) <- errors.Parse(e.Message).As(e.Code, e.Context)
+			rsp.(chan any) <- socketErrorOf(err.Error)
 		} else {
 			rsp.(chan any) <- result
 		}
@@ -290,11 +563,7 @@ func (socket *DefaultSocket) handleMessage(mType int, message []byte) error {
 	}
 
 	// unknow message, notify to caller
-	if socket.eventHandle != nil {
-		go socket.eventHandle(EventTypeMessage, result)
-	} else {
-		log.Debug("uncatch result", result)
-	}
+	socket.dispatchMessage(result)
 	return nil
 
 }
@@ -304,12 +573,12 @@ func (socket *DefaultSocket) handleMessage(mType int, message []byte) error {
 func (socket *DefaultSocket) Send(message *rtapi.Envelope, sendTimeout *int) any {
 	if !socket.adapter.IsOpen() {
 		if err := socket.reconnect(3); err != nil {
-			return errors.As(err)
+			return wrapErr(err)
 		}
 	}
 
+	// not closed, a late response may still be delivered to it
 	rsp := make(chan any, 1)
-	defer close(rsp)
 
 	cid := socket.GenerateCID()
 	message.Cid = cid // write a seq number
@@ -323,76 +592,230 @@ func (socket *DefaultSocket) Send(message *rtapi.Envelope, sendTimeout *int) any
 	//	handleEncodedData(msgMap, "party_data_send")
 	//}
 
-	if err := socket.adapter.Send(message); err != nil {
-		return errors.As(err)
+	traceId := socket.traceRequest(message)
+	if socket.IsVerbose() {
+		dumpEnvelope("send", message)
 	}
-
 	if sendTimeout == nil {
 		sendTimeout = new(int)
 		*sendTimeout = DefaultTimeoutMs
 	}
+	// the timeout runs from the send
+	t := socket.newTimer(time.Duration(*sendTimeout) * time.Millisecond)
+	defer t.Stop()
+
+	sentAt := time.Now()
+	if err := socket.adapter.Send(message); err != nil {
+		return socket.traceResponse(traceId, cid, wrapErr(err), sentAt)
+	}
 
-	t := time.NewTimer(time.Duration(*sendTimeout) * time.Millisecond)
 	select {
-	case <-t.C:
-		return errors.New("timeout")
+	case <-t.C():
+		return socket.traceResponse(traceId, cid, newError("timeout"), sentAt)
+	case <-socket.lifecycle.Context().Done():
+		return socket.traceResponse(traceId, cid, newError("socket closed"), sentAt)
 	case data := <-rsp: //
-		return data
+		if result, ok := data.(*RspResult); ok {
+			socket.clock.observeEnvelope(result.Decoded, sentAt, time.Now())
+		}
+		return socket.traceResponse(traceId, cid, data, sentAt)
 	}
 }
 
-// CreateMatch sends a request to create a match and returns the created Match.
+// AddMatchmaker adds the user to the matchmaker pool and returns the ticket, see ActiveTickets.
+// countMultiple is optional, an empty query matches all the tickets. The match found is received by OnMatchmakerMatched,
+// join it with JoinMatchedMatch.
+func (socket *DefaultSocket) AddMatchmaker(query string, minCount, maxCount int32, stringProperties map[string]string, numericProperties map[string]float64, countMultiple *int32) (*rtapi.MatchmakerTicket, error) {
+	if err := checkMatchmakerCounts(minCount, maxCount, countMultiple); err != nil {
+		return nil, wrapErr(err)
+	}
+	add := &rtapi.MatchmakerAdd{
+		Query:             query,
+		MinCount:          minCount,
+		MaxCount:          maxCount,
+		StringProperties:  stringProperties,
+		NumericProperties: numericProperties,
+	}
+	if countMultiple != nil {
+		add.CountMultiple = wrapperspb.Int32(*countMultiple)
+	}
+	req := &rtapi.Envelope{
+		Message: &rtapi.Envelope_MatchmakerAdd{MatchmakerAdd: add},
+	}
+
+	result := socket.Send(req, nil)
+	if err, ok := result.(error); ok {
+		return nil, wrapErr(err)
+	}
+
+	ticket := result.(*RspResult).Decoded.GetMatchmakerTicket()
+	if ticket == nil {
+		return nil, newError("unexpected response").With(envelopeType(result.(*RspResult).Decoded))
+	}
+	socket.tickets.add(ticket.GetTicket(), "")
+	return ticket, nil
+}
+
+// AddMatchmakerParty adds the party to the matchmaker pool and returns the ticket, the user must be the leader.
+// countMultiple is optional.
+func (socket *DefaultSocket) AddMatchmakerParty(partyID, query string, minCount, maxCount int32, stringProperties map[string]string, numericProperties map[string]float64, countMultiple *int32) (*rtapi.PartyMatchmakerTicket, error) {
+	if err := socket.requireExperimental(FeatureParties); err != nil {
+		return nil, wrapErr(err)
+	}
+	if err := checkMatchmakerCounts(minCount, maxCount, countMultiple); err != nil {
+		return nil, wrapErr(err)
+	}
+	add := &rtapi.PartyMatchmakerAdd{
+		PartyId:           partyID,
+		Query:             query,
+		MinCount:          minCount,
+		MaxCount:          maxCount,
+		StringProperties:  stringProperties,
+		NumericProperties: numericProperties,
+	}
+	if countMultiple != nil {
+		add.CountMultiple = wrapperspb.Int32(*countMultiple)
+	}
+	req := &rtapi.Envelope{
+		Message: &rtapi.Envelope_PartyMatchmakerAdd{PartyMatchmakerAdd: add},
+	}
+
+	result := socket.Send(req, nil)
+	if err, ok := result.(error); ok {
+		return nil, wrapErr(err)
+	}
+
+	ticket := result.(*RspResult).Decoded.GetPartyMatchmakerTicket()
+	if ticket == nil {
+		return nil, newError("unexpected response").With(envelopeType(result.(*RspResult).Decoded))
+	}
+	socket.tickets.add(ticket.GetTicket(), partyID)
+	return ticket, nil
+}
+
+// CreateMatch sends a request to create a relayed match and returns it, the user joins it.
+// name is optional, the matches created with the same name are the same match.
 func (socket *DefaultSocket) CreateMatch(name *string) (*rtapi.Match, error) {
 	req := &rtapi.Envelope{
 		Message: &rtapi.Envelope_MatchCreate{
-			MatchCreate: &rtapi.MatchCreate{Name: *name},
+			MatchCreate: &rtapi.MatchCreate{},
 		},
 	}
+	if name != nil {
+		req.GetMatchCreate().Name = *name
+	}
+	return matchOf(socket.Send(req, nil))
+}
 
-	result := socket.Send(req, nil)
+// matchOf returns the match of the result of a match create or join.
+func matchOf(result any) (*rtapi.Match, error) {
+	return replyOf(result, (*rtapi.Envelope).GetMatch)
+}
+
+// replyOf returns the message of the reply got by Send, an error when the server answered with another message.
+func replyOf[T any](result any, get func(*rtapi.Envelope) *T) (*T, error) {
 	if err, ok := result.(error); ok {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 	rsp, ok := result.(*RspResult)
 	if !ok {
-		return nil, errors.New("unknow protocal").As(result)
+		return nil, newError("unknow protocal").With(result)
 	}
-
-	return rsp.Decoded.GetMessage().(*rtapi.Envelope_Match).Match, nil
+	reply := get(rsp.Decoded)
+	if reply == nil {
+		return nil, newError("unexpected response").With(envelopeType(rsp.Decoded))
+	}
+	return reply, nil
 }
 
-// CreateParty Example methods for handling specific socket calls
-func (socket *DefaultSocket) CreateParty(open bool, maxSize int32) (*rtapi.Party, error) {
+// AcceptPartyMember accepts the join request of a user to a closed party, for its leader.
+func (socket *DefaultSocket) AcceptPartyMember(partyID string, member *rtapi.UserPresence) error {
+	if err := socket.requireExperimental(FeatureParties); err != nil {
+		return wrapErr(err)
+	}
+	if partyID == "" || member == nil {
+		return newError("'partyID' and 'member' are required parameters")
+	}
 	req := &rtapi.Envelope{
-		Message: &rtapi.Envelope_PartyCreate{
-			PartyCreate: &rtapi.PartyCreate{Open: open, MaxSize: maxSize},
+		Message: &rtapi.Envelope_PartyAccept{
+			PartyAccept: &rtapi.PartyAccept{
+				PartyId:  partyID,
+				Presence: member,
+			},
 		},
 	}
 
+	// the server answers with an empty envelope
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return nil, errors.As(err)
+		return wrapErr(err)
 	}
-
-	return result.(*RspResult).Decoded.GetMessage().(*rtapi.Envelope_Party).Party, nil
+	return nil
 }
 
-// FollowUsers sends a request to follow a list of user IDs and returns the status.
-func (socket *DefaultSocket) FollowUsers(userIds []string) (*rtapi.Status, error) {
+// CloseParty closes a party and removes all its members, for its leader.
+func (socket *DefaultSocket) CloseParty(partyID string) error {
+	if err := socket.requireExperimental(FeatureParties); err != nil {
+		return wrapErr(err)
+	}
+	if partyID == "" {
+		return newError("'partyID' is a required parameter but is empty")
+	}
 	req := &rtapi.Envelope{
-		Message: &rtapi.Envelope_StatusFollow{
-			StatusFollow: &rtapi.StatusFollow{
-				UserIds: userIds,
+		Message: &rtapi.Envelope_PartyClose{
+			PartyClose: &rtapi.PartyClose{
+				PartyId: partyID,
 			},
 		},
 	}
 
+	// the server answers with an empty envelope
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return nil, errors.As(err)
+		return wrapErr(err)
+	}
+	return nil
+}
+
+// CreateParty creates a party led by the current user, open parties are joined without the approval of the leader.
+func (socket *DefaultSocket) CreateParty(open bool, maxSize int32) (*rtapi.Party, error) {
+	if err := socket.requireExperimental(FeatureParties); err != nil {
+		return nil, wrapErr(err)
+	}
+	req := &rtapi.Envelope{
+		Message: &rtapi.Envelope_PartyCreate{
+			PartyCreate: &rtapi.PartyCreate{Open: open, MaxSize: maxSize},
+		},
+	}
+
+	return replyOf(socket.Send(req, nil), (*rtapi.Envelope).GetParty)
+}
+
+// FollowUsers sends a request to follow a list of user IDs and returns the presences of the users online.
+// Their status changes are then pushed, see OnStatusPresence.
+func (socket *DefaultSocket) FollowUsers(userIds []string) (*rtapi.Status, error) {
+	if len(userIds) == 0 {
+		return nil, newError("'userIds' is a required parameter but is empty")
 	}
+	return socket.followStatus(&rtapi.StatusFollow{UserIds: userIds})
+}
 
-	return result.(*RspResult).Decoded.GetMessage().(*rtapi.Envelope_Status).Status, nil
+// FollowUsernames follows the users like FollowUsers, by their usernames.
+func (socket *DefaultSocket) FollowUsernames(usernames []string) (*rtapi.Status, error) {
+	if len(usernames) == 0 {
+		return nil, newError("'usernames' is a required parameter but is empty")
+	}
+	return socket.followStatus(&rtapi.StatusFollow{Usernames: usernames})
+}
+
+func (socket *DefaultSocket) followStatus(follow *rtapi.StatusFollow) (*rtapi.Status, error) {
+	req := &rtapi.Envelope{
+		Message: &rtapi.Envelope_StatusFollow{
+			StatusFollow: follow,
+		},
+	}
+
+	return replyOf(socket.Send(req, nil), (*rtapi.Envelope).GetStatus)
 }
 
 func (socket *DefaultSocket) joinChat(target *rtapi.ChannelJoin) (*rtapi.Channel, error) {
@@ -402,56 +825,77 @@ func (socket *DefaultSocket) joinChat(target *rtapi.ChannelJoin) (*rtapi.Channel
 		},
 	}
 
-	result := socket.Send(req, nil)
-	if err, ok := result.(error); ok {
-		return nil, errors.As(err)
-	}
-	return result.(*RspResult).Decoded.GetMessage().(*rtapi.Envelope_Channel).Channel, nil
+	return replyOf(socket.Send(req, nil), (*rtapi.Envelope).GetChannel)
 }
 
-// JoinChat sends a request to join a chat and returns the joined Channel.
-func (socket *DefaultSocket) JoinChat(target string, chatType int32, persistence, hidden bool) (*rtapi.Channel, error) {
-	targetChannel := &rtapi.ChannelJoin{
-		Target:      target,
-		Type:        chatType,
-		Persistence: wrapperspb.Bool(persistence),
-		Hidden:      wrapperspb.Bool(hidden),
+// JoinChat sends a request to join the chat of the target and returns the joined Channel, e.g. JoinChat(RoomTarget("lobby", false, false)).
+// Joining again a chat with the same target, type and persistence returns the channel already joined.
+func (socket *DefaultSocket) JoinChat(target ChatTarget) (*rtapi.Channel, error) {
+	targetChannel, err := target.channelJoin()
+	if err != nil {
+		return nil, wrapErr(err)
 	}
-	channel, err := socket.joinChat(targetChannel)
+	channel, err := socket.chats.join(targetChannel, socket.joinChat)
 	if err != nil {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 	return channel, nil
 }
 
+// JoinedChats returns the channels joined by JoinChat and not left, they are rejoined after a reconnect.
+func (socket *DefaultSocket) JoinedChats() []*rtapi.Channel {
+	return socket.chats.channels()
+}
+
 // JoinMatch sends a request to join a match and returns the joined Match.
 func (socket *DefaultSocket) JoinMatch(matchID, token *string, metadata map[string]string) (*rtapi.Match, error) {
-	matchId := &rtapi.MatchJoin_MatchId{MatchId: *matchID}
-	matchToken := &rtapi.MatchJoin_Token{Token: *token}
 	matchJoin := &rtapi.MatchJoin{
 		Metadata: metadata,
 	}
-	if token != nil && *token != "" {
-		matchJoin.Id = matchToken
+	if !isEmpty(token) {
+		matchJoin.Id = &rtapi.MatchJoin_Token{Token: *token}
+	} else if !isEmpty(matchID) {
+		matchJoin.Id = &rtapi.MatchJoin_MatchId{MatchId: *matchID}
 	} else {
-		matchJoin.Id = matchId
+		return nil, newError("'matchID' or 'token' is required but both are empty")
+	}
+	return socket.joinMatch(matchJoin)
+}
+
+// JoinMatchedMatch joins the match found by the matchmaker, using the token when the server has issued one.
+// The metadata is passed to the match handler as the user metadata.
+func (socket *DefaultSocket) JoinMatchedMatch(matched *rtapi.MatchmakerMatched, metadata map[string]string) (*rtapi.Match, error) {
+	if matched == nil {
+		return nil, newError("'matched' is a required parameter but is null")
 	}
+	matchJoin := &rtapi.MatchJoin{
+		Metadata: metadata,
+	}
+	switch {
+	case matched.GetToken() != "":
+		matchJoin.Id = &rtapi.MatchJoin_Token{Token: matched.GetToken()}
+	case matched.GetMatchId() != "":
+		matchJoin.Id = &rtapi.MatchJoin_MatchId{MatchId: matched.GetMatchId()}
+	default:
+		return nil, newError("matchmaker matched has neither token nor match id").With(matched.GetTicket())
+	}
+	return socket.joinMatch(matchJoin)
+}
+
+func (socket *DefaultSocket) joinMatch(matchJoin *rtapi.MatchJoin) (*rtapi.Match, error) {
 	req := &rtapi.Envelope{
 		Message: &rtapi.Envelope_MatchJoin{
 			MatchJoin: matchJoin,
 		},
 	}
-
-	result := socket.Send(req, nil)
-	if err, ok := result.(error); ok {
-		return nil, errors.As(err)
-	}
-
-	return result.(*RspResult).Decoded.GetMessage().(*rtapi.Envelope_Match).Match, nil
+	return matchOf(socket.Send(req, nil))
 }
 
 // JoinParty sends a request to join a party.
 func (socket *DefaultSocket) JoinParty(partyID string) error {
+	if err := socket.requireExperimental(FeatureParties); err != nil {
+		return wrapErr(err)
+	}
 	req := &rtapi.Envelope{
 		Message: &rtapi.Envelope_PartyJoin{
 			PartyJoin: &rtapi.PartyJoin{
@@ -460,13 +904,11 @@ func (socket *DefaultSocket) JoinParty(partyID string) error {
 		},
 	}
 
+	// the server answers with an empty envelope, the party is joined once the leader accepts a closed party
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return errors.As(err)
+		return wrapErr(err)
 	}
-
-	// TODO: need response?
-
 	return nil
 }
 
@@ -482,14 +924,17 @@ func (socket *DefaultSocket) LeaveChat(channelID string) error {
 
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return errors.As(err)
+		return wrapErr(err)
 	}
-
+	socket.chats.leave(channelID)
 	return nil
 }
 
 // LeaveMatch sends a request to leave a match.
 func (socket *DefaultSocket) LeaveMatch(matchID string) error {
+	if matchID == "" {
+		return newError("'matchID' is a required parameter but is empty")
+	}
 	req := &rtapi.Envelope{
 		Message: &rtapi.Envelope_MatchLeave{
 			MatchLeave: &rtapi.MatchLeave{
@@ -498,18 +943,19 @@ func (socket *DefaultSocket) LeaveMatch(matchID string) error {
 		},
 	}
 
+	// the server answers with an empty envelope
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return errors.As(err)
+		return wrapErr(err)
 	}
-
-	// TODO: decode?
-
 	return nil
 }
 
 // LeaveParty sends a request to leave a party.
 func (socket *DefaultSocket) LeaveParty(partyID string) error {
+	if err := socket.requireExperimental(FeatureParties); err != nil {
+		return wrapErr(err)
+	}
 	req := &rtapi.Envelope{
 		Message: &rtapi.Envelope_PartyLeave{
 			PartyLeave: &rtapi.PartyLeave{
@@ -518,18 +964,19 @@ func (socket *DefaultSocket) LeaveParty(partyID string) error {
 		},
 	}
 
+	// the server answers with an empty envelope
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return errors.As(err)
+		return wrapErr(err)
 	}
-
-	// TODO: decode
-
 	return nil
 }
 
 // ListPartyJoinRequests fetches the list of join requests for a given party ID.
 func (socket *DefaultSocket) ListPartyJoinRequests(partyID string) (*rtapi.PartyJoinRequest, error) {
+	if err := socket.requireExperimental(FeatureParties); err != nil {
+		return nil, wrapErr(err)
+	}
 	req := &rtapi.Envelope{
 		Message: &rtapi.Envelope_PartyJoinRequestList{
 			PartyJoinRequestList: &rtapi.PartyJoinRequestList{
@@ -538,13 +985,7 @@ func (socket *DefaultSocket) ListPartyJoinRequests(partyID string) (*rtapi.Party
 		},
 	}
 
-	// TODO: confirm the main key is channel.
-	result := socket.Send(req, nil)
-	if err, ok := result.(error); ok {
-		return nil, errors.As(err)
-	}
-
-	return result.(*RspResult).Decoded.GetMessage().(*rtapi.Envelope_PartyJoinRequest).PartyJoinRequest, nil
+	return replyOf(socket.Send(req, nil), (*rtapi.Envelope).GetPartyJoinRequest)
 }
 
 // RemoveChatMessage sends a request to remove a chat message and returns the ChannelMessageAck.
@@ -560,13 +1001,17 @@ func (socket *DefaultSocket) RemoveChatMessage(channelID, messageID string) (*rt
 
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 	return result.(*RspResult).Decoded.GetMessage().(*rtapi.Envelope_ChannelMessageAck).ChannelMessageAck, nil
 }
 
 // PromotePartyMember promotes a party member to party leader and returns the new PartyLeader.
+// The server acknowledges the promotion and announces the new leader to the members, see OnPartyLeader.
 func (socket *DefaultSocket) PromotePartyMember(partyID string, partyMember *rtapi.UserPresence) (*rtapi.PartyLeader, error) {
+	if err := socket.requireExperimental(FeatureParties); err != nil {
+		return nil, wrapErr(err)
+	}
 	req := &rtapi.Envelope{
 		Message: &rtapi.Envelope_PartyPromote{
 			PartyPromote: &rtapi.PartyPromote{
@@ -578,13 +1023,27 @@ func (socket *DefaultSocket) PromotePartyMember(partyID string, partyMember *rta
 
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
+	}
+	rsp, ok := result.(*RspResult)
+	if !ok {
+		return nil, newError("unknow protocal").With(result)
+	}
+	switch {
+	case rsp.Decoded.GetPartyLeader() != nil:
+		return rsp.Decoded.GetPartyLeader(), nil
+	case rsp.Decoded.GetMessage() != nil:
+		return nil, newError("unexpected response").With(envelopeType(rsp.Decoded))
 	}
-	return result.(*RspResult).Decoded.GetMessage().(*rtapi.Envelope_PartyLeader).PartyLeader, nil
+	// an empty acknowledgement
+	return &rtapi.PartyLeader{PartyId: partyID, Presence: partyMember}, nil
 }
 
 // RemoveMatchmaker sends a request to remove a matchmaker ticket.
 func (socket *DefaultSocket) RemoveMatchmaker(ticket string) error {
+	if ticket == "" {
+		return newError("'ticket' is a required parameter but is empty")
+	}
 	req := &rtapi.Envelope{
 		Message: &rtapi.Envelope_MatchmakerRemove{
 			MatchmakerRemove: &rtapi.MatchmakerRemove{
@@ -595,14 +1054,18 @@ func (socket *DefaultSocket) RemoveMatchmaker(ticket string) error {
 
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return errors.As(err)
+		return wrapErr(err)
 	}
+	socket.tickets.remove(ticket)
 
 	return nil
 }
 
 // RemoveMatchmakerParty sends a request to remove a matchmaker ticket from a party.
 func (socket *DefaultSocket) RemoveMatchmakerParty(partyID, ticket string) error {
+	if err := socket.requireExperimental(FeatureParties); err != nil {
+		return wrapErr(err)
+	}
 	req := &rtapi.Envelope{
 		Message: &rtapi.Envelope_PartyMatchmakerRemove{
 			PartyMatchmakerRemove: &rtapi.PartyMatchmakerRemove{
@@ -614,14 +1077,18 @@ func (socket *DefaultSocket) RemoveMatchmakerParty(partyID, ticket string) error
 
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return errors.As(err)
+		return wrapErr(err)
 	}
+	socket.tickets.remove(ticket)
 
 	return nil
 }
 
 // RemovePartyMember sends a request to remove a member from a party.
 func (socket *DefaultSocket) RemovePartyMember(partyID string, member *rtapi.UserPresence) error {
+	if err := socket.requireExperimental(FeatureParties); err != nil {
+		return wrapErr(err)
+	}
 	req := &rtapi.Envelope{
 		Message: &rtapi.Envelope_PartyRemove{
 			PartyRemove: &rtapi.PartyRemove{
@@ -633,7 +1100,7 @@ func (socket *DefaultSocket) RemovePartyMember(partyID string, member *rtapi.Use
 
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	return nil
@@ -653,13 +1120,15 @@ func (socket *DefaultSocket) Rpc(id, payload, httpKey string) (*api.Rpc, error)
 
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 	return result.(*RspResult).Decoded.GetMessage().(*rtapi.Envelope_Rpc).Rpc, nil
 }
 
 // SendMatchState sends match state updates to the server.
+// The data of the time-sensitive op codes is timestamped, see SetTimeSensitiveOpCodes.
 func (socket *DefaultSocket) SendMatchState(matchID string, opCode int64, data []byte, presences []*rtapi.UserPresence, reliable bool) error {
+	data = socket.timestamp(opCode, data)
 	req := &rtapi.Envelope{
 		Message: &rtapi.Envelope_MatchDataSend{
 			MatchDataSend: &rtapi.MatchDataSend{
@@ -672,17 +1141,36 @@ func (socket *DefaultSocket) SendMatchState(matchID string, opCode int64, data [
 		},
 	}
 
-	// TODO: confirm the msg_key
-	result := socket.Send(req, nil)
-	if err, ok := result.(error); ok {
-		return errors.As(err)
+	fragmentOpCode, fragments, err := socket.fragment(req, opCode, data)
+	if err != nil {
+		return wrapErr(err, matchID)
+	}
+	for _, fragment := range fragments {
+		// a lost fragment would lose the whole message
+		send := &rtapi.MatchDataSend{MatchId: matchID, OpCode: fragmentOpCode, Data: fragment, Presences: presences, Reliable: true}
+		if err := socket.SendNoReply(&rtapi.Envelope{Message: &rtapi.Envelope_MatchDataSend{MatchDataSend: send}}); err != nil {
+			return wrapErr(err)
+		}
+	}
+	if fragments != nil {
+		socket.replay.Load().record(matchID, true, opCode, "", data)
+		return nil
 	}
 
+	// the server doesn't answer the match data
+	if err := socket.SendNoReply(req); err != nil {
+		return wrapErr(err)
+	}
+	socket.replay.Load().record(matchID, true, opCode, "", data)
+
 	return nil
 }
 
 // SendPartyData sends party data updates to the server.
 func (socket *DefaultSocket) SendPartyData(partyID string, opCode int64, data []byte) error {
+	if err := socket.requireExperimental(FeatureParties); err != nil {
+		return wrapErr(err)
+	}
 	req := &rtapi.Envelope{
 		Message: &rtapi.Envelope_PartyDataSend{
 			PartyDataSend: &rtapi.PartyDataSend{
@@ -693,16 +1181,59 @@ func (socket *DefaultSocket) SendPartyData(partyID string, opCode int64, data []
 		},
 	}
 
+	fragmentOpCode, fragments, err := socket.fragment(req, opCode, data)
+	if err != nil {
+		return wrapErr(err, partyID)
+	}
+	for _, fragment := range fragments {
+		send := &rtapi.PartyDataSend{PartyId: partyID, OpCode: fragmentOpCode, Data: fragment}
+		if err := socket.SendNoReply(&rtapi.Envelope{Message: &rtapi.Envelope_PartyDataSend{PartyDataSend: send}}); err != nil {
+			return wrapErr(err)
+		}
+	}
+	if fragments != nil {
+		return nil
+	}
+
+	// the server doesn't answer the party data
+	if err := socket.SendNoReply(req); err != nil {
+		return wrapErr(err)
+	}
+
+	return nil
+}
+
+// UpdateParty sets the label of a party and opens or closes it, for its leader.
+func (socket *DefaultSocket) UpdateParty(partyID, label string, open bool) error {
+	if err := socket.requireExperimental(FeatureParties); err != nil {
+		return wrapErr(err)
+	}
+	if partyID == "" {
+		return newError("'partyID' is a required parameter but is empty")
+	}
+	req := &rtapi.Envelope{
+		Message: &rtapi.Envelope_PartyUpdate{
+			PartyUpdate: &rtapi.PartyUpdate{
+				PartyId: partyID,
+				Label:   label,
+				Open:    open,
+			},
+		},
+	}
+
+	// the server answers with an empty envelope
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return errors.As(err)
+		return wrapErr(err)
 	}
-
 	return nil
 }
 
 // UnfollowUsers sends a request to unfollow the specified users.
 func (socket *DefaultSocket) UnfollowUsers(userIDs []string) error {
+	if len(userIDs) == 0 {
+		return newError("'userIDs' is a required parameter but is empty")
+	}
 	req := &rtapi.Envelope{
 		Message: &rtapi.Envelope_StatusUnfollow{
 			StatusUnfollow: &rtapi.StatusUnfollow{
@@ -711,9 +1242,10 @@ func (socket *DefaultSocket) UnfollowUsers(userIDs []string) error {
 		},
 	}
 
+	// the server answers with an empty envelope
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	return nil
@@ -733,25 +1265,27 @@ func (socket *DefaultSocket) UpdateChatMessage(channelID, messageID string, cont
 
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 
 	return result.(*RspResult).Decoded.GetMessage().(*rtapi.Envelope_ChannelMessageAck).ChannelMessageAck, nil
 }
 
-// UpdateStatus sends a status update to the server.
+// UpdateStatus sends a status update to the server, pushed to the followers of the user. A nil status appears offline.
 func (socket *DefaultSocket) UpdateStatus(status *string) error {
+	update := &rtapi.StatusUpdate{}
+	if status != nil {
+		update.Status = wrapperspb.String(*status)
+	}
 	req := &rtapi.Envelope{
 		Message: &rtapi.Envelope_StatusUpdate{
-			StatusUpdate: &rtapi.StatusUpdate{
-				Status: wrapperspb.String(*status),
-			},
+			StatusUpdate: update,
 		},
 	}
 
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return errors.As(err)
+		return wrapErr(err)
 	}
 
 	return nil
@@ -771,48 +1305,14 @@ func (socket *DefaultSocket) WriteChatMessage(channelID, content string) (*rtapi
 
 	result := socket.Send(req, nil)
 	if err, ok := result.(error); ok {
-		return nil, errors.As(err)
+		return nil, wrapErr(err)
 	}
 	return result.(*RspResult).Decoded.GetMessage().(*rtapi.Envelope_ChannelMessageAck).ChannelMessageAck, nil
 }
 
-// pingPong does a periodic ping-pong check with the server.
-func (socket *DefaultSocket) pingPong(ctx context.Context) {
-	ticker := time.NewTicker(time.Duration(socket.heartbeatTimeoutMs) * time.Millisecond)
-	defer ticker.Stop()
-	log.Println("before pingpong socket is nil:", socket.adapter.socket == nil)
-
-	pingReq := &rtapi.Envelope{
-		Message: &rtapi.Envelope_Ping{
-			Ping: &rtapi.Ping{},
-		},
-	}
-
-	for {
-		select {
-		case <-ticker.C:
-			if socket.userClosed.Load() {
-				// user closed
-				return
-			}
-			starTime := time.Now()
-			result := socket.Send(pingReq, &socket.heartbeatTimeoutMs)
-			if err, ok := result.(error); ok {
-				log.Println("Failed to send ping:", err)
-				continue
-			}
-			if socket.eventHandle != nil {
-				go socket.eventHandle(EventTypePingPong, &RspResult{Data: []byte(time.Now().Sub(starTime).String())})
-			}
-		case <-ctx.Done():
-			return
-		}
-	}
-}
-
 // OnHeartbeatTimeout handles heartbeat timeouts.
 func (socket *DefaultSocket) OnHeartbeatTimeout() {
-	if socket.verbose {
-		fmt.Println("Heartbeat timeout")
+	if socket.IsVerbose() {
+		GetLogger().Warn("Heartbeat timeout")
 	}
 }
@@ -0,0 +1,184 @@
+package nakama
+
+import (
+	"sync"
+
+	"github.com/gwaylib/log"
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// socketCallbacks are the typed callbacks of the messages pushed by the server, see DefaultSocket.OnChannelMessage.
+type socketCallbacks struct {
+	mu                sync.RWMutex
+	channelMessage    func(message *api.ChannelMessage)
+	channelPresence   func(event *rtapi.ChannelPresenceEvent)
+	matchData         func(data *rtapi.MatchData)
+	matchPresence     func(event *rtapi.MatchPresenceEvent)
+	matchmakerMatched func(matched *rtapi.MatchmakerMatched)
+	notification      func(notification *api.Notification)
+	statusPresence    func(event *rtapi.StatusPresenceEvent)
+	streamData        func(data *rtapi.StreamData)
+	streamPresence    func(event *rtapi.StreamPresenceEvent)
+	partyData         func(data *rtapi.PartyData)
+	partyPresence     func(event *rtapi.PartyPresenceEvent)
+	partyLeader       func(leader *rtapi.PartyLeader)
+	partyJoinRequest  func(request *rtapi.PartyJoinRequest)
+	partyClose        func(close *rtapi.PartyClose)
+}
+
+// call calls the callback of the message, called is false when there's none.
+// The callback runs without the lock, so it can set the callbacks.
+func (cb *socketCallbacks) call(envelope *rtapi.Envelope) (called bool) {
+	fn := cb.bind(envelope)
+	if fn == nil {
+		return false
+	}
+	fn()
+	return true
+}
+
+// bind returns the callback of the message bound to it, nil when there's none.
+func (cb *socketCallbacks) bind(envelope *rtapi.Envelope) func() {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	switch msg := envelope.GetMessage().(type) {
+	case *rtapi.Envelope_ChannelMessage:
+		return bound(cb.channelMessage, msg.ChannelMessage)
+	case *rtapi.Envelope_ChannelPresenceEvent:
+		return bound(cb.channelPresence, msg.ChannelPresenceEvent)
+	case *rtapi.Envelope_MatchData:
+		return bound(cb.matchData, msg.MatchData)
+	case *rtapi.Envelope_MatchPresenceEvent:
+		return bound(cb.matchPresence, msg.MatchPresenceEvent)
+	case *rtapi.Envelope_MatchmakerMatched:
+		return bound(cb.matchmakerMatched, msg.MatchmakerMatched)
+	case *rtapi.Envelope_Notifications:
+		fn := cb.notification
+		if fn == nil {
+			return nil
+		}
+		return func() {
+			for _, notification := range msg.Notifications.GetNotifications() {
+				fn(notification)
+			}
+		}
+	case *rtapi.Envelope_StatusPresenceEvent:
+		return bound(cb.statusPresence, msg.StatusPresenceEvent)
+	case *rtapi.Envelope_StreamData:
+		return bound(cb.streamData, msg.StreamData)
+	case *rtapi.Envelope_StreamPresenceEvent:
+		return bound(cb.streamPresence, msg.StreamPresenceEvent)
+	case *rtapi.Envelope_PartyData:
+		return bound(cb.partyData, msg.PartyData)
+	case *rtapi.Envelope_PartyPresenceEvent:
+		return bound(cb.partyPresence, msg.PartyPresenceEvent)
+	case *rtapi.Envelope_PartyLeader:
+		return bound(cb.partyLeader, msg.PartyLeader)
+	case *rtapi.Envelope_PartyJoinRequest:
+		return bound(cb.partyJoinRequest, msg.PartyJoinRequest)
+	case *rtapi.Envelope_PartyClose:
+		return bound(cb.partyClose, msg.PartyClose)
+	}
+	return nil
+}
+
+// bound returns fn bound to the message, nil when fn is.
+func bound[T any](fn func(T), msg T) func() {
+	if fn == nil {
+		return nil
+	}
+	return func() { fn(msg) }
+}
+
+// set sets a callback under the lock.
+func (cb *socketCallbacks) set(set func()) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	set()
+}
+
+// deliver hands a message pushed by the server to its typed callback, then to the EventHandler.
+// It runs on the stream of the message, see SetStreamQueueSize.
+func (socket *DefaultSocket) deliver(event EventType, result *RspResult) {
+	called := result.Decoded != nil && socket.callbacks.call(result.Decoded)
+	if socket.eventHandle != nil {
+		socket.eventHandle(event, result)
+	} else if !called {
+		log.Debug("uncatch result", result)
+	}
+}
+
+// The typed callbacks receive the messages pushed by the server, in the order received for each chat channel,
+// match, party and stream like the EventHandler, which still receives them after the callback.
+// Setting a callback replaces the previous one, nil removes it. See SetOnDisconnect for the disconnects.
+
+// OnChannelMessage sets the callback of the chat messages.
+func (socket *DefaultSocket) OnChannelMessage(fn func(message *api.ChannelMessage)) {
+	socket.callbacks.set(func() { socket.callbacks.channelMessage = fn })
+}
+
+// OnChannelPresence sets the callback of the users joining and leaving the chat channels.
+func (socket *DefaultSocket) OnChannelPresence(fn func(event *rtapi.ChannelPresenceEvent)) {
+	socket.callbacks.set(func() { socket.callbacks.channelPresence = fn })
+}
+
+// OnMatchData sets the callback of the match data, it isn't called for the match data of the fast path of SetOnMatchData.
+func (socket *DefaultSocket) OnMatchData(fn func(data *rtapi.MatchData)) {
+	socket.callbacks.set(func() { socket.callbacks.matchData = fn })
+}
+
+// OnMatchPresence sets the callback of the users joining and leaving the matches.
+func (socket *DefaultSocket) OnMatchPresence(fn func(event *rtapi.MatchPresenceEvent)) {
+	socket.callbacks.set(func() { socket.callbacks.matchPresence = fn })
+}
+
+// OnMatchmakerMatched sets the callback of the matches found by the matchmaker, see JoinMatchedMatch.
+func (socket *DefaultSocket) OnMatchmakerMatched(fn func(matched *rtapi.MatchmakerMatched)) {
+	socket.callbacks.set(func() { socket.callbacks.matchmakerMatched = fn })
+}
+
+// OnNotification sets the callback of the notifications, called once per notification of a message.
+func (socket *DefaultSocket) OnNotification(fn func(notification *api.Notification)) {
+	socket.callbacks.set(func() { socket.callbacks.notification = fn })
+}
+
+// OnStatusPresence sets the callback of the status changes of the users followed.
+func (socket *DefaultSocket) OnStatusPresence(fn func(event *rtapi.StatusPresenceEvent)) {
+	socket.callbacks.set(func() { socket.callbacks.statusPresence = fn })
+}
+
+// OnStreamData sets the callback of the data of the custom streams.
+func (socket *DefaultSocket) OnStreamData(fn func(data *rtapi.StreamData)) {
+	socket.callbacks.set(func() { socket.callbacks.streamData = fn })
+}
+
+// OnStreamPresence sets the callback of the users joining and leaving the custom streams.
+func (socket *DefaultSocket) OnStreamPresence(fn func(event *rtapi.StreamPresenceEvent)) {
+	socket.callbacks.set(func() { socket.callbacks.streamPresence = fn })
+}
+
+// OnPartyData sets the callback of the party data.
+func (socket *DefaultSocket) OnPartyData(fn func(data *rtapi.PartyData)) {
+	socket.callbacks.set(func() { socket.callbacks.partyData = fn })
+}
+
+// OnPartyPresence sets the callback of the members joining and leaving the parties.
+func (socket *DefaultSocket) OnPartyPresence(fn func(event *rtapi.PartyPresenceEvent)) {
+	socket.callbacks.set(func() { socket.callbacks.partyPresence = fn })
+}
+
+// OnPartyLeader sets the callback of the leader changes of the parties.
+func (socket *DefaultSocket) OnPartyLeader(fn func(leader *rtapi.PartyLeader)) {
+	socket.callbacks.set(func() { socket.callbacks.partyLeader = fn })
+}
+
+// OnPartyJoinRequest sets the callback of the join requests of the closed parties led by the user, see AcceptPartyMember.
+func (socket *DefaultSocket) OnPartyJoinRequest(fn func(request *rtapi.PartyJoinRequest)) {
+	socket.callbacks.set(func() { socket.callbacks.partyJoinRequest = fn })
+}
+
+// OnPartyClose sets the callback of the parties closed by their leader.
+func (socket *DefaultSocket) OnPartyClose(fn func(close *rtapi.PartyClose)) {
+	socket.callbacks.set(func() { socket.callbacks.partyClose = fn })
+}
@@ -0,0 +1,85 @@
+package nakama
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSocketCallbacks(t *testing.T) {
+	server := newScriptedServer(t, nil)
+	socket, _ := server.socket(nil)
+	messages := make(chan string, 4)
+	notifications := make(chan string, 4)
+	data := make(chan []byte, 4)
+	socket.OnChannelMessage(func(message *api.ChannelMessage) { messages <- message.GetContent() })
+	socket.OnNotification(func(notification *api.Notification) { notifications <- notification.GetSubject() })
+	socket.OnMatchData(func(match *rtapi.MatchData) { data <- match.GetData() })
+	assert.NoError(t, socket.Connect())
+
+	// without EventHandler
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_ChannelMessage{ChannelMessage: &api.ChannelMessage{Content: "hi"}}})
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_Notifications{Notifications: &rtapi.Notifications{
+		Notifications: []*api.Notification{{Subject: "a"}, {Subject: "b"}},
+	}}})
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_MatchData{MatchData: &rtapi.MatchData{MatchId: "m1", Data: []byte{7}}}})
+	assert.Equal(t, "hi", <-messages)
+	assert.Equal(t, "a", <-notifications)
+	assert.Equal(t, "b", <-notifications)
+	assert.Equal(t, []byte{7}, <-data)
+
+	// removed with nil
+	socket.OnChannelMessage(nil)
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_ChannelMessage{ChannelMessage: &api.ChannelMessage{Content: "lost"}}})
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_MatchData{MatchData: &rtapi.MatchData{MatchId: "m1", Data: []byte{8}}}})
+	assert.Equal(t, []byte{8}, <-data)
+	assert.Empty(t, messages)
+}
+
+func TestSocketCallbacksWithEventHandler(t *testing.T) {
+	server := newScriptedServer(t, nil)
+	order := make(chan string, 2)
+	socket, _ := server.socket(func(event EventType, data *RspResult) {
+		if event == EventTypeMessage {
+			order <- "handler"
+		}
+	})
+	socket.OnStatusPresence(func(event *rtapi.StatusPresenceEvent) { order <- "callback" })
+	assert.NoError(t, socket.Connect())
+
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_StatusPresenceEvent{StatusPresenceEvent: &rtapi.StatusPresenceEvent{}}})
+	assert.Equal(t, "callback", <-order)
+	assert.Equal(t, "handler", <-order)
+}
+
+func TestSocketCallbacksSetFromCallback(t *testing.T) {
+	server := newScriptedServer(t, nil)
+	socket, _ := server.socket(nil)
+	data := make(chan []byte, 1)
+	matched := make(chan struct{})
+	socket.OnMatchmakerMatched(func(*rtapi.MatchmakerMatched) {
+		// e.g. before joining the match found
+		socket.OnMatchData(func(match *rtapi.MatchData) { data <- match.GetData() })
+		socket.OnMatchmakerMatched(nil)
+		close(matched)
+	})
+	assert.NoError(t, socket.Connect())
+
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_MatchmakerMatched{MatchmakerMatched: &rtapi.MatchmakerMatched{Ticket: "t1"}}})
+	// the matchmaker and the match are on different streams, the data may be delivered first otherwise
+	select {
+	case <-matched:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the callback setting a callback has deadlocked")
+	}
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_MatchData{MatchData: &rtapi.MatchData{MatchId: "m1", Data: []byte{1}}}})
+	select {
+	case got := <-data:
+		assert.Equal(t, []byte{1}, got)
+	case <-time.After(2 * time.Second):
+		t.Fatal("the callback setting a callback has deadlocked")
+	}
+}
@@ -0,0 +1,64 @@
+package nakama
+
+import (
+	"github.com/coder/websocket"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"google.golang.org/protobuf/proto"
+)
+
+// SocketFormat is the encoding of the socket messages, negotiated with the format query parameter of the handshake.
+type SocketFormat string
+
+const (
+	// SocketFormatJSON sends the envelopes in protojson text messages, the default.
+	SocketFormatJSON SocketFormat = "json"
+	// SocketFormatProtobuf sends the envelopes in protobuf binary messages, smaller and cheaper to encode,
+	// e.g. for the match data sent at a high rate.
+	SocketFormatProtobuf SocketFormat = "protobuf"
+)
+
+// envelopeProtoMarshal is shared by the binary sends, the options are read only.
+var envelopeProtoMarshal = proto.MarshalOptions{}
+
+// marshalFormat marshals the envelope in the format into a pooled buffer, put it back with releaseEnvelope once sent,
+// and returns the websocket message type of the format.
+func marshalFormat(format SocketFormat, envelope *rtapi.Envelope) (*[]byte, websocket.MessageType, error) {
+	if format != SocketFormatProtobuf {
+		buf, err := marshalEnvelope(envelope)
+		return buf, websocket.MessageText, err
+	}
+	buf := envelopeBuffers.Get().(*[]byte)
+	data, err := envelopeProtoMarshal.MarshalAppend((*buf)[:0], envelope)
+	if err != nil {
+		releaseEnvelope(buf)
+		return nil, 0, wrapErr(err, envelopeType(envelope))
+	}
+	*buf = data
+	return buf, websocket.MessageBinary, nil
+}
+
+// SetFormat sets the encoding of the messages of the socket, it applies to the next connection.
+// With SocketFormatProtobuf, RspResult.Data is the protobuf of the envelope and SetOnUnknownEnvelope
+// isn't called since the binary messages don't carry the names of the unknown messages.
+func (socket *DefaultSocket) SetFormat(format SocketFormat) error {
+	switch format {
+	case SocketFormatJSON, SocketFormatProtobuf:
+	default:
+		return newError("unknown socket format").With(format)
+	}
+	socket.adapter.SetFormat(format)
+	return nil
+}
+
+// WithSocketFormat sets the encoding of the messages of the sockets created by the client, see DefaultSocket.SetFormat.
+func WithSocketFormat(format SocketFormat) ClientOption {
+	return func(opts *ClientOptions) error {
+		switch format {
+		case SocketFormatJSON, SocketFormatProtobuf:
+		default:
+			return newError("unknown socket format").With(format)
+		}
+		opts.SocketFormat = format
+		return nil
+	}
+}
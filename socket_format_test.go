@@ -0,0 +1,41 @@
+package nakama
+
+import (
+	"testing"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSocketFormatProtobuf(t *testing.T) {
+	server := newScriptedServer(t, answerChannelJoins)
+	messages := make(chan *RspResult, 1)
+	socket, _ := server.socket(func(event EventType, data *RspResult) {
+		if event == EventTypeMessage {
+			messages <- data
+		}
+	})
+	assert.Error(t, socket.SetFormat("xml"))
+	assert.NoError(t, socket.SetFormat(SocketFormatProtobuf))
+	assert.NoError(t, socket.Connect())
+
+	// the requests and their responses are binary
+	channel, err := socket.JoinChat(RoomTarget("lobby", false, false))
+	assert.NoError(t, err)
+	assert.Equal(t, "2...lobby", channel.GetId())
+	assert.True(t, server.conn(0).protobuf)
+
+	// the messages pushed are binary too
+	server.conn(0).Reply(&rtapi.Envelope{}, &rtapi.Envelope{Message: &rtapi.Envelope_ChannelMessage{ChannelMessage: &api.ChannelMessage{Content: `{"a":1}`}}})
+	assert.Equal(t, `{"a":1}`, (<-messages).Decoded.GetChannelMessage().GetContent())
+
+	// and the match data go through the fast path
+	frames := make(chan []byte, 1)
+	socket.SetOnMatchData(func(frame *MatchDataFrame) { frames <- frame.Retain() })
+	server.conn(0).Reply(&rtapi.Envelope{}, &rtapi.Envelope{Message: &rtapi.Envelope_MatchData{MatchData: &rtapi.MatchData{MatchId: "m1", OpCode: 3, Data: []byte{0, 1, 2}}}})
+	assert.Equal(t, []byte{0, 1, 2}, <-frames)
+	assert.NoError(t, socket.SendMatchState("m1", 4, []byte{9}, nil, false))
+	eventually(t, func() bool { return len(server.requestsOf("match_data_send")) == 1 }, "the match data have not been sent")
+	assert.Equal(t, []byte{9}, server.requestsOf("match_data_send")[0].GetMatchDataSend().GetData())
+}
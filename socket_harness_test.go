@@ -0,0 +1,652 @@
+package nakama
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// scriptedConn is a connection of the scripted server, the script answers the requests through it.
+type scriptedConn struct {
+	t        *testing.T
+	conn     *websocket.Conn
+	protobuf bool       // the handshake asked for the protobuf format
+	mu       sync.Mutex // the writes of the delayed replies
+}
+
+// Reply sends the response of req, rsp gets the cid of req.
+func (c *scriptedConn) Reply(req, rsp *rtapi.Envelope) {
+	rsp.Cid = req.Cid
+	if c.protobuf {
+		data, err := proto.Marshal(rsp)
+		if err != nil {
+			c.t.Error(err)
+			return
+		}
+		c.write(websocket.MessageBinary, data)
+		return
+	}
+	data, err := protojson.Marshal(rsp)
+	if err != nil {
+		c.t.Error(err)
+		return
+	}
+	c.WriteRaw(data)
+}
+
+// ReplyAfter sends the response of req after the delay, without blocking the script.
+func (c *scriptedConn) ReplyAfter(delay time.Duration, req, rsp *rtapi.Envelope) {
+	go func() {
+		time.Sleep(delay)
+		c.Reply(req, rsp)
+	}()
+}
+
+// WriteRaw sends a frame as is, e.g. a malformed one.
+func (c *scriptedConn) WriteRaw(data []byte) {
+	c.write(websocket.MessageText, data)
+}
+
+func (c *scriptedConn) write(mType websocket.MessageType, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.Write(c.t.Context(), mType, data)
+}
+
+// Drop ends the connection without a close frame, like a lost network.
+func (c *scriptedConn) Drop() {
+	c.conn.CloseNow()
+}
+
+// scriptedServer is an in-process Nakama socket server answering the requests with a script.
+type scriptedServer struct {
+	t        *testing.T
+	server   *httptest.Server
+	host     string
+	port     string
+	accepted atomic.Int32
+	refuse   atomic.Int32 // the count of the next handshakes refused
+
+	mu       sync.Mutex
+	conns    []*scriptedConn
+	requests []*rtapi.Envelope
+}
+
+// newScriptedServer starts a server calling script for each request, script may be nil to ignore them.
+func newScriptedServer(t *testing.T, script func(conn *scriptedConn, req *rtapi.Envelope)) *scriptedServer {
+	s := &scriptedServer{t: t}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.refuse.Load() > 0 {
+			s.refuse.Add(-1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		sc := &scriptedConn{t: t, conn: conn, protobuf: r.URL.Query().Get("format") == "protobuf"}
+		s.mu.Lock()
+		s.conns = append(s.conns, sc)
+		s.mu.Unlock()
+		s.accepted.Add(1)
+		for {
+			mType, data, err := conn.Read(r.Context())
+			if err != nil {
+				return
+			}
+			req := &rtapi.Envelope{}
+			unmarshal := protojson.Unmarshal
+			if mType == websocket.MessageBinary {
+				unmarshal = proto.Unmarshal
+			}
+			if err := unmarshal(data, req); err != nil {
+				t.Errorf("malformed request %s", data)
+				return
+			}
+			s.mu.Lock()
+			s.requests = append(s.requests, req)
+			s.mu.Unlock()
+			if script != nil {
+				script(sc, req)
+			}
+		}
+	}))
+	t.Cleanup(s.server.Close)
+	u, _ := url.Parse(s.server.URL)
+	s.host, s.port, _ = net.SplitHostPort(u.Host)
+	return s
+}
+
+// socket returns a socket to the server with a fake sleep recording the waits of the reconnects.
+func (s *scriptedServer) socket(eventHandle EventHandler) (*DefaultSocket, *fakeSleep) {
+	socket := NewDefaultSocket(eventHandle, s.host, s.port, "token", false, false, nil, nil)
+	socket.SetPingIntervalMs(-1)
+	sleep := &fakeSleep{}
+	socket.sleep = sleep.Sleep
+	s.t.Cleanup(socket.Disconnect)
+	return socket, sleep
+}
+
+// conn returns the i-th connection accepted, it waits for the server since the dial of the client returns first.
+func (s *scriptedServer) conn(i int) *scriptedConn {
+	s.t.Helper()
+	var conn *scriptedConn
+	eventually(s.t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if i < len(s.conns) {
+			conn = s.conns[i]
+		}
+		return conn != nil
+	}, "the connection has not been accepted")
+	return conn
+}
+
+// requestsOf returns the requests received of a type, e.g. "channel_join".
+func (s *scriptedServer) requestsOf(name string) []*rtapi.Envelope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := []*rtapi.Envelope{}
+	for _, req := range s.requests {
+		if envelopeType(req) == name {
+			requests = append(requests, req)
+		}
+	}
+	return requests
+}
+
+// fakeSleep records the waits instead of sleeping, so the reconnect delays are checked without waiting for them.
+type fakeSleep struct {
+	mu    sync.Mutex
+	waits []time.Duration
+}
+
+func (f *fakeSleep) Sleep(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.waits = append(f.waits, d)
+}
+
+func (f *fakeSleep) Waits() []time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]time.Duration{}, f.waits...)
+}
+
+// fakeTimers are the timers of a socket fired by the test instead of the wall clock, see DefaultSocket.newTimer.
+type fakeTimers struct {
+	mu      sync.Mutex
+	pending []*fakeTimer
+}
+
+type fakeTimer struct {
+	timers *fakeTimers
+	d      time.Duration
+	c      chan time.Time
+}
+
+func (f *fakeTimers) New(d time.Duration) socketTimer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	timer := &fakeTimer{timers: f, d: d, c: make(chan time.Time, 1)}
+	f.pending = append(f.pending, timer)
+	return timer
+}
+
+// Fire waits for a timer of d to be started and fires the timers of d pending.
+func (f *fakeTimers) Fire(t *testing.T, d time.Duration) {
+	t.Helper()
+	fired := false
+	eventually(t, func() bool {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		pending := f.pending[:0]
+		for _, timer := range f.pending {
+			if timer.d == d {
+				timer.c <- time.Now()
+				fired = true
+			} else {
+				pending = append(pending, timer)
+			}
+		}
+		f.pending = pending
+		return fired
+	}, "no timer of "+d.String())
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.timers.mu.Lock()
+	defer t.timers.mu.Unlock()
+	for i, timer := range t.timers.pending {
+		if timer == t {
+			t.timers.pending = slices.Delete(t.timers.pending, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// eventually waits up to a second for cond.
+func eventually(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// answerChannelJoins answers the chat joins with a channel.
+func answerChannelJoins(conn *scriptedConn, req *rtapi.Envelope) {
+	if join := req.GetChannelJoin(); join != nil {
+		conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Channel{Channel: &rtapi.Channel{Id: "2..." + join.Target}}})
+	}
+}
+
+func TestSocketReconnectAfterDrop(t *testing.T) {
+	server := newScriptedServer(t, answerChannelJoins)
+	var reconnected atomic.Int32
+	socket, sleep := server.socket(func(event EventType, data *RspResult) {
+		if event == EventTypeReConnected {
+			reconnected.Add(1)
+		}
+	})
+	socket.SetReconnectPolicy(ReconnectPolicy{InitialDelay: 2 * time.Second, Interval: 3 * time.Second})
+	assert.NoError(t, socket.Connect())
+	_, err := socket.JoinChat(RoomTarget("lobby", false, false))
+	assert.NoError(t, err)
+
+	server.conn(0).Drop()
+	eventually(t, func() bool { return reconnected.Load() == 1 }, "the socket has not reconnected")
+	assert.Equal(t, int32(2), server.accepted.Load())
+	assert.Equal(t, []time.Duration{2 * time.Second}, sleep.Waits(), "the initial delay is waited once")
+
+	// the chats are rejoined on the new connection, and the requests go through it
+	eventually(t, func() bool { return len(server.requestsOf("channel_join")) == 2 }, "the chat has not been rejoined")
+	_, err = socket.JoinChat(GroupTarget("guild"))
+	assert.NoError(t, err)
+}
+
+func TestSocketRejoinUnexpectedReply(t *testing.T) {
+	// the chat is joined, its rejoin is answered by another message
+	var joins atomic.Int32
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		if req.GetChannelJoin() != nil && joins.Add(1) > 1 {
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Rpc{Rpc: &api.Rpc{Id: "unexpected"}}})
+			return
+		}
+		answerChannelJoins(conn, req)
+	})
+	var reconnected atomic.Int32
+	socket, _ := server.socket(func(event EventType, data *RspResult) {
+		if event == EventTypeReConnected {
+			reconnected.Add(1)
+		}
+	})
+	assert.NoError(t, socket.Connect())
+	_, err := socket.JoinChat(RoomTarget("lobby", false, false))
+	assert.NoError(t, err)
+
+	// the chat failing to rejoin is forgotten
+	server.conn(0).Drop()
+	eventually(t, func() bool { return reconnected.Load() == 1 }, "the socket has not reconnected")
+	assert.Empty(t, socket.JoinedChats())
+	_, err = socket.JoinChat(RoomTarget("lobby", false, false))
+	assert.ErrorContains(t, err, "unexpected response")
+}
+
+func TestSocketReconnectRetries(t *testing.T) {
+	server := newScriptedServer(t, nil)
+	socket, sleep := server.socket(nil)
+	socket.SetReconnectPolicy(ReconnectPolicy{Interval: 3 * time.Second})
+	assert.NoError(t, socket.Connect())
+
+	// the handshakes fail twice, the third attempt connects
+	server.refuse.Store(2)
+	server.conn(0).Drop()
+	eventually(t, func() bool { return server.accepted.Load() == 2 }, "the socket has not reconnected")
+	assert.Equal(t, []time.Duration{0, 3 * time.Second, 3 * time.Second}, sleep.Waits())
+
+	// a send doesn't reconnect a socket closed by the user
+	socket.Disconnect()
+	err, ok := socket.Send(&rtapi.Envelope{Message: &rtapi.Envelope_Ping{Ping: &rtapi.Ping{}}}, nil).(error)
+	assert.True(t, ok)
+	assert.Contains(t, err.Error(), "closed")
+}
+
+func TestSocketDisconnectDuringBudgetWait(t *testing.T) {
+	server := newScriptedServer(t, nil)
+	socket, sleep := server.socket(nil)
+	budget := NewReconnectBudget(0.001, 1)
+	budget.Reserve()
+	socket.SetReconnectPolicy(ReconnectPolicy{Interval: time.Second, Budget: budget})
+	assert.NoError(t, socket.Connect())
+
+	// the reconnect waits about 1000s for the budget, Disconnect doesn't
+	server.conn(0).Drop()
+	eventually(t, func() bool { return len(sleep.Waits()) == 1 }, "the socket has not lost its connection")
+	start := time.Now()
+	socket.Disconnect()
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(1), server.accepted.Load())
+}
+
+func TestSocketCorrelationTimeout(t *testing.T) {
+	late := make(chan *rtapi.Envelope, 1)
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		if rpc := req.GetRpc(); rpc != nil && rpc.Payload == "late" {
+			late <- req
+		} else if rpc != nil {
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Rpc{Rpc: rpc}})
+		}
+	})
+	socket, _ := server.socket(nil)
+	timers := &fakeTimers{}
+	socket.newTimer = timers.New
+	assert.NoError(t, socket.Connect())
+
+	timeoutMs := 50
+	rpc := func(payload string) any {
+		return socket.Send(&rtapi.Envelope{Message: &rtapi.Envelope_Rpc{Rpc: &api.Rpc{Id: "echo", Payload: payload}}}, &timeoutMs)
+	}
+	_, ok := rpc("now").(*RspResult)
+	assert.True(t, ok, "a response within the timeout")
+
+	timedOut := make(chan any)
+	go func() { timedOut <- rpc("late") }()
+	req := <-late
+	timers.Fire(t, 50*time.Millisecond)
+	err, ok := (<-timedOut).(error)
+	assert.True(t, ok)
+	assert.Contains(t, err.Error(), "timeout")
+
+	// the late response has no caller left, the next requests are still correlated
+	server.conn(0).Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Rpc{Rpc: req.GetRpc()}})
+	result, ok := rpc("next").(*RspResult)
+	assert.True(t, ok)
+	assert.Equal(t, "next", result.Decoded.GetRpc().GetPayload())
+}
+
+func TestSocketMalformedFrames(t *testing.T) {
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		if req.GetStatusUpdate() != nil {
+			conn.WriteRaw([]byte(`{"cid":"` + req.Cid + `",`))
+			conn.WriteRaw([]byte(`{"unknown_message":{}}`))
+			conn.Reply(req, &rtapi.Envelope{})
+		}
+	})
+	socket, _ := server.socket(nil)
+	assert.NoError(t, socket.Connect())
+
+	status := "online"
+	assert.NoError(t, socket.UpdateStatus(&status))
+	assert.True(t, socket.adapter.IsOpen(), "the malformed frames don't close the connection")
+	assert.Equal(t, int32(1), server.accepted.Load())
+}
+
+func TestSocketHeartbeat(t *testing.T) {
+	var answer atomic.Bool
+	answer.Store(true)
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		if req.GetPing() != nil && answer.Load() {
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Pong{Pong: &rtapi.Pong{}}})
+		}
+	})
+	socket, _ := server.socket(nil)
+	timers := &fakeTimers{}
+	socket.newTimer = timers.New
+	socket.SetHeartbeatTimeoutMs(50)
+	assert.NoError(t, socket.Connect())
+	socket.SetPingIntervalMs(10)
+
+	timers.Fire(t, 10*time.Millisecond)
+	eventually(t, func() bool { last, _ := socket.PingLatency(); return last > 0 }, "no pong")
+
+	// the server answers the pings of the server
+	server.conn(0).WriteRaw([]byte(`{"cid":"srv-1","ping":{}}`))
+	eventually(t, func() bool {
+		for _, pong := range server.requestsOf("pong") {
+			if pong.Cid == "srv-1" {
+				return true
+			}
+		}
+		return false
+	}, "no pong to the server ping")
+
+	// the missed pongs time out without closing the connection
+	answer.Store(false)
+	pings := len(server.requestsOf("ping"))
+	timers.Fire(t, 10*time.Millisecond)
+	timers.Fire(t, 50*time.Millisecond)
+	timers.Fire(t, 10*time.Millisecond)
+	eventually(t, func() bool { return len(server.requestsOf("ping")) == pings+2 }, "the pings have stopped")
+	assert.True(t, socket.adapter.IsOpen())
+}
+
+func TestSocketMatchCalls(t *testing.T) {
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		switch {
+		case req.GetMatchCreate() != nil:
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Match{Match: &rtapi.Match{MatchId: "m." + req.GetMatchCreate().GetName()}}})
+		case req.GetMatchJoin().GetToken() == "bad":
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Error{Error: &rtapi.Error{Code: int32(rtapi.Error_MATCH_JOIN_REJECTED), Message: "rejected"}}})
+		case req.GetMatchJoin() != nil:
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Match{Match: &rtapi.Match{MatchId: req.GetMatchJoin().GetMatchId()}}})
+		case req.GetMatchLeave() != nil:
+			conn.Reply(req, &rtapi.Envelope{})
+		}
+	})
+	socket, _ := server.socket(nil)
+	assert.NoError(t, socket.Connect())
+
+	match, err := socket.CreateMatch(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "m.", match.GetMatchId())
+	name := "arena"
+	match, err = socket.CreateMatch(&name)
+	assert.NoError(t, err)
+	assert.Equal(t, "m.arena", match.GetMatchId())
+
+	match, err = socket.JoinMatch(&match.MatchId, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "m.arena", match.GetMatchId())
+	bad := "bad"
+	_, err = socket.JoinMatch(nil, &bad, nil)
+	code, ok := socketErrorCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, rtapi.Error_MATCH_JOIN_REJECTED, code)
+
+	assert.NoError(t, socket.LeaveMatch("m.arena"))
+	assert.Error(t, socket.LeaveMatch(""))
+}
+
+func TestSocketJoinMatchedMatch(t *testing.T) {
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		if join := req.GetMatchJoin(); join != nil {
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Match{Match: &rtapi.Match{MatchId: "m." + join.GetToken() + join.GetMatchId()}}})
+		}
+	})
+	socket, _ := server.socket(nil)
+	assert.NoError(t, socket.Connect())
+
+	// the authoritative matches are joined by token, the relayed ones by match id
+	metadata := map[string]string{"skin": "red"}
+	match, err := socket.JoinMatchedMatch(&rtapi.MatchmakerMatched{Ticket: "t1", Id: &rtapi.MatchmakerMatched_Token{Token: "token"}}, metadata)
+	assert.NoError(t, err)
+	assert.Equal(t, "m.token", match.GetMatchId())
+	match, err = socket.JoinMatchedMatch(&rtapi.MatchmakerMatched{Ticket: "t2", Id: &rtapi.MatchmakerMatched_MatchId{MatchId: "relayed"}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "m.relayed", match.GetMatchId())
+
+	joins := server.requestsOf("match_join")
+	assert.Len(t, joins, 2)
+	assert.Equal(t, "token", joins[0].GetMatchJoin().GetToken())
+	assert.Equal(t, metadata, joins[0].GetMatchJoin().GetMetadata())
+	assert.Equal(t, "relayed", joins[1].GetMatchJoin().GetMatchId())
+
+	_, err = socket.JoinMatchedMatch(nil, nil)
+	assert.Error(t, err)
+	_, err = socket.JoinMatchedMatch(&rtapi.MatchmakerMatched{Ticket: "t3"}, nil)
+	assert.Error(t, err)
+	assert.Len(t, server.requestsOf("match_join"), 2, "nothing to join isn't sent")
+}
+
+func TestSocketPartyCalls(t *testing.T) {
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		switch {
+		case req.GetPartyCreate() != nil:
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Party{Party: &rtapi.Party{PartyId: "p1", Open: req.GetPartyCreate().GetOpen()}}})
+		case req.GetPartyPromote().GetPresence().GetUserId() == "wrong":
+			// a wrong reply isn't taken for the leader
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Party{Party: &rtapi.Party{PartyId: "p1"}}})
+		case req.GetPartyPromote() != nil:
+			// acknowledged, the new leader is announced to the members
+			conn.Reply(req, &rtapi.Envelope{})
+			push(t, conn, &rtapi.Envelope{Message: &rtapi.Envelope_PartyLeader{PartyLeader: &rtapi.PartyLeader{
+				PartyId: "p1", Presence: req.GetPartyPromote().GetPresence(),
+			}}})
+		case req.GetPartyJoinRequestList() != nil:
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_PartyJoinRequest{PartyJoinRequest: &rtapi.PartyJoinRequest{
+				PartyId: "p1", Presences: []*rtapi.UserPresence{{UserId: "u2"}},
+			}}})
+		default:
+			conn.Reply(req, &rtapi.Envelope{})
+		}
+	})
+	socket, _ := server.socket(nil)
+	assert.NoError(t, socket.EnableExperimental(FeatureParties))
+	leaders := make(chan string, 1)
+	socket.OnPartyLeader(func(leader *rtapi.PartyLeader) { leaders <- leader.GetPresence().GetUserId() })
+	closed := make(chan string, 1)
+	socket.OnPartyClose(func(close *rtapi.PartyClose) { closed <- close.GetPartyId() })
+	assert.NoError(t, socket.Connect())
+
+	party, err := socket.CreateParty(false, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "p1", party.GetPartyId())
+	requests, err := socket.ListPartyJoinRequests("p1")
+	assert.NoError(t, err)
+	member := requests.GetPresences()[0]
+	assert.NoError(t, socket.AcceptPartyMember("p1", member))
+	assert.NoError(t, socket.UpdateParty("p1", "ranked", true))
+	leader, err := socket.PromotePartyMember("p1", member)
+	assert.NoError(t, err)
+	assert.Equal(t, "u2", leader.GetPresence().GetUserId())
+	assert.Equal(t, "u2", <-leaders)
+	_, err = socket.PromotePartyMember("p1", &rtapi.UserPresence{UserId: "wrong"})
+	assert.Error(t, err)
+	assert.NoError(t, socket.CloseParty("p1"))
+
+	assert.Equal(t, "u2", server.requestsOf("party_accept")[0].GetPartyAccept().GetPresence().GetUserId())
+	update := server.requestsOf("party_update")[0].GetPartyUpdate()
+	assert.Equal(t, "ranked", update.GetLabel())
+	assert.True(t, update.GetOpen())
+	assert.Len(t, server.requestsOf("party_close"), 1)
+
+	assert.Error(t, socket.AcceptPartyMember("p1", nil))
+	assert.Error(t, socket.CloseParty(""))
+	assert.Error(t, socket.UpdateParty("", "", false))
+
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_PartyClose{PartyClose: &rtapi.PartyClose{PartyId: "p1"}}})
+	assert.Equal(t, "p1", <-closed)
+}
+
+func TestSocketStatusCalls(t *testing.T) {
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		if follow := req.GetStatusFollow(); follow != nil {
+			presences := []*rtapi.UserPresence{}
+			for _, id := range follow.GetUserIds() {
+				presences = append(presences, &rtapi.UserPresence{UserId: id, Status: wrapperspb.String("online")})
+			}
+			for _, username := range follow.GetUsernames() {
+				presences = append(presences, &rtapi.UserPresence{Username: username})
+			}
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Status{Status: &rtapi.Status{Presences: presences}}})
+			return
+		}
+		conn.Reply(req, &rtapi.Envelope{})
+	})
+	socket, _ := server.socket(nil)
+	presences := make(chan *rtapi.StatusPresenceEvent, 1)
+	socket.OnStatusPresence(func(event *rtapi.StatusPresenceEvent) { presences <- event })
+	assert.NoError(t, socket.Connect())
+
+	status, err := socket.FollowUsers([]string{"u1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "online", status.GetPresences()[0].GetStatus().GetValue())
+	status, err = socket.FollowUsernames([]string{"alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", status.GetPresences()[0].GetUsername())
+	assert.NoError(t, socket.UnfollowUsers([]string{"u1"}))
+	_, err = socket.FollowUsers(nil)
+	assert.Error(t, err)
+	assert.Error(t, socket.UnfollowUsers(nil))
+
+	// a nil status appears offline
+	assert.NoError(t, socket.UpdateStatus(nil))
+	assert.Nil(t, server.requestsOf("status_update")[0].GetStatusUpdate().GetStatus())
+
+	push(t, server.conn(0), &rtapi.Envelope{Message: &rtapi.Envelope_StatusPresenceEvent{StatusPresenceEvent: &rtapi.StatusPresenceEvent{
+		Joins: []*rtapi.UserPresence{{UserId: "u2"}},
+	}}})
+	assert.Equal(t, "u2", (<-presences).GetJoins()[0].GetUserId())
+}
+
+func TestSocketRejoinChatAfterDisconnect(t *testing.T) {
+	server := newScriptedServer(t, answerChannelJoins)
+	socket, _ := server.socket(nil)
+	assert.NoError(t, socket.Connect())
+	_, err := socket.JoinChat(RoomTarget("lobby", false, false))
+	assert.NoError(t, err)
+
+	// the server has left the chats of the closed connection
+	socket.Disconnect()
+	assert.NoError(t, socket.Connect())
+	channel, err := socket.JoinChat(RoomTarget("lobby", false, false))
+	assert.NoError(t, err)
+	assert.Equal(t, "2...lobby", channel.GetId())
+	assert.Len(t, server.requestsOf("channel_join"), 2)
+}
+
+func TestSocketPendingRequestsFailOnDisconnect(t *testing.T) {
+	// the rpc is never answered
+	server := newScriptedServer(t, nil)
+	socket, _ := server.socket(nil)
+	socket.SetReconnectPolicy(ReconnectPolicy{Interval: time.Millisecond})
+	assert.NoError(t, socket.Connect())
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := socket.Rpc("slow", "{}", "")
+		result <- err
+	}()
+	eventually(t, func() bool { return len(server.requestsOf("rpc")) == 1 }, "the rpc has not been sent")
+	server.conn(0).Drop()
+	select {
+	case err := <-result:
+		assert.True(t, errors.Is(err, ErrConnectionLost), err)
+	case <-time.After(time.Second):
+		t.Fatal("the rpc has waited for its timeout")
+	}
+}
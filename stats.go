@@ -0,0 +1,200 @@
+package nakama
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultRecentErrorsSize is the number of errors kept by ClientStats.
+const DefaultRecentErrorsSize = 20
+
+// RecentError is an error recorded by ClientStats.
+type RecentError struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	Error  string    `json:"error"`
+}
+
+// ClientStats collects the counters of the http calls.
+type ClientStats struct {
+	requests  atomic.Int64
+	failures  atomic.Int64
+	queued    atomic.Int64
+	queueWait atomic.Int64 // nanoseconds
+
+	mu     sync.Mutex
+	recent []RecentError
+}
+
+// NewClientStats creates an empty ClientStats.
+func NewClientStats() *ClientStats {
+	return &ClientStats{}
+}
+
+func (s *ClientStats) record(req *http.Request, err error) {
+	if s == nil {
+		return
+	}
+	s.requests.Add(1)
+	if err == nil {
+		return
+	}
+	s.failures.Add(1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.recent) >= DefaultRecentErrorsSize {
+		s.recent = s.recent[1:]
+	}
+	s.recent = append(s.recent, RecentError{
+		Time:   time.Now(),
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Error:  err.Error(),
+	})
+}
+
+// recordWait counts the time a request waited for a slot of the RequestLimiter.
+func (s *ClientStats) recordWait(wait time.Duration) {
+	if s == nil || wait <= 0 {
+		return
+	}
+	s.queued.Add(1)
+	s.queueWait.Add(int64(wait))
+}
+
+// Requests returns the number of http calls done.
+func (s *ClientStats) Requests() int64 {
+	return s.requests.Load()
+}
+
+// Failures returns the number of http calls failed.
+func (s *ClientStats) Failures() int64 {
+	return s.failures.Load()
+}
+
+// QueuedRequests returns the number of http requests which waited for a slot, see WithMaxConcurrentRequests.
+func (s *ClientStats) QueuedRequests() int64 {
+	return s.queued.Load()
+}
+
+// QueueWait returns the total time the http requests waited for a slot.
+func (s *ClientStats) QueueWait() time.Duration {
+	return time.Duration(s.queueWait.Load())
+}
+
+// RecentErrors returns the last errors, the oldest first.
+func (s *ClientStats) RecentErrors() []RecentError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecentError{}, s.recent...)
+}
+
+// socketRegistry keeps the sockets of a client from their connection to their Disconnect,
+// the sockets never connected aren't reported. A nil registry keeps nothing.
+type socketRegistry struct {
+	mu      sync.Mutex
+	sockets []*DefaultSocket
+}
+
+func (r *socketRegistry) add(socket *DefaultSocket) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !slices.Contains(r.sockets, socket) {
+		r.sockets = append(r.sockets, socket)
+	}
+}
+
+func (r *socketRegistry) remove(socket *DefaultSocket) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sockets = slices.DeleteFunc(r.sockets, func(s *DefaultSocket) bool { return s == socket })
+}
+
+func (r *socketRegistry) list() []*DefaultSocket {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*DefaultSocket{}, r.sockets...)
+}
+
+// SocketState is the connection state of a socket reported by DebugHandler.
+type SocketState struct {
+	Open         bool  `json:"open"`
+	ClosedByUser bool  `json:"closed_by_user"`
+	PingMs       int64 `json:"ping_ms"`     // round trip of the last ping
+	AvgPingMs    int64 `json:"avg_ping_ms"` // average round trip of the pings
+}
+
+// DebugReport is the JSON body served by DebugHandler.
+type DebugReport struct {
+	Status              string        `json:"status"` // "ok" or "degraded"
+	BasePath            string        `json:"base_path"`
+	Requests            int64         `json:"requests"`
+	Failures            int64         `json:"failures"`
+	QueuedRequests      int64         `json:"queued_requests"`
+	QueueWaitMs         int64         `json:"queue_wait_ms"`
+	RecentErrors        []RecentError `json:"recent_errors"`
+	Sockets             []SocketState `json:"sockets"`
+	ServerClockOffsetMs int64         `json:"server_clock_offset_ms"`
+}
+
+// DebugReport returns the stats and the connection state of the client.
+func (c *Client) DebugReport() *DebugReport {
+	report := &DebugReport{
+		Status:       "ok",
+		BasePath:     c.basePath,
+		RecentErrors: []RecentError{},
+		Sockets:      []SocketState{},
+	}
+	if stats := c.stats; stats != nil {
+		report.Requests = stats.Requests()
+		report.Failures = stats.Failures()
+		report.QueuedRequests = stats.QueuedRequests()
+		report.QueueWaitMs = stats.QueueWait().Milliseconds()
+		report.RecentErrors = stats.RecentErrors()
+	}
+	for _, socket := range c.sockets.list() {
+		last, average := socket.PingLatency()
+		state := SocketState{
+			Open:         socket.adapter.IsOpen(),
+			ClosedByUser: socket.userClosed.Load(),
+			PingMs:       last.Milliseconds(),
+			AvgPingMs:    average.Milliseconds(),
+		}
+		if !state.Open && !state.ClosedByUser {
+			report.Status = "degraded"
+		}
+		report.Sockets = append(report.Sockets, state)
+	}
+	if c.Clock != nil {
+		report.ServerClockOffsetMs = c.Clock.Offset().Milliseconds()
+	}
+	return report
+}
+
+// DebugHandler returns a http handler serving the DebugReport as JSON for the health dashboards,
+// the status code is 503 when a socket has lost its connection.
+func (c *Client) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.DebugReport()
+		w.Header().Set("Content-Type", "application/json")
+		if report.Status != "ok" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,41 @@
+package nakama
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugReportSockets(t *testing.T) {
+	server := newScriptedServer(t, nil)
+	client, err := NewClientWithOptions(WithURL(server.server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a socket never connected isn't reported
+	idle := client.CreateSocket(nil, "token", false, false, nil, nil)
+	socket := client.CreateSocket(nil, "token", false, false, nil, nil)
+	socket.SetPingIntervalMs(-1)
+	wait := make(chan struct{})
+	socket.sleep = func(time.Duration) { <-wait }
+	t.Cleanup(socket.Disconnect)
+	assert.NoError(t, socket.Connect())
+	report := client.DebugReport()
+	assert.Equal(t, "ok", report.Status)
+	assert.Len(t, report.Sockets, 1)
+
+	// the lost connection degrades the report until the socket reconnects
+	server.conn(0).Drop()
+	eventually(t, func() bool { return client.DebugReport().Status == "degraded" }, "the lost connection is not reported")
+	close(wait)
+	eventually(t, func() bool { return client.DebugReport().Status == "ok" }, "the socket has not reconnected")
+
+	// the sockets are forgotten by Disconnect
+	socket.Disconnect()
+	idle.Disconnect()
+	report = client.DebugReport()
+	assert.Equal(t, "ok", report.Status)
+	assert.Empty(t, report.Sockets)
+}
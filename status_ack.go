@@ -0,0 +1,143 @@
+package nakama
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ErrStatusSuperseded is returned by UpdateStatusAcked when a newer status has been set before the server confirmed it.
+var ErrStatusSuperseded = newError("status superseded")
+
+// statusUpdates tracks the latest status set by UpdateStatusAcked, it's sent again after each reconnect
+// since the server forgets the status of the previous connection.
+type statusUpdates struct {
+	mu      sync.Mutex
+	set     bool    // a status has been set
+	status  *string // nil appears offline
+	seq     uint64  // the seq of the latest status
+	acked   uint64  // the latest seq confirmed by the server
+	changed chan struct{}
+}
+
+// update sets the latest status and returns its seq.
+func (s *statusUpdates) update(status *string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set = true
+	s.status = status
+	s.seq++
+	s.notify()
+	return s.seq
+}
+
+// ack records the confirmation of the status of seq.
+func (s *statusUpdates) ack(seq uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.acked = max(s.acked, seq)
+	s.notify()
+}
+
+// state returns the latest seq and whether seq is confirmed, and a channel closed on the next change.
+func (s *statusUpdates) state(seq uint64) (latest uint64, acked bool, changed <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.changed == nil {
+		s.changed = make(chan struct{})
+	}
+	return s.seq, s.acked >= seq, s.changed
+}
+
+// notify wakes the waiting updates, s.mu is held.
+func (s *statusUpdates) notify() {
+	if s.changed != nil {
+		close(s.changed)
+		s.changed = nil
+	}
+}
+
+// sendStatus sends the status of seq unless a newer one has been set.
+func (socket *DefaultSocket) sendStatus(seq uint64) error {
+	s := &socket.status
+	s.mu.Lock()
+	status, latest := s.status, s.seq
+	s.mu.Unlock()
+	if seq != latest {
+		return ErrStatusSuperseded
+	}
+	update := &rtapi.StatusUpdate{}
+	if status != nil {
+		update.Status = wrapperspb.String(*status)
+	}
+	result := socket.Send(&rtapi.Envelope{Message: &rtapi.Envelope_StatusUpdate{StatusUpdate: update}}, nil)
+	if err, ok := result.(error); ok {
+		return wrapErr(err)
+	}
+	s.ack(seq)
+	return nil
+}
+
+// isServerError tells if the server has answered the request with an error, unlike a lost connection or a timeout.
+func isServerError(err error) bool {
+	_, ok := socketErrorCode(err)
+	return ok
+}
+
+// UpdateStatusAcked sets the status like UpdateStatus and retries until the server confirms it, e.g. across a reconnect.
+// The errors of the server aren't retried.
+// Only the latest status wins: a call returns ErrStatusSuperseded once a newer status is set, and the latest status
+// is sent again after each reconnect. A nil status appears offline.
+func (socket *DefaultSocket) UpdateStatusAcked(ctx context.Context, status *string) error {
+	seq := socket.status.update(status)
+	for {
+		err := socket.sendStatus(seq)
+		if err == nil {
+			return nil
+		}
+		latest, acked, changed := socket.status.state(seq)
+		switch {
+		case acked && latest == seq:
+			// sent again by a reconnect
+			return nil
+		case latest != seq:
+			return ErrStatusSuperseded.With(seq)
+		case socket.userClosed.Load():
+			return wrapErr(err)
+		case isServerError(err):
+			// rejected by the server, it would be rejected again
+			return wrapErr(err)
+		}
+		GetLogger().Warn(wrapErr(err, "status update, retrying"))
+
+		wait := time.NewTimer(socket.reconnectPolicy.interval())
+		select {
+		case <-ctx.Done():
+			wait.Stop()
+			return wrapErr(ctx.Err())
+		case <-changed:
+		case <-wait.C:
+		}
+		wait.Stop()
+		if latest, acked, _ := socket.status.state(seq); acked && latest == seq {
+			return nil
+		}
+	}
+}
+
+// resendStatus sends the latest status again after a reconnect, if a status has been set with UpdateStatusAcked.
+func (socket *DefaultSocket) resendStatus() {
+	socket.status.mu.Lock()
+	set, seq := socket.status.set, socket.status.seq
+	socket.status.mu.Unlock()
+	if !set {
+		return
+	}
+	if err := socket.sendStatus(seq); err != nil && !errors.Is(err, ErrStatusSuperseded) {
+		GetLogger().Warn(wrapErr(err, "status resend"))
+	}
+}
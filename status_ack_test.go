@@ -0,0 +1,67 @@
+package nakama
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateStatusAcked(t *testing.T) {
+	var updates atomic.Int32
+	server := newScriptedServer(t, func(conn *scriptedConn, req *rtapi.Envelope) {
+		update := req.GetStatusUpdate()
+		if update == nil {
+			return
+		}
+		switch {
+		case updates.Add(1) == 1:
+			// lost with the connection
+			conn.Drop()
+		case update.GetStatus().GetValue() == "rejected":
+			conn.Reply(req, &rtapi.Envelope{Message: &rtapi.Envelope_Error{Error: &rtapi.Error{Code: int32(rtapi.Error_BAD_INPUT), Message: "too long"}}})
+		case update.GetStatus().GetValue() == "slow":
+			conn.ReplyAfter(50*time.Millisecond, req, &rtapi.Envelope{Message: &rtapi.Envelope_Error{Error: &rtapi.Error{Code: 3, Message: "busy"}}})
+		default:
+			conn.Reply(req, &rtapi.Envelope{})
+		}
+	})
+	socket, _ := server.socket(nil)
+	assert.NoError(t, socket.Connect())
+
+	status := "in lobby"
+	assert.NoError(t, socket.UpdateStatusAcked(context.Background(), &status))
+	assert.Equal(t, int32(2), server.accepted.Load())
+	sent := server.requestsOf("status_update")
+	assert.Equal(t, "in lobby", sent[len(sent)-1].GetStatusUpdate().GetStatus().GetValue())
+
+	// the errors of the server aren't retried
+	rejected := "rejected"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := socket.UpdateStatusAcked(ctx, &rejected)
+	code, ok := socketErrorCode(err)
+	assert.True(t, ok, err)
+	assert.Equal(t, rtapi.Error_BAD_INPUT, code)
+	assert.Len(t, server.requestsOf("status_update"), len(sent)+1)
+	sent = server.requestsOf("status_update")
+
+	// a newer status wins over the one being retried
+	slow, fast := "slow", "in match"
+	result := make(chan error, 1)
+	go func() { result <- socket.UpdateStatusAcked(context.Background(), &slow) }()
+	eventually(t, func() bool { return len(server.requestsOf("status_update")) == len(sent)+1 }, "the slow status has not been sent")
+	assert.NoError(t, socket.UpdateStatusAcked(context.Background(), &fast))
+	assert.True(t, errors.Is(<-result, ErrStatusSuperseded))
+
+	// the latest status is sent again after a reconnect
+	server.conn(1).Drop()
+	eventually(t, func() bool {
+		sent := server.requestsOf("status_update")
+		return server.accepted.Load() == 3 && sent[len(sent)-1].GetStatusUpdate().GetStatus().GetValue() == "in match"
+	}, "the status has not been sent again")
+}
@@ -0,0 +1,344 @@
+package nakama
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultStorageIndexMaxAge is the age of an indexed object served without reading it again, see WithStorageIndex.
+const DefaultStorageIndexMaxAge = 5 * time.Minute
+
+// ErrStorageOffline is returned by the indexed reads when the server can't be reached and some objects aren't indexed.
+var ErrStorageOffline = newError("storage objects unavailable offline")
+
+// IndexedObject is a storage object kept by a StorageIndex, with the time it was read from the server.
+type IndexedObject struct {
+	Object *api.StorageObject
+	ReadAt time.Time
+}
+
+// StorageIndexQuery selects the indexed objects of a collection, UserId and KeyPrefix are optional.
+type StorageIndexQuery struct {
+	Collection string
+	UserId     string
+	KeyPrefix  string
+}
+
+// StorageIndex keeps the storage objects read from the server, so they're read locally when the server can't be reached.
+// The values are kept as stored by the server, e.g. encrypted by the ValueTransformer of their collection.
+// MemoryStorageIndex and FileStorageIndex implement it, an embedded database like bbolt can too.
+type StorageIndex interface {
+	Put(objects []*IndexedObject) error
+	// Get returns the object, nil when it isn't indexed.
+	Get(collection, key, userId string) (*IndexedObject, error)
+	// Query returns the objects sorted by collection, owner and key.
+	Query(query StorageIndexQuery) ([]*IndexedObject, error)
+	Delete(collection, key, userId string) error
+}
+
+// storageIndexKey identifies an object of the index.
+type storageIndexKey struct {
+	Collection string
+	Key        string
+	UserId     string
+}
+
+// MemoryStorageIndex is a StorageIndex in memory, lost when the app exits.
+type MemoryStorageIndex struct {
+	mu      sync.RWMutex
+	objects map[storageIndexKey]*IndexedObject
+}
+
+// NewMemoryStorageIndex creates an empty MemoryStorageIndex.
+func NewMemoryStorageIndex() *MemoryStorageIndex {
+	return &MemoryStorageIndex{objects: map[storageIndexKey]*IndexedObject{}}
+}
+
+// Put adds or replaces the objects.
+func (idx *MemoryStorageIndex) Put(objects []*IndexedObject) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, o := range objects {
+		key := storageIndexKey{o.Object.GetCollection(), o.Object.GetKey(), o.Object.GetUserId()}
+		idx.objects[key] = &IndexedObject{Object: proto.Clone(o.Object).(*api.StorageObject), ReadAt: o.ReadAt}
+	}
+	return nil
+}
+
+// Get returns a copy of the object, nil when it isn't indexed.
+func (idx *MemoryStorageIndex) Get(collection, key, userId string) (*IndexedObject, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	o, ok := idx.objects[storageIndexKey{collection, key, userId}]
+	if !ok {
+		return nil, nil
+	}
+	return &IndexedObject{Object: proto.Clone(o.Object).(*api.StorageObject), ReadAt: o.ReadAt}, nil
+}
+
+// Query returns copies of the objects selected.
+func (idx *MemoryStorageIndex) Query(query StorageIndexQuery) ([]*IndexedObject, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	found := []*IndexedObject{}
+	for key, o := range idx.objects {
+		if key.Collection != query.Collection || query.UserId != "" && key.UserId != query.UserId ||
+			!strings.HasPrefix(key.Key, query.KeyPrefix) {
+			continue
+		}
+		found = append(found, &IndexedObject{Object: proto.Clone(o.Object).(*api.StorageObject), ReadAt: o.ReadAt})
+	}
+	slices.SortFunc(found, func(a, b *IndexedObject) int {
+		return strings.Compare(a.Object.GetUserId()+"/"+a.Object.GetKey(), b.Object.GetUserId()+"/"+b.Object.GetKey())
+	})
+	return found, nil
+}
+
+// Delete removes the object, if indexed.
+func (idx *MemoryStorageIndex) Delete(collection, key, userId string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	delete(idx.objects, storageIndexKey{collection, key, userId})
+	return nil
+}
+
+// FileStorageIndex is a MemoryStorageIndex saved to a file on each change, so the objects are read offline
+// after a restart. It suits the small collections, e.g. the settings or the inventory of the player.
+type FileStorageIndex struct {
+	*MemoryStorageIndex
+	Path string
+
+	mu sync.Mutex // the saves
+}
+
+// storedIndexObject is an object in the file of a FileStorageIndex.
+type storedIndexObject struct {
+	Object json.RawMessage `json:"object"` // protojson
+	ReadAt time.Time       `json:"read_at"`
+}
+
+// NewFileStorageIndex creates a FileStorageIndex loading the objects saved in path, if any.
+func NewFileStorageIndex(path string) (*FileStorageIndex, error) {
+	idx := &FileStorageIndex{MemoryStorageIndex: NewMemoryStorageIndex(), Path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	} else if err != nil {
+		return nil, wrapErr(err, path)
+	}
+	stored := []storedIndexObject{}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, wrapErr(err, path)
+	}
+	objects := make([]*IndexedObject, 0, len(stored))
+	for _, s := range stored {
+		object := &api.StorageObject{}
+		if err := responseUnmarshal.Unmarshal(s.Object, object); err != nil {
+			return nil, wrapErr(err, path)
+		}
+		objects = append(objects, &IndexedObject{Object: object, ReadAt: s.ReadAt})
+	}
+	idx.MemoryStorageIndex.Put(objects)
+	return idx, nil
+}
+
+// Put adds or replaces the objects and saves the index.
+func (idx *FileStorageIndex) Put(objects []*IndexedObject) error {
+	idx.MemoryStorageIndex.Put(objects)
+	return idx.save()
+}
+
+// Delete removes the object and saves the index.
+func (idx *FileStorageIndex) Delete(collection, key, userId string) error {
+	idx.MemoryStorageIndex.Delete(collection, key, userId)
+	return idx.save()
+}
+
+// save writes the objects to the file, through a temporary file so a crash keeps the previous version.
+func (idx *FileStorageIndex) save() error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.MemoryStorageIndex.mu.RLock()
+	stored := make([]storedIndexObject, 0, len(idx.objects))
+	for _, o := range idx.objects {
+		data, err := protojson.Marshal(o.Object)
+		if err != nil {
+			idx.MemoryStorageIndex.mu.RUnlock()
+			return wrapErr(err)
+		}
+		stored = append(stored, storedIndexObject{Object: data, ReadAt: o.ReadAt})
+	}
+	idx.MemoryStorageIndex.mu.RUnlock()
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return wrapErr(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(idx.Path), 0700); err != nil {
+		return wrapErr(err, idx.Path)
+	}
+	tmp := idx.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return wrapErr(err, idx.Path)
+	}
+	if err := os.Rename(tmp, idx.Path); err != nil {
+		return wrapErr(err, idx.Path)
+	}
+	return nil
+}
+
+// StorageIndexOptions are the index of the storage objects read and the age of the objects served from it.
+type StorageIndexOptions struct {
+	Index  StorageIndex
+	MaxAge time.Duration
+}
+
+// WithStorageIndex keeps the storage objects read by the client in index, ReadIndexedStorageObjects serves them
+// without reading them again until they're older than maxAge, DefaultStorageIndexMaxAge if 0, and serves
+// the older ones when the server can't be reached. The writes and the deletes of the client drop their objects.
+func WithStorageIndex(index StorageIndex, maxAge time.Duration) ClientOption {
+	return func(opts *ClientOptions) error {
+		if index == nil || maxAge < 0 {
+			return newError("invalid storage index").With(maxAge)
+		}
+		if maxAge == 0 {
+			maxAge = DefaultStorageIndexMaxAge
+		}
+		opts.StorageIndex = &StorageIndexOptions{Index: index, MaxAge: maxAge}
+		return nil
+	}
+}
+
+// indexStorageObjects adds the objects read from the server to the index, before their values are decoded.
+func (c *Client) indexStorageObjects(objects []*api.StorageObject) {
+	if c.storageIndex == nil || len(objects) == 0 {
+		return
+	}
+	now := c.now()
+	indexed := make([]*IndexedObject, 0, len(objects))
+	for _, object := range objects {
+		indexed = append(indexed, &IndexedObject{Object: proto.Clone(object).(*api.StorageObject), ReadAt: now})
+	}
+	if err := c.storageIndex.Index.Put(indexed); err != nil {
+		GetLogger().Warn(wrapErr(err, "storage index"))
+	}
+}
+
+// unindexStorageObjects drops the objects written or deleted from the index.
+func (c *Client) unindexStorageObjects(session *Session, ids []storageIndexKey) {
+	if c.storageIndex == nil {
+		return
+	}
+	for _, id := range ids {
+		if id.UserId == "" {
+			id.UserId = session.UserID
+		}
+		if err := c.storageIndex.Index.Delete(id.Collection, id.Key, id.UserId); err != nil {
+			GetLogger().Warn(wrapErr(err, "storage index"))
+		}
+	}
+}
+
+// decodedIndexedObjects returns copies of the objects with their values decoded.
+func (c *Client) decodedIndexedObjects(indexed []*IndexedObject) ([]*IndexedObject, error) {
+	decoded := make([]*IndexedObject, 0, len(indexed))
+	objects := make([]*api.StorageObject, 0, len(indexed))
+	for _, o := range indexed {
+		object := proto.Clone(o.Object).(*api.StorageObject)
+		objects = append(objects, object)
+		decoded = append(decoded, &IndexedObject{Object: object, ReadAt: o.ReadAt})
+	}
+	if err := c.decodeStorageObjects(objects); err != nil {
+		return nil, wrapErr(err)
+	}
+	return decoded, nil
+}
+
+// serverUnreachable reports whether err is a network failure to reach the server, e.g. a refused connection,
+// a failed dns lookup or a timeout of the transport, rather than an answer of the server.
+func serverUnreachable(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && httpStatusOf(err) == 0
+}
+
+// ReadIndexedStorageObjects reads the objects from the index of WithStorageIndex when they've been read within
+// the max age, from the server otherwise. When the server can't be reached, the indexed objects are returned
+// whatever their age, check their ReadAt, and ErrStorageOffline if some aren't indexed.
+// The objects missing on the server are dropped from the index and from the result.
+func (c *Client) ReadIndexedStorageObjects(session *Session, ids []*api.ReadStorageObjectId) ([]*IndexedObject, error) {
+	if c.storageIndex == nil {
+		return nil, newError("storage index not enabled, see WithStorageIndex")
+	}
+	index := c.storageIndex.Index
+	now := c.now()
+	indexed := make([]*IndexedObject, 0, len(ids))
+	fresh := true
+	for _, id := range ids {
+		userId := id.GetUserId()
+		if userId == "" {
+			userId = session.UserID
+		}
+		o, err := index.Get(id.GetCollection(), id.GetKey(), userId)
+		if err != nil {
+			return nil, wrapErr(err)
+		}
+		if o == nil || now.Sub(o.ReadAt) >= c.storageIndex.MaxAge {
+			fresh = false
+		}
+		if o != nil {
+			indexed = append(indexed, o)
+		}
+	}
+	if fresh {
+		return c.decodedIndexedObjects(indexed)
+	}
+
+	objects, err := c.ReadStorageObjects(session, &api.ReadStorageObjectsRequest{ObjectIds: ids})
+	if serverUnreachable(err) {
+		if len(indexed) < len(ids) {
+			return nil, ErrStorageOffline.With(len(ids)-len(indexed), err)
+		}
+		return c.decodedIndexedObjects(indexed)
+	} else if err != nil {
+		return nil, wrapErr(err)
+	}
+
+	// the objects not returned have been deleted or can't be read anymore
+	read := map[storageIndexKey]bool{}
+	result := make([]*IndexedObject, 0, len(objects.GetObjects()))
+	for _, object := range objects.GetObjects() {
+		read[storageIndexKey{object.GetCollection(), object.GetKey(), object.GetUserId()}] = true
+		result = append(result, &IndexedObject{Object: object, ReadAt: now})
+	}
+	for _, o := range indexed {
+		key := storageIndexKey{o.Object.GetCollection(), o.Object.GetKey(), o.Object.GetUserId()}
+		if !read[key] {
+			c.unindexStorageObjects(session, []storageIndexKey{key})
+		}
+	}
+	return result, nil
+}
+
+// QueryStorageIndex returns the indexed objects selected by the query with their values decoded,
+// without any request, e.g. to list a collection offline.
+func (c *Client) QueryStorageIndex(query StorageIndexQuery) ([]*IndexedObject, error) {
+	if c.storageIndex == nil {
+		return nil, newError("storage index not enabled, see WithStorageIndex")
+	}
+	indexed, err := c.storageIndex.Index.Query(query)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return c.decodedIndexedObjects(indexed)
+}
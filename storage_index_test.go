@@ -0,0 +1,120 @@
+package nakama
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageIndex(t *testing.T) {
+	var reads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/storage":
+			reads.Add(1)
+			w.Write([]byte(`{"objects":[{"collection":"settings","key":"audio","user_id":"user","value":"{\"volume\":3}"}]}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/storage":
+			w.Write([]byte(`{"acks":[{"collection":"settings","key":"audio","user_id":"user"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	path := filepath.Join(t.TempDir(), "index.json")
+	index, err := NewFileStorageIndex(path)
+	assert.NoError(t, err)
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	client, err := NewClientWithOptions(WithURL(server.URL), WithClock(clock), WithStorageIndex(index, time.Minute))
+	assert.NoError(t, err)
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+	ids := []*api.ReadStorageObjectId{{Collection: "settings", Key: "audio", UserId: "user"}}
+
+	objects, err := client.ReadIndexedStorageObjects(session, ids)
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+	assert.Equal(t, `{"volume":3}`, objects[0].Object.GetValue())
+
+	// served by the index while fresh, read again once stale
+	_, err = client.ReadIndexedStorageObjects(session, ids)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), reads.Load())
+	clock.now = clock.now.Add(time.Minute)
+	_, err = client.ReadIndexedStorageObjects(session, ids)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), reads.Load())
+
+	// served by the index when offline, even stale and after a restart
+	server.Close()
+	index, err = NewFileStorageIndex(path)
+	assert.NoError(t, err)
+	client, err = NewClientWithOptions(WithURL(server.URL), WithClock(clock), WithStorageIndex(index, time.Minute),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	assert.NoError(t, err)
+	clock.now = clock.now.Add(time.Hour)
+	objects, err = client.ReadIndexedStorageObjects(session, ids)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1060, 0), objects[0].ReadAt.Local())
+	_, err = client.ReadIndexedStorageObjects(session, []*api.ReadStorageObjectId{{Collection: "settings", Key: "video", UserId: "user"}})
+	assert.True(t, errors.Is(err, ErrStorageOffline))
+
+	found, err := client.QueryStorageIndex(StorageIndexQuery{Collection: "settings", KeyPrefix: "au"})
+	assert.NoError(t, err)
+	assert.Len(t, found, 1)
+	found, err = client.QueryStorageIndex(StorageIndexQuery{Collection: "settings", UserId: "other"})
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestServerUnreachable(t *testing.T) {
+	refused := &url.Error{Op: "Post", URL: "http://127.0.0.1:1", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}
+	assert.True(t, serverUnreachable(wrapErr(refused)))
+	assert.True(t, serverUnreachable(&RetryError{Attempts: []RetryAttempt{{Err: refused}}}))
+
+	// the answers of the server and the local failures aren't network failures
+	assert.False(t, serverUnreachable(nil))
+	assert.False(t, serverUnreachable(&HTTPError{StatusCode: http.StatusServiceUnavailable}))
+	assert.False(t, serverUnreachable(newError("invalid response")))
+	assert.False(t, serverUnreachable(context.Canceled))
+}
+
+func TestStorageIndexWrites(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"objects":[{"collection":"decks","key":"d1","user_id":"user","value":"{}"},{"collection":"decks","key":"d2","user_id":"user","value":"{}"}]}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	index := NewMemoryStorageIndex()
+	client, err := NewClientWithOptions(WithURL(server.URL), WithStorageIndex(index, 0))
+	assert.NoError(t, err)
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+
+	_, err = client.ListStorageObjects(session, "decks", nil, 10, "")
+	assert.NoError(t, err)
+	found, _ := index.Query(StorageIndexQuery{Collection: "decks"})
+	assert.Len(t, found, 2)
+
+	// the objects written and deleted are read again
+	_, err = client.WriteStorageObjects(session, []*api.WriteStorageObject{{Collection: "decks", Key: "d1", Value: "{}"}})
+	assert.NoError(t, err)
+	assert.NoError(t, client.DeleteStorageObjects(session, &api.DeleteStorageObjectsRequest{
+		ObjectIds: []*api.DeleteStorageObjectId{{Collection: "decks", Key: "d2"}},
+	}))
+	found, _ = index.Query(StorageIndexQuery{Collection: "decks"})
+	assert.Empty(t, found)
+}
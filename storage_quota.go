@@ -0,0 +1,120 @@
+package nakama
+
+import (
+	"context"
+
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// ErrStorageQuotaExceeded is returned by WriteStorageObjects when a write would exceed the quota of a collection,
+// nothing is written.
+var ErrStorageQuotaExceeded = newError("storage quota exceeded")
+
+// StorageQuota caps the objects of the user in a collection, e.g. the save slots. The zero values don't cap.
+// The caps are enforced by the client only, the server doesn't know about them.
+type StorageQuota struct {
+	MaxObjects int
+	MaxBytes   int64 // the size of the values as stored, after the transformers
+}
+
+// StorageUsage is the usage of a collection by the user.
+type StorageUsage struct {
+	Collection string
+	Objects    int
+	Bytes      int64
+	Quota      StorageQuota // the quota of the collection set on the client, if any
+
+	sizes map[string]int64 // key:size
+}
+
+// Used returns the highest fraction of the quota used by the objects or the bytes, e.g. to warn above 0.8.
+// It's 0 without quota.
+func (u *StorageUsage) Used() float64 {
+	used := 0.0
+	if u.Quota.MaxObjects > 0 {
+		used = float64(u.Objects) / float64(u.Quota.MaxObjects)
+	}
+	if u.Quota.MaxBytes > 0 {
+		used = max(used, float64(u.Bytes)/float64(u.Quota.MaxBytes))
+	}
+	return used
+}
+
+// WithStorageQuota sets the quota of a collection, checked by WriteStorageObjects before writing to it.
+// Each checked write lists the objects of the collection first.
+func WithStorageQuota(collection string, quota StorageQuota) ClientOption {
+	return func(opts *ClientOptions) error {
+		if quota.MaxObjects < 0 || quota.MaxBytes < 0 {
+			return newError("invalid storage quota").With(collection)
+		}
+		if opts.StorageQuotas == nil {
+			opts.StorageQuotas = map[string]StorageQuota{}
+		}
+		opts.StorageQuotas[collection] = quota
+		return nil
+	}
+}
+
+// StorageUsage walks the objects of the user in the collection and returns their count and size.
+func (c *Client) StorageUsage(ctx context.Context, session *Session, collection string) (*StorageUsage, error) {
+	if err := c.refreshSession(session); err != nil {
+		return nil, wrapErr(err)
+	}
+	apiClient := c.ApiClient.WithContext(ContextWithSession(ctx, session))
+	userId := session.UserID
+	usage := &StorageUsage{Collection: collection, Quota: c.storageQuotas[collection], sizes: map[string]int64{}}
+	// the raw values, the quota counts the bytes stored
+	objects := paginate(ctx, func(cursor string) ([]*api.StorageObject, string, error) {
+		list, err := apiClient.ListStorageObjects(session.Token, collection, &userId, DefaultPageSize, cursor, make(map[string]string))
+		if err != nil {
+			return nil, "", err
+		}
+		return list.Objects, list.Cursor, nil
+	})
+	for object, err := range objects {
+		if err != nil {
+			return nil, wrapErr(err, collection)
+		}
+		usage.sizes[object.GetKey()] = int64(len(object.GetValue()))
+		usage.Objects++
+		usage.Bytes += int64(len(object.GetValue()))
+	}
+	return usage, nil
+}
+
+// checkStorageQuotas checks the objects written fit in the quotas of their collections.
+func (c *Client) checkStorageQuotas(session *Session, objects []*api.WriteStorageObject) error {
+	if len(c.storageQuotas) == 0 {
+		return nil
+	}
+	usages := map[string]*StorageUsage{}
+	for _, object := range objects {
+		collection := object.GetCollection()
+		if _, ok := c.storageQuotas[collection]; !ok {
+			continue
+		}
+		usage, ok := usages[collection]
+		if !ok {
+			var err error
+			if usage, err = c.StorageUsage(c.Context(), session, collection); err != nil {
+				return wrapErr(err)
+			}
+			usages[collection] = usage
+		}
+		size := int64(len(object.GetValue()))
+		if old, ok := usage.sizes[object.GetKey()]; ok {
+			usage.Bytes += size - old
+		} else {
+			usage.Objects++
+			usage.Bytes += size
+		}
+		usage.sizes[object.GetKey()] = size
+	}
+	for collection, usage := range usages {
+		quota := usage.Quota
+		if quota.MaxObjects > 0 && usage.Objects > quota.MaxObjects || quota.MaxBytes > 0 && usage.Bytes > quota.MaxBytes {
+			return ErrStorageQuotaExceeded.With(collection, usage.Objects, usage.Bytes)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package nakama
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageQuota(t *testing.T) {
+	var writes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Query().Get("cursor") == "":
+			assert.Equal(t, "user", r.URL.Query().Get("user_id"))
+			w.Write([]byte(`{"objects":[{"collection":"saves","key":"slot1","value":"{\"hp\":10}"}],"cursor":"next"}`))
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{"objects":[{"collection":"saves","key":"slot2","value":"{}"}]}`))
+		default:
+			writes.Add(1)
+			w.Write([]byte(`{"acks":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL), WithStorageQuota("saves", StorageQuota{MaxObjects: 2, MaxBytes: 20}))
+	assert.NoError(t, err)
+	session := Restore(testToken(time.Now().Unix()+3600), "")
+
+	usage, err := client.StorageUsage(context.Background(), session, "saves")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, usage.Objects)
+	assert.Equal(t, int64(11), usage.Bytes)
+	assert.Equal(t, 1.0, usage.Used())
+
+	// overwriting a slot fits
+	_, err = client.WriteStorageObjects(session, []*api.WriteStorageObject{{Collection: "saves", Key: "slot1", Value: `{"hp":9}`}})
+	assert.NoError(t, err)
+	// a third slot doesn't
+	_, err = client.WriteStorageObjects(session, []*api.WriteStorageObject{{Collection: "saves", Key: "slot3", Value: `{}`}})
+	assert.True(t, errors.Is(err, ErrStorageQuotaExceeded))
+	// neither do too many bytes
+	_, err = client.WriteStorageObjects(session, []*api.WriteStorageObject{{Collection: "saves", Key: "slot2", Value: `{"inventory":[]}`}})
+	assert.True(t, errors.Is(err, ErrStorageQuotaExceeded))
+	// the other collections have no quota
+	_, err = client.WriteStorageObjects(session, []*api.WriteStorageObject{{Collection: "settings", Key: "audio", Value: `{}`}})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), writes.Load())
+}
@@ -0,0 +1,113 @@
+package nakama
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+
+	api "github.com/heroiclabs/nakama-common/api"
+)
+
+// storageStreamBufferSize is the buffer of the streamed bodies, the writes to the connection are this big.
+const storageStreamBufferSize = 32 * 1024
+
+// WithStorageStreaming streams the body of the storage writes whose values add up to minBytes or more,
+// encoding the objects one at a time with a chunked transfer encoding instead of marshaling the whole batch,
+// to cap the peak memory of the large batches on the constrained devices.
+// A write is buffered again when the server or a proxy refuses the chunked body.
+func WithStorageStreaming(minBytes int) ClientOption {
+	return func(opts *ClientOptions) error {
+		if minBytes <= 0 {
+			return newError("invalid storage streaming threshold").With(minBytes)
+		}
+		opts.StorageStreamBytes = minBytes
+		return nil
+	}
+}
+
+// storageValueBytes returns the size of the values of the objects written.
+func storageValueBytes(body *api.WriteStorageObjectsRequest) int {
+	size := 0
+	for _, object := range body.GetObjects() {
+		size += len(object.GetValue())
+	}
+	return size
+}
+
+// chunkedRefused reports whether err is the answer of a server or a proxy refusing a chunked body.
+func chunkedRefused(err error) bool {
+	switch httpStatusOf(err) {
+	case http.StatusLengthRequired, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// writeStorageObjectsStreamed is WriteStorageObjects with a chunked body, see WithStorageStreaming.
+func (napi *NakamaApi) writeStorageObjectsStreamed(
+	bearerToken string,
+	body *api.WriteStorageObjectsRequest,
+	options map[string]string,
+) (*api.StorageObjectAcks, error) {
+	fullUrl := napi.buildFullUrl(napi.BasePath, "/v2/storage", url.Values{})
+	req, err := http.NewRequest("PUT", fullUrl, nil)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	// a new stream for each attempt, the transport closes the body of the attempts failed
+	req.GetBody = func() (io.ReadCloser, error) {
+		return streamStorageObjects(body.GetObjects()), nil
+	}
+	req.Body, _ = req.GetBody()
+	req.ContentLength = -1 // chunked
+	req.Header.Set("Content-Type", "application/json")
+
+	var result api.StorageObjectAcks
+	if err := napi.doReq("WriteStorageObjects", bearerToken, req, options, &result); err != nil {
+		return nil, wrapErr(err)
+	}
+	return &result, nil
+}
+
+// streamStorageObjects encodes the request of the objects into a pipe, the objects are marshaled one at a time.
+func streamStorageObjects(objects []*api.WriteStorageObject) io.ReadCloser {
+	r, w := io.Pipe()
+	go func() {
+		bw := bufio.NewWriterSize(w, storageStreamBufferSize)
+		err := writeStorageObjectsJSON(bw, objects)
+		if err == nil {
+			err = bw.Flush()
+		}
+		w.CloseWithError(err)
+	}()
+	return r
+}
+
+// writeStorageObjectsJSON writes the JSON of a WriteStorageObjectsRequest like json.Marshal.
+func writeStorageObjectsJSON(w io.Writer, objects []*api.WriteStorageObject) error {
+	if len(objects) == 0 {
+		_, err := io.WriteString(w, "{}")
+		return err
+	}
+	if _, err := io.WriteString(w, `{"objects":[`); err != nil {
+		return err
+	}
+	for i, object := range objects {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(object)
+		if err != nil {
+			return wrapErr(err, object.GetCollection(), object.GetKey())
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]}")
+	return err
+}
@@ -0,0 +1,59 @@
+package nakama
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestWriteStorageObjectsStreamed(t *testing.T) {
+	objects := []*api.WriteStorageObject{
+		{Collection: "saves", Key: "slot1", Value: `{"level":` + strings.Repeat("1", 100) + `}`, PermissionRead: wrapperspb.Int32(1)},
+		{Collection: "saves", Key: "slot2", Value: `{"level":2}`, Version: "*"},
+	}
+	expected, err := json.Marshal(&api.WriteStorageObjectsRequest{Objects: objects})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var refuseChunked atomic.Bool
+	bodies := make(chan []byte, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chunked := len(r.TransferEncoding) > 0 && r.TransferEncoding[0] == "chunked"
+		if chunked && refuseChunked.Load() {
+			w.WriteHeader(http.StatusLengthRequired)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, !refuseChunked.Load(), chunked)
+		bodies <- body
+		w.Write([]byte(`{"acks":[{"key":"slot1"},{"key":"slot2"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithOptions(WithURL(server.URL), WithStorageStreaming(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := NewSession(testToken(time.Now().Add(time.Hour).Unix()), "", false)
+
+	acks, err := client.WriteStorageObjects(session, objects)
+	assert.NoError(t, err)
+	assert.Len(t, acks.GetAcks(), 2)
+	assert.JSONEq(t, string(expected), string(<-bodies), "the streamed body is the buffered one")
+
+	// the chunked body refused, the write is buffered
+	refuseChunked.Store(true)
+	_, err = client.WriteStorageObjects(session, objects)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(<-bodies))
+}
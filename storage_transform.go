@@ -0,0 +1,198 @@
+package nakama
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"strings"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"google.golang.org/protobuf/proto"
+)
+
+// StorageTransformKey is the key tagging a transformed storage value, the values are stored as
+// {"nk_transform":"gzip,aes-gcm","data":"<base64>"} since the server only accepts JSON objects.
+// The values without the tag are read as is, so the plain and the transformed objects can coexist during a migration.
+const StorageTransformKey = "nk_transform"
+
+// ValueTransformer transforms the storage values of a collection, e.g. to compress or to encrypt them.
+type ValueTransformer interface {
+	// Name tags the values transformed, it must be stable and must not contain a comma.
+	Name() string
+	Encode(value []byte) ([]byte, error)
+	Decode(value []byte) ([]byte, error)
+}
+
+// transformedValue is a storage value transformed.
+type transformedValue struct {
+	Transform string `json:"nk_transform"`
+	Data      []byte `json:"data"`
+}
+
+type gzipTransformer struct{}
+
+// GzipTransformer compresses the values with gzip.
+func GzipTransformer() ValueTransformer {
+	return gzipTransformer{}
+}
+
+func (gzipTransformer) Name() string { return "gzip" }
+
+func (gzipTransformer) Encode(value []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write(value); err != nil {
+		return nil, wrapErr(err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, wrapErr(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipTransformer) Decode(value []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(value))
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return data, nil
+}
+
+// aesTransformer encrypts the values with the AesGcmCipher of the session stores.
+type aesTransformer struct {
+	cipher *AesGcmCipher
+}
+
+// AESTransformer encrypts the values with AES-GCM, the key must be 16, 24 or 32 bytes.
+// The key ships with the game, it hides the values from the casual readers, not from the players.
+func AESTransformer(key []byte) (ValueTransformer, error) {
+	c, err := NewAesGcmCipher(key)
+	if err != nil {
+		return nil, wrapErr(err)
+	}
+	return &aesTransformer{cipher: c}, nil
+}
+
+func (t *aesTransformer) Name() string { return "aes-gcm" }
+
+func (t *aesTransformer) Encode(value []byte) ([]byte, error) {
+	return t.cipher.Encrypt(value)
+}
+
+func (t *aesTransformer) Decode(value []byte) ([]byte, error) {
+	return t.cipher.Decrypt(value)
+}
+
+// WithStorageTransformers transforms the values of the collection written and read by the storage calls
+// of the client, in the order given on write and in the reverse order on read.
+func WithStorageTransformers(collection string, transformers ...ValueTransformer) ClientOption {
+	return func(opts *ClientOptions) error {
+		for _, t := range transformers {
+			if t == nil || t.Name() == "" || strings.Contains(t.Name(), ",") {
+				return newError("invalid storage transformer").With(collection)
+			}
+		}
+		if opts.StorageTransformers == nil {
+			opts.StorageTransformers = map[string][]ValueTransformer{}
+		}
+		opts.StorageTransformers[collection] = transformers
+		return nil
+	}
+}
+
+// encodeStorageValue transforms a value of the collection, it's returned as is without transformers.
+func (c *Client) encodeStorageValue(collection, value string) (string, error) {
+	transformers := c.storageTransformers[collection]
+	if len(transformers) == 0 {
+		return value, nil
+	}
+	data := []byte(value)
+	names := make([]string, 0, len(transformers))
+	for _, t := range transformers {
+		encoded, err := t.Encode(data)
+		if err != nil {
+			return "", wrapErr(err, collection, t.Name())
+		}
+		data = encoded
+		names = append(names, t.Name())
+	}
+	out, err := json.Marshal(&transformedValue{Transform: strings.Join(names, ","), Data: data})
+	if err != nil {
+		return "", wrapErr(err)
+	}
+	return string(out), nil
+}
+
+// decodeStorageValue restores a value of the collection, the values without the tag are returned as is.
+func (c *Client) decodeStorageValue(collection, value string) (string, error) {
+	if !strings.Contains(value, StorageTransformKey) {
+		return value, nil
+	}
+	tv := transformedValue{}
+	if err := json.Unmarshal([]byte(value), &tv); err != nil || tv.Transform == "" {
+		// a plain value using the key
+		return value, nil
+	}
+
+	byName := map[string]ValueTransformer{}
+	for _, t := range c.storageTransformers[collection] {
+		byName[t.Name()] = t
+	}
+	names := strings.Split(tv.Transform, ",")
+	data := tv.Data
+	for i := len(names) - 1; i >= 0; i-- {
+		t, ok := byName[names[i]]
+		if !ok {
+			return "", newError("no storage transformer").With(collection, names[i])
+		}
+		decoded, err := t.Decode(data)
+		if err != nil {
+			return "", wrapErr(err, collection, names[i])
+		}
+		data = decoded
+	}
+	return string(data), nil
+}
+
+// encodeStorageObjects returns the objects with their values transformed, objects is left unchanged.
+func (c *Client) encodeStorageObjects(objects []*api.WriteStorageObject) ([]*api.WriteStorageObject, error) {
+	if len(c.storageTransformers) == 0 {
+		return objects, nil
+	}
+	encoded := make([]*api.WriteStorageObject, 0, len(objects))
+	for _, object := range objects {
+		if len(c.storageTransformers[object.GetCollection()]) == 0 {
+			encoded = append(encoded, object)
+			continue
+		}
+		value, err := c.encodeStorageValue(object.GetCollection(), object.GetValue())
+		if err != nil {
+			return nil, wrapErr(err, object.GetKey())
+		}
+		clone := proto.Clone(object).(*api.WriteStorageObject)
+		clone.Value = value
+		encoded = append(encoded, clone)
+	}
+	return encoded, nil
+}
+
+// decodeStorageObjects restores the values of the objects read.
+func (c *Client) decodeStorageObjects(objects []*api.StorageObject) error {
+	if len(c.storageTransformers) == 0 {
+		return nil
+	}
+	for _, object := range objects {
+		value, err := c.decodeStorageValue(object.GetCollection(), object.GetValue())
+		if err != nil {
+			return wrapErr(err, object.GetKey())
+		}
+		object.Value = value
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package nakama
+
+import (
+	"strings"
+	"testing"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageTransformers(t *testing.T) {
+	aesGcm, err := AESTransformer([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClientWithOptions(WithStorageTransformers("saves", GzipTransformer(), aesGcm))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value := `{"level":12,"inventory":["sword","shield"]}`
+	objects := []*api.WriteStorageObject{
+		{Collection: "saves", Key: "slot1", Value: value},
+		{Collection: "settings", Key: "audio", Value: `{"volume":3}`},
+	}
+	encoded, err := client.encodeStorageObjects(objects)
+	assert.NoError(t, err)
+	assert.Equal(t, value, objects[0].Value, "the objects of the caller are left unchanged")
+	assert.True(t, strings.HasPrefix(encoded[0].Value, `{"nk_transform":"gzip,aes-gcm","data":"`))
+	assert.Same(t, objects[1], encoded[1], "the other collections aren't transformed")
+
+	read := []*api.StorageObject{
+		{Collection: "saves", Key: "slot1", Value: encoded[0].Value},
+		{Collection: "saves", Key: "slot2", Value: value}, // written before the migration
+	}
+	assert.NoError(t, client.decodeStorageObjects(read))
+	assert.Equal(t, value, read[0].Value)
+	assert.Equal(t, value, read[1].Value)
+
+	// the values can't be read without their transformers
+	other, _ := NewClientWithOptions(WithStorageTransformers("saves", GzipTransformer()))
+	assert.Error(t, other.decodeStorageObjects([]*api.StorageObject{{Collection: "saves", Value: encoded[0].Value}}))
+
+	_, err = NewClientWithOptions(WithStorageTransformers("saves", nil))
+	assert.Error(t, err)
+}
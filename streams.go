@@ -0,0 +1,121 @@
+package nakama
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// Custom stream rpc defaults
+const (
+	DefaultStreamJoinRpcId  = "stream_join"
+	DefaultStreamLeaveRpcId = "stream_leave"
+)
+
+// StreamHandler is called with the data received on a custom stream.
+type StreamHandler func(stream *rtapi.Stream, sender *rtapi.UserPresence, data string, reliable bool)
+
+// streamRequest is the payload of the join and leave rpcs.
+type streamRequest struct {
+	Mode       int32  `json:"mode"`
+	Subject    string `json:"subject,omitempty"`
+	Subcontext string `json:"subcontext,omitempty"`
+	Label      string `json:"label"`
+}
+
+// CustomStreams joins and leaves the custom streams of the server runtime, and routes their data by label.
+// The clients can't join a stream by themselves, the server runtime must expose the join and the leave
+// as rpcs receiving {"mode","subject","subcontext","label"} and calling StreamUserJoin and StreamUserLeave.
+type CustomStreams struct {
+	JoinRpcId  string
+	LeaveRpcId string
+
+	// OnPresence is called when the users join or leave a custom stream.
+	OnPresence func(event *rtapi.StreamPresenceEvent)
+
+	socket *DefaultSocket
+
+	mu       sync.RWMutex
+	handlers map[string]StreamHandler // label:handler
+}
+
+// NewCustomStreams creates a CustomStreams calling the rpcs through the socket.
+func NewCustomStreams(socket *DefaultSocket) *CustomStreams {
+	return &CustomStreams{
+		JoinRpcId:  DefaultStreamJoinRpcId,
+		LeaveRpcId: DefaultStreamLeaveRpcId,
+		socket:     socket,
+		handlers:   map[string]StreamHandler{},
+	}
+}
+
+// Handle sets the handler of the data received on the streams with the label, a nil handler removes it.
+func (cs *CustomStreams) Handle(label string, handler StreamHandler) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if handler == nil {
+		delete(cs.handlers, label)
+		return
+	}
+	cs.handlers[label] = handler
+}
+
+func (cs *CustomStreams) call(rpcId string, stream *rtapi.Stream) error {
+	if stream == nil || stream.Label == "" {
+		return newError("'stream.Label' is a required parameter but is empty")
+	}
+	payload, err := json.Marshal(&streamRequest{
+		Mode:       stream.Mode,
+		Subject:    stream.Subject,
+		Subcontext: stream.Subcontext,
+		Label:      stream.Label,
+	})
+	if err != nil {
+		return wrapErr(err)
+	}
+	if _, err := cs.socket.Rpc(rpcId, string(payload), ""); err != nil {
+		return wrapErr(err, rpcId, stream.Label)
+	}
+	return nil
+}
+
+// Join asks the server to join the current user to the stream, and routes its data to handler.
+// The handler is kept when the rpc fails, the server may have joined the user anyway.
+func (cs *CustomStreams) Join(stream *rtapi.Stream, handler StreamHandler) error {
+	if stream != nil && handler != nil {
+		cs.Handle(stream.Label, handler)
+	}
+	return cs.call(cs.JoinRpcId, stream)
+}
+
+// Leave asks the server to remove the current user from the stream, and removes the handler of its label.
+func (cs *CustomStreams) Leave(stream *rtapi.Stream) error {
+	if err := cs.call(cs.LeaveRpcId, stream); err != nil {
+		return wrapErr(err)
+	}
+	cs.Handle(stream.Label, nil)
+	return nil
+}
+
+// HandleEvent is an EventHandler routing the stream data and presences received on the socket,
+// chain it in the EventHandler passed to CreateSocket.
+func (cs *CustomStreams) HandleEvent(event EventType, data *RspResult) {
+	if event != EventTypeMessage || data == nil || data.Decoded == nil {
+		return
+	}
+	switch msg := data.Decoded.GetMessage().(type) {
+	case *rtapi.Envelope_StreamData:
+		streamData := msg.StreamData
+		cs.mu.RLock()
+		handler := cs.handlers[streamData.GetStream().GetLabel()]
+		cs.mu.RUnlock()
+		if handler != nil {
+			handler(streamData.GetStream(), streamData.GetSender(), streamData.GetData(), streamData.GetReliable())
+		}
+	case *rtapi.Envelope_StreamPresenceEvent:
+		if cs.OnPresence != nil {
+			cs.OnPresence(msg.StreamPresenceEvent)
+		}
+	}
+}
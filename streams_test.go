@@ -0,0 +1,37 @@
+package nakama
+
+import (
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func streamDataResult(label, data string) *RspResult {
+	return &RspResult{Decoded: &rtapi.Envelope{Message: &rtapi.Envelope_StreamData{
+		StreamData: &rtapi.StreamData{Stream: &rtapi.Stream{Mode: 10, Label: label}, Data: data},
+	}}}
+}
+
+func TestCustomStreamsRouting(t *testing.T) {
+	streams := NewCustomStreams(nil)
+	received := map[string][]string{}
+	streams.Handle("scores", func(stream *rtapi.Stream, sender *rtapi.UserPresence, data string, reliable bool) {
+		received[stream.Label] = append(received[stream.Label], data)
+	})
+	var presences int
+	streams.OnPresence = func(event *rtapi.StreamPresenceEvent) { presences++ }
+
+	streams.HandleEvent(EventTypeMessage, streamDataResult("scores", "1"))
+	streams.HandleEvent(EventTypeMessage, streamDataResult("other", "2"))
+	streams.HandleEvent(EventTypeMessage, &RspResult{Decoded: &rtapi.Envelope{Message: &rtapi.Envelope_StreamPresenceEvent{
+		StreamPresenceEvent: &rtapi.StreamPresenceEvent{Stream: &rtapi.Stream{Label: "scores"}},
+	}}})
+	streams.Handle("scores", nil)
+	streams.HandleEvent(EventTypeMessage, streamDataResult("scores", "3"))
+
+	assert.Equal(t, map[string][]string{"scores": {"1"}}, received)
+	assert.Equal(t, 1, presences)
+	assert.Equal(t, "stream:scores", streamKey(streamDataResult("scores", "").Decoded))
+	assert.Error(t, streams.Join(&rtapi.Stream{}, nil))
+}
@@ -0,0 +1,70 @@
+package nakama
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+)
+
+// TLSOptions is the TLS configuration of the http calls and the sockets of a client,
+// e.g. to meet the requirements of a platform certification.
+type TLSOptions struct {
+	// MinVersion is the minimum TLS version accepted, tls.VersionTLS12 when zero.
+	MinVersion uint16
+	// MaxVersion is the maximum TLS version accepted, the highest supported when zero.
+	MaxVersion uint16
+	// CipherSuites restricts the cipher suites of TLS 1.2 and below, the Go defaults when empty.
+	// The TLS 1.3 suites are not configurable.
+	CipherSuites []uint16
+	// NextProtos is the ALPN list of the http calls, the sockets always negotiate "http/1.1".
+	NextProtos []string
+	// RootCAs verifies the server certificate, the system pool when nil.
+	RootCAs *x509.CertPool
+	// ServerName overrides the name checked in the server certificate, the host when empty.
+	ServerName string
+}
+
+// Config returns the tls.Config of the options.
+func (o *TLSOptions) Config() *tls.Config {
+	minVersion := o.MinVersion
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+	config := &tls.Config{
+		MinVersion: minVersion,
+		MaxVersion: o.MaxVersion,
+		RootCAs:    o.RootCAs,
+		ServerName: o.ServerName,
+	}
+	// an empty non-nil list would disable all the suites
+	if len(o.CipherSuites) > 0 {
+		config.CipherSuites = append([]uint16{}, o.CipherSuites...)
+	}
+	if len(o.NextProtos) > 0 {
+		config.NextProtos = append([]string{}, o.NextProtos...)
+	}
+	return config
+}
+
+// socketConfig returns the tls.Config of the websocket handshake, which needs http/1.1.
+func (o *TLSOptions) socketConfig() *tls.Config {
+	config := o.Config()
+	config.NextProtos = []string{"http/1.1"}
+	return config
+}
+
+// httpClient returns a http.Client using the options.
+func (o *TLSOptions) httpClient() *http.Client {
+	transport := NewHttpTransport()
+	transport.TLSClientConfig = o.Config()
+	return &http.Client{Transport: transport}
+}
+
+// WithTLS sets the TLS configuration of the http calls and of the sockets created by the client.
+// The http calls ignore it when WithHTTPClient or WithHTTPTransport is used, configure that transport instead.
+func WithTLS(tlsOptions TLSOptions) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.TLS = &tlsOptions
+		return nil
+	}
+}
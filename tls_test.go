@@ -0,0 +1,41 @@
+package nakama
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTLSOptions(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	if config := (&TLSOptions{}).Config(); config.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("default min version %x", config.MinVersion)
+	}
+
+	client, err := NewClientWithOptions(WithURL(server.URL), WithTLS(TLSOptions{RootCAs: pool}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.ApiClient.Healthcheck("", nil); err != nil {
+		t.Fatalf("tls 1.2 should be accepted: %v", err)
+	}
+
+	strict, err := NewClientWithOptions(WithURL(server.URL), WithTLS(TLSOptions{RootCAs: pool, MinVersion: tls.VersionTLS13}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := strict.ApiClient.Healthcheck("", nil); err == nil {
+		t.Fatal("tls 1.2 should be refused by a tls 1.3 minimum")
+	}
+}
@@ -0,0 +1,86 @@
+package nakama
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// TraceMetadataKey is the metadata key carrying the trace id of a request, e.g. in the metadata of a match join,
+// so the match handler can log it next to the client traces.
+const TraceMetadataKey = "trace_id"
+
+// newTraceId returns a random trace id of 16 hex digits.
+func newTraceId() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		// never happens, but keep the request going
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// envelopeType returns the name of the message of an envelope, e.g. "match_join".
+func envelopeType(envelope *rtapi.Envelope) string {
+	if envelope == nil {
+		return ""
+	}
+	msg := envelope.ProtoReflect()
+	field := msg.WhichOneof(msg.Descriptor().Oneofs().ByName("message"))
+	if field == nil {
+		return ""
+	}
+	return string(field.Name())
+}
+
+// SetTracing turns the request tracing on or off. When on, each request sent gets a trace id
+// logged in both directions with its cid, set in RspResult.TraceId and added to the errors of the request.
+// The trace id is also sent to the server where the message has metadata, see TraceMetadataKey.
+func (socket *DefaultSocket) SetTracing(on bool) {
+	socket.tracing.Store(on)
+}
+
+// IsTracing reports whether the request tracing is on.
+func (socket *DefaultSocket) IsTracing() bool {
+	return socket.tracing.Load()
+}
+
+// traceRequest returns the trace id of a request and attaches it to the message, empty when tracing is off.
+func (socket *DefaultSocket) traceRequest(message *rtapi.Envelope) string {
+	if !socket.IsTracing() {
+		return ""
+	}
+	traceId := newTraceId()
+	if join := message.GetMatchJoin(); join != nil {
+		if join.Metadata == nil {
+			join.Metadata = map[string]string{}
+		}
+		if id, ok := join.Metadata[TraceMetadataKey]; ok {
+			// keep the trace id set by the caller
+			traceId = id
+		} else {
+			join.Metadata[TraceMetadataKey] = traceId
+		}
+	}
+	GetLogger().Debugf("send %s cid=%s trace=%s", envelopeType(message), message.Cid, traceId)
+	return traceId
+}
+
+// traceResponse logs the response of a traced request and tags it with the trace id.
+func (socket *DefaultSocket) traceResponse(traceId, cid string, data any, sentAt time.Time) any {
+	if traceId == "" {
+		return data
+	}
+	elapsed := time.Since(sentAt)
+	switch rsp := data.(type) {
+	case *RspResult:
+		rsp.TraceId = traceId
+		GetLogger().Debugf("recv %s cid=%s trace=%s in %s", envelopeType(rsp.Decoded), cid, traceId, elapsed)
+	case error:
+		GetLogger().Warnf("recv error cid=%s trace=%s in %s: %s", cid, traceId, elapsed, rsp.Error())
+		return wrapErr(rsp, "trace", traceId)
+	}
+	return data
+}
@@ -0,0 +1,65 @@
+package nakama
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestSocketTracing(t *testing.T) {
+	metadata := make(chan map[string]string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		for {
+			_, data, err := conn.Read(r.Context())
+			if err != nil {
+				return
+			}
+			req := &rtapi.Envelope{}
+			if err := protojson.Unmarshal(data, req); err != nil {
+				return
+			}
+			rsp := &rtapi.Envelope{Cid: req.Cid, Message: &rtapi.Envelope_Pong{Pong: &rtapi.Pong{}}}
+			if join := req.GetMatchJoin(); join != nil {
+				metadata <- join.Metadata
+				rsp.Message = &rtapi.Envelope_Match{Match: &rtapi.Match{MatchId: join.GetMatchId()}}
+			}
+			data, _ = protojson.Marshal(rsp)
+			conn.Write(r.Context(), websocket.MessageText, data)
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+	socket := NewDefaultSocket(nil, host, port, "token", false, false, nil, nil)
+	assert.NoError(t, socket.Connect())
+	defer socket.Disconnect()
+
+	matchId := "match"
+	_, err := socket.JoinMatch(&matchId, nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, <-metadata, "no trace id while tracing is off")
+
+	socket.SetTracing(true)
+	req := &rtapi.Envelope{Message: &rtapi.Envelope_MatchJoin{MatchJoin: &rtapi.MatchJoin{
+		Id: &rtapi.MatchJoin_MatchId{MatchId: matchId},
+	}}}
+	result, ok := socket.Send(req, nil).(*RspResult)
+	assert.True(t, ok)
+	traceId := result.TraceId
+	assert.Len(t, traceId, 16)
+	assert.Equal(t, traceId, (<-metadata)[TraceMetadataKey], "the server should receive the trace id")
+	assert.Equal(t, "match", envelopeType(result.Decoded))
+}
@@ -0,0 +1,69 @@
+package nakama
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/heroiclabs/nakama-common/rtapi"
+)
+
+// UnknownEnvelopeHandler receives the envelopes of a message type unknown to the client, e.g. a message of a newer
+// server, with the JSON name of the message and the raw envelope. It runs on the read loop of the socket.
+type UnknownEnvelopeHandler func(name string, data []byte)
+
+// envelopeFields are the JSON and proto names of the fields of the envelope.
+var envelopeFields = sync.OnceValue(func() map[string]bool {
+	fields := map[string]bool{}
+	descriptor := (&rtapi.Envelope{}).ProtoReflect().Descriptor().Fields()
+	for i := range descriptor.Len() {
+		fields[string(descriptor.Get(i).Name())] = true
+		fields[descriptor.Get(i).JSONName()] = true
+	}
+	return fields
+})
+
+// SetOnUnknownEnvelope sends the envelopes of an unknown message type to handler instead of the EventHandler,
+// which gets them undecoded otherwise, so the server messages not implemented yet are detected, e.g. in the tests.
+// The malformed messages still go to the EventHandler. Nil restores the default.
+func (socket *DefaultSocket) SetOnUnknownEnvelope(handler UnknownEnvelopeHandler) {
+	if handler == nil {
+		socket.onUnknownEnvelope.Store(nil)
+		return
+	}
+	socket.onUnknownEnvelope.Store(&handler)
+}
+
+// handleUnknownEnvelope delivers a message failing to decode to the unknown envelope handler,
+// handled is false when there's no handler or the message isn't a well-formed envelope of an unknown type.
+func (socket *DefaultSocket) handleUnknownEnvelope(message []byte) (handled bool) {
+	handler := socket.onUnknownEnvelope.Load()
+	if handler == nil {
+		return false
+	}
+	name, ok := unknownEnvelopeType(message)
+	if !ok {
+		return false
+	}
+	(*handler)(name, message)
+	return true
+}
+
+// unknownEnvelopeType returns the name of the message of an envelope unknown to rtapi.
+func unknownEnvelopeType(message []byte) (string, bool) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(message, &fields); err != nil {
+		return "", false
+	}
+	unknown := ""
+	for name := range fields {
+		if envelopeFields()[name] {
+			continue
+		}
+		if unknown != "" {
+			// not an envelope
+			return "", false
+		}
+		unknown = name
+	}
+	return unknown, unknown != ""
+}
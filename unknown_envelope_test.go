@@ -0,0 +1,48 @@
+package nakama
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnUnknownEnvelope(t *testing.T) {
+	server := newScriptedServer(t, nil)
+	var mu sync.Mutex
+	events, unknown := []string{}, []string{}
+	socket, _ := server.socket(func(event EventType, data *RspResult) {
+		if event == EventTypeMessage {
+			mu.Lock()
+			events = append(events, string(data.Data))
+			mu.Unlock()
+		}
+	})
+	socket.SetOnUnknownEnvelope(func(name string, data []byte) {
+		mu.Lock()
+		unknown = append(unknown, name+" "+string(data))
+		mu.Unlock()
+	})
+	assert.NoError(t, socket.Connect())
+
+	server.conn(0).WriteRaw([]byte(`{"party_kick":{"party_id":"p"}}`))
+	server.conn(0).WriteRaw([]byte(`{"cid":"1","rank_changed":{}}`))
+	server.conn(0).WriteRaw([]byte(`{"notifications":{}}`))
+	server.conn(0).WriteRaw([]byte(`{"status":{"unknown_field":1}}`))
+	server.conn(0).WriteRaw([]byte(`{"a":{},"b":{}}`))
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(unknown) == 2 && len(events) == 3
+	}, "the envelopes have not been delivered")
+	assert.Equal(t, []string{`party_kick {"party_kick":{"party_id":"p"}}`, `rank_changed {"cid":"1","rank_changed":{}}`}, unknown)
+
+	// the default delivers them to the EventHandler
+	socket.SetOnUnknownEnvelope(nil)
+	server.conn(0).WriteRaw([]byte(`{"party_kick":{}}`))
+	eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(events) == 4
+	}, "the envelope has not been delivered to the EventHandler")
+}
@@ -1,12 +1,18 @@
 package nakama
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/url"
 	"reflect"
 	"strconv"
 	"time"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
 )
 
 // BuildFetchOptions constructs fetch options similar to the JavaScript version.
@@ -126,3 +132,82 @@ func ToJSON(value interface{}) []byte {
 	}
 	return data
 }
+
+// DecodeJSON decodes the data like json.Unmarshal, but keeps the numbers of the dynamic values as json.Number,
+// so the int64 fields like scores and timestamps don't lose precision in a float64.
+func DecodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// DecodeRpcPayload decodes the payload of an rpc response into a map with DecodeJSON.
+func DecodeRpcPayload(rpc *api.Rpc) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	if rpc == nil || rpc.Payload == "" {
+		return result, nil
+	}
+	if err := DecodeJSON([]byte(rpc.Payload), &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Int64Value converts a decoded JSON number to int64 without going through a float64 when possible.
+func Int64Value(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return 0, err
+		}
+		return float64ToInt64(f)
+	case float64:
+		return float64ToInt64(v)
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	}
+	return 0, newError("value is not a number")
+}
+
+// GetInt64 reads an int64 from a map decoded by DecodeJSON.
+func GetInt64(data map[string]interface{}, key string) (int64, error) {
+	value, ok := data[key]
+	if !ok {
+		return 0, newError("key not found in map")
+	}
+	return Int64Value(value)
+}
+
+func float64ToInt64(f float64) (int64, error) {
+	if f != math.Trunc(f) || f > math.MaxInt64 || f < math.MinInt64 {
+		return 0, newError("value is not an int64")
+	}
+	return int64(f), nil
+}
+
+// httpStatusOf returns the http status code of an error returned by the api calls, 0 if it's not a http error.
+func httpStatusOf(err error) int {
+	httpErr := &HTTPError{}
+	if !errors.As(err, &httpErr) {
+		return 0
+	}
+	return httpErr.StatusCode
+}
+
+// socketErrorCode returns the code of an error envelope returned by the socket calls,
+// ok is false if it's not a socket error.
+func socketErrorCode(err error) (code rtapi.Error_Code, ok bool) {
+	socketErr := &SocketError{}
+	if !errors.As(err, &socketErr) {
+		return 0, false
+	}
+	return rtapi.Error_Code(socketErr.Code), true
+}
@@ -0,0 +1,50 @@
+package nakama
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	api "github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeRpcPayload_LargeInt64(t *testing.T) {
+	payload, err := DecodeRpcPayload(&api.Rpc{Payload: `{"score":9007199254740993,"subscore":1.5}`})
+	assert.NoError(t, err)
+
+	score, err := GetInt64(payload, "score")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9007199254740993), score)
+
+	_, err = GetInt64(payload, "subscore")
+	assert.Error(t, err)
+	_, err = GetInt64(payload, "missing")
+	assert.Error(t, err)
+}
+
+func TestHttpStatusOf(t *testing.T) {
+	err := &HTTPError{StatusCode: 404, Status: "404 Not Found"}
+	assert.Equal(t, 404, httpStatusOf(wrapErr(err)))
+	assert.True(t, errors.Is(groupError(err, "group"), ErrGroupPermissionDenied))
+	assert.Equal(t, 0, httpStatusOf(newError("request timed out")))
+	assert.Equal(t, 0, httpStatusOf(nil))
+}
+
+func TestSocketErrorCode(t *testing.T) {
+	// built like the errors of DefaultSocket.handleMessage
+	err := wrapErr(&SocketError{Code: 3, Message: "Could not find message to remove in channel history"})
+	code, ok := socketErrorCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, rtapi.Error_BAD_INPUT, code)
+	assert.True(t, errors.Is(chatError(err, "channel", "message"), ErrChatMessageNotFound))
+
+	// the code survives the wrapping of the error
+	code, ok = socketErrorCode(fmt.Errorf("remove message: %w", err))
+	assert.True(t, ok)
+	assert.Equal(t, rtapi.Error_BAD_INPUT, code)
+
+	_, ok = socketErrorCode(newError("timeout"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,11 @@
+package nakama
+
+// isEmpty reports whether the optional string is missing, nil or "". The required parameters are checked with it.
+func isEmpty(s *string) bool {
+	return s == nil || *s == ""
+}
+
+// hasValue reports whether the optional parameter is set, false and 0 are values, e.g. to send reset=false.
+func hasValue[T any](v *T) bool {
+	return v != nil
+}
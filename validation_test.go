@@ -0,0 +1,84 @@
+package nakama
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsEmpty(t *testing.T) {
+	empty, value := "", "x"
+	assert.True(t, isEmpty(nil))
+	assert.True(t, isEmpty(&empty))
+	assert.False(t, isEmpty(&value))
+
+	no, yes := false, true
+	assert.False(t, hasValue[bool](nil))
+	assert.True(t, hasValue(&no), "false is a value")
+	assert.True(t, hasValue(&yes))
+}
+
+// emptyArgs returns the empty values of a string parameter of a call, nil and "" for a *string.
+func emptyArgs(t reflect.Type) []reflect.Value {
+	switch {
+	case t.Kind() == reflect.String:
+		return []reflect.Value{reflect.Zero(t)}
+	case t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.String:
+		return []reflect.Value{reflect.Zero(t), reflect.New(t.Elem())}
+	}
+	return nil
+}
+
+func TestRequiredParams(t *testing.T) {
+	sent := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Method + " " + r.URL.Path
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	transport := &contextTransport{}
+	client := reflect.ValueOf(&NakamaApi{BasePath: server.URL, HttpClient: &http.Client{Transport: transport}})
+
+	// the calls with all their parameters reach the server, e.g. UpdateGroup, LeaveGroup and WriteTournamentRecord2
+	// rejected a valid id with their inverted checks, and their requests carry their endpoint
+	for _, endpoint := range Endpoints() {
+		call := client.MethodByName(endpoint.Name)
+		args := []reflect.Value{}
+		for j := range call.Type().NumIn() {
+			args = append(args, endpointArg(call.Type().In(j)))
+		}
+		sent = ""
+		call.Call(args)
+		assert.NotEmpty(t, sent, endpoint.Name)
+		assert.Equal(t, endpoint.Name+"#1", transport.calls[len(transport.calls)-1])
+	}
+
+	// an empty parameter is either rejected before the network or isn't part of the path
+	for _, endpoint := range Endpoints() {
+		call := client.MethodByName(endpoint.Name)
+		for i := range call.Type().NumIn() {
+			for _, empty := range emptyArgs(call.Type().In(i)) {
+				args := []reflect.Value{}
+				for j := range call.Type().NumIn() {
+					args = append(args, endpointArg(call.Type().In(j)))
+				}
+				args[i] = empty
+
+				sent = ""
+				results := call.Call(args)
+				err, _ := results[len(results)-1].Interface().(error)
+				if sent == "" {
+					assert.Error(t, err, "%s argument %d", endpoint.Name, i)
+					continue
+				}
+				method, path, _ := strings.Cut(sent, " ")
+				_, ok := endpoint.Match(method, path)
+				assert.True(t, ok, "%s argument %d sent %s", endpoint.Name, i, sent)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package nakama
+
+import (
+	"runtime/debug"
+)
+
+// ModulePath is the module path of the SDK.
+const ModulePath = "github.com/NorthNorthGames/nakama-go"
+
+// develVersion is reported when the SDK is built from a source tree without module version, e.g. its own tests.
+const develVersion = "(devel)"
+
+// Version returns the version of the SDK linked in the binary, e.g. "v1.4.0", read from the build info.
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return develVersion
+	}
+	if info.Main.Path == ModulePath && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != ModulePath {
+			continue
+		}
+		if dep.Replace != nil && dep.Replace.Version != "" {
+			return dep.Replace.Version
+		}
+		return dep.Version
+	}
+	return develVersion
+}
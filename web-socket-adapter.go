@@ -2,25 +2,55 @@ package nakama
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/coder/websocket"
-	"github.com/gwaylib/errors"
 	"github.com/gwaylib/log"
 	"github.com/heroiclabs/nakama-common/rtapi"
-	"google.golang.org/protobuf/encoding/protojson"
 )
 
+// DefaultMaxMessageSize is the default limit of an inbound message in bytes.
+const DefaultMaxMessageSize = 32768
+
+var (
+	// ErrMessageTooBig is reported to the error handler when an inbound message exceeds MaxMessageSize,
+	// the connection is closed by the adapter.
+	ErrMessageTooBig = newError("websocket message too big")
+)
+
+// WebSocketOptions tunes the memory used by a WebSocketAdapter and its handshake.
+type WebSocketOptions struct {
+	ReadBufferSize  int   // The read buffer of the http transport in bytes, 0 uses the default.
+	WriteBufferSize int   // The write buffer of the http transport in bytes, 0 uses the default.
+	MaxMessageSize  int64 // The limit of an inbound message in bytes, 0 uses DefaultMaxMessageSize, -1 disables it.
+
+	// Header is added to the handshake request, e.g. the auth cookie or token of a reverse proxy.
+	Header http.Header
+	// Subprotocols are offered in the handshake, see WebSocketAdapter.Subprotocol for the negotiated one.
+	Subprotocols []string
+}
+
 // WebSocketAdapter is a text-based WebSocket adapter for transmitting payloads over UTF-8.
 type WebSocketAdapter struct {
-	uri       string
-	socket    *websocket.Conn
-	onError   func(err error)
-	onMessage func(mType int, message []byte)
-	mu        sync.Mutex // To guard websocket connection reference
+	uri          string
+	socket       *websocket.Conn
+	options      WebSocketOptions
+	tlsConfig    *tls.Config
+	faults       *FaultInjector
+	outbound     *OutboundLog
+	format       SocketFormat // SocketFormatJSON if empty
+	proxy        ProxyFunc    // the proxy of the environment if nil
+	onError      func(err error)
+	onDisconnect func(reason *DisconnectReason) // called before onError when the connection ends
+	onMessage    func(mType int, message []byte)
+	lifecycle    *Lifecycle // runs the read loops, nil runs them unmanaged
+	mu           sync.Mutex // To guard websocket connection reference
 }
 
 // NewWebSocketAdapterText creates a new instance of WebSocketAdapter.
@@ -36,6 +66,74 @@ func NewWebSocketAdapterText(scheme, host, port string, createStatus bool, token
 	}
 }
 
+// SetOptions sets the buffer sizes, the message limit and the handshake options, it applies to the next connection.
+func (w *WebSocketAdapter) SetOptions(options WebSocketOptions) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.options = options
+}
+
+// SetFaultInjector sets the faults injected in the next connection, nil injects nothing.
+func (w *WebSocketAdapter) SetFaultInjector(faults *FaultInjector) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.faults = faults
+}
+
+// SetToken sets the session token of the next connection.
+func (w *WebSocketAdapter) SetToken(token string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	u, err := url.Parse(w.uri)
+	if err != nil {
+		return
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	w.uri = u.String()
+}
+
+// SetFormat sets the encoding of the messages of the next connection.
+func (w *WebSocketAdapter) SetFormat(format SocketFormat) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	u, err := url.Parse(w.uri)
+	if err != nil {
+		return
+	}
+	query := u.Query()
+	if format == SocketFormatProtobuf {
+		query.Set("format", string(format))
+	} else {
+		query.Del("format")
+	}
+	u.RawQuery = query.Encode()
+	w.uri = u.String()
+	w.format = format
+}
+
+// SetOutboundLog sets the log of the messages sent, nil stops the logging.
+func (w *WebSocketAdapter) SetOutboundLog(outbound *OutboundLog) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.outbound = outbound
+}
+
+// SetTLSConfig sets the TLS configuration used by the next connection.
+func (w *WebSocketAdapter) SetTLSConfig(config *tls.Config) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tlsConfig = config
+}
+
+func (w *WebSocketAdapter) maxMessageSize() int64 {
+	if w.options.MaxMessageSize == 0 {
+		return DefaultMaxMessageSize
+	}
+	return w.options.MaxMessageSize
+}
+
 // IsOpen determines if the WebSocket connection is open.
 func (w *WebSocketAdapter) IsOpen() bool {
 	w.mu.Lock()
@@ -43,6 +141,16 @@ func (w *WebSocketAdapter) IsOpen() bool {
 	return w.socket != nil
 }
 
+// Subprotocol returns the subprotocol negotiated by the current connection, empty if none.
+func (w *WebSocketAdapter) Subprotocol() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.socket == nil {
+		return ""
+	}
+	return w.socket.Subprotocol()
+}
+
 // Close closes the WebSocket connection.
 func (w *WebSocketAdapter) Close() {
 	w.mu.Lock()
@@ -53,26 +161,76 @@ func (w *WebSocketAdapter) Close() {
 	}
 }
 
+// Drop ends the connection at once without the close handshake, e.g. a half-dead connection,
+// the disconnect is reported like a lost connection.
+func (w *WebSocketAdapter) Drop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.socket != nil {
+		_ = w.socket.CloseNow()
+		w.socket = nil
+	}
+}
+
 // Connect connects to the WebSocket using the specified arguments.
 func (w *WebSocketAdapter) Connect() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
 	if len(w.uri) == 0 {
-		return errors.New("uri not set")
+		return newError("uri not set")
 	}
 
 	var err error
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	w.socket, _, err = websocket.Dial(ctx, w.uri, nil)
+	dialOptions := &websocket.DialOptions{
+		HTTPHeader:   w.options.Header.Clone(),
+		Subprotocols: w.options.Subprotocols,
+	}
+	if w.options.ReadBufferSize > 0 || w.options.WriteBufferSize > 0 || w.tlsConfig != nil || w.proxy != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.ReadBufferSize = w.options.ReadBufferSize
+		transport.WriteBufferSize = w.options.WriteBufferSize
+		if w.tlsConfig != nil {
+			transport.TLSClientConfig = w.tlsConfig.Clone()
+		}
+		if w.proxy != nil {
+			transport.Proxy = w.proxy
+		}
+		dialOptions.HTTPClient = &http.Client{Transport: transport}
+	}
+	w.socket, _, err = websocket.Dial(ctx, w.uri, dialOptions)
 	if err != nil {
 		return err
 	}
+	w.socket.SetReadLimit(w.maxMessageSize())
 
-	go w.listen()
-
+	conn := w.socket
+	done := make(chan struct{})
+	if w.lifecycle == nil {
+		if w.faults != nil {
+			go w.faults.injectDisconnects(conn, done)
+		}
+		go w.listen(context.Background(), conn, done)
+		return nil
+	}
+	err = w.lifecycle.Go(func(ctx context.Context) error {
+		w.listen(ctx, conn, done)
+		return nil
+	})
+	if err != nil {
+		w.socket = nil
+		conn.CloseNow()
+		return err
+	}
+	if w.faults != nil {
+		w.lifecycle.Go(func(ctx context.Context) error {
+			w.faults.injectDisconnects(conn, done)
+			return nil
+		})
+	}
 	return nil
 }
 
@@ -82,20 +240,24 @@ func (w *WebSocketAdapter) Send(message *rtapi.Envelope) error {
 	defer w.mu.Unlock()
 
 	if w.socket == nil {
-		return fmt.Errorf("WebSocket is not connected")
+		return ErrNotConnected
+	}
+	if err := w.outbound.Append(message); err != nil {
+		// the log must not stop the game
+		GetLogger().Warnf("outbound log: %s", err.Error())
 	}
 
-	//msgBytes, err := json.Marshal(message)
-	msgBytes, err := protojson.Marshal(message)
+	msgBytes, mType, err := marshalFormat(w.format, message)
 	if err != nil {
-		return errors.As(err)
+		return wrapErr(err)
 	}
+	defer releaseEnvelope(msgBytes)
 
 	// ctx, cancel := context.WithCancel(context.Background())
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	if err := w.socket.Write(ctx, websocket.MessageText, msgBytes); err != nil {
-		return errors.As(err)
+	if err := w.socket.Write(ctx, mType, *msgBytes); err != nil {
+		return wrapErr(err)
 	}
 
 	return nil
@@ -107,7 +269,7 @@ func (w *WebSocketAdapter) Read() ([]byte, error) {
 	defer w.mu.Unlock()
 
 	if w.socket == nil {
-		return nil, fmt.Errorf("WebSocket is not connected")
+		return nil, ErrNotConnected
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -121,24 +283,36 @@ func (w *WebSocketAdapter) Read() ([]byte, error) {
 	return message, nil
 }
 
-// listen listens for messages or errors from the WebSocket server.
-func (w *WebSocketAdapter) listen() {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+// isReadLimited tells if the read failed on a message over the read limit, which the websocket library
+// reports with an untyped "failed to read: read limited at N bytes" error.
+func isReadLimited(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "read limited at")
+}
+
+// listen listens for messages or errors from the connection until it ends or ctx is done.
+func (w *WebSocketAdapter) listen(ctx context.Context, conn *websocket.Conn, done chan struct{}) {
+	defer close(done)
 	for {
-		mType, message, err := w.socket.Read(ctx)
+		mType, message, err := conn.Read(ctx)
 		if err != nil {
 			w.mu.Lock()
-			socket := w.socket
+			current := w.socket == conn
 			w.mu.Unlock()
 
+			reason := disconnectReasonOf(err)
 			closeStatus := websocket.CloseStatus(err)
+			if closeStatus == websocket.StatusMessageTooBig || isReadLimited(err) {
+				err = ErrMessageTooBig.With(w.maxMessageSize(), err.Error())
+			}
 
-			if socket != nil {
+			if current {
 				w.Close()
 			}
+			if w.onDisconnect != nil {
+				w.onDisconnect(reason)
+			}
 			if w.onError != nil {
-				w.onError(errors.As(err, closeStatus))
+				w.onError(wrapErr(err, closeStatus))
 			} else {
 				log.Infof("WebSocket closed with status: %d, cause:%s", closeStatus, err.Error())
 			}
@@ -148,6 +322,9 @@ func (w *WebSocketAdapter) listen() {
 			// message handler not set
 			continue
 		}
+		if w.faults.dropSocketMessage() {
+			continue
+		}
 		w.onMessage(int(mType), message)
 		continue
 	}
@@ -0,0 +1,123 @@
+package nakama
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebSocketAdapterHandshake(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Gateway-Token") != "gw-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{"nakama.v2"}})
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		conn.Read(r.Context())
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+
+	adapter := NewWebSocketAdapterText("ws://", host, port, false, "token")
+	assert.Error(t, adapter.Connect(), "the gateway should refuse the handshake without its token")
+
+	adapter.SetOptions(WebSocketOptions{
+		Header:       http.Header{"X-Gateway-Token": []string{"gw-secret"}},
+		Subprotocols: []string{"nakama.v1", "nakama.v2"},
+	})
+	assert.NoError(t, adapter.Connect())
+	defer adapter.Close()
+	assert.Equal(t, "nakama.v2", adapter.Subprotocol())
+}
+
+func TestSocketDisconnectReason(t *testing.T) {
+	var accepted atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		accepted.Add(1)
+		conn.Close(websocket.StatusNormalClosure, "Session expired")
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+
+	reasons := make(chan *DisconnectReason, 1)
+	socket := NewDefaultSocket(nil, host, port, "token", false, false, nil, nil)
+	socket.SetReconnectPolicy(ReconnectPolicy{Interval: time.Millisecond})
+	socket.SetOnDisconnect(func(reason *DisconnectReason) { reasons <- reason })
+	assert.NoError(t, socket.Connect())
+	defer socket.Disconnect()
+
+	select {
+	case reason := <-reasons:
+		assert.Equal(t, DisconnectSessionExpired, reason.Kind)
+		assert.Equal(t, int(websocket.StatusNormalClosure), reason.Code)
+		assert.Equal(t, "Session expired", reason.Reason)
+		assert.False(t, reason.Reconnectable())
+	case <-time.After(5 * time.Second):
+		t.Fatal("no disconnect reason")
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), accepted.Load(), "an expired session should not reconnect")
+}
+
+func TestDisconnectReasonOf(t *testing.T) {
+	reason := disconnectReasonOf(websocket.CloseError{Code: websocket.StatusGoingAway, Reason: ""})
+	assert.Equal(t, DisconnectServerShutdown, reason.Kind)
+	assert.True(t, reason.Reconnectable())
+
+	reason = disconnectReasonOf(websocket.CloseError{Code: websocket.StatusNormalClosure, Reason: "Duplicate connection"})
+	assert.Equal(t, DisconnectDuplicate, reason.Kind)
+
+	reason = disconnectReasonOf(websocket.CloseError{Code: websocket.StatusNormalClosure, Reason: "server-side session disconnect"})
+	assert.Equal(t, DisconnectDuplicate, reason.Kind)
+	reason = disconnectReasonOf(websocket.CloseError{Code: websocket.StatusNormalClosure, Reason: "joined another match"})
+	assert.Equal(t, DisconnectNormal, reason.Kind)
+
+	reason = disconnectReasonOf(fmt.Errorf("failed to read: %w", errors.New("read limited at 1025 bytes")))
+	assert.Equal(t, DisconnectMessageTooBig, reason.Kind)
+
+	reason = disconnectReasonOf(io.EOF)
+	assert.Equal(t, DisconnectUnknown, reason.Kind)
+	assert.Equal(t, -1, reason.Code)
+}
+
+func TestWebSocketAdapterMessageTooBig(t *testing.T) {
+	server := newScriptedServer(t, nil)
+	adapter := NewWebSocketAdapterText("ws://", server.host, server.port, false, "token")
+	adapter.SetOptions(WebSocketOptions{MaxMessageSize: 1024})
+	errs := make(chan error, 1)
+	adapter.onError = func(err error) { errs <- err }
+	assert.NoError(t, adapter.Connect())
+	defer adapter.Close()
+
+	eventually(t, func() bool { return server.accepted.Load() == 1 }, "the connection has not been accepted")
+	server.conn(0).WriteRaw([]byte(`{"rpc":{"payload":"` + strings.Repeat("x", 2048) + `"}}`))
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrMessageTooBig)
+	case <-time.After(2 * time.Second):
+		t.Fatal("the connection has not failed")
+	}
+}
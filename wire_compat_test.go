@@ -0,0 +1,69 @@
+package nakama
+
+//go:generate go run ./internal/wirefixtures -out testdata/wire
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NorthNorthGames/nakama-go/internal/wire"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestWireCompat decodes the fixtures of every nakama-common version with the decoder of the client,
+// and checks that they survive a round trip through the encoder of the socket.
+func TestWireCompat(t *testing.T) {
+	root := filepath.Join("testdata", "wire")
+	versions, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := wire.CommonVersion()
+	if _, err := os.Stat(filepath.Join(root, current)); err != nil {
+		t.Errorf("no fixtures for nakama-common %s, run go generate", current)
+	}
+
+	messages := wire.Messages()
+	for _, version := range versions {
+		files, err := filepath.Glob(filepath.Join(root, version.Name(), "*.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range files {
+			name := strings.TrimSuffix(filepath.Base(file), ".json")
+			t.Run(version.Name()+"/"+name, func(t *testing.T) {
+				expected, ok := messages[name]
+				if !ok {
+					t.Fatalf("no message for the fixture %s", name)
+				}
+				data, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				decoded := expected.ProtoReflect().New().Interface()
+				if err := protojson.Unmarshal(data, decoded); err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				if version.Name() == current && !proto.Equal(decoded, expected) {
+					t.Fatalf("decoded %v, expected %v", decoded, expected)
+				}
+
+				encoded, err := protojson.Marshal(decoded)
+				if err != nil {
+					t.Fatalf("encode: %v", err)
+				}
+				again := expected.ProtoReflect().New().Interface()
+				if err := protojson.Unmarshal(encoded, again); err != nil {
+					t.Fatalf("decode again: %v", err)
+				}
+				if !proto.Equal(decoded, again) {
+					t.Fatalf("round trip changed %v into %v", decoded, again)
+				}
+			})
+		}
+	}
+}